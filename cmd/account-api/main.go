@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,7 +12,23 @@ import (
 	"bankmore/internal/account/handlers"
 	"bankmore/internal/account/repository"
 	"bankmore/internal/account/service"
+	incomeReportDomain "bankmore/internal/incomereport/domain"
+	incomeReportHandlers "bankmore/internal/incomereport/handlers"
+	incomeReportRepository "bankmore/internal/incomereport/repository"
+	incomeReportService "bankmore/internal/incomereport/service"
+	ledgerDomain "bankmore/internal/ledger/domain"
+	ledgerHandlers "bankmore/internal/ledger/handlers"
+	ledgerRepository "bankmore/internal/ledger/repository"
+	ledgerServices "bankmore/internal/ledger/service"
+	notificationDomain "bankmore/internal/notification/domain"
+	notificationHandlers "bankmore/internal/notification/handlers"
+	notificationRepository "bankmore/internal/notification/repository"
+	notificationService "bankmore/internal/notification/service"
+	"bankmore/internal/shared/idempotency"
+	"bankmore/internal/shared/kafka"
 	"bankmore/internal/shared/middleware"
+	"bankmore/internal/shared/notification"
+	"bankmore/internal/shared/realtime"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -58,14 +73,81 @@ func main() {
 		logger.WithError(err).Fatal("Failed to connect to database")
 	}
 
-	if err := db.AutoMigrate(&domain.Account{}, &domain.Movement{}, &domain.Idempotency{}); err != nil {
+	if err := db.AutoMigrate(&domain.Account{}, &domain.Movement{}, &domain.Idempotency{}, &domain.RecoveryCode{}, &domain.Operator{}, &domain.AccountStatusChange{}, &domain.BalanceBlock{}, &domain.BlockRelease{}, &domain.Hold{}, &domain.PixKey{}, &idempotency.Record{}, &notificationDomain.Preference{}, &ledgerDomain.LedgerAccount{}, &ledgerDomain.JournalEntry{}, &ledgerDomain.Posting{}, &ledgerDomain.AccountBalanceRecord{}, &ledgerDomain.BalanceSnapshot{}, &ledgerDomain.BalanceDrift{}, &ledgerDomain.AccountingPeriod{}, &ledgerDomain.PeriodAccountTotal{}, &incomeReportDomain.IncomeReport{}); err != nil {
 		logger.WithError(err).Fatal("Failed to migrate database")
 	}
 
+	ledgerService := ledgerServices.NewLedgerService(ledgerRepository.NewLedgerRepository(db), logger)
+	if err := ledgerService.Initialize(); err != nil {
+		logger.WithError(err).Fatal("Failed to initialize ledger")
+	}
+	ledgerHandler := ledgerHandlers.NewLedgerHandler(ledgerService, logger)
+
+	producer, err := kafka.NewProducer(logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create Kafka producer")
+	}
+	defer producer.Close()
+
+	instanceID := realtime.GetInstanceID()
+	broker := realtime.NewBroker(realtime.GetMaxConnections(), realtime.GetMaxConnectionsPerAccount(), logger)
+	relay := realtime.NewRelay(broker, producer, instanceID, logger)
+	realtimeHandler := handlers.NewRealtimeHandler(broker, logger)
+
+	// Cada instância consome em um grupo próprio para receber todos os eventos.
+	consumer, err := kafka.NewTopicConsumer("account-realtime-"+instanceID, realtime.Topics(), relay, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create Kafka consumer")
+	}
+	defer consumer.Close()
+
+	consumerCtx, cancelConsumer := context.WithCancel(context.Background())
+	defer cancelConsumer()
+
+	go func() {
+		logger.Info("Starting realtime Kafka consumer")
+		if err := consumer.Start(consumerCtx); err != nil {
+			logger.WithError(err).Error("Kafka consumer error")
+		}
+	}()
+
 	accountRepo := repository.NewAccountRepository(db)
-	accountService := service.NewAccountService(accountRepo, logger)
+	accountService := service.NewAccountService(accountRepo, relay, logger)
 	accountHandler := handlers.NewAccountHandler(accountService, logger)
 
+	operatorRepo := repository.NewOperatorRepository(db)
+	operatorService := service.NewOperatorService(operatorRepo, logger)
+	operatorHandler := handlers.NewOperatorHandler(operatorService, logger)
+
+	blockRepo := repository.NewBlockRepository(db)
+	blockService := service.NewBlockService(accountRepo, blockRepo, logger)
+	blockHandler := handlers.NewBlockHandler(blockService, logger)
+
+	statementRepo := repository.NewStatementRepository(db)
+	statementService := service.NewStatementService(accountRepo, statementRepo, logger)
+	statementHandler := handlers.NewStatementHandler(statementService, logger)
+
+	reportService := incomeReportService.NewIncomeReportService(incomeReportRepository.NewIncomeReportRepository(db), logger)
+	incomeReportHandler := incomeReportHandlers.NewIncomeReportHandler(reportService, logger)
+
+	holdRepo := repository.NewHoldRepository(db)
+	holdService := service.NewHoldService(accountRepo, holdRepo, logger)
+	holdHandler := handlers.NewHoldHandler(holdService, logger)
+
+	pixKeyRepo := repository.NewPixKeyRepository(db)
+	pixKeyService := service.NewPixKeyService(accountRepo, pixKeyRepo, notification.NewLogNotifier(logger), logger)
+	pixKeyHandler := handlers.NewPixKeyHandler(pixKeyService, logger)
+
+	preferenceService := notificationService.NewPreferenceService(notificationRepository.NewNotificationRepository(db), logger)
+	preferenceHandler := notificationHandlers.NewPreferenceHandler(preferenceService, logger)
+
+	if err := operatorService.EnsureBootstrapAdmin(os.Getenv("ADMIN_LOGIN"), os.Getenv("ADMIN_PASSWORD")); err != nil {
+		logger.WithError(err).Fatal("Failed to create bootstrap admin operator")
+	}
+
+	idempotencyStore := idempotency.NewStore(db)
+	idempotent := idempotency.Middleware(idempotencyStore, "account-api", logger)
+
 	router := gin.New()
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
@@ -73,7 +155,7 @@ func main() {
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Idempotency-Key, Prefer")
 		
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -87,15 +169,94 @@ func main() {
 	{
 		api.POST("/register", accountHandler.Register)
 		api.POST("/login", accountHandler.Login)
-		api.GET("/exists/:accountNumber", accountHandler.AccountExists)
-		api.GET("/balance/:accountNumber", accountHandler.GetBalanceByAccountNumber)
+		api.POST("/login/2fa", accountHandler.CompleteTwoFactorLogin)
+		api.POST("/operators/login", operatorHandler.Login)
+
+		lookup := api.Group("")
+		lookup.Use(middleware.JWTMiddleware(), middleware.RequireScope(middleware.ScopeAccountsRead))
+		{
+			lookup.GET("/exists/:accountNumber", accountHandler.AccountExists)
+			lookup.GET("/balance/:accountNumber", accountHandler.GetBalanceByAccountNumber)
+		}
+
+		movements := api.Group("")
+		movements.Use(middleware.JWTMiddleware(), middleware.RequireScope(middleware.ScopeMovementsWrite), idempotent)
+		{
+			movements.POST("/movement", accountHandler.CreateMovement)
+		}
+
+		keyLookup := api.Group("/keys")
+		keyLookup.Use(middleware.JWTMiddleware(), middleware.RequireRole(middleware.RoleCustomer, middleware.RoleService))
+		{
+			keyLookup.GET("/resolve", pixKeyHandler.ResolveKey)
+		}
+
+		holds := api.Group("/holds")
+		holds.Use(middleware.JWTMiddleware(), middleware.RequireScope(middleware.ScopeHoldsManage), idempotent)
+		{
+			holds.POST("", holdHandler.PlaceHold)
+			holds.GET("/:holdId", holdHandler.GetHold)
+			holds.POST("/:holdId/capture", holdHandler.CaptureHold)
+			holds.POST("/:holdId/void", holdHandler.VoidHold)
+		}
+
+		operators := api.Group("/operators")
+		operators.Use(middleware.JWTMiddleware(), middleware.RequireScope(middleware.ScopeOperatorsManage), idempotent)
+		{
+			operators.POST("", operatorHandler.Create)
+			operators.GET("", operatorHandler.List)
+		}
+
+		ledger := api.Group("/ledger")
+		ledger.Use(middleware.JWTMiddleware(), middleware.RequireScope(middleware.ScopeLedgerRead))
+		{
+			ledger.GET("/trial-balance", ledgerHandler.GetTrialBalance)
+			ledger.GET("/entries/:entryId", ledgerHandler.GetEntry)
+			ledger.GET("/accounts/:code/entries", ledgerHandler.GetAccountEntries)
+			ledger.GET("/accounts/:code/balance", ledgerHandler.GetAccountBalanceAt)
+			ledger.GET("/drifts", ledgerHandler.ListDrifts)
+			ledger.POST("/consistency-check", middleware.RequireScope(middleware.ScopeLedgerManage), ledgerHandler.CheckConsistency)
+			ledger.POST("/eod", middleware.RequireScope(middleware.ScopeLedgerManage), ledgerHandler.RunEndOfDay)
+			ledger.GET("/periods", ledgerHandler.ListPeriods)
+			ledger.GET("/periods/:date", ledgerHandler.GetPeriod)
+		}
+
+		admin := api.Group("/admin/:accountNumber")
+		admin.Use(middleware.JWTMiddleware(), idempotent)
+		{
+			admin.PUT("/reactivate", middleware.RequireScope(middleware.ScopeAccountsManage), accountHandler.ReactivateAccount)
+			admin.PUT("/block", middleware.RequireScope(middleware.ScopeAccountsManage), accountHandler.BlockAccount)
+			admin.GET("/history", middleware.RequireScope(middleware.ScopeAccountsRead), accountHandler.GetStatusHistory)
+			admin.POST("/blocks", middleware.RequireScope(middleware.ScopeBlocksManage), blockHandler.CreateBlock)
+			admin.GET("/blocks", middleware.RequireScope(middleware.ScopeAccountsRead), blockHandler.ListBlocks)
+			admin.POST("/blocks/:blockId/release", middleware.RequireScope(middleware.ScopeBlocksManage), blockHandler.ReleaseBlock)
+		}
 
 		protected := api.Group("")
-		protected.Use(middleware.JWTMiddleware())
+		protected.Use(middleware.JWTMiddleware(), middleware.RequireRole(middleware.RoleCustomer))
 		{
-			protected.PUT("/deactivate", accountHandler.Deactivate)
-			protected.POST("/movement", accountHandler.CreateMovement)
+			protected.PUT("/deactivate", idempotent, accountHandler.Deactivate)
+			protected.POST("/close", idempotent, accountHandler.CloseAccount)
 			protected.GET("/balance", accountHandler.GetBalance)
+			protected.GET("/balance/daily", ledgerHandler.GetCustomerBalanceAt)
+			protected.GET("/statement/export", statementHandler.ExportStatement)
+			protected.GET("/income-report", incomeReportHandler.ListReports)
+			protected.GET("/income-report/:year", incomeReportHandler.GetReport)
+			protected.GET("/events", realtimeHandler.StreamEvents)
+
+			protected.POST("/2fa/enroll", accountHandler.EnrollTwoFactor)
+			protected.POST("/2fa/confirm", accountHandler.ConfirmTwoFactor)
+			protected.POST("/2fa/disable", accountHandler.DisableTwoFactor)
+			protected.POST("/2fa/recovery-codes", accountHandler.RegenerateRecoveryCodes)
+			protected.POST("/2fa/verify", accountHandler.VerifyTwoFactor)
+
+			protected.POST("/keys", idempotent, pixKeyHandler.RegisterKey)
+			protected.GET("/keys", pixKeyHandler.ListKeys)
+			protected.POST("/keys/:keyId/confirm", idempotent, pixKeyHandler.ConfirmKey)
+			protected.DELETE("/keys/:keyId", idempotent, pixKeyHandler.DeleteKey)
+
+			protected.GET("/notifications/preferences", preferenceHandler.GetPreferences)
+			protected.PUT("/notifications/preferences", idempotent, preferenceHandler.UpdatePreferences)
 		}
 	}
 
@@ -126,10 +287,22 @@ func main() {
 		}
 	}()
 
+	stopSweeper := make(chan struct{})
+	service.StartHoldSweeper(holdService, service.GetHoldSweepInterval(), stopSweeper)
+	idempotency.StartCleanup(idempotencyStore, logger, time.Hour, stopSweeper)
+	realtime.StartHistoryCleanup(broker, time.Minute, stopSweeper)
+	ledgerServices.StartSnapshotWorker(ledgerService, ledgerServices.GetSnapshotInterval(), stopSweeper)
+	ledgerServices.StartConsistencyChecker(ledgerService, ledgerServices.GetConsistencyInterval(), stopSweeper)
+	ledgerServices.StartEndOfDayWorker(ledgerService, ledgerServices.GetEndOfDayInterval(), stopSweeper)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	close(stopSweeper)
+	cancelConsumer()
+	broker.Close()
+
 	logger.Info("Shutting down Account API server...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"bankmore/internal/shared/cnab"
+
+	"github.com/sirupsen/logrus"
+)
+
+// cnab valida um arquivo de remessa ou retorno CNAB 240/400 sem acessar o banco de
+// dados nem as APIs: lista os erros por linha e posição ou, se o arquivo estiver
+// válido, um resumo dos pagamentos. Termina com código 1 quando há erros, para uso em
+// scripts antes do envio pela API de transferências.
+func main() {
+	path := flag.String("file", "", "arquivo CNAB a validar")
+	flag.Parse()
+
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetLevel(logrus.InfoLevel)
+
+	if *path == "" && flag.NArg() > 0 {
+		*path = flag.Arg(0)
+	}
+	if *path == "" {
+		logger.Fatal("CNAB file path is required (-file)")
+	}
+
+	content, err := os.ReadFile(*path)
+	if err != nil {
+		logger.WithError(err).WithField("file", *path).Fatal("Failed to read CNAB file")
+	}
+
+	file, err := cnab.Parse(content)
+	if err != nil {
+		var validationErr *cnab.ValidationError
+		if errors.As(err, &validationErr) {
+			fmt.Printf("%s: %s\n", *path, validationErr.Error())
+			for _, fieldErr := range validationErr.Errors {
+				fmt.Printf("  %s\n", fieldErr.String())
+			}
+			os.Exit(1)
+		}
+		logger.WithError(err).WithField("file", *path).Fatal("Failed to parse CNAB file")
+	}
+
+	fmt.Printf("%s: CNAB %s, %s %d, banco %s, conta %s\n", *path, file.Layout, file.Kind, file.Sequence, file.BankCode, file.AccountNumber)
+	for _, payment := range file.Payments {
+		target := payment.Barcode
+		if payment.Type == cnab.PaymentTransfer {
+			target = fmt.Sprintf("%s/%s", payment.DestinationBank, payment.DestinationAccount)
+		}
+		fmt.Printf("  linha %d: %s %s %.2f em %s", payment.Line, payment.Type, target, payment.Amount, payment.PaymentDate.Format("02/01/2006"))
+		for _, code := range payment.Occurrences {
+			fmt.Printf(" [%s %s]", code, cnab.OccurrenceDescription(code))
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%d pagamento(s), total %.2f\n", len(file.Payments), file.TotalAmount())
+}
@@ -13,6 +13,7 @@ import (
 	"bankmore/internal/fee/repository"
 	"bankmore/internal/fee/service"
 	"bankmore/internal/shared/kafka"
+	"bankmore/internal/shared/middleware"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -37,6 +38,11 @@ import (
 // @host localhost:8003
 // @BasePath /
 
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and JWT token.
+
 func main() {
 	logger := logrus.New()
 	logger.SetFormatter(&logrus.JSONFormatter{})
@@ -57,7 +63,13 @@ func main() {
 	}
 
 	feeRepo := repository.NewFeeRepository(db)
-	feeService := service.NewFeeService(feeRepo, logger)
+	producer, err := kafka.NewProducer(logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create Kafka producer")
+	}
+	defer producer.Close()
+
+	feeService := service.NewFeeService(feeRepo, producer, logger)
 	feeHandler := handlers.NewFeeHandler(feeService, logger)
 
 	consumer, err := kafka.NewConsumer("fee-service", feeService, logger)
@@ -76,6 +88,9 @@ func main() {
 		}
 	}()
 
+	stopBilling := make(chan struct{})
+	service.StartBillingWorker(feeService, service.GetBillingInterval(), stopBilling)
+
 	router := gin.New()
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
@@ -94,9 +109,17 @@ func main() {
 	})
 
 	api := router.Group("/api/fee")
+	api.Use(middleware.JWTMiddleware())
 	{
-		api.GET("/:accountNumber", feeHandler.GetFeesByAccount)
-		api.GET("/fee/:id", feeHandler.GetFeeByID)
+		api.GET("", middleware.RequireRole(middleware.RoleCustomer), feeHandler.GetOwnFees)
+
+		lookup := api.Group("")
+		lookup.Use(middleware.RequireScope(middleware.ScopeFeesRead))
+		{
+			lookup.GET("/quote", feeHandler.GetFeeQuote)
+			lookup.GET("/:accountNumber", feeHandler.GetFeesByAccount)
+			lookup.GET("/fee/:id", feeHandler.GetFeeByID)
+		}
 	}
 
 	router.GET("/health", func(c *gin.Context) {
@@ -132,6 +155,7 @@ func main() {
 
 	logger.Info("Shutting down Fee API server...")
 	cancel()
+	close(stopBilling)
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"time"
+
+	"bankmore/internal/incomereport/domain"
+	"bankmore/internal/incomereport/repository"
+	"bankmore/internal/incomereport/service"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// income-report gera o informe de rendimentos de todas as contas com saldo ou tarifas no
+// ano-calendário e grava um arquivo por conta. Sem -year, usa o ano anterior; pensado
+// para rodar no início de fevereiro, antes do prazo da declaração. Os informes também
+// ficam no banco para o download pela API de contas.
+func main() {
+	year := flag.Int("year", time.Now().Year()-1, "ano-calendário do informe")
+	format := flag.String("format", "pdf", "formato dos arquivos: pdf ou json")
+	output := flag.String("output", "./informes", "diretório dos arquivos (vazio: só grava no banco)")
+	flag.Parse()
+
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetLevel(logrus.InfoLevel)
+
+	if _, err := service.ValidateFormat(*format); err != nil {
+		logger.WithField("format", *format).Fatal("Unsupported report format")
+	}
+
+	dbPath := os.Getenv("DB_PATH")
+	if dbPath == "" {
+		dbPath = "./database/bankmore.db"
+	}
+
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to connect to database")
+	}
+
+	if err := db.AutoMigrate(&domain.IncomeReport{}); err != nil {
+		logger.WithError(err).Fatal("Failed to migrate database")
+	}
+
+	if *output != "" {
+		if err := os.MkdirAll(*output, 0o755); err != nil {
+			logger.WithError(err).Fatal("Failed to create output directory")
+		}
+	}
+
+	reportService := service.NewIncomeReportService(repository.NewIncomeReportRepository(db), logger)
+
+	var write func(report *domain.IncomeReport) error
+	if *output != "" {
+		write = func(report *domain.IncomeReport) error {
+			return writeFile(reportService, report, *format, *output)
+		}
+	}
+
+	result, err := reportService.RunBatch(*year, write)
+	if err != nil {
+		logger.WithError(err).Fatal("Income report generation failed")
+	}
+	if result.Failed > 0 {
+		logger.WithField("failed", result.Failed).Fatal("Some income reports could not be generated")
+	}
+}
+
+func writeFile(reportService service.IncomeReportService, report *domain.IncomeReport, format, dir string) error {
+	file, err := os.Create(filepath.Join(dir, service.FileName(report, format)))
+	if err != nil {
+		return err
+	}
+	if err := reportService.WriteReport(report, format, file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"bankmore/internal/notification/domain"
+	"bankmore/internal/notification/repository"
+	"bankmore/internal/notification/service"
+	"bankmore/internal/shared/kafka"
+	"bankmore/internal/shared/notification"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// notification-worker consome os eventos de transferência e tarifa e avisa os clientes
+// pelos canais escolhidos nas preferências de notificação.
+func main() {
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetLevel(logrus.InfoLevel)
+
+	dbPath := os.Getenv("DB_PATH")
+	if dbPath == "" {
+		dbPath = "./database/bankmore.db"
+	}
+
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to connect to database")
+	}
+
+	if err := db.AutoMigrate(&domain.Preference{}, &domain.Notification{}); err != nil {
+		logger.WithError(err).Fatal("Failed to migrate database")
+	}
+
+	notificationRepo := repository.NewNotificationRepository(db)
+	notificationService := service.NewNotificationService(notificationRepo, notification.NewNotifierFromEnv(logger), logger)
+
+	consumer, err := kafka.NewTopicConsumer("notification-service", []string{kafka.TopicTransferEvents, kafka.TopicTransferStatusEvents, kafka.TopicFeeEvents}, notificationService, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create Kafka consumer")
+	}
+	defer consumer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		logger.Info("Starting Kafka consumer")
+		if err := consumer.Start(ctx); err != nil {
+			logger.WithError(err).Error("Kafka consumer error")
+		}
+	}()
+
+	stopDispatcher := make(chan struct{})
+	service.StartDispatcher(notificationService, service.GetDispatchInterval(), stopDispatcher)
+
+	logger.Info("Notification worker started")
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	close(stopDispatcher)
+	cancel()
+
+	logger.Info("Notification worker exited")
+}
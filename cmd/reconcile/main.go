@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"time"
+
+	"bankmore/internal/reconciliation/domain"
+	"bankmore/internal/reconciliation/repository"
+	"bankmore/internal/reconciliation/service"
+	"bankmore/internal/shared/kafka"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// reconcile concilia transferências, movimentos, eventos e tarifas de um período e
+// escreve o relatório em JSON ou CSV. Sem datas, concilia o dia anterior; pensado para
+// rodar uma vez por dia (cron). Com -fix, corrige as quebras seguras antes de escrever.
+func main() {
+	yesterday := time.Now().AddDate(0, 0, -1).Format(domain.DateLayout)
+
+	from := flag.String("from", yesterday, "primeiro dia do período (AAAA-MM-DD)")
+	to := flag.String("to", "", "último dia do período (AAAA-MM-DD, padrão igual a -from)")
+	format := flag.String("format", "json", "formato do relatório: json ou csv")
+	output := flag.String("output", "", "arquivo de saída (padrão: saída padrão)")
+	fix := flag.Bool("fix", false, "corrige as quebras corrigíveis")
+	flag.Parse()
+
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetLevel(logrus.InfoLevel)
+
+	if *to == "" {
+		*to = *from
+	}
+	if *format != "json" && *format != "csv" {
+		logger.WithField("format", *format).Fatal("Unsupported report format")
+	}
+
+	dbPath := os.Getenv("DB_PATH")
+	if dbPath == "" {
+		dbPath = "./database/bankmore.db"
+	}
+
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to connect to database")
+	}
+
+	if err := db.AutoMigrate(&domain.Report{}, &domain.Break{}); err != nil {
+		logger.WithError(err).Fatal("Failed to migrate database")
+	}
+
+	// O produtor só é necessário para republicar eventos perdidos; sem Kafka as demais
+	// correções continuam possíveis.
+	var producer *kafka.Producer
+	if *fix {
+		producer, err = kafka.NewProducer(logger)
+		if err != nil {
+			logger.WithError(err).Error("Failed to create Kafka producer, lost events will not be republished")
+		} else {
+			defer producer.Close()
+		}
+	}
+
+	reconciliationService := service.NewReconciliationService(repository.NewReconciliationRepository(db), producer, logger)
+
+	report, err := reconciliationService.Run(*from, *to)
+	if err != nil {
+		logger.WithError(err).Fatal("Reconciliation failed")
+	}
+
+	if *fix && report.BreakCount > 0 {
+		if _, err := reconciliationService.FixBreaks(report.ID, nil); err != nil {
+			logger.WithError(err).Fatal("Failed to fix reconciliation breaks")
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to create report file")
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := writeReport(reconciliationService, report.ID, *format, out); err != nil {
+		logger.WithError(err).Fatal("Failed to write reconciliation report")
+	}
+}
+
+// writeReport relê o relatório para incluir o resultado das correções.
+func writeReport(reconciliationService service.ReconciliationService, reportID, format string, out io.Writer) error {
+	if format == "csv" {
+		data, err := reconciliationService.GetReportCSV(reportID)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	report, err := reconciliationService.GetReport(reportID)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
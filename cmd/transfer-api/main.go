@@ -8,12 +8,29 @@ import (
 	"syscall"
 	"time"
 
+	boletoDomain "bankmore/internal/boleto/domain"
+	boletoHandlers "bankmore/internal/boleto/handlers"
+	boletoRepository "bankmore/internal/boleto/repository"
+	boletoService "bankmore/internal/boleto/service"
+	cnabDomain "bankmore/internal/cnab/domain"
+	cnabHandlers "bankmore/internal/cnab/handlers"
+	cnabRepository "bankmore/internal/cnab/repository"
+	cnabService "bankmore/internal/cnab/service"
+	reconciliationDomain "bankmore/internal/reconciliation/domain"
+	reconciliationHandlers "bankmore/internal/reconciliation/handlers"
+	reconciliationRepository "bankmore/internal/reconciliation/repository"
+	reconciliationService "bankmore/internal/reconciliation/service"
+	"bankmore/internal/shared/idempotency"
 	"bankmore/internal/shared/kafka"
 	"bankmore/internal/shared/middleware"
 	"bankmore/internal/transfer/domain"
 	"bankmore/internal/transfer/handlers"
 	"bankmore/internal/transfer/repository"
 	"bankmore/internal/transfer/service"
+	webhookDomain "bankmore/internal/webhook/domain"
+	webhookHandlers "bankmore/internal/webhook/handlers"
+	webhookRepository "bankmore/internal/webhook/repository"
+	webhookService "bankmore/internal/webhook/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -58,7 +75,7 @@ func main() {
 		logger.WithError(err).Fatal("Failed to connect to database")
 	}
 
-	if err := db.AutoMigrate(&domain.Transfer{}); err != nil {
+	if err := db.AutoMigrate(&domain.Transfer{}, &domain.Charge{}, &domain.StandingOrder{}, &domain.TransferBatch{}, &domain.TransferBatchItem{}, &idempotency.Record{}, &webhookDomain.Webhook{}, &webhookDomain.Delivery{}, &reconciliationDomain.Report{}, &reconciliationDomain.Break{}, &boletoDomain.Boleto{}, &boletoDomain.Payment{}, &cnabDomain.Remittance{}, &cnabDomain.RemittanceItem{}); err != nil {
 		logger.WithError(err).Fatal("Failed to migrate database")
 	}
 
@@ -69,8 +86,52 @@ func main() {
 	defer producer.Close()
 
 	transferRepo := repository.NewTransferRepository(db)
-	transferService := service.NewTransferService(transferRepo, producer, logger)
+	batchRepo := repository.NewBatchRepository(db)
+	transferService := service.NewTransferService(transferRepo, batchRepo, producer, logger)
 	transferHandler := handlers.NewTransferHandler(transferService, logger)
+	batchHandler := handlers.NewBatchHandler(transferService, logger)
+
+	chargeRepo := repository.NewChargeRepository(db)
+	chargeService := service.NewChargeService(chargeRepo, transferRepo, transferService, logger)
+	chargeHandler := handlers.NewChargeHandler(chargeService, logger)
+
+	standingOrderRepo := repository.NewStandingOrderRepository(db)
+	standingOrderService := service.NewStandingOrderService(standingOrderRepo, transferRepo, transferService, logger)
+	standingOrderHandler := handlers.NewStandingOrderHandler(standingOrderService, logger)
+
+	webhookRepo := webhookRepository.NewWebhookRepository(db)
+	webhooks := webhookService.NewWebhookService(webhookRepo, logger)
+	webhookHandler := webhookHandlers.NewWebhookHandler(webhooks, logger)
+
+	reconciliationRepo := reconciliationRepository.NewReconciliationRepository(db)
+	reconciliation := reconciliationService.NewReconciliationService(reconciliationRepo, producer, logger)
+	reconciliationHandler := reconciliationHandlers.NewReconciliationHandler(reconciliation, logger)
+
+	boletoRepo := boletoRepository.NewBoletoRepository(db)
+	boletos := boletoService.NewBoletoService(boletoRepo, logger)
+	boletoHandler := boletoHandlers.NewBoletoHandler(boletos, logger)
+
+	remittanceRepo := cnabRepository.NewRemittanceRepository(db)
+	remittances := cnabService.NewRemittanceService(remittanceRepo, transferService, boletos, logger)
+	remittanceHandler := cnabHandlers.NewRemittanceHandler(remittances, logger)
+
+	webhookConsumer, err := kafka.NewTopicConsumer("webhook-service", []string{kafka.TopicTransferEvents, kafka.TopicTransferStatusEvents, kafka.TopicFeeEvents}, webhooks, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create Kafka consumer")
+	}
+	defer webhookConsumer.Close()
+
+	consumerCtx, cancelConsumer := context.WithCancel(context.Background())
+	defer cancelConsumer()
+
+	go func() {
+		logger.Info("Starting webhook Kafka consumer")
+		if err := webhookConsumer.Start(consumerCtx); err != nil {
+			logger.WithError(err).Error("Kafka consumer error")
+		}
+	}()
+
+	idempotencyStore := idempotency.NewStore(db)
 
 	router := gin.New()
 	router.Use(gin.Logger())
@@ -79,7 +140,7 @@ func main() {
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Idempotency-Key, Prefer")
 		
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -90,9 +151,102 @@ func main() {
 	})
 
 	api := router.Group("/api/transfer")
-	api.Use(middleware.JWTMiddleware())
+	api.Use(middleware.JWTMiddleware(), idempotency.Middleware(idempotencyStore, "transfer-api", logger))
 	{
-		api.POST("", transferHandler.CreateTransfer)
+		api.POST("", middleware.RequireRole(middleware.RoleCustomer), transferHandler.CreateTransfer)
+		api.POST("/quote", middleware.RequireRole(middleware.RoleCustomer), transferHandler.QuoteTransfer)
+		api.POST("/pay", middleware.RequireRole(middleware.RoleCustomer), chargeHandler.PayByPayload)
+		api.GET("/scheduled", middleware.RequireRole(middleware.RoleCustomer), transferHandler.ListScheduledTransfers)
+		api.DELETE("/scheduled/:id", middleware.RequireRole(middleware.RoleCustomer), transferHandler.CancelScheduledTransfer)
+		api.GET("/:id", middleware.RequireRole(middleware.RoleCustomer), transferHandler.GetTransfer)
+		api.POST("/:id/refund", middleware.RequireRole(middleware.RoleCustomer), transferHandler.RefundTransfer)
+		api.GET("/:id/refunds", middleware.RequireRole(middleware.RoleCustomer), transferHandler.ListRefunds)
+
+		charges := api.Group("/charges")
+		charges.Use(middleware.RequireRole(middleware.RoleCustomer))
+		{
+			charges.POST("/static", chargeHandler.CreateStaticCharge)
+			charges.POST("", chargeHandler.CreateDynamicCharge)
+			charges.GET("", chargeHandler.ListCharges)
+			charges.GET("/:txid", chargeHandler.GetCharge)
+			charges.GET("/:txid/qrcode", chargeHandler.GetChargeQRCode)
+		}
+
+		batches := api.Group("/batch")
+		batches.Use(middleware.RequireRole(middleware.RoleCustomer))
+		{
+			batches.POST("", batchHandler.CreateBatch)
+			batches.GET("", batchHandler.ListBatches)
+			batches.GET("/:id", batchHandler.GetBatch)
+			batches.GET("/:id/report", batchHandler.GetBatchReport)
+		}
+
+		standingOrders := api.Group("/standing-orders")
+		standingOrders.Use(middleware.RequireRole(middleware.RoleCustomer))
+		{
+			standingOrders.POST("", standingOrderHandler.CreateStandingOrder)
+			standingOrders.GET("", standingOrderHandler.ListStandingOrders)
+			standingOrders.GET("/:id", standingOrderHandler.GetStandingOrder)
+			standingOrders.PUT("/:id/pause", standingOrderHandler.PauseStandingOrder)
+			standingOrders.PUT("/:id/resume", standingOrderHandler.ResumeStandingOrder)
+			standingOrders.DELETE("/:id", standingOrderHandler.CancelStandingOrder)
+		}
+
+		boletoRoutes := api.Group("/boletos")
+		boletoRoutes.Use(middleware.RequireRole(middleware.RoleCustomer))
+		{
+			boletoRoutes.POST("", boletoHandler.IssueBoleto)
+			boletoRoutes.GET("", boletoHandler.ListBoletos)
+			boletoRoutes.GET("/lookup", boletoHandler.Lookup)
+			boletoRoutes.POST("/pay", boletoHandler.PayBoleto)
+			boletoRoutes.GET("/payments", boletoHandler.ListPayments)
+			boletoRoutes.GET("/payments/:id", boletoHandler.GetPayment)
+			boletoRoutes.GET("/:id", boletoHandler.GetBoleto)
+			boletoRoutes.POST("/:id/cancel", boletoHandler.CancelBoleto)
+		}
+
+		cnabRoutes := api.Group("/cnab")
+		cnabRoutes.Use(middleware.RequireRole(middleware.RoleCustomer))
+		{
+			cnabRoutes.POST("/remittances", remittanceHandler.ImportRemittance)
+			cnabRoutes.GET("/remittances", remittanceHandler.ListRemittances)
+			cnabRoutes.GET("/remittances/:id", remittanceHandler.GetRemittance)
+			cnabRoutes.GET("/remittances/:id/return", remittanceHandler.GetReturn)
+		}
+
+		webhookRoutes := api.Group("/webhooks")
+		webhookRoutes.Use(middleware.RequireRole(middleware.RoleCustomer))
+		{
+			webhookRoutes.POST("", webhookHandler.CreateWebhook)
+			webhookRoutes.GET("", webhookHandler.ListWebhooks)
+			webhookRoutes.GET("/:id", webhookHandler.GetWebhook)
+			webhookRoutes.PUT("/:id", webhookHandler.UpdateWebhook)
+			webhookRoutes.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhookRoutes.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+			webhookRoutes.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+		}
+
+		internal := api.Group("/internal")
+		internal.Use(middleware.RequireRole(middleware.RoleService))
+		{
+			internal.POST("/sweep", transferHandler.CreateSweepTransfer)
+		}
+	}
+
+	reconciliationRoutes := router.Group("/api/transfer/admin/reconciliation")
+	reconciliationRoutes.Use(middleware.JWTMiddleware())
+	{
+		reconciliationRoutes.POST("", middleware.RequireScope(middleware.ScopeReconciliationManage), reconciliationHandler.RunReconciliation)
+		reconciliationRoutes.GET("", middleware.RequireScope(middleware.ScopeReconciliationRead), reconciliationHandler.ListReports)
+		reconciliationRoutes.GET("/:id", middleware.RequireScope(middleware.ScopeReconciliationRead), reconciliationHandler.GetReport)
+		reconciliationRoutes.POST("/:id/fix", middleware.RequireScope(middleware.ScopeReconciliationManage), reconciliationHandler.FixBreaks)
+	}
+
+	boletoAdminRoutes := router.Group("/api/transfer/admin/boletos")
+	boletoAdminRoutes.Use(middleware.JWTMiddleware(), middleware.RequireScope(middleware.ScopeBoletosManage))
+	{
+		boletoAdminRoutes.POST("/settlement", boletoHandler.RunSettlement)
+		boletoAdminRoutes.POST("/clearing-payments", boletoHandler.ReceiveClearingPayment)
 	}
 
 	router.GET("/health", func(c *gin.Context) {
@@ -122,10 +276,22 @@ func main() {
 		}
 	}()
 
+	stopScheduler := make(chan struct{})
+	service.StartTransferScheduler(transferService, service.GetSchedulerInterval(), stopScheduler)
+	service.StartStandingOrderScheduler(standingOrderService, service.GetSchedulerInterval(), stopScheduler)
+	service.StartBatchProcessor(transferService, service.GetBatchProcessorInterval(), stopScheduler)
+	service.StartAsyncTransferWorkers(transferService, service.GetAsyncWorkerCount(), service.GetAsyncPollInterval(), stopScheduler)
+	boletoService.StartSettlementWorker(boletos, boletoService.GetSettlementInterval(), stopScheduler)
+	webhookService.StartDeliveryWorker(webhooks, webhookService.GetDeliveryInterval(), stopScheduler)
+	idempotency.StartCleanup(idempotencyStore, logger, time.Hour, stopScheduler)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	close(stopScheduler)
+	cancelConsumer()
+
 	logger.Info("Shutting down Transfer API server...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
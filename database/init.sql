@@ -4,9 +4,16 @@ CREATE TABLE IF NOT EXISTS contacorrente (
 	nome TEXT(100) NOT NULL,
 	cpf TEXT(11) NOT NULL UNIQUE,
 	ativo INTEGER(1) NOT NULL default 1,
+	situacao TEXT(10) NOT NULL default 'ACTIVE',
 	senha TEXT(100) NOT NULL,
 	salt TEXT(100) NOT NULL,
-	CHECK (ativo in (0,1))
+	totp_ativo INTEGER(1) NOT NULL default 0,
+	totp_segredo TEXT(64),
+	totp_ultimo_passo INTEGER NOT NULL default 0,
+	totp_tentativas INTEGER NOT NULL default 0,
+	totp_bloqueado_ate TEXT(25),
+	CHECK (ativo in (0,1)),
+	CHECK (situacao in ('ACTIVE','BLOCKED','CLOSING','CLOSED'))
 );
 
 CREATE TABLE IF NOT EXISTS movimento (
@@ -15,7 +22,9 @@ CREATE TABLE IF NOT EXISTS movimento (
 	datamovimento TEXT(25) NOT NULL,
 	tipomovimento TEXT(1) NOT NULL,
 	valor REAL NOT NULL,
+	categoria TEXT(10),
 	idempotencia_key TEXT(37),
+	data_contabil TEXT(25),
 	CHECK (tipomovimento in ('C','D')),
 	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente)
 );
@@ -25,6 +34,11 @@ CREATE TABLE IF NOT EXISTS tarifa (
 	idcontacorrente TEXT(37) NOT NULL,
 	datamovimento TEXT(25) NOT NULL,
 	valor REAL NOT NULL,
+	idempotencia_key TEXT(37),
+	idtransferencia TEXT(37),
+	data_contabil TEXT(25),
+	data_cobranca TEXT(25),
+	data_debito TEXT(25),
 	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente)
 );
 
@@ -38,7 +52,24 @@ CREATE TABLE IF NOT EXISTS transferencia (
 	data_conclusao TEXT(25),
 	descricao TEXT(255),
 	idempotencia_key TEXT(37),
-	CHECK (status in (0,1,2)),
+	idreserva TEXT(37),
+	data_agendamento TEXT(25),
+	tentativas INTEGER NOT NULL default 0,
+	data_proxima_tentativa TEXT(25),
+	ultimo_erro TEXT(255),
+	idordem TEXT(37),
+	idtransferencia_original TEXT(37),
+	motivo_devolucao TEXT(4),
+	valor_devolvido REAL NOT NULL default 0,
+	assincrona INTEGER(1) NOT NULL default 0,
+	data_processamento TEXT(25),
+	tarifa_garantida REAL,
+	tipo TEXT(10),
+	data_publicacao_evento TEXT(25),
+	data_contabil TEXT(25),
+	data_execucao TEXT(25),
+	CHECK (status in (0,1,2,3,4)),
+	CHECK (valor_devolvido <= valor + 0.005),
 	FOREIGN KEY(idcontacorrente_origem) REFERENCES contacorrente(idcontacorrente),
 	FOREIGN KEY(idcontacorrente_destino) REFERENCES contacorrente(idcontacorrente)
 );
@@ -49,7 +80,520 @@ CREATE TABLE IF NOT EXISTS idempotencia (
 	resultado TEXT(1000)
 );
 
+CREATE TABLE IF NOT EXISTS codigo_recuperacao (
+	idcodigo TEXT(37) PRIMARY KEY,
+	idcontacorrente TEXT(37) NOT NULL,
+	codigo_hash TEXT(100) NOT NULL,
+	utilizado INTEGER(1) NOT NULL default 0,
+	CHECK (utilizado in (0,1)),
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente)
+);
+
+CREATE TABLE IF NOT EXISTS historico_situacao (
+	idhistorico TEXT(37) PRIMARY KEY,
+	idcontacorrente TEXT(37) NOT NULL,
+	situacao_anterior TEXT(10) NOT NULL,
+	situacao_nova TEXT(10) NOT NULL,
+	motivo TEXT(255),
+	responsavel TEXT(100) NOT NULL,
+	data TEXT(25) NOT NULL,
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente)
+);
+
+CREATE TABLE IF NOT EXISTS bloqueio_saldo (
+	idbloqueio TEXT(37) PRIMARY KEY,
+	idcontacorrente TEXT(37) NOT NULL,
+	tipo TEXT(20) NOT NULL,
+	numero_referencia TEXT(100) NOT NULL,
+	valor REAL NOT NULL,
+	valor_liberado REAL NOT NULL default 0,
+	situacao TEXT(20) NOT NULL,
+	motivo TEXT(255),
+	responsavel TEXT(100) NOT NULL,
+	data_bloqueio TEXT(25) NOT NULL,
+	data_liberacao TEXT(25),
+	CHECK (tipo in ('JUDICIAL','ADMINISTRATIVE')),
+	CHECK (situacao in ('ACTIVE','PARTIALLY_RELEASED','RELEASED')),
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente)
+);
+
+CREATE TABLE IF NOT EXISTS liberacao_bloqueio (
+	idliberacao TEXT(37) PRIMARY KEY,
+	idbloqueio TEXT(37) NOT NULL,
+	valor REAL NOT NULL,
+	numero_referencia TEXT(100) NOT NULL,
+	motivo TEXT(255),
+	responsavel TEXT(100) NOT NULL,
+	data TEXT(25) NOT NULL,
+	FOREIGN KEY(idbloqueio) REFERENCES bloqueio_saldo(idbloqueio)
+);
+
+CREATE TABLE IF NOT EXISTS reserva (
+	idreserva TEXT(37) PRIMARY KEY,
+	idcontacorrente TEXT(37) NOT NULL,
+	idempotencia_key TEXT(50) NOT NULL UNIQUE,
+	valor REAL NOT NULL,
+	valor_capturado REAL NOT NULL default 0,
+	situacao TEXT(20) NOT NULL default 'AUTHORIZED',
+	descricao TEXT(255),
+	categoria TEXT(10),
+	idmovimento TEXT(37),
+	data_criacao TEXT(25) NOT NULL,
+	data_expiracao TEXT(25) NOT NULL,
+	data_conclusao TEXT(25),
+	CHECK (situacao in ('AUTHORIZED','CAPTURED','VOIDED','EXPIRED')),
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente)
+);
+
+CREATE TABLE IF NOT EXISTS chave_pix (
+	idchave TEXT(37) PRIMARY KEY,
+	idcontacorrente TEXT(37) NOT NULL,
+	tipo TEXT(10) NOT NULL,
+	valor TEXT(77) NOT NULL UNIQUE,
+	situacao TEXT(10) NOT NULL default 'PENDING',
+	codigo_confirmacao TEXT(100),
+	data_expiracao_codigo TEXT(25),
+	tentativas_confirmacao INTEGER NOT NULL default 0,
+	data_criacao TEXT(25) NOT NULL,
+	data_confirmacao TEXT(25),
+	CHECK (tipo in ('CPF','EMAIL','PHONE','RANDOM')),
+	CHECK (situacao in ('PENDING','ACTIVE')),
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente)
+);
+
+CREATE TABLE IF NOT EXISTS cobranca (
+	idcobranca TEXT(37) PRIMARY KEY,
+	txid TEXT(25) NOT NULL UNIQUE,
+	idcontacorrente TEXT(37) NOT NULL,
+	chave TEXT(77) NOT NULL,
+	valor REAL NOT NULL,
+	descricao TEXT(72),
+	situacao TEXT(20) NOT NULL default 'ACTIVE',
+	payload TEXT(512) NOT NULL,
+	data_criacao TEXT(25) NOT NULL,
+	data_expiracao TEXT(25) NOT NULL,
+	data_pagamento TEXT(25),
+	idtransferencia TEXT(37),
+	CHECK (situacao in ('ACTIVE','PROCESSING','PAID','EXPIRED')),
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente),
+	FOREIGN KEY(idtransferencia) REFERENCES transferencia(idtransferencia)
+);
+
+CREATE TABLE IF NOT EXISTS ordem_recorrente (
+	idordem TEXT(37) PRIMARY KEY,
+	idcontacorrente_origem TEXT(37) NOT NULL,
+	idcontacorrente_destino TEXT(37) NOT NULL,
+	numero_conta_destino TEXT(10) NOT NULL,
+	valor REAL NOT NULL,
+	descricao TEXT(255),
+	frequencia TEXT(10) NOT NULL,
+	dia_mes INTEGER,
+	expressao_cron TEXT(100),
+	data_inicio TEXT(25) NOT NULL,
+	data_fim TEXT(25),
+	max_ocorrencias INTEGER NOT NULL default 0,
+	ocorrencias INTEGER NOT NULL default 0,
+	data_proxima_execucao TEXT(25),
+	data_ultima_execucao TEXT(25),
+	situacao TEXT(20) NOT NULL default 'ACTIVE',
+	data_criacao TEXT(25) NOT NULL,
+	CHECK (frequencia in ('WEEKLY','MONTHLY','CRON')),
+	CHECK (situacao in ('ACTIVE','PAUSED','CANCELLED','COMPLETED')),
+	FOREIGN KEY(idcontacorrente_origem) REFERENCES contacorrente(idcontacorrente),
+	FOREIGN KEY(idcontacorrente_destino) REFERENCES contacorrente(idcontacorrente)
+);
+
+CREATE TABLE IF NOT EXISTS lote_transferencia (
+	idlote TEXT(37) PRIMARY KEY,
+	idcontacorrente TEXT(37) NOT NULL,
+	idempotencia_key TEXT(50) NOT NULL UNIQUE,
+	modo TEXT(20) NOT NULL,
+	situacao TEXT(20) NOT NULL default 'PENDING',
+	quantidade_itens INTEGER NOT NULL,
+	valor_total REAL NOT NULL,
+	itens_concluidos INTEGER NOT NULL default 0,
+	itens_falha INTEGER NOT NULL default 0,
+	valor_concluido REAL NOT NULL default 0,
+	data_criacao TEXT(25) NOT NULL,
+	data_processamento TEXT(25),
+	data_conclusao TEXT(25),
+	CHECK (modo in ('ALL_OR_NOTHING','BEST_EFFORT')),
+	CHECK (situacao in ('PENDING','PROCESSING','COMPLETED','PARTIALLY_COMPLETED','FAILED')),
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente)
+);
+
+CREATE TABLE IF NOT EXISTS item_lote_transferencia (
+	iditem TEXT(37) PRIMARY KEY,
+	idlote TEXT(37) NOT NULL,
+	sequencia INTEGER NOT NULL,
+	idcontacorrente_destino TEXT(37) NOT NULL,
+	numero_conta_destino TEXT(10) NOT NULL,
+	chave_destino TEXT(77),
+	valor REAL NOT NULL,
+	descricao TEXT(255),
+	situacao TEXT(20) NOT NULL default 'PENDING',
+	idreserva TEXT(37),
+	idtransferencia TEXT(37),
+	erro TEXT(255),
+	CHECK (situacao in ('PENDING','RESERVED','COMPLETED','FAILED','CANCELLED')),
+	FOREIGN KEY(idlote) REFERENCES lote_transferencia(idlote),
+	FOREIGN KEY(idcontacorrente_destino) REFERENCES contacorrente(idcontacorrente)
+);
+
+CREATE TABLE IF NOT EXISTS requisicao_idempotente (
+	escopo TEXT(100) NOT NULL,
+	chave TEXT(255) NOT NULL,
+	hash_requisicao TEXT(64) NOT NULL,
+	situacao TEXT(20) NOT NULL,
+	status_http INTEGER,
+	cabecalhos TEXT(1000),
+	resposta BLOB,
+	data_criacao TEXT(25) NOT NULL,
+	data_expiracao TEXT(25) NOT NULL,
+	PRIMARY KEY (escopo, chave),
+	CHECK (situacao in ('IN_PROGRESS','COMPLETED'))
+);
+
+CREATE TABLE IF NOT EXISTS webhook (
+	idwebhook TEXT(37) PRIMARY KEY,
+	idcontacorrente TEXT(37) NOT NULL,
+	url TEXT(2000) NOT NULL,
+	eventos TEXT(200) NOT NULL,
+	segredo TEXT(80) NOT NULL,
+	situacao TEXT(10) NOT NULL,
+	falhas_consecutivas INTEGER NOT NULL DEFAULT 0,
+	motivo_desativacao TEXT(200),
+	data_criacao TEXT(25) NOT NULL,
+	data_desativacao TEXT(25),
+	CHECK (situacao in ('ACTIVE','DISABLED')),
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente)
+);
+
+CREATE TABLE IF NOT EXISTS entrega_webhook (
+	identrega TEXT(37) PRIMARY KEY,
+	idwebhook TEXT(37) NOT NULL,
+	idevento TEXT(37) NOT NULL,
+	tipo_evento TEXT(40) NOT NULL,
+	payload TEXT NOT NULL,
+	situacao TEXT(10) NOT NULL,
+	tentativas INTEGER NOT NULL DEFAULT 0,
+	data_proxima_tentativa TEXT(25),
+	ultimo_status_http INTEGER,
+	ultimo_erro TEXT(500),
+	identrega_original TEXT(37),
+	data_criacao TEXT(25) NOT NULL,
+	data_entrega TEXT(25),
+	CHECK (situacao in ('PENDING','SUCCEEDED','FAILED')),
+	FOREIGN KEY(idwebhook) REFERENCES webhook(idwebhook)
+);
+
+CREATE TABLE IF NOT EXISTS preferencia_notificacao (
+	idcontacorrente TEXT(37) PRIMARY KEY,
+	idioma TEXT(5) NOT NULL,
+	email TEXT(254),
+	telefone TEXT(16),
+	token_push TEXT(500),
+	email_ativo INTEGER(1) NOT NULL DEFAULT 0,
+	sms_ativo INTEGER(1) NOT NULL DEFAULT 0,
+	push_ativo INTEGER(1) NOT NULL DEFAULT 0,
+	silencio_inicio TEXT(5),
+	silencio_fim TEXT(5),
+	fuso_horario TEXT(50) NOT NULL,
+	data_atualizacao TEXT(25),
+	CHECK (idioma in ('pt-BR','en')),
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente)
+);
+
+CREATE TABLE IF NOT EXISTS notificacao (
+	idnotificacao TEXT(37) PRIMARY KEY,
+	idevento TEXT(37) NOT NULL,
+	tipo_evento TEXT(40) NOT NULL,
+	idcontacorrente TEXT(37) NOT NULL,
+	canal TEXT(10) NOT NULL,
+	destinatario TEXT(500) NOT NULL,
+	assunto TEXT(200),
+	corpo TEXT NOT NULL,
+	situacao TEXT(10) NOT NULL,
+	tentativas INTEGER NOT NULL DEFAULT 0,
+	data_envio_prevista TEXT(25) NOT NULL,
+	data_envio TEXT(25),
+	ultimo_erro TEXT(500),
+	data_criacao TEXT(25) NOT NULL,
+	CHECK (canal in ('EMAIL','SMS','PUSH')),
+	CHECK (situacao in ('PENDING','SENT','FAILED'))
+);
+
+CREATE TABLE IF NOT EXISTS conta_contabil (
+	codigo TEXT(30) PRIMARY KEY,
+	nome TEXT(100) NOT NULL,
+	natureza TEXT(10) NOT NULL,
+	idcontacorrente TEXT(37) UNIQUE,
+	data_criacao TEXT(25) NOT NULL,
+	CHECK (natureza in ('ASSET','LIABILITY','REVENUE','EXPENSE')),
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente)
+);
+
+CREATE TABLE IF NOT EXISTS lancamento_contabil (
+	idlancamento TEXT(37) PRIMARY KEY,
+	data TEXT(25) NOT NULL,
+	historico TEXT(100) NOT NULL,
+	categoria TEXT(10),
+	idmovimento TEXT(37) UNIQUE,
+	data_contabil TEXT(25),
+	data_criacao TEXT(25) NOT NULL,
+	FOREIGN KEY(idmovimento) REFERENCES movimento(idmovimento)
+);
+
+CREATE TABLE IF NOT EXISTS partida_contabil (
+	idpartida TEXT(37) PRIMARY KEY,
+	idlancamento TEXT(37) NOT NULL,
+	codigo_conta TEXT(30) NOT NULL,
+	tipo TEXT(1) NOT NULL,
+	valor REAL NOT NULL,
+	CHECK (tipo in ('C','D')),
+	CHECK (valor > 0),
+	FOREIGN KEY(idlancamento) REFERENCES lancamento_contabil(idlancamento),
+	FOREIGN KEY(codigo_conta) REFERENCES conta_contabil(codigo)
+);
+
+INSERT OR IGNORE INTO conta_contabil (codigo, nome, natureza, data_criacao) VALUES
+	('1.1.01', 'Caixa', 'ASSET', datetime('now')),
+	('1.1.02', 'Compensação de transferências', 'ASSET', datetime('now')),
+	('1.1.03', 'Compensação de boletos', 'ASSET', datetime('now')),
+	('2.1.01', 'Depósitos à vista de clientes', 'LIABILITY', datetime('now')),
+	('3.1.01', 'Receita de tarifas', 'REVENUE', datetime('now')),
+	('9.9.01', 'Conta transitória', 'ASSET', datetime('now'));
+
+CREATE TABLE IF NOT EXISTS saldo_conta_contabil (
+	codigo_conta TEXT(30) PRIMARY KEY,
+	debitos REAL NOT NULL DEFAULT 0,
+	creditos REAL NOT NULL DEFAULT 0,
+	data_atualizacao TEXT(25) NOT NULL,
+	FOREIGN KEY(codigo_conta) REFERENCES conta_contabil(codigo)
+);
+
+CREATE TABLE IF NOT EXISTS saldo_diario (
+	codigo_conta TEXT(30) NOT NULL,
+	data TEXT(25) NOT NULL,
+	debitos REAL NOT NULL,
+	creditos REAL NOT NULL,
+	data_criacao TEXT(25) NOT NULL,
+	PRIMARY KEY (codigo_conta, data),
+	FOREIGN KEY(codigo_conta) REFERENCES conta_contabil(codigo)
+);
+
+CREATE TABLE IF NOT EXISTS divergencia_saldo (
+	iddivergencia TEXT(37) PRIMARY KEY,
+	codigo_conta TEXT(30) NOT NULL,
+	verificacao TEXT(30) NOT NULL,
+	valor_esperado REAL NOT NULL,
+	valor_encontrado REAL NOT NULL,
+	data_deteccao TEXT(25) NOT NULL,
+	CHECK (verificacao in ('MATERIALIZED_BALANCE','MOVEMENTS'))
+);
+
+CREATE TABLE IF NOT EXISTS periodo_contabil (
+	data TEXT(25) PRIMARY KEY,
+	lancamentos INTEGER NOT NULL DEFAULT 0,
+	total_debitos REAL NOT NULL DEFAULT 0,
+	total_creditos REAL NOT NULL DEFAULT 0,
+	data_fechamento TEXT(25) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS total_periodo_contabil (
+	data TEXT(25) NOT NULL,
+	codigo_conta TEXT(30) NOT NULL,
+	debitos REAL NOT NULL DEFAULT 0,
+	creditos REAL NOT NULL DEFAULT 0,
+	debitos_acumulados REAL NOT NULL DEFAULT 0,
+	creditos_acumulados REAL NOT NULL DEFAULT 0,
+	saldo REAL NOT NULL DEFAULT 0,
+	PRIMARY KEY (data, codigo_conta),
+	FOREIGN KEY(data) REFERENCES periodo_contabil(data),
+	FOREIGN KEY(codigo_conta) REFERENCES conta_contabil(codigo)
+);
+
+CREATE TRIGGER IF NOT EXISTS lancamento_contabil_imutavel_update BEFORE UPDATE ON lancamento_contabil
+BEGIN SELECT RAISE(ABORT, 'lançamentos contábeis são imutáveis'); END;
+CREATE TRIGGER IF NOT EXISTS lancamento_contabil_imutavel_delete BEFORE DELETE ON lancamento_contabil
+BEGIN SELECT RAISE(ABORT, 'lançamentos contábeis são imutáveis'); END;
+CREATE TRIGGER IF NOT EXISTS partida_contabil_imutavel_update BEFORE UPDATE ON partida_contabil
+BEGIN SELECT RAISE(ABORT, 'lançamentos contábeis são imutáveis'); END;
+CREATE TRIGGER IF NOT EXISTS partida_contabil_imutavel_delete BEFORE DELETE ON partida_contabil
+BEGIN SELECT RAISE(ABORT, 'lançamentos contábeis são imutáveis'); END;
+
+CREATE TABLE IF NOT EXISTS conciliacao (
+	idconciliacao TEXT(37) PRIMARY KEY,
+	data_inicio TEXT(25) NOT NULL,
+	data_fim TEXT(25) NOT NULL,
+	transferencias_conferidas INTEGER NOT NULL DEFAULT 0,
+	tarifas_conferidas INTEGER NOT NULL DEFAULT 0,
+	tarifas_sem_vinculo INTEGER NOT NULL DEFAULT 0,
+	quebras INTEGER NOT NULL DEFAULT 0,
+	data_criacao TEXT(25) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS quebra_conciliacao (
+	idquebra TEXT(37) PRIMARY KEY,
+	idconciliacao TEXT(37) NOT NULL,
+	classe TEXT(20) NOT NULL,
+	perna TEXT(10) NOT NULL,
+	idtransferencia TEXT(37),
+	idtarifa TEXT(37),
+	idcontacorrente TEXT(37),
+	valor_esperado REAL NOT NULL,
+	valor_encontrado REAL NOT NULL,
+	descricao TEXT(255),
+	corrigivel INTEGER(1) NOT NULL DEFAULT 0,
+	data_correcao TEXT(25),
+	erro_correcao TEXT(255),
+	CHECK (classe in ('MISSING_LEG','DUPLICATE_LEG','AMOUNT_MISMATCH','ORPHAN_FEE')),
+	FOREIGN KEY(idconciliacao) REFERENCES conciliacao(idconciliacao)
+);
+
+CREATE TABLE IF NOT EXISTS informe_rendimentos (
+	idinforme TEXT(37) PRIMARY KEY,
+	idcontacorrente TEXT(37) NOT NULL,
+	ano INTEGER NOT NULL,
+	numero_conta INTEGER NOT NULL,
+	nome TEXT(100) NOT NULL,
+	cpf TEXT(11) NOT NULL,
+	saldo_anterior REAL NOT NULL DEFAULT 0,
+	saldo REAL NOT NULL DEFAULT 0,
+	tarifas_pagas REAL NOT NULL DEFAULT 0,
+	codigo_banco TEXT(10) NOT NULL,
+	data_geracao TEXT(25) NOT NULL,
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente)
+);
+
+CREATE TABLE IF NOT EXISTS boleto (
+	idboleto TEXT(37) PRIMARY KEY,
+	nosso_numero TEXT(13) NOT NULL UNIQUE,
+	idcontacorrente TEXT(37) NOT NULL,
+	numero_conta INTEGER NOT NULL,
+	beneficiario_nome TEXT(100) NOT NULL,
+	pagador_nome TEXT(100) NOT NULL,
+	pagador_documento TEXT(14) NOT NULL,
+	valor REAL NOT NULL,
+	vencimento TEXT(25) NOT NULL,
+	descricao TEXT(200),
+	codigo_barras TEXT(44) NOT NULL UNIQUE,
+	linha_digitavel TEXT(60) NOT NULL,
+	situacao TEXT(20) NOT NULL,
+	data_criacao TEXT(25) NOT NULL,
+	data_pagamento TEXT(25),
+	idpagamento TEXT(37),
+	data_cancelamento TEXT(25),
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente)
+);
+
+CREATE TABLE IF NOT EXISTS pagamento_boleto (
+	idpagamento TEXT(37) PRIMARY KEY,
+	idempotencia_key TEXT(100) NOT NULL UNIQUE,
+	origem TEXT(20) NOT NULL,
+	idcontacorrente TEXT(37),
+	tipo TEXT(20) NOT NULL,
+	codigo_barras TEXT(44) NOT NULL,
+	codigo_banco TEXT(3),
+	idboleto TEXT(37),
+	valor REAL NOT NULL,
+	vencimento TEXT(25),
+	situacao TEXT(20) NOT NULL,
+	erro TEXT(500),
+	data_criacao TEXT(25) NOT NULL,
+	data_pagamento TEXT(25),
+	data_liquidacao TEXT(25),
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente),
+	FOREIGN KEY(idboleto) REFERENCES boleto(idboleto)
+);
+
+CREATE TABLE IF NOT EXISTS remessa_cnab (
+	idremessa TEXT(37) PRIMARY KEY,
+	idcontacorrente TEXT(37) NOT NULL,
+	nsa INTEGER NOT NULL,
+	layout TEXT(3) NOT NULL,
+	nome_arquivo TEXT(255),
+	hash_arquivo TEXT(64) NOT NULL,
+	conteudo TEXT NOT NULL,
+	idlote TEXT(37),
+	quantidade_registros INTEGER NOT NULL,
+	registros_recusados INTEGER NOT NULL,
+	valor_total REAL NOT NULL,
+	data_importacao TEXT(25) NOT NULL,
+	FOREIGN KEY(idcontacorrente) REFERENCES contacorrente(idcontacorrente),
+	FOREIGN KEY(idlote) REFERENCES lote_transferencia(idlote)
+);
+
+CREATE TABLE IF NOT EXISTS item_remessa_cnab (
+	iditem TEXT(37) PRIMARY KEY,
+	idremessa TEXT(37) NOT NULL,
+	linha INTEGER NOT NULL,
+	tipo TEXT(20) NOT NULL,
+	seu_numero TEXT(25),
+	valor REAL NOT NULL,
+	sequencia_lote INTEGER,
+	idpagamento TEXT(37),
+	situacao TEXT(20) NOT NULL,
+	ocorrencia TEXT(2),
+	erro TEXT(500),
+	FOREIGN KEY(idremessa) REFERENCES remessa_cnab(idremessa),
+	FOREIGN KEY(idpagamento) REFERENCES pagamento_boleto(idpagamento)
+);
+
+CREATE TABLE IF NOT EXISTS operador (
+	idoperador TEXT(37) PRIMARY KEY,
+	login TEXT(100) NOT NULL UNIQUE,
+	nome TEXT(100) NOT NULL,
+	perfil TEXT(20) NOT NULL,
+	ativo INTEGER(1) NOT NULL default 1,
+	senha TEXT(100) NOT NULL,
+	salt TEXT(100) NOT NULL,
+	data_criacao TEXT(25) NOT NULL,
+	CHECK (perfil in ('support','admin')),
+	CHECK (ativo in (0,1))
+);
+
 CREATE INDEX IF NOT EXISTS idx_movimento_conta ON movimento(idcontacorrente);
 CREATE INDEX IF NOT EXISTS idx_transferencia_origem ON transferencia(idcontacorrente_origem);
 CREATE INDEX IF NOT EXISTS idx_transferencia_destino ON transferencia(idcontacorrente_destino);
+CREATE INDEX IF NOT EXISTS idx_transferencia_agendamento ON transferencia(status, data_agendamento);
 CREATE INDEX IF NOT EXISTS idx_tarifa_conta ON tarifa(idcontacorrente);
+CREATE INDEX IF NOT EXISTS idx_codigo_recuperacao_conta ON codigo_recuperacao(idcontacorrente);
+CREATE INDEX IF NOT EXISTS idx_historico_situacao_conta ON historico_situacao(idcontacorrente);
+CREATE INDEX IF NOT EXISTS idx_bloqueio_saldo_conta ON bloqueio_saldo(idcontacorrente);
+CREATE INDEX IF NOT EXISTS idx_reserva_conta_situacao ON reserva(idcontacorrente, situacao);
+CREATE INDEX IF NOT EXISTS idx_chave_pix_conta ON chave_pix(idcontacorrente);
+CREATE INDEX IF NOT EXISTS idx_cobranca_conta ON cobranca(idcontacorrente);
+CREATE INDEX IF NOT EXISTS idx_ordem_recorrente_conta ON ordem_recorrente(idcontacorrente_origem);
+CREATE INDEX IF NOT EXISTS idx_ordem_recorrente_execucao ON ordem_recorrente(situacao, data_proxima_execucao);
+CREATE INDEX IF NOT EXISTS idx_transferencia_ordem ON transferencia(idordem);
+CREATE INDEX IF NOT EXISTS idx_transferencia_original ON transferencia(idtransferencia_original);
+CREATE INDEX IF NOT EXISTS idx_transferencia_idempotencia ON transferencia(idempotencia_key);
+CREATE INDEX IF NOT EXISTS idx_transferencia_assincrona ON transferencia(status, assincrona, data_processamento);
+CREATE INDEX IF NOT EXISTS idx_lote_transferencia_conta ON lote_transferencia(idcontacorrente);
+CREATE INDEX IF NOT EXISTS idx_lote_transferencia_situacao ON lote_transferencia(situacao);
+CREATE INDEX IF NOT EXISTS idx_item_lote_transferencia_lote ON item_lote_transferencia(idlote, sequencia);
+CREATE INDEX IF NOT EXISTS idx_requisicao_idempotente_expiracao ON requisicao_idempotente(data_expiracao);
+CREATE INDEX IF NOT EXISTS idx_webhook_conta ON webhook(idcontacorrente);
+CREATE INDEX IF NOT EXISTS idx_entrega_webhook_webhook ON entrega_webhook(idwebhook, data_criacao);
+CREATE INDEX IF NOT EXISTS idx_entrega_webhook_pendente ON entrega_webhook(situacao, data_proxima_tentativa);
+CREATE INDEX IF NOT EXISTS idx_notificacao_pendente ON notificacao(situacao, data_envio_prevista);
+CREATE INDEX IF NOT EXISTS idx_notificacao_conta ON notificacao(idcontacorrente);
+CREATE INDEX IF NOT EXISTS idx_partida_contabil_lancamento ON partida_contabil(idlancamento);
+CREATE INDEX IF NOT EXISTS idx_partida_contabil_conta ON partida_contabil(codigo_conta);
+CREATE INDEX IF NOT EXISTS idx_lancamento_contabil_data ON lancamento_contabil(data);
+CREATE INDEX IF NOT EXISTS idx_saldo_diario_data ON saldo_diario(data);
+CREATE INDEX IF NOT EXISTS idx_divergencia_saldo_data ON divergencia_saldo(data_deteccao);
+CREATE INDEX IF NOT EXISTS idx_tarifa_transferencia ON tarifa(idtransferencia);
+CREATE INDEX IF NOT EXISTS idx_tarifa_data ON tarifa(datamovimento);
+CREATE INDEX IF NOT EXISTS idx_movimento_idempotencia ON movimento(idempotencia_key);
+CREATE INDEX IF NOT EXISTS idx_transferencia_data ON transferencia(datamovimento, status);
+CREATE INDEX IF NOT EXISTS idx_quebra_conciliacao_conciliacao ON quebra_conciliacao(idconciliacao);
+CREATE INDEX IF NOT EXISTS idx_lancamento_contabil_data_contabil ON lancamento_contabil(data_contabil);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_informe_conta_ano ON informe_rendimentos(idcontacorrente, ano);
+CREATE INDEX IF NOT EXISTS idx_boleto_conta ON boleto(idcontacorrente);
+CREATE INDEX IF NOT EXISTS idx_pagamento_boleto_conta ON pagamento_boleto(idcontacorrente);
+CREATE INDEX IF NOT EXISTS idx_pagamento_boleto_situacao ON pagamento_boleto(situacao, data_pagamento);
+CREATE INDEX IF NOT EXISTS idx_pagamento_boleto_codigo ON pagamento_boleto(codigo_barras);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_remessa_cnab_conta_nsa ON remessa_cnab(idcontacorrente, nsa);
+CREATE INDEX IF NOT EXISTS idx_item_remessa_cnab_remessa ON item_remessa_cnab(idremessa);
@@ -1,27 +1,39 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Account struct {
-	ID           string    `json:"id" gorm:"column:idcontacorrente;primaryKey"`
-	Number       int       `json:"number" gorm:"column:numero;unique"`
-	Name         string    `json:"name" gorm:"column:nome"`
-	CPF          string    `json:"cpf" gorm:"column:cpf;unique"`
-	Active       bool      `json:"active" gorm:"column:ativo"`
-	PasswordHash string    `json:"-" gorm:"column:senha"`
-	Salt         string    `json:"-" gorm:"column:salt"`
-	CreatedAt    time.Time `json:"createdAt" gorm:"-"`
-	UpdatedAt    time.Time `json:"updatedAt" gorm:"-"`
+	ID                 string     `json:"id" gorm:"column:idcontacorrente;primaryKey"`
+	Number             int        `json:"number" gorm:"column:numero;unique"`
+	Name               string     `json:"name" gorm:"column:nome"`
+	CPF                string     `json:"cpf" gorm:"column:cpf;unique"`
+	Active             bool       `json:"active" gorm:"column:ativo"`
+	Status             string     `json:"status" gorm:"column:situacao"`
+	PasswordHash       string     `json:"-" gorm:"column:senha"`
+	Salt               string     `json:"-" gorm:"column:salt"`
+	TwoFactorEnabled   bool       `json:"twoFactorEnabled" gorm:"column:totp_ativo"`
+	TOTPSecret         string     `json:"-" gorm:"column:totp_segredo"`
+	TOTPLastStep       int64      `json:"-" gorm:"column:totp_ultimo_passo"`
+	TOTPFailedAttempts int        `json:"-" gorm:"column:totp_tentativas"`
+	TOTPLockedUntil    *time.Time `json:"-" gorm:"column:totp_bloqueado_ate"`
+	CreatedAt          time.Time  `json:"createdAt" gorm:"-"`
+	UpdatedAt          time.Time  `json:"updatedAt" gorm:"-"`
 }
 
 func (Account) TableName() string {
 	return "contacorrente"
 }
 
+// ErrStatusChanged é devolvido quando a situação da conta muda entre a validação e a
+// gravação, como no início de um encerramento.
+var ErrStatusChanged = errors.New("situação da conta não permite a operação")
+
 func NewAccount(name, cpf, passwordHash, salt string, number int) *Account {
 	return &Account{
 		ID:           uuid.New().String(),
@@ -29,6 +41,7 @@ func NewAccount(name, cpf, passwordHash, salt string, number int) *Account {
 		Name:         name,
 		CPF:          cpf,
 		Active:       true,
+		Status:       AccountStatusActive,
 		PasswordHash: passwordHash,
 		Salt:         salt,
 		CreatedAt:    time.Now(),
@@ -36,36 +49,114 @@ func NewAccount(name, cpf, passwordHash, salt string, number int) *Account {
 	}
 }
 
-func (a *Account) Deactivate() {
-	a.Active = false
+// CurrentStatus considera contas anteriores à coluna situacao, que só têm o flag ativo.
+func (a *Account) CurrentStatus() string {
+	if a.Status != "" {
+		return a.Status
+	}
+	if a.Active {
+		return AccountStatusActive
+	}
+	return AccountStatusClosed
+}
+
+func (a *Account) CanTransitionTo(status string) bool {
+	for _, allowed := range accountStatusTransitions[a.CurrentStatus()] {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionTo aplica a mudança de situação e devolve o registro de histórico a ser persistido.
+func (a *Account) TransitionTo(status, reason, actor string) (*AccountStatusChange, error) {
+	if !a.CanTransitionTo(status) {
+		return nil, fmt.Errorf("transição de %s para %s não permitida", a.CurrentStatus(), status)
+	}
+
+	change := NewAccountStatusChange(a.ID, a.CurrentStatus(), status, reason, actor)
+
+	a.Status = status
+	a.Active = status == AccountStatusActive
+	a.UpdatedAt = time.Now()
+
+	return change, nil
+}
+
+// CanLogin vale para as duas etapas do login (senha e 2FA): só contas encerradas ficam
+// sem acesso; bloqueadas e em encerramento continuam consultando a conta.
+func (a *Account) CanLogin() bool {
+	return a.CurrentStatus() != AccountStatusClosed
+}
+
+func (a *Account) CanCredit() bool {
+	status := a.CurrentStatus()
+	return status == AccountStatusActive || status == AccountStatusBlocked
+}
+
+func (a *Account) CanDebit() bool {
+	status := a.CurrentStatus()
+	return status == AccountStatusActive || status == AccountStatusClosing
+}
+
+// CanPost aplica CanCredit ou CanDebit conforme o tipo da movimentação.
+func (a *Account) CanPost(movementType string) bool {
+	if movementType == MovementTypeCredit {
+		return a.CanCredit()
+	}
+	return a.CanDebit()
+}
+
+// CanPlaceHold exige conta ativa: em encerramento, o saldo lido para a transferência
+// final não pode ser reservado depois.
+func (a *Account) CanPlaceHold() bool {
+	return a.CurrentStatus() == AccountStatusActive
+}
+
+func (a *Account) StartTwoFactorEnrollment(secret string) {
+	a.TOTPSecret = secret
+	a.TwoFactorEnabled = false
+	a.TOTPLastStep = 0
+	a.UpdatedAt = time.Now()
+}
+
+func (a *Account) EnableTwoFactor() {
+	a.TwoFactorEnabled = true
 	a.UpdatedAt = time.Now()
 }
 
-func (a *Account) Activate() {
-	a.Active = true
+func (a *Account) DisableTwoFactor() {
+	a.TwoFactorEnabled = false
+	a.TOTPSecret = ""
+	a.TOTPLastStep = 0
 	a.UpdatedAt = time.Now()
 }
 
 type Movement struct {
-	ID              string    `json:"id" gorm:"column:idmovimento;primaryKey"`
-	AccountID       string    `json:"accountId" gorm:"column:idcontacorrente"`
-	Date            time.Time `json:"date" gorm:"column:datamovimento"`
-	Type            string    `json:"type" gorm:"column:tipomovimento"`
-	Amount          float64   `json:"amount" gorm:"column:valor"`
-	IdempotencyKey  *string   `json:"idempotencyKey" gorm:"column:idempotencia_key"`
+	ID             string     `json:"id" gorm:"column:idmovimento;primaryKey"`
+	AccountID      string     `json:"accountId" gorm:"column:idcontacorrente"`
+	Date           time.Time  `json:"date" gorm:"column:datamovimento"`
+	Type           string     `json:"type" gorm:"column:tipomovimento"`
+	Amount         float64    `json:"amount" gorm:"column:valor"`
+	Category       string     `json:"category" gorm:"column:categoria"`
+	IdempotencyKey *string    `json:"idempotencyKey" gorm:"column:idempotencia_key"`
+	AccountingDate *time.Time `json:"accountingDate,omitempty" gorm:"column:data_contabil"`
 }
 
 func (Movement) TableName() string {
 	return "movimento"
 }
 
-func NewMovement(accountID, movementType string, amount float64, idempotencyKey *string) *Movement {
+// NewMovement cria a movimentação; a categoria define a contrapartida no razão contábil.
+func NewMovement(accountID, movementType, category string, amount float64, idempotencyKey *string) *Movement {
 	return &Movement{
 		ID:             uuid.New().String(),
 		AccountID:      accountID,
 		Date:           time.Now(),
 		Type:           movementType,
 		Amount:         amount,
+		Category:       category,
 		IdempotencyKey: idempotencyKey,
 	}
 }
@@ -80,7 +171,66 @@ func (Idempotency) TableName() string {
 	return "idempotencia"
 }
 
+type AccountStatusChange struct {
+	ID         string    `json:"id" gorm:"column:idhistorico;primaryKey"`
+	AccountID  string    `json:"accountId" gorm:"column:idcontacorrente"`
+	FromStatus string    `json:"fromStatus" gorm:"column:situacao_anterior"`
+	ToStatus   string    `json:"toStatus" gorm:"column:situacao_nova"`
+	Reason     string    `json:"reason" gorm:"column:motivo"`
+	Actor      string    `json:"actor" gorm:"column:responsavel"`
+	Date       time.Time `json:"date" gorm:"column:data"`
+}
+
+func (AccountStatusChange) TableName() string {
+	return "historico_situacao"
+}
+
+func NewAccountStatusChange(accountID, fromStatus, toStatus, reason, actor string) *AccountStatusChange {
+	return &AccountStatusChange{
+		ID:         uuid.New().String(),
+		AccountID:  accountID,
+		FromStatus: fromStatus,
+		ToStatus:   toStatus,
+		Reason:     reason,
+		Actor:      actor,
+		Date:       time.Now(),
+	}
+}
+
+type RecoveryCode struct {
+	ID        string `json:"id" gorm:"column:idcodigo;primaryKey"`
+	AccountID string `json:"accountId" gorm:"column:idcontacorrente"`
+	CodeHash  string `json:"-" gorm:"column:codigo_hash"`
+	Used      bool   `json:"used" gorm:"column:utilizado"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "codigo_recuperacao"
+}
+
+func NewRecoveryCode(accountID, codeHash string) *RecoveryCode {
+	return &RecoveryCode{
+		ID:        uuid.New().String(),
+		AccountID: accountID,
+		CodeHash:  codeHash,
+	}
+}
+
 const (
 	MovementTypeCredit = "C"
 	MovementTypeDebit  = "D"
 )
+
+const (
+	AccountStatusActive  = "ACTIVE"
+	AccountStatusBlocked = "BLOCKED"
+	AccountStatusClosing = "CLOSING"
+	AccountStatusClosed  = "CLOSED"
+)
+
+var accountStatusTransitions = map[string][]string{
+	AccountStatusActive:  {AccountStatusBlocked, AccountStatusClosing, AccountStatusClosed},
+	AccountStatusBlocked: {AccountStatusActive, AccountStatusClosing, AccountStatusClosed},
+	AccountStatusClosing: {AccountStatusActive, AccountStatusBlocked, AccountStatusClosed},
+	AccountStatusClosed:  {AccountStatusActive},
+}
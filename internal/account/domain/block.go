@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	BlockTypeJudicial       = "JUDICIAL"
+	BlockTypeAdministrative = "ADMINISTRATIVE"
+)
+
+const (
+	BlockStatusActive            = "ACTIVE"
+	BlockStatusPartiallyReleased = "PARTIALLY_RELEASED"
+	BlockStatusReleased          = "RELEASED"
+)
+
+// BalanceBlock congela parte do saldo por ordem judicial ou investigação. O valor
+// bloqueado deixa de compor o saldo disponível, mas a conta continua ativa.
+type BalanceBlock struct {
+	ID              string     `json:"id" gorm:"column:idbloqueio;primaryKey"`
+	AccountID       string     `json:"accountId" gorm:"column:idcontacorrente"`
+	Type            string     `json:"type" gorm:"column:tipo"`
+	ReferenceNumber string     `json:"referenceNumber" gorm:"column:numero_referencia"`
+	Amount          float64    `json:"amount" gorm:"column:valor"`
+	ReleasedAmount  float64    `json:"releasedAmount" gorm:"column:valor_liberado"`
+	Status          string     `json:"status" gorm:"column:situacao"`
+	Reason          string     `json:"reason" gorm:"column:motivo"`
+	CreatedBy       string     `json:"createdBy" gorm:"column:responsavel"`
+	CreatedAt       time.Time  `json:"createdAt" gorm:"column:data_bloqueio"`
+	ReleasedAt      *time.Time `json:"releasedAt" gorm:"column:data_liberacao"`
+}
+
+func (BalanceBlock) TableName() string {
+	return "bloqueio_saldo"
+}
+
+func NewBalanceBlock(accountID, blockType, referenceNumber string, amount float64, reason, createdBy string) *BalanceBlock {
+	return &BalanceBlock{
+		ID:              uuid.New().String(),
+		AccountID:       accountID,
+		Type:            blockType,
+		ReferenceNumber: referenceNumber,
+		Amount:          amount,
+		Status:          BlockStatusActive,
+		Reason:          reason,
+		CreatedBy:       createdBy,
+		CreatedAt:       time.Now(),
+	}
+}
+
+func (b *BalanceBlock) RemainingAmount() float64 {
+	return b.Amount - b.ReleasedAmount
+}
+
+// Release libera parte ou todo o valor remanescente do bloqueio.
+func (b *BalanceBlock) Release(amount float64) error {
+	if b.Status == BlockStatusReleased {
+		return fmt.Errorf("bloqueio já liberado")
+	}
+	if amount <= 0 {
+		return fmt.Errorf("valor de liberação deve ser positivo")
+	}
+	if amount > b.RemainingAmount()+0.005 {
+		return fmt.Errorf("valor de liberação excede o saldo bloqueado de %.2f", b.RemainingAmount())
+	}
+
+	b.ReleasedAmount += amount
+	if b.RemainingAmount() < 0.005 {
+		b.ReleasedAmount = b.Amount
+		b.Status = BlockStatusReleased
+		now := time.Now()
+		b.ReleasedAt = &now
+	} else {
+		b.Status = BlockStatusPartiallyReleased
+	}
+
+	return nil
+}
+
+func IsValidBlockType(blockType string) bool {
+	return blockType == BlockTypeJudicial || blockType == BlockTypeAdministrative
+}
+
+type BlockRelease struct {
+	ID              string    `json:"id" gorm:"column:idliberacao;primaryKey"`
+	BlockID         string    `json:"blockId" gorm:"column:idbloqueio"`
+	Amount          float64   `json:"amount" gorm:"column:valor"`
+	ReferenceNumber string    `json:"referenceNumber" gorm:"column:numero_referencia"`
+	Reason          string    `json:"reason" gorm:"column:motivo"`
+	Actor           string    `json:"actor" gorm:"column:responsavel"`
+	Date            time.Time `json:"date" gorm:"column:data"`
+}
+
+func (BlockRelease) TableName() string {
+	return "liberacao_bloqueio"
+}
+
+func NewBlockRelease(blockID string, amount float64, referenceNumber, reason, actor string) *BlockRelease {
+	return &BlockRelease{
+		ID:              uuid.New().String(),
+		BlockID:         blockID,
+		Amount:          amount,
+		ReferenceNumber: referenceNumber,
+		Reason:          reason,
+		Actor:           actor,
+		Date:            time.Now(),
+	}
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	HoldStatusAuthorized = "AUTHORIZED"
+	HoldStatusCaptured   = "CAPTURED"
+	HoldStatusVoided     = "VOIDED"
+	HoldStatusExpired    = "EXPIRED"
+)
+
+// Hold reserva saldo antes do débito definitivo. Enquanto autorizada e dentro da
+// validade, o valor reservado não compõe o saldo disponível.
+type Hold struct {
+	ID             string     `json:"id" gorm:"column:idreserva;primaryKey"`
+	AccountID      string     `json:"accountId" gorm:"column:idcontacorrente"`
+	RequestID      string     `json:"requestId" gorm:"column:idempotencia_key;unique"`
+	Amount         float64    `json:"amount" gorm:"column:valor"`
+	CapturedAmount float64    `json:"capturedAmount" gorm:"column:valor_capturado"`
+	Status         string     `json:"status" gorm:"column:situacao"`
+	Description    string     `json:"description" gorm:"column:descricao"`
+	Category       string     `json:"category" gorm:"column:categoria"`
+	MovementID     *string    `json:"movementId" gorm:"column:idmovimento"`
+	CreatedAt      time.Time  `json:"createdAt" gorm:"column:data_criacao"`
+	ExpiresAt      time.Time  `json:"expiresAt" gorm:"column:data_expiracao"`
+	FinishedAt     *time.Time `json:"finishedAt" gorm:"column:data_conclusao"`
+}
+
+func (Hold) TableName() string {
+	return "reserva"
+}
+
+func NewHold(accountID, requestID string, amount float64, description, category string, ttl time.Duration) *Hold {
+	now := time.Now()
+	return &Hold{
+		ID:          uuid.New().String(),
+		AccountID:   accountID,
+		RequestID:   requestID,
+		Amount:      amount,
+		Status:      HoldStatusAuthorized,
+		Description: description,
+		Category:    category,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+}
+
+func (h *Hold) IsExpired(now time.Time) bool {
+	return h.Status == HoldStatusAuthorized && !now.Before(h.ExpiresAt)
+}
+
+// Capture confirma a reserva pelo valor informado; o restante é liberado.
+func (h *Hold) Capture(amount float64, movementID string, now time.Time) error {
+	if h.Status != HoldStatusAuthorized {
+		return fmt.Errorf("reserva na situação %s não pode ser capturada", h.Status)
+	}
+	if h.IsExpired(now) {
+		return fmt.Errorf("reserva expirada")
+	}
+	if amount <= 0 {
+		return fmt.Errorf("valor de captura deve ser positivo")
+	}
+	if amount > h.Amount+0.005 {
+		return fmt.Errorf("valor de captura excede o valor reservado de %.2f", h.Amount)
+	}
+
+	h.CapturedAmount = amount
+	h.MovementID = &movementID
+	h.finish(HoldStatusCaptured, now)
+	return nil
+}
+
+func (h *Hold) Void(now time.Time) error {
+	if h.Status != HoldStatusAuthorized {
+		return fmt.Errorf("reserva na situação %s não pode ser cancelada", h.Status)
+	}
+	h.finish(HoldStatusVoided, now)
+	return nil
+}
+
+func (h *Hold) Expire(now time.Time) {
+	h.finish(HoldStatusExpired, now)
+}
+
+func (h *Hold) finish(status string, now time.Time) {
+	h.Status = status
+	h.FinishedAt = &now
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operator é um usuário do back-office (suporte ou administração). Operadores não
+// possuem conta corrente e autenticam por login, separados dos clientes.
+type Operator struct {
+	ID           string    `json:"id" gorm:"column:idoperador;primaryKey"`
+	Login        string    `json:"login" gorm:"column:login;unique"`
+	Name         string    `json:"name" gorm:"column:nome"`
+	Role         string    `json:"role" gorm:"column:perfil"`
+	Active       bool      `json:"active" gorm:"column:ativo"`
+	PasswordHash string    `json:"-" gorm:"column:senha"`
+	Salt         string    `json:"-" gorm:"column:salt"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"column:data_criacao"`
+}
+
+func (Operator) TableName() string {
+	return "operador"
+}
+
+func NewOperator(login, name, role, passwordHash, salt string) *Operator {
+	return &Operator{
+		ID:           uuid.New().String(),
+		Login:        login,
+		Name:         name,
+		Role:         role,
+		Active:       true,
+		PasswordHash: passwordHash,
+		Salt:         salt,
+		CreatedAt:    time.Now(),
+	}
+}
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bankmore/internal/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PixKeyTypeCPF    = "CPF"
+	PixKeyTypeEmail  = "EMAIL"
+	PixKeyTypePhone  = "PHONE"
+	PixKeyTypeRandom = "RANDOM"
+)
+
+const (
+	PixKeyStatusPending = "PENDING"
+	PixKeyStatusActive  = "ACTIVE"
+)
+
+const (
+	MaxPixKeysPerAccount          = 5
+	PixKeyConfirmationTTL         = 10 * time.Minute
+	PixKeyMaxConfirmationAttempts = 5
+)
+
+// PixKey associa um identificador (CPF, e-mail, telefone ou chave aleatória) a uma
+// conta. Chaves de e-mail e telefone ficam pendentes até o titular confirmar a posse.
+type PixKey struct {
+	ID                    string     `json:"id" gorm:"column:idchave;primaryKey"`
+	AccountID             string     `json:"accountId" gorm:"column:idcontacorrente"`
+	Type                  string     `json:"type" gorm:"column:tipo"`
+	Value                 string     `json:"value" gorm:"column:valor;unique"`
+	Status                string     `json:"status" gorm:"column:situacao"`
+	ConfirmationHash      string     `json:"-" gorm:"column:codigo_confirmacao"`
+	ConfirmationExpiresAt *time.Time `json:"-" gorm:"column:data_expiracao_codigo"`
+	ConfirmationAttempts  int        `json:"-" gorm:"column:tentativas_confirmacao"`
+	CreatedAt             time.Time  `json:"createdAt" gorm:"column:data_criacao"`
+	ConfirmedAt           *time.Time `json:"confirmedAt" gorm:"column:data_confirmacao"`
+}
+
+func (PixKey) TableName() string {
+	return "chave_pix"
+}
+
+func NewPixKey(accountID, keyType, value string) *PixKey {
+	now := time.Now()
+	key := &PixKey{
+		ID:        uuid.New().String(),
+		AccountID: accountID,
+		Type:      keyType,
+		Value:     value,
+		Status:    PixKeyStatusPending,
+		CreatedAt: now,
+	}
+	if !key.RequiresConfirmation() {
+		key.activate(now)
+	}
+	return key
+}
+
+// RequiresConfirmation indica se a posse da chave precisa ser comprovada por código.
+func (k *PixKey) RequiresConfirmation() bool {
+	return k.Type == PixKeyTypeEmail || k.Type == PixKeyTypePhone
+}
+
+func (k *PixKey) SetConfirmationCode(code string, now time.Time) {
+	expiresAt := now.Add(PixKeyConfirmationTTL)
+	k.ConfirmationHash = utils.HashPassword(code, k.ID)
+	k.ConfirmationExpiresAt = &expiresAt
+	k.ConfirmationAttempts = 0
+}
+
+// IsStalePending indica uma chave pendente cujo código expirou; ela não reserva mais o valor.
+func (k *PixKey) IsStalePending(now time.Time) bool {
+	return k.Status == PixKeyStatusPending && k.ConfirmationExpiresAt != nil && now.After(*k.ConfirmationExpiresAt)
+}
+
+// CheckConfirmable indica por que a chave não aceita mais tentativas de confirmação.
+func (k *PixKey) CheckConfirmable(now time.Time) error {
+	if k.Status != PixKeyStatusPending {
+		return fmt.Errorf("chave já confirmada")
+	}
+	if k.IsStalePending(now) {
+		return fmt.Errorf("código de confirmação expirado")
+	}
+	if k.ConfirmationAttempts >= PixKeyMaxConfirmationAttempts {
+		return fmt.Errorf("número máximo de tentativas excedido")
+	}
+	return nil
+}
+
+// Confirm confere o código e ativa a chave. A tentativa já deve ter sido reservada no
+// repositório, que conta as tentativas de forma atômica.
+func (k *PixKey) Confirm(code string, now time.Time) error {
+	if err := k.CheckConfirmable(now); err != nil {
+		return err
+	}
+	if !utils.VerifyPassword(code, k.ID, k.ConfirmationHash) {
+		return fmt.Errorf("código de confirmação inválido")
+	}
+
+	k.activate(now)
+	return nil
+}
+
+func (k *PixKey) activate(now time.Time) {
+	k.Status = PixKeyStatusActive
+	k.ConfirmedAt = &now
+	k.ConfirmationHash = ""
+	k.ConfirmationExpiresAt = nil
+}
+
+// NormalizePixKey valida o valor conforme o tipo e devolve a forma canônica usada
+// no diretório de chaves.
+func NormalizePixKey(keyType, value string) (string, error) {
+	switch keyType {
+	case PixKeyTypeCPF:
+		cpf := utils.CleanCPF(value)
+		if !utils.ValidateCPF(cpf) {
+			return "", fmt.Errorf("CPF inválido")
+		}
+		return cpf, nil
+	case PixKeyTypeEmail:
+		return utils.NormalizeEmail(value)
+	case PixKeyTypePhone:
+		return utils.NormalizePhone(value)
+	case PixKeyTypeRandom:
+		id, err := uuid.Parse(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("chave aleatória inválida")
+		}
+		return id.String(), nil
+	default:
+		return "", fmt.Errorf("tipo de chave inválido")
+	}
+}
+
+// DetectPixKeyType identifica o tipo de uma chave informada sem tipo. Telefones
+// devem vir com o prefixo +55 para não serem confundidos com CPF.
+func DetectPixKeyType(value string) string {
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.Contains(value, "@"):
+		return PixKeyTypeEmail
+	case strings.HasPrefix(value, "+"):
+		return PixKeyTypePhone
+	}
+	if _, err := uuid.Parse(value); err == nil {
+		return PixKeyTypeRandom
+	}
+	return PixKeyTypeCPF
+}
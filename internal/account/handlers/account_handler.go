@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"bankmore/internal/account/service"
@@ -134,13 +135,15 @@ func (h *AccountHandler) Deactivate(c *gin.Context) {
 }
 
 // @Summary Realiza movimentação na conta corrente
-// @Description Realiza depósito ou saque na conta corrente
+// @Description Realiza depósito ou saque na conta corrente informada. Uso interno entre os serviços (requer escopo movements:write)
 // @Tags Account
 // @Accept json
 // @Produce json
 // @Param request body service.MovementRequest true "Dados da movimentação"
 // @Success 204
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
 // @Security BearerAuth
 // @Router /api/account/movement [post]
 func (h *AccountHandler) CreateMovement(c *gin.Context) {
@@ -154,6 +157,20 @@ func (h *AccountHandler) CreateMovement(c *gin.Context) {
 	}
 
 	err := h.service.CreateMovement(request)
+	if errors.Is(err, service.ErrIdempotencyKeyReused) {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Type:    models.ErrorIdempotencyKeyReused,
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, service.ErrInsufficientBalance) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInsufficientBalance,
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		h.logger.WithError(err).Error("Error creating movement")
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
@@ -198,11 +215,13 @@ func (h *AccountHandler) GetBalance(c *gin.Context) {
 }
 
 // @Summary Verifica se uma conta existe pelo número
-// @Description Verifica se uma conta existe pelo número
+// @Description Verifica se uma conta existe pelo número (requer escopo accounts:read: suporte, admin ou serviços internos)
 // @Tags Account
 // @Produce json
 // @Param accountNumber path string true "Número da conta"
 // @Success 200 {boolean} bool
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
 // @Router /api/account/exists/{accountNumber} [get]
 func (h *AccountHandler) AccountExists(c *gin.Context) {
 	accountNumber := c.Param("accountNumber")
@@ -221,12 +240,14 @@ func (h *AccountHandler) AccountExists(c *gin.Context) {
 }
 
 // @Summary Consulta o saldo de uma conta pelo número
-// @Description Consulta o saldo de uma conta pelo número
+// @Description Consulta o saldo de uma conta pelo número (requer escopo accounts:read: suporte, admin ou serviços internos)
 // @Tags Account
 // @Produce json
 // @Param accountNumber path string true "Número da conta"
 // @Success 200 {object} service.BalanceResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
 // @Router /api/account/balance/{accountNumber} [get]
 func (h *AccountHandler) GetBalanceByAccountNumber(c *gin.Context) {
 	accountNumber := c.Param("accountNumber")
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+
+	"bankmore/internal/account/service"
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type BlockHandler struct {
+	service service.BlockService
+	logger  *logrus.Logger
+}
+
+func NewBlockHandler(service service.BlockService, logger *logrus.Logger) *BlockHandler {
+	return &BlockHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// @Summary Bloqueia saldo da conta
+// @Description Cria um bloqueio judicial ou administrativo sobre parte ou todo o saldo disponível (requer escopo blocks:manage)
+// @Tags Account Admin
+// @Accept json
+// @Produce json
+// @Param accountNumber path string true "Número da conta"
+// @Param request body service.CreateBlockRequest true "Dados do bloqueio"
+// @Success 201 {object} domain.BalanceBlock
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/admin/{accountNumber}/blocks [post]
+func (h *BlockHandler) CreateBlock(c *gin.Context) {
+	var request service.CreateBlockRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	block, err := h.service.CreateBlock(c.Param("accountNumber"), request, actorFromToken(c))
+	if err != nil {
+		h.logger.WithError(err).Error("Error creating balance block")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidOperation,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, block)
+}
+
+// @Summary Libera um bloqueio de saldo
+// @Description Libera parcial ou totalmente um bloqueio; sem valor, libera todo o remanescente (requer escopo blocks:manage)
+// @Tags Account Admin
+// @Accept json
+// @Produce json
+// @Param accountNumber path string true "Número da conta"
+// @Param blockId path string true "ID do bloqueio"
+// @Param request body service.ReleaseBlockRequest true "Dados da liberação"
+// @Success 200 {object} domain.BalanceBlock
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/admin/{accountNumber}/blocks/{blockId}/release [post]
+func (h *BlockHandler) ReleaseBlock(c *gin.Context) {
+	var request service.ReleaseBlockRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	block, err := h.service.ReleaseBlock(c.Param("accountNumber"), c.Param("blockId"), request, actorFromToken(c))
+	if err != nil {
+		h.logger.WithError(err).Error("Error releasing balance block")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidOperation,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, block)
+}
+
+// @Summary Lista os bloqueios de saldo da conta
+// @Description Lista os bloqueios com valor remanescente e liberações (requer escopo accounts:read)
+// @Tags Account Admin
+// @Produce json
+// @Param accountNumber path string true "Número da conta"
+// @Success 200 {array} service.BlockDetails
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/admin/{accountNumber}/blocks [get]
+func (h *BlockHandler) ListBlocks(c *gin.Context) {
+	blocks, err := h.service.ListBlocks(c.Param("accountNumber"))
+	if err != nil {
+		h.logger.WithError(err).Error("Error listing balance blocks")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, blocks)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"bankmore/internal/account/service"
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type HoldHandler struct {
+	service service.HoldService
+	logger  *logrus.Logger
+}
+
+func NewHoldHandler(service service.HoldService, logger *logrus.Logger) *HoldHandler {
+	return &HoldHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// @Summary Reserva saldo na conta
+// @Description Cria uma reserva (autorização) que reduz o saldo disponível até ser capturada, cancelada ou expirar (requer escopo holds:manage)
+// @Tags Account Holds
+// @Accept json
+// @Produce json
+// @Param request body service.PlaceHoldRequest true "Dados da reserva"
+// @Success 201 {object} domain.Hold
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/holds [post]
+func (h *HoldHandler) PlaceHold(c *gin.Context) {
+	var request service.PlaceHoldRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	hold, err := h.service.PlaceHold(request)
+	if err != nil {
+		h.logger.WithError(err).Error("Error placing hold")
+		errorType := models.ErrorInvalidOperation
+		if errors.Is(err, service.ErrInsufficientBalance) {
+			errorType = models.ErrorInsufficientBalance
+		}
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    errorType,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// @Summary Captura uma reserva
+// @Description Debita o valor capturado (total ou parcial) e libera o restante da reserva (requer escopo holds:manage)
+// @Tags Account Holds
+// @Accept json
+// @Produce json
+// @Param holdId path string true "ID da reserva"
+// @Param request body service.CaptureHoldRequest false "Valor a capturar"
+// @Success 200 {object} domain.Hold
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/holds/{holdId}/capture [post]
+func (h *HoldHandler) CaptureHold(c *gin.Context) {
+	var request service.CaptureHoldRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Type:    models.ErrorInvalidData,
+				Message: "Dados inválidos",
+			})
+			return
+		}
+	}
+
+	hold, err := h.service.CaptureHold(c.Param("holdId"), request)
+	if err != nil {
+		h.logger.WithError(err).Error("Error capturing hold")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidOperation,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+// @Summary Cancela uma reserva
+// @Description Libera todo o valor reservado sem debitar a conta (requer escopo holds:manage)
+// @Tags Account Holds
+// @Produce json
+// @Param holdId path string true "ID da reserva"
+// @Success 200 {object} domain.Hold
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/holds/{holdId}/void [post]
+func (h *HoldHandler) VoidHold(c *gin.Context) {
+	hold, err := h.service.VoidHold(c.Param("holdId"))
+	if err != nil {
+		h.logger.WithError(err).Error("Error voiding hold")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidOperation,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+// @Summary Consulta uma reserva
+// @Description Retorna a situação da reserva (requer escopo holds:manage)
+// @Tags Account Holds
+// @Produce json
+// @Param holdId path string true "ID da reserva"
+// @Success 200 {object} domain.Hold
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/holds/{holdId} [get]
+func (h *HoldHandler) GetHold(c *gin.Context) {
+	hold, err := h.service.GetHold(c.Param("holdId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
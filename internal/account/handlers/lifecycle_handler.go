@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+
+	"bankmore/internal/account/service"
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Encerra a conta corrente
+// @Description Encerra a conta do usuário logado. Com saldo, exige a conta de destino para transferência do saldo remanescente
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param request body service.CloseAccountRequest true "Senha, motivo e conta para transferência do saldo"
+// @Success 200 {object} service.CloseAccountResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/close [post]
+func (h *AccountHandler) CloseAccount(c *gin.Context) {
+	var request service.CloseAccountRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.CloseAccount(accountID, request, actorFromToken(c))
+	if err != nil {
+		h.logger.WithError(err).Error("Error closing account")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidOperation,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Reativa uma conta corrente
+// @Description Reativa uma conta bloqueada, em encerramento ou encerrada (requer escopo accounts:manage)
+// @Tags Account Admin
+// @Accept json
+// @Param accountNumber path string true "Número da conta"
+// @Param request body service.StatusChangeRequest true "Motivo"
+// @Success 204
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/admin/{accountNumber}/reactivate [put]
+func (h *AccountHandler) ReactivateAccount(c *gin.Context) {
+	var request service.StatusChangeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	if err := h.service.ReactivateAccount(c.Param("accountNumber"), request.Reason, actorFromToken(c)); err != nil {
+		h.logger.WithError(err).Error("Error reactivating account")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidOperation,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Bloqueia uma conta corrente
+// @Description Bloqueia a conta para débitos; créditos continuam aceitos (requer escopo accounts:manage)
+// @Tags Account Admin
+// @Accept json
+// @Param accountNumber path string true "Número da conta"
+// @Param request body service.StatusChangeRequest true "Motivo"
+// @Success 204
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/admin/{accountNumber}/block [put]
+func (h *AccountHandler) BlockAccount(c *gin.Context) {
+	var request service.StatusChangeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	if err := h.service.BlockAccount(c.Param("accountNumber"), request.Reason, actorFromToken(c)); err != nil {
+		h.logger.WithError(err).Error("Error blocking account")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidOperation,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Consulta o histórico de situação da conta
+// @Description Lista as transições de situação com motivo e responsável (requer escopo accounts:read)
+// @Tags Account Admin
+// @Produce json
+// @Param accountNumber path string true "Número da conta"
+// @Success 200 {array} domain.AccountStatusChange
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/admin/{accountNumber}/history [get]
+func (h *AccountHandler) GetStatusHistory(c *gin.Context) {
+	history, err := h.service.GetStatusHistory(c.Param("accountNumber"))
+	if err != nil {
+		h.logger.WithError(err).Error("Error getting account status history")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// actorFromToken identifica o responsável por uma operação no formato perfil:id.
+func actorFromToken(c *gin.Context) string {
+	return c.GetString("role") + ":" + c.GetString("subject")
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+
+	"bankmore/internal/account/service"
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type OperatorHandler struct {
+	service service.OperatorService
+	logger  *logrus.Logger
+}
+
+func NewOperatorHandler(service service.OperatorService, logger *logrus.Logger) *OperatorHandler {
+	return &OperatorHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// @Summary Realiza login de operador do back-office
+// @Description Autentica um operador de suporte ou administração e retorna token JWT com perfil e escopos
+// @Tags Operator
+// @Accept json
+// @Produce json
+// @Param request body service.OperatorLoginRequest true "Dados de login"
+// @Success 200 {object} service.OperatorLoginResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /api/account/operators/login [post]
+func (h *OperatorHandler) Login(c *gin.Context) {
+	var request service.OperatorLoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	response, err := h.service.Login(request)
+	if err != nil {
+		h.logger.WithError(err).Error("Error logging in operator")
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorUserUnauthorized,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Cadastra um operador do back-office
+// @Description Cadastra um operador com perfil support ou admin (requer perfil admin)
+// @Tags Operator
+// @Accept json
+// @Produce json
+// @Param request body service.CreateOperatorRequest true "Dados do operador"
+// @Success 201 {object} domain.Operator
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/operators [post]
+func (h *OperatorHandler) Create(c *gin.Context) {
+	var request service.CreateOperatorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	operator, err := h.service.Create(request)
+	if err != nil {
+		h.logger.WithError(err).Error("Error creating operator")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, operator)
+}
+
+// @Summary Lista os operadores do back-office
+// @Description Lista todos os operadores cadastrados (requer perfil admin)
+// @Tags Operator
+// @Produce json
+// @Success 200 {array} domain.Operator
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/operators [get]
+func (h *OperatorHandler) List(c *gin.Context) {
+	operators, err := h.service.List()
+	if err != nil {
+		h.logger.WithError(err).Error("Error listing operators")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: "Erro interno do servidor",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, operators)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"bankmore/internal/account/service"
+	"bankmore/internal/shared/middleware"
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PixKeyHandler struct {
+	service service.PixKeyService
+	logger  *logrus.Logger
+}
+
+func NewPixKeyHandler(service service.PixKeyService, logger *logrus.Logger) *PixKeyHandler {
+	return &PixKeyHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// @Summary Cadastra uma chave Pix
+// @Description Cadastra chave CPF, EMAIL, PHONE ou RANDOM. Chaves de e-mail e telefone ficam pendentes até a confirmação do código enviado
+// @Tags Pix Keys
+// @Accept json
+// @Produce json
+// @Param request body service.RegisterPixKeyRequest true "Tipo e valor da chave"
+// @Success 201 {object} domain.PixKey
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/keys [post]
+func (h *PixKeyHandler) RegisterKey(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	var request service.RegisterPixKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	key, err := h.service.RegisterKey(accountID, request)
+	if err != nil {
+		h.logger.WithError(err).Error("Error registering pix key")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidOperation,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// @Summary Confirma a posse de uma chave Pix
+// @Description Valida o código enviado por e-mail ou SMS e ativa a chave
+// @Tags Pix Keys
+// @Accept json
+// @Produce json
+// @Param keyId path string true "ID da chave"
+// @Param request body service.ConfirmPixKeyRequest true "Código de confirmação"
+// @Success 200 {object} domain.PixKey
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/keys/{keyId}/confirm [post]
+func (h *PixKeyHandler) ConfirmKey(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	var request service.ConfirmPixKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	key, err := h.service.ConfirmKey(accountID, c.Param("keyId"), request)
+	if err != nil {
+		h.respondPixKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+// @Summary Lista as chaves Pix da conta
+// @Description Lista as chaves ativas e pendentes da conta logada
+// @Tags Pix Keys
+// @Produce json
+// @Success 200 {array} domain.PixKey
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/keys [get]
+func (h *PixKeyHandler) ListKeys(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.service.ListKeys(accountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// @Summary Exclui uma chave Pix
+// @Description Remove a chave da conta logada, liberando o valor para novo cadastro
+// @Tags Pix Keys
+// @Param keyId path string true "ID da chave"
+// @Success 204
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/keys/{keyId} [delete]
+func (h *PixKeyHandler) DeleteKey(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteKey(accountID, c.Param("keyId")); err != nil {
+		h.respondPixKeyError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Consulta o titular de uma chave Pix
+// @Description Retorna tipo da chave, nome e CPF mascarados do titular para conferência. Telefones devem ser informados com +55
+// @Tags Pix Keys
+// @Produce json
+// @Param key query string true "Chave Pix"
+// @Success 200 {object} service.ResolvedPixKey
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/keys/resolve [get]
+func (h *PixKeyHandler) ResolveKey(c *gin.Context) {
+	resolved, err := h.service.ResolveKey(c.Query("key"))
+	if err != nil {
+		h.respondPixKeyError(c, err)
+		return
+	}
+
+	// O número da conta é usado internamente pela API de transferências e não é
+	// exposto aos clientes.
+	if c.GetString("role") != middleware.RoleService {
+		resolved.AccountNumber = ""
+	}
+
+	c.JSON(http.StatusOK, resolved)
+}
+
+func (h *PixKeyHandler) respondPixKeyError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrPixKeyNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Type:    models.ErrorPixKeyNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithError(err).Error("Error handling pix key")
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Type:    models.ErrorInvalidOperation,
+		Message: err.Error(),
+	})
+}
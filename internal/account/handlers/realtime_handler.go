@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"bankmore/internal/shared/models"
+	"bankmore/internal/shared/realtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// retryMillis é o intervalo de reconexão sugerido ao EventSource.
+const retryMillis = 3000
+
+type RealtimeHandler struct {
+	broker    *realtime.Broker
+	heartbeat time.Duration
+	logger    *logrus.Logger
+}
+
+func NewRealtimeHandler(broker *realtime.Broker, logger *logrus.Logger) *RealtimeHandler {
+	return &RealtimeHandler{
+		broker:    broker,
+		heartbeat: realtime.GetHeartbeatInterval(),
+		logger:    logger,
+	}
+}
+
+// @Summary Recebe saldo e movimentações em tempo real
+// @Description Stream Server-Sent Events com os eventos da conta logada: MovementPosted (com o saldo após o lançamento), TransferSent, TransferReceived e FeeCharged. Envia um comentário de heartbeat periodicamente. Com Last-Event-ID (ou lastEventId na query) reenvia os eventos posteriores; se o ID não estiver mais disponível, envia o evento reset e o cliente deve recarregar saldo e extrato
+// @Tags Account
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID do último evento recebido"
+// @Success 200 {string} string "text/event-stream"
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/events [get]
+func (h *RealtimeHandler) StreamEvents(c *gin.Context) {
+	accountID, exists := c.Get("accountId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorUserUnauthorized,
+			Message: "Token inválido",
+		})
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+
+	sub, replay, resumed, err := h.broker.Subscribe(accountID.(string), lastEventID)
+	if err != nil {
+		status := http.StatusTooManyRequests
+		if errors.Is(err, realtime.ErrBrokerClosed) {
+			status = http.StatusServiceUnavailable
+		}
+		c.Header("Retry-After", "30")
+		c.JSON(status, models.ErrorResponse{
+			Type:    models.ErrorTooManyConnections,
+			Message: err.Error(),
+		})
+		return
+	}
+	defer h.broker.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", retryMillis)
+	if !resumed {
+		fmt.Fprint(c.Writer, "event: reset\ndata: {}\n\n")
+	}
+	for _, event := range replay {
+		writeEvent(c, event)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			writeEvent(c, event)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// writeEvent envia o evento completo (id, type, data e occurredAt) como JSON em uma linha.
+func writeEvent(c *gin.Context, event realtime.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, payload)
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"bankmore/internal/account/service"
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Inicia o cadastro de autenticação em dois fatores
+// @Description Gera o segredo TOTP da conta; o 2FA só é habilitado após a confirmação de um código
+// @Tags Account
+// @Produce json
+// @Success 200 {object} service.TwoFactorEnrollmentResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/2fa/enroll [post]
+func (h *AccountHandler) EnrollTwoFactor(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.EnrollTwoFactor(accountID)
+	if err != nil {
+		h.logger.WithError(err).Error("Error enrolling two-factor authentication")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidOperation,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Confirma o cadastro de autenticação em dois fatores
+// @Description Valida o primeiro código TOTP, habilita o 2FA e retorna os códigos de recuperação
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param request body service.TwoFactorCodeRequest true "Código TOTP"
+// @Success 200 {object} service.RecoveryCodesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/2fa/confirm [post]
+func (h *AccountHandler) ConfirmTwoFactor(c *gin.Context) {
+	var request service.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.ConfirmTwoFactor(accountID, request.Code)
+	if err != nil {
+		h.logger.WithError(err).Error("Error confirming two-factor authentication")
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Desabilita a autenticação em dois fatores
+// @Description Desabilita o 2FA mediante senha e código TOTP válido
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param request body service.TwoFactorDisableRequest true "Senha e código TOTP"
+// @Success 204
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/2fa/disable [post]
+func (h *AccountHandler) DisableTwoFactor(c *gin.Context) {
+	var request service.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DisableTwoFactor(accountID, request); err != nil {
+		h.logger.WithError(err).Error("Error disabling two-factor authentication")
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Gera novos códigos de recuperação
+// @Description Invalida os códigos de recuperação anteriores e emite um novo conjunto
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param request body service.TwoFactorCodeRequest true "Código TOTP"
+// @Success 200 {object} service.RecoveryCodesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/2fa/recovery-codes [post]
+func (h *AccountHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var request service.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.RegenerateRecoveryCodes(accountID, request.Code)
+	if err != nil {
+		h.logger.WithError(err).Error("Error regenerating recovery codes")
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Valida um código TOTP para operações sensíveis
+// @Description Usado pela API de transferências antes de valores acima do limite de 2FA. Contas sem 2FA são aceitas sem código
+// @Tags Account
+// @Accept json
+// @Param request body VerifyTwoFactorRequest true "Código TOTP"
+// @Success 204
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/2fa/verify [post]
+func (h *AccountHandler) VerifyTwoFactor(c *gin.Context) {
+	type VerifyTwoFactorRequest struct {
+		Code string `json:"code"`
+	}
+
+	var request VerifyTwoFactorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.VerifyTwoFactor(accountID, request.Code); err != nil {
+		h.logger.WithError(err).Warn("Two-factor verification failed")
+		h.respondTwoFactorError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Conclui o login com autenticação em dois fatores
+// @Description Troca o token parcial do login e um código TOTP (ou de recuperação) pelo token de acesso
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param request body service.TwoFactorLoginRequest true "Token parcial e código"
+// @Success 200 {object} service.LoginResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Router /api/account/login/2fa [post]
+func (h *AccountHandler) CompleteTwoFactorLogin(c *gin.Context) {
+	var request service.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	response, err := h.service.CompleteTwoFactorLogin(request)
+	if errors.Is(err, service.ErrTwoFactorLocked) {
+		h.respondTwoFactorError(c, err)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Error completing two-factor login")
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorUserUnauthorized,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AccountHandler) respondTwoFactorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTwoFactorRequired):
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorTwoFactorRequired,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidTwoFactor):
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorInvalidTwoFactor,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrTwoFactorLocked):
+		c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+			Type:    models.ErrorTwoFactorLocked,
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidOperation,
+			Message: err.Error(),
+		})
+	}
+}
+
+func accountIDFromToken(c *gin.Context) (string, bool) {
+	accountID, exists := c.Get("accountId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorUserUnauthorized,
+			Message: "Token inválido",
+		})
+		return "", false
+	}
+	return accountID.(string), true
+}
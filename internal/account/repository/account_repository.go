@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
 	"bankmore/internal/account/domain"
-	"strconv"
+	ledgerDomain "bankmore/internal/ledger/domain"
+	ledgerRepository "bankmore/internal/ledger/repository"
 
 	"gorm.io/gorm"
 )
@@ -13,13 +17,30 @@ type AccountRepository interface {
 	GetByID(id string) (*domain.Account, error)
 	GetByNumber(number string) (*domain.Account, error)
 	Update(account *domain.Account) error
+	UpdateStatus(account *domain.Account, change *domain.AccountStatusChange) error
+	StartClosing(account *domain.Account, decide func(funds ClosingFunds) (*domain.AccountStatusChange, error)) error
+	GetStatusHistory(accountID string) ([]domain.AccountStatusChange, error)
 	GetBalance(accountID string) (float64, error)
-	CreateMovement(movement *domain.Movement) error
+	GetBlockedAmount(accountID string) (float64, error)
+	GetHeldAmount(accountID string) (float64, error)
+	CreateMovement(movement *domain.Movement) (bool, error)
 	GetNextAccountNumber() (int, error)
 	CheckIdempotency(key string) (*domain.Idempotency, error)
 	SaveIdempotency(idempotency *domain.Idempotency) error
+	ReplaceRecoveryCodes(accountID string, codes []domain.RecoveryCode) error
+	GetUnusedRecoveryCodes(accountID string) ([]domain.RecoveryCode, error)
+	MarkRecoveryCodeUsed(id string) error
+	ReserveTwoFactorAttempt(accountID string, maxAttempts int, now time.Time) (bool, error)
+	LockTwoFactorIfExhausted(accountID string, maxAttempts int, until time.Time) (bool, error)
+	ResetTwoFactorAttempts(accountID string) error
 }
 
+var errMovementNotCovered = errors.New("debit not covered by available balance")
+
+// twoFactorAttemptColumns ficam fora de Update e UpdateStatus: um Save com a conta lida
+// antes de uma tentativa simultânea desfaria a contagem.
+var twoFactorAttemptColumns = []string{"totp_tentativas", "totp_bloqueado_ate"}
+
 type accountRepository struct {
 	db *gorm.DB
 }
@@ -60,20 +81,185 @@ func (r *accountRepository) GetByNumber(number string) (*domain.Account, error)
 }
 
 func (r *accountRepository) Update(account *domain.Account) error {
-	return r.db.Save(account).Error
+	return r.db.Omit(twoFactorAttemptColumns...).Save(account).Error
+}
+
+func (r *accountRepository) UpdateStatus(account *domain.Account, change *domain.AccountStatusChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(twoFactorAttemptColumns...).Save(account).Error; err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+}
+
+// ClosingFunds são o saldo, os bloqueios e as reservas lidos no início do encerramento.
+type ClosingFunds struct {
+	Balance float64
+	Blocked float64
+	Held    float64
+}
+
+// StartClosing passa a conta para CLOSING antes de ler o saldo, os bloqueios e as
+// reservas, na mesma transação: créditos e reservas gravados depois conferem a nova
+// situação e são recusados, então o saldo lido é o que será transferido. decide
+// devolve a mudança de situação a gravar; se devolver erro, nada é gravado.
+func (r *accountRepository) StartClosing(account *domain.Account, decide func(funds ClosingFunds) (*domain.AccountStatusChange, error)) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Account{}).
+			Where("idcontacorrente = ?", account.ID).
+			Updates(map[string]interface{}{"situacao": domain.AccountStatusClosing, "ativo": false}).Error; err != nil {
+			return err
+		}
+
+		var funds ClosingFunds
+		var err error
+		if funds.Balance, err = ledgerRepository.CustomerBalance(tx, account.ID); err != nil {
+			return err
+		}
+		if funds.Blocked, err = blockedAmount(tx, account.ID); err != nil {
+			return err
+		}
+		if funds.Held, err = heldAmount(tx, account.ID, time.Now()); err != nil {
+			return err
+		}
+
+		change, err := decide(funds)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Omit(twoFactorAttemptColumns...).Save(account).Error; err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+}
+
+func (r *accountRepository) GetStatusHistory(accountID string) ([]domain.AccountStatusChange, error) {
+	var history []domain.AccountStatusChange
+	err := r.db.Where("idcontacorrente = ?", accountID).
+		Order("data DESC").
+		Find(&history).Error
+	return history, err
 }
 
+// GetBalance lê o saldo do razão contábil, que é a fonte oficial do saldo da conta.
 func (r *accountRepository) GetBalance(accountID string) (float64, error) {
-	var balance float64
-	err := r.db.Model(&domain.Movement{}).
-		Select("COALESCE(SUM(CASE WHEN tipomovimento = 'C' THEN valor ELSE -valor END), 0)").
-		Where("idcontacorrente = ?", accountID).
-		Scan(&balance).Error
-	return balance, err
+	return ledgerRepository.CustomerBalance(r.db, accountID)
+}
+
+func (r *accountRepository) GetBlockedAmount(accountID string) (float64, error) {
+	return blockedAmount(r.db, accountID)
+}
+
+func (r *accountRepository) GetHeldAmount(accountID string) (float64, error) {
+	return heldAmount(r.db, accountID, time.Now())
+}
+
+func blockedAmount(db *gorm.DB, accountID string) (float64, error) {
+	var blocked float64
+	err := db.Model(&domain.BalanceBlock{}).
+		Select("COALESCE(SUM(valor - valor_liberado), 0)").
+		Where("idcontacorrente = ? AND situacao <> ?", accountID, domain.BlockStatusReleased).
+		Scan(&blocked).Error
+	return blocked, err
 }
 
-func (r *accountRepository) CreateMovement(movement *domain.Movement) error {
-	return r.db.Create(movement).Error
+func heldAmount(db *gorm.DB, accountID string, now time.Time) (float64, error) {
+	var held float64
+	err := db.Model(&domain.Hold{}).
+		Select("COALESCE(SUM(valor), 0)").
+		Where("idcontacorrente = ? AND situacao = ? AND data_expiracao > ?", accountID, domain.HoldStatusAuthorized, now).
+		Scan(&held).Error
+	return held, err
+}
+
+// CreateMovement grava a movimentação e, nos débitos, confere o saldo disponível na
+// mesma transação, já com o débito: débitos simultâneos não deixam a conta negativa nem
+// consomem valores bloqueados ou reservados. Retorna false se o saldo não cobre o débito.
+func (r *accountRepository) CreateMovement(movement *domain.Movement) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkAccountStatus(tx, movement.AccountID, func(account *domain.Account) bool {
+			return account.CanPost(movement.Type)
+		}); err != nil {
+			return err
+		}
+		if err := createMovement(tx, movement); err != nil {
+			return err
+		}
+		if movement.Type != domain.MovementTypeDebit {
+			return nil
+		}
+
+		balance, err := ledgerRepository.CustomerBalance(tx, movement.AccountID)
+		if err != nil {
+			return err
+		}
+		blocked, err := blockedAmount(tx, movement.AccountID)
+		if err != nil {
+			return err
+		}
+		held, err := heldAmount(tx, movement.AccountID, movement.Date)
+		if err != nil {
+			return err
+		}
+
+		if balance-blocked-held < -0.005 {
+			return errMovementNotCovered
+		}
+		return nil
+	})
+	if errors.Is(err, errMovementNotCovered) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// checkAccountStatus relê a situação da conta na transação da gravação, para que uma
+// mudança feita depois da validação do serviço, como o início do encerramento, já valha.
+func checkAccountStatus(tx *gorm.DB, accountID string, allowed func(account *domain.Account) bool) error {
+	var account domain.Account
+	if err := tx.Select("idcontacorrente", "ativo", "situacao").Where("idcontacorrente = ?", accountID).First(&account).Error; err != nil {
+		return err
+	}
+	if !allowed(&account) {
+		return domain.ErrStatusChanged
+	}
+	return nil
+}
+
+// createMovement grava a movimentação com sua data contábil e a registra no razão.
+func createMovement(tx *gorm.DB, movement *domain.Movement) error {
+	accountingDate, err := ledgerRepository.AccountingDate(tx, movement.Date)
+	if err != nil {
+		return err
+	}
+	movement.AccountingDate = &accountingDate
+
+	if err := tx.Create(movement).Error; err != nil {
+		return err
+	}
+	return postMovement(tx, movement)
+}
+
+// postMovement registra a movimentação no razão na mesma transação em que ela é gravada.
+func postMovement(tx *gorm.DB, movement *domain.Movement) error {
+	var account domain.Account
+	if err := tx.Select("idcontacorrente", "numero").Where("idcontacorrente = ?", movement.AccountID).First(&account).Error; err != nil {
+		return err
+	}
+
+	return ledgerRepository.PostMovement(tx, ledgerDomain.MovementRecord{
+		MovementID:     movement.ID,
+		AccountID:      movement.AccountID,
+		AccountNumber:  account.Number,
+		Type:           movement.Type,
+		Category:       movement.Category,
+		Amount:         movement.Amount,
+		Date:           movement.Date,
+		AccountingDate: movement.AccountingDate,
+	})
 }
 
 func (r *accountRepository) GetNextAccountNumber() (int, error) {
@@ -99,3 +285,55 @@ func (r *accountRepository) CheckIdempotency(key string) (*domain.Idempotency, e
 func (r *accountRepository) SaveIdempotency(idempotency *domain.Idempotency) error {
 	return r.db.Create(idempotency).Error
 }
+
+func (r *accountRepository) ReplaceRecoveryCodes(accountID string, codes []domain.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("idcontacorrente = ?", accountID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *accountRepository) GetUnusedRecoveryCodes(accountID string) ([]domain.RecoveryCode, error) {
+	var codes []domain.RecoveryCode
+	err := r.db.Where("idcontacorrente = ? AND utilizado = ?", accountID, false).Find(&codes).Error
+	return codes, err
+}
+
+func (r *accountRepository) MarkRecoveryCodeUsed(id string) error {
+	return r.db.Model(&domain.RecoveryCode{}).
+		Where("idcodigo = ? AND utilizado = ?", id, false).
+		Update("utilizado", true).Error
+}
+
+// ReserveTwoFactorAttempt conta uma tentativa de código antes da verificação. O UPDATE
+// condicional é atômico, então requisições simultâneas não passam de maxAttempts; retorna
+// false quando as tentativas se esgotaram ou o 2FA está bloqueado.
+func (r *accountRepository) ReserveTwoFactorAttempt(accountID string, maxAttempts int, now time.Time) (bool, error) {
+	result := r.db.Model(&domain.Account{}).
+		Where("idcontacorrente = ? AND totp_tentativas < ? AND (totp_bloqueado_ate IS NULL OR totp_bloqueado_ate <= ?)", accountID, maxAttempts, now).
+		Update("totp_tentativas", gorm.Expr("totp_tentativas + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// LockTwoFactorIfExhausted bloqueia o 2FA até until e zera o contador quando as tentativas
+// chegaram a maxAttempts. Retorna true apenas para quem efetivamente bloqueou.
+func (r *accountRepository) LockTwoFactorIfExhausted(accountID string, maxAttempts int, until time.Time) (bool, error) {
+	result := r.db.Model(&domain.Account{}).
+		Where("idcontacorrente = ? AND totp_tentativas >= ?", accountID, maxAttempts).
+		Updates(map[string]interface{}{
+			"totp_tentativas":    0,
+			"totp_bloqueado_ate": until,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *accountRepository) ResetTwoFactorAttempts(accountID string) error {
+	return r.db.Model(&domain.Account{}).
+		Where("idcontacorrente = ?", accountID).
+		Update("totp_tentativas", 0).Error
+}
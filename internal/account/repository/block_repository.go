@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"bankmore/internal/account/domain"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var errBlockNotCovered = errors.New("release exceeds remaining blocked amount")
+
+type BlockRepository interface {
+	Create(block *domain.BalanceBlock) error
+	GetByID(id string) (*domain.BalanceBlock, error)
+	GetByAccountID(accountID string) ([]domain.BalanceBlock, error)
+	Release(block *domain.BalanceBlock, release *domain.BlockRelease) (bool, error)
+	GetReleases(blockID string) ([]domain.BlockRelease, error)
+}
+
+type blockRepository struct {
+	db *gorm.DB
+}
+
+func NewBlockRepository(db *gorm.DB) BlockRepository {
+	return &blockRepository{db: db}
+}
+
+func (r *blockRepository) Create(block *domain.BalanceBlock) error {
+	return r.db.Create(block).Error
+}
+
+func (r *blockRepository) GetByID(id string) (*domain.BalanceBlock, error) {
+	var block domain.BalanceBlock
+	err := r.db.Where("idbloqueio = ?", id).First(&block).Error
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (r *blockRepository) GetByAccountID(accountID string) ([]domain.BalanceBlock, error) {
+	var blocks []domain.BalanceBlock
+	err := r.db.Where("idcontacorrente = ?", accountID).
+		Order("data_bloqueio DESC").
+		Find(&blocks).Error
+	return blocks, err
+}
+
+// Release soma a liberação ao valor liberado de forma atômica, desde que não passe do
+// remanescente: duas liberações parciais simultâneas não se sobrescrevem. Retorna false
+// se o remanescente não cobre mais a liberação. Em caso de sucesso, block é relido.
+func (r *blockRepository) Release(block *domain.BalanceBlock, release *domain.BlockRelease) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// O SET usa os valores anteriores à atualização em todas as expressões.
+		result := tx.Model(&domain.BalanceBlock{}).
+			Where("idbloqueio = ? AND situacao <> ? AND valor - valor_liberado >= ? - 0.005", block.ID, domain.BlockStatusReleased, release.Amount).
+			Updates(map[string]interface{}{
+				"valor_liberado": gorm.Expr("CASE WHEN valor - valor_liberado - ? < 0.005 THEN valor ELSE valor_liberado + ? END", release.Amount, release.Amount),
+				"situacao":       gorm.Expr("CASE WHEN valor - valor_liberado - ? < 0.005 THEN ? ELSE ? END", release.Amount, domain.BlockStatusReleased, domain.BlockStatusPartiallyReleased),
+				"data_liberacao": gorm.Expr("CASE WHEN valor - valor_liberado - ? < 0.005 THEN ? ELSE data_liberacao END", release.Amount, time.Now()),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return errBlockNotCovered
+		}
+
+		if err := tx.Create(release).Error; err != nil {
+			return err
+		}
+		return tx.Where("idbloqueio = ?", block.ID).First(block).Error
+	})
+	if errors.Is(err, errBlockNotCovered) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *blockRepository) GetReleases(blockID string) ([]domain.BlockRelease, error) {
+	var releases []domain.BlockRelease
+	err := r.db.Where("idbloqueio = ?", blockID).
+		Order("data").
+		Find(&releases).Error
+	return releases, err
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"bankmore/internal/account/domain"
+	ledgerRepository "bankmore/internal/ledger/repository"
+
+	"gorm.io/gorm"
+)
+
+type HoldRepository interface {
+	CreateIfAvailable(hold *domain.Hold) (bool, error)
+	GetByID(id string) (*domain.Hold, error)
+	GetByRequestID(requestID string) (*domain.Hold, error)
+	Finish(hold *domain.Hold) (bool, error)
+	Capture(hold *domain.Hold, movement *domain.Movement) (bool, error)
+	GetExpired(now time.Time) ([]domain.Hold, error)
+}
+
+// errHoldNotCovered desfaz a transação de CreateIfAvailable quando falta saldo.
+var errHoldNotCovered = errors.New("hold not covered by available balance")
+
+type holdRepository struct {
+	db *gorm.DB
+}
+
+func NewHoldRepository(db *gorm.DB) HoldRepository {
+	return &holdRepository{db: db}
+}
+
+// CreateIfAvailable grava a reserva e confere o saldo disponível na mesma transação. O
+// INSERT vem antes da consulta para que a transação já detenha a escrita no banco: duas
+// reservas simultâneas não leem o mesmo saldo disponível. Retorna false, sem gravar,
+// quando o saldo não cobre a reserva.
+func (r *holdRepository) CreateIfAvailable(hold *domain.Hold) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkAccountStatus(tx, hold.AccountID, (*domain.Account).CanPlaceHold); err != nil {
+			return err
+		}
+		if err := tx.Create(hold).Error; err != nil {
+			return err
+		}
+
+		balance, err := ledgerRepository.CustomerBalance(tx, hold.AccountID)
+		if err != nil {
+			return err
+		}
+		blocked, err := blockedAmount(tx, hold.AccountID)
+		if err != nil {
+			return err
+		}
+		// A soma já inclui a reserva recém-gravada.
+		held, err := heldAmount(tx, hold.AccountID, hold.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		if balance-blocked-held < -0.005 {
+			return errHoldNotCovered
+		}
+		return nil
+	})
+	if errors.Is(err, errHoldNotCovered) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *holdRepository) GetByID(id string) (*domain.Hold, error) {
+	var hold domain.Hold
+	err := r.db.Where("idreserva = ?", id).First(&hold).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *holdRepository) GetByRequestID(requestID string) (*domain.Hold, error) {
+	var hold domain.Hold
+	err := r.db.Where("idempotencia_key = ?", requestID).First(&hold).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// Finish grava a conclusão (cancelamento ou expiração) de uma reserva ainda autorizada.
+// Retorna false quando outra requisição já a concluiu.
+func (r *holdRepository) Finish(hold *domain.Hold) (bool, error) {
+	return finishAuthorizedHold(r.db, hold)
+}
+
+// Capture conclui a reserva e grava o débito na mesma transação. Entre capturas
+// simultâneas só a primeira encontra a reserva autorizada e debita; as demais recebem
+// false.
+func (r *holdRepository) Capture(hold *domain.Hold, movement *domain.Movement) (bool, error) {
+	captured := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		finished, err := finishAuthorizedHold(tx, hold)
+		if err != nil || !finished {
+			return err
+		}
+		if err := createMovement(tx, movement); err != nil {
+			return err
+		}
+		captured = true
+		return nil
+	})
+	return captured && err == nil, err
+}
+
+func finishAuthorizedHold(db *gorm.DB, hold *domain.Hold) (bool, error) {
+	result := db.Model(&domain.Hold{}).
+		Where("idreserva = ? AND situacao = ?", hold.ID, domain.HoldStatusAuthorized).
+		Updates(map[string]interface{}{
+			"situacao":        hold.Status,
+			"valor_capturado": hold.CapturedAmount,
+			"idmovimento":     hold.MovementID,
+			"data_conclusao":  hold.FinishedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *holdRepository) GetExpired(now time.Time) ([]domain.Hold, error) {
+	var holds []domain.Hold
+	err := r.db.Where("situacao = ? AND data_expiracao <= ?", domain.HoldStatusAuthorized, now).
+		Find(&holds).Error
+	return holds, err
+}
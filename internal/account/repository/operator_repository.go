@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"bankmore/internal/account/domain"
+
+	"gorm.io/gorm"
+)
+
+type OperatorRepository interface {
+	Create(operator *domain.Operator) error
+	GetByLogin(login string) (*domain.Operator, error)
+	List() ([]domain.Operator, error)
+	Count() (int64, error)
+}
+
+type operatorRepository struct {
+	db *gorm.DB
+}
+
+func NewOperatorRepository(db *gorm.DB) OperatorRepository {
+	return &operatorRepository{db: db}
+}
+
+func (r *operatorRepository) Create(operator *domain.Operator) error {
+	return r.db.Create(operator).Error
+}
+
+func (r *operatorRepository) GetByLogin(login string) (*domain.Operator, error) {
+	var operator domain.Operator
+	err := r.db.Where("login = ?", login).First(&operator).Error
+	if err != nil {
+		return nil, err
+	}
+	return &operator, nil
+}
+
+func (r *operatorRepository) List() ([]domain.Operator, error) {
+	var operators []domain.Operator
+	err := r.db.Order("login").Find(&operators).Error
+	return operators, err
+}
+
+func (r *operatorRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Operator{}).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"bankmore/internal/account/domain"
+
+	"gorm.io/gorm"
+)
+
+type PixKeyRepository interface {
+	Create(key *domain.PixKey) error
+	GetByID(id string) (*domain.PixKey, error)
+	GetByValue(value string) (*domain.PixKey, error)
+	GetByAccountID(accountID string) ([]domain.PixKey, error)
+	CountByAccountID(accountID string) (int64, error)
+	Update(key *domain.PixKey) error
+	Delete(key *domain.PixKey) error
+	ReserveConfirmationAttempt(id string, maxAttempts int) (bool, error)
+	Activate(key *domain.PixKey, confirmationHash string) (bool, error)
+}
+
+type pixKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewPixKeyRepository(db *gorm.DB) PixKeyRepository {
+	return &pixKeyRepository{db: db}
+}
+
+func (r *pixKeyRepository) Create(key *domain.PixKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *pixKeyRepository) GetByID(id string) (*domain.PixKey, error) {
+	var key domain.PixKey
+	err := r.db.Where("idchave = ?", id).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *pixKeyRepository) GetByValue(value string) (*domain.PixKey, error) {
+	var key domain.PixKey
+	err := r.db.Where("valor = ?", value).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *pixKeyRepository) GetByAccountID(accountID string) ([]domain.PixKey, error) {
+	var keys []domain.PixKey
+	err := r.db.Where("idcontacorrente = ?", accountID).
+		Order("data_criacao ASC").
+		Find(&keys).Error
+	return keys, err
+}
+
+func (r *pixKeyRepository) CountByAccountID(accountID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.PixKey{}).Where("idcontacorrente = ?", accountID).Count(&count).Error
+	return count, err
+}
+
+func (r *pixKeyRepository) Update(key *domain.PixKey) error {
+	return r.db.Save(key).Error
+}
+
+func (r *pixKeyRepository) Delete(key *domain.PixKey) error {
+	return r.db.Delete(key).Error
+}
+
+// ReserveConfirmationAttempt soma uma tentativa de confirmação de forma atômica, desde
+// que a chave esteja pendente e abaixo do limite. Tentativas simultâneas não passam do
+// limite porque cada uma precisa da sua reserva.
+func (r *pixKeyRepository) ReserveConfirmationAttempt(id string, maxAttempts int) (bool, error) {
+	result := r.db.Model(&domain.PixKey{}).
+		Where("idchave = ? AND situacao = ? AND tentativas_confirmacao < ?", id, domain.PixKeyStatusPending, maxAttempts).
+		Update("tentativas_confirmacao", gorm.Expr("tentativas_confirmacao + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
+// Activate grava a confirmação se a chave ainda estiver pendente com o código conferido;
+// um novo código enviado nesse meio tempo invalida a confirmação.
+func (r *pixKeyRepository) Activate(key *domain.PixKey, confirmationHash string) (bool, error) {
+	result := r.db.Model(&domain.PixKey{}).
+		Where("idchave = ? AND situacao = ? AND codigo_confirmacao = ?", key.ID, domain.PixKeyStatusPending, confirmationHash).
+		Updates(map[string]interface{}{
+			"situacao":              key.Status,
+			"codigo_confirmacao":    key.ConfirmationHash,
+			"data_expiracao_codigo": key.ConfirmationExpiresAt,
+			"data_confirmacao":      key.ConfirmedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+
+	"bankmore/internal/account/domain"
+
+	"gorm.io/gorm"
+)
+
+// statementBatchSize limita quantos movimentos ficam em memória de cada vez no extrato.
+const statementBatchSize = 500
+
+type StatementRepository interface {
+	GetBalanceBefore(accountID string, before time.Time) (float64, error)
+	ForEachMovement(accountID string, from, to time.Time, fn func(movement domain.Movement) error) error
+}
+
+type statementRepository struct {
+	db *gorm.DB
+}
+
+func NewStatementRepository(db *gorm.DB) StatementRepository {
+	return &statementRepository{db: db}
+}
+
+// GetBalanceBefore soma os movimentos da conta anteriores a before: o saldo de abertura do extrato.
+func (r *statementRepository) GetBalanceBefore(accountID string, before time.Time) (float64, error) {
+	var balance float64
+	err := r.db.Model(&domain.Movement{}).
+		Select("COALESCE(SUM(CASE WHEN tipomovimento = ? THEN valor ELSE -valor END), 0)", domain.MovementTypeCredit).
+		Where("idcontacorrente = ? AND datamovimento < ?", accountID, before).
+		Scan(&balance).Error
+	return balance, err
+}
+
+// ForEachMovement chama fn para cada movimento da conta em [from, to), em ordem
+// cronológica. Os movimentos são lidos em lotes pela posição do último lido, sem manter
+// um cursor aberto no banco enquanto fn escreve a resposta.
+func (r *statementRepository) ForEachMovement(accountID string, from, to time.Time, fn func(movement domain.Movement) error) error {
+	var last *domain.Movement
+	for {
+		query := r.db.Where("idcontacorrente = ? AND datamovimento >= ? AND datamovimento < ?", accountID, from, to)
+		if last != nil {
+			query = query.Where("(datamovimento > ? OR (datamovimento = ? AND idmovimento > ?))", last.Date, last.Date, last.ID)
+		}
+
+		var movements []domain.Movement
+		err := query.Order("datamovimento ASC, idmovimento ASC").Limit(statementBatchSize).Find(&movements).Error
+		if err != nil {
+			return err
+		}
+
+		for _, movement := range movements {
+			if err := fn(movement); err != nil {
+				return err
+			}
+		}
+		if len(movements) < statementBatchSize {
+			return nil
+		}
+		last = &movements[len(movements)-1]
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"time"
 
 	"bankmore/internal/account/domain"
 	"bankmore/internal/account/repository"
+	ledgerDomain "bankmore/internal/ledger/domain"
 	"bankmore/internal/shared/middleware"
-	"bankmore/internal/shared/models"
+	"bankmore/internal/shared/realtime"
 	"bankmore/internal/shared/utils"
 
 	"github.com/sirupsen/logrus"
@@ -24,17 +27,35 @@ type AccountService interface {
 	GetBalance(accountID string) (*BalanceResponse, error)
 	GetBalanceByAccountNumber(accountNumber string) (*BalanceResponse, error)
 	AccountExists(accountNumber string) (bool, error)
+	EnrollTwoFactor(accountID string) (*TwoFactorEnrollmentResponse, error)
+	ConfirmTwoFactor(accountID, code string) (*RecoveryCodesResponse, error)
+	DisableTwoFactor(accountID string, request TwoFactorDisableRequest) error
+	RegenerateRecoveryCodes(accountID, code string) (*RecoveryCodesResponse, error)
+	VerifyTwoFactor(accountID, code string) error
+	CompleteTwoFactorLogin(request TwoFactorLoginRequest) (*LoginResponse, error)
+	CloseAccount(accountID string, request CloseAccountRequest, actor string) (*CloseAccountResponse, error)
+	ReactivateAccount(accountNumber, reason, actor string) error
+	BlockAccount(accountNumber, reason, actor string) error
+	GetStatusHistory(accountNumber string) ([]domain.AccountStatusChange, error)
 }
 
 type accountService struct {
-	repo   repository.AccountRepository
-	logger *logrus.Logger
+	repo      repository.AccountRepository
+	publisher realtime.Publisher
+	logger    *logrus.Logger
+	clock     utils.Clock
 }
 
-func NewAccountService(repo repository.AccountRepository, logger *logrus.Logger) AccountService {
+func NewAccountService(repo repository.AccountRepository, publisher realtime.Publisher, logger *logrus.Logger) AccountService {
+	return NewAccountServiceWithClock(repo, publisher, logger, utils.SystemClock{})
+}
+
+func NewAccountServiceWithClock(repo repository.AccountRepository, publisher realtime.Publisher, logger *logrus.Logger, clock utils.Clock) AccountService {
 	return &accountService{
-		repo:   repo,
-		logger: logger,
+		repo:      repo,
+		publisher: publisher,
+		logger:    logger,
+		clock:     clock,
 	}
 }
 
@@ -54,20 +75,31 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token         string `json:"token"`
-	AccountNumber string `json:"accountNumber"`
+	Token             string `json:"token,omitempty"`
+	AccountNumber     string `json:"accountNumber"`
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	PartialToken      string `json:"partialToken,omitempty"`
 }
 
+// ErrIdempotencyKeyReused indica um requestId já usado em uma movimentação diferente.
+var ErrIdempotencyKeyReused = errors.New("requestId já utilizado com outros dados")
+
+// MovementRequest.Category define a contrapartida no razão contábil: CASH (padrão,
+// depósito ou saque), TRANSFER, FEE ou BOLETO.
 type MovementRequest struct {
 	RequestID     string  `json:"requestId" binding:"required"`
 	AccountNumber string  `json:"accountNumber" binding:"required"`
 	Amount        float64 `json:"amount" binding:"required"`
 	Type          string  `json:"type" binding:"required"`
+	Category      string  `json:"category"`
 }
 
 type BalanceResponse struct {
-	AccountNumber string  `json:"accountNumber"`
-	Balance       float64 `json:"balance"`
+	AccountNumber    string  `json:"accountNumber"`
+	Balance          float64 `json:"balance"`
+	AvailableBalance float64 `json:"availableBalance"`
+	BlockedBalance   float64 `json:"blockedBalance"`
+	HeldBalance      float64 `json:"heldBalance"`
 }
 
 func (s *accountService) Register(request RegisterRequest) (*RegisterResponse, error) {
@@ -130,7 +162,7 @@ func (s *accountService) Login(request LoginRequest) (*LoginResponse, error) {
 		return nil, fmt.Errorf("erro interno do servidor")
 	}
 
-	if !account.Active {
+	if !account.CanLogin() {
 		return nil, fmt.Errorf("conta inativa")
 	}
 
@@ -138,51 +170,30 @@ func (s *accountService) Login(request LoginRequest) (*LoginResponse, error) {
 		return nil, fmt.Errorf("credenciais inválidas")
 	}
 
-	token, err := middleware.GenerateJWT(account.ID, strconv.Itoa(account.Number), account.CPF)
-	if err != nil {
-		s.logger.WithError(err).Error("Error generating JWT token")
-		return nil, fmt.Errorf("erro interno do servidor")
-	}
-
-	s.logger.WithFields(logrus.Fields{
-		"accountId":     account.ID,
-		"accountNumber": account.Number,
-		"cpf":           cleanCPF,
-	}).Info("User logged in successfully")
-
-	return &LoginResponse{
-		Token:         token,
-		AccountNumber: strconv.Itoa(account.Number),
-	}, nil
-}
-
-func (s *accountService) Deactivate(accountID, password string) error {
-	account, err := s.repo.GetByID(accountID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return fmt.Errorf("conta não encontrada")
+	if account.TwoFactorEnabled {
+		partialToken, err := middleware.GeneratePartialJWT(account.ID)
+		if err != nil {
+			s.logger.WithError(err).Error("Error generating partial JWT token")
+			return nil, fmt.Errorf("erro interno do servidor")
 		}
-		s.logger.WithError(err).Error("Error getting account by ID")
-		return fmt.Errorf("erro interno do servidor")
-	}
-
-	if !utils.VerifyPassword(password, account.Salt, account.PasswordHash) {
-		return fmt.Errorf("senha inválida")
-	}
 
-	account.Deactivate()
-
-	if err := s.repo.Update(account); err != nil {
-		s.logger.WithError(err).Error("Error deactivating account")
-		return fmt.Errorf("erro interno do servidor")
+		return &LoginResponse{
+			AccountNumber:     strconv.Itoa(account.Number),
+			TwoFactorRequired: true,
+			PartialToken:      partialToken,
+		}, nil
 	}
 
-	s.logger.WithFields(logrus.Fields{
-		"accountId":     account.ID,
-		"accountNumber": account.Number,
-	}).Info("Account deactivated successfully")
+	return s.issueLoginToken(account)
+}
 
-	return nil
+// Deactivate mantém a rota antiga de inativação: encerra a conta somente se não houver saldo.
+func (s *accountService) Deactivate(accountID, password string) error {
+	_, err := s.CloseAccount(accountID, CloseAccountRequest{
+		Password: password,
+		Reason:   "Inativação solicitada pelo titular",
+	}, middleware.RoleCustomer+":"+accountID)
+	return err
 }
 
 func (s *accountService) CreateMovement(request MovementRequest) error {
@@ -194,12 +205,27 @@ func (s *accountService) CreateMovement(request MovementRequest) error {
 		return fmt.Errorf("valor deve ser positivo")
 	}
 
+	if request.Category == "" {
+		request.Category = ledgerDomain.CategoryCash
+	}
+	if !ledgerDomain.IsValidCategory(request.Category) {
+		return fmt.Errorf("categoria de movimentação inválida")
+	}
+
 	idempotency, err := s.repo.CheckIdempotency(request.RequestID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		s.logger.WithError(err).Error("Error checking idempotency")
 		return fmt.Errorf("erro interno do servidor")
 	}
 	if idempotency != nil {
+		var original MovementRequest
+		if err := json.Unmarshal([]byte(idempotency.Request), &original); err != nil ||
+			original.AccountNumber != request.AccountNumber ||
+			original.Type != request.Type ||
+			math.Abs(original.Amount-request.Amount) >= 0.005 {
+			s.logger.WithField("requestId", request.RequestID).Warn("Idempotency key reused with different payload")
+			return ErrIdempotencyKeyReused
+		}
 		s.logger.WithField("requestId", request.RequestID).Info("Duplicate request ignored")
 		return nil
 	}
@@ -213,27 +239,27 @@ func (s *accountService) CreateMovement(request MovementRequest) error {
 		return fmt.Errorf("erro interno do servidor")
 	}
 
-	if !account.Active {
-		return fmt.Errorf("conta inativa")
+	if request.Type == domain.MovementTypeCredit && !account.CanCredit() {
+		return fmt.Errorf("conta não aceita créditos na situação %s", account.CurrentStatus())
 	}
 
-	if request.Type == domain.MovementTypeDebit {
-		balance, err := s.repo.GetBalance(account.ID)
-		if err != nil {
-			s.logger.WithError(err).Error("Error getting account balance")
-			return fmt.Errorf("erro interno do servidor")
-		}
-		if balance < request.Amount {
-			return fmt.Errorf("saldo insuficiente")
-		}
+	if request.Type == domain.MovementTypeDebit && !account.CanDebit() {
+		return fmt.Errorf("conta não aceita débitos na situação %s", account.CurrentStatus())
 	}
 
-	movement := domain.NewMovement(account.ID, request.Type, request.Amount, &request.RequestID)
+	movement := domain.NewMovement(account.ID, request.Type, request.Category, request.Amount, &request.RequestID)
 
-	if err := s.repo.CreateMovement(movement); err != nil {
+	created, err := s.repo.CreateMovement(movement)
+	if errors.Is(err, domain.ErrStatusChanged) {
+		return err
+	}
+	if err != nil {
 		s.logger.WithError(err).Error("Error creating movement")
 		return fmt.Errorf("erro interno do servidor")
 	}
+	if !created {
+		return ErrInsufficientBalance
+	}
 
 	requestData, _ := json.Marshal(request)
 	idempotencyRecord := &domain.Idempotency{
@@ -247,13 +273,50 @@ func (s *accountService) CreateMovement(request MovementRequest) error {
 		"accountId":     account.ID,
 		"accountNumber": account.Number,
 		"movementType":  request.Type,
+		"category":      request.Category,
 		"amount":        request.Amount,
 		"requestId":     request.RequestID,
 	}).Info("Movement created successfully")
 
+	s.publishMovement(account, movement)
+
 	return nil
 }
 
+// MovementPostedData é o conteúdo do evento MovementPosted, com o saldo após o lançamento.
+type MovementPostedData struct {
+	MovementID       string    `json:"movementId"`
+	Type             string    `json:"type"`
+	Amount           float64   `json:"amount"`
+	Date             time.Time `json:"date"`
+	RequestID        string    `json:"requestId"`
+	Balance          *float64  `json:"balance,omitempty"`
+	AvailableBalance *float64  `json:"availableBalance,omitempty"`
+}
+
+// publishMovement notifica os clientes conectados da conta. Sem o saldo, o evento vai
+// sem ele: o lançamento já foi gravado e não deve falhar por causa da notificação.
+func (s *accountService) publishMovement(account *domain.Account, movement *domain.Movement) {
+	data := MovementPostedData{
+		MovementID: movement.ID,
+		Type:       movement.Type,
+		Amount:     movement.Amount,
+		Date:       movement.Date,
+		RequestID:  *movement.IdempotencyKey,
+	}
+	if balance, err := s.getBalanceResponse(account); err == nil {
+		data.Balance = &balance.Balance
+		data.AvailableBalance = &balance.AvailableBalance
+	}
+
+	event, err := realtime.NewEvent(movement.ID, account.ID, realtime.EventMovementPosted, data, movement.Date)
+	if err != nil {
+		s.logger.WithError(err).WithField("movementId", movement.ID).Error("Error building movement event")
+		return
+	}
+	s.publisher.Publish(event)
+}
+
 func (s *accountService) GetBalance(accountID string) (*BalanceResponse, error) {
 	account, err := s.repo.GetByID(accountID)
 	if err != nil {
@@ -264,16 +327,7 @@ func (s *accountService) GetBalance(accountID string) (*BalanceResponse, error)
 		return nil, fmt.Errorf("erro interno do servidor")
 	}
 
-	balance, err := s.repo.GetBalance(accountID)
-	if err != nil {
-		s.logger.WithError(err).Error("Error getting account balance")
-		return nil, fmt.Errorf("erro interno do servidor")
-	}
-
-	return &BalanceResponse{
-		AccountNumber: strconv.Itoa(account.Number),
-		Balance:       balance,
-	}, nil
+	return s.getBalanceResponse(account)
 }
 
 func (s *accountService) GetBalanceByAccountNumber(accountNumber string) (*BalanceResponse, error) {
@@ -286,15 +340,40 @@ func (s *accountService) GetBalanceByAccountNumber(accountNumber string) (*Balan
 		return nil, fmt.Errorf("erro interno do servidor")
 	}
 
+	return s.getBalanceResponse(account)
+}
+
+// getBalanceResponse separa o saldo contábil dos valores bloqueados e reservados e do disponível para débito.
+func (s *accountService) getBalanceResponse(account *domain.Account) (*BalanceResponse, error) {
 	balance, err := s.repo.GetBalance(account.ID)
 	if err != nil {
 		s.logger.WithError(err).Error("Error getting account balance")
 		return nil, fmt.Errorf("erro interno do servidor")
 	}
 
+	blocked, err := s.repo.GetBlockedAmount(account.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting blocked amount")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	held, err := s.repo.GetHeldAmount(account.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting held amount")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	available := balance - blocked - held
+	if available < 0 {
+		available = 0
+	}
+
 	return &BalanceResponse{
-		AccountNumber: strconv.Itoa(account.Number),
-		Balance:       balance,
+		AccountNumber:    strconv.Itoa(account.Number),
+		Balance:          balance,
+		AvailableBalance: available,
+		BlockedBalance:   blocked,
+		HeldBalance:      held,
 	}, nil
 }
 
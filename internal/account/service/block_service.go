@@ -0,0 +1,215 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"bankmore/internal/account/domain"
+	"bankmore/internal/account/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type BlockService interface {
+	CreateBlock(accountNumber string, request CreateBlockRequest, actor string) (*domain.BalanceBlock, error)
+	ReleaseBlock(accountNumber, blockID string, request ReleaseBlockRequest, actor string) (*domain.BalanceBlock, error)
+	ListBlocks(accountNumber string) ([]BlockDetails, error)
+}
+
+type blockService struct {
+	accountRepo repository.AccountRepository
+	repo        repository.BlockRepository
+	logger      *logrus.Logger
+}
+
+func NewBlockService(accountRepo repository.AccountRepository, repo repository.BlockRepository, logger *logrus.Logger) BlockService {
+	return &blockService{
+		accountRepo: accountRepo,
+		repo:        repo,
+		logger:      logger,
+	}
+}
+
+type CreateBlockRequest struct {
+	Type            string  `json:"type" binding:"required"`
+	ReferenceNumber string  `json:"referenceNumber" binding:"required"`
+	Amount          float64 `json:"amount"`
+	AllFunds        bool    `json:"allFunds"`
+	Reason          string  `json:"reason" binding:"required"`
+}
+
+type ReleaseBlockRequest struct {
+	Amount          float64 `json:"amount"`
+	ReferenceNumber string  `json:"referenceNumber" binding:"required"`
+	Reason          string  `json:"reason" binding:"required"`
+}
+
+type BlockDetails struct {
+	domain.BalanceBlock
+	RemainingAmount float64               `json:"remainingAmount"`
+	Releases        []domain.BlockRelease `json:"releases"`
+}
+
+// CreateBlock bloqueia o valor informado ou, com allFunds, todo o saldo disponível no momento.
+func (s *blockService) CreateBlock(accountNumber string, request CreateBlockRequest, actor string) (*domain.BalanceBlock, error) {
+	blockType := strings.ToUpper(request.Type)
+	if !domain.IsValidBlockType(blockType) {
+		return nil, fmt.Errorf("tipo de bloqueio inválido")
+	}
+
+	account, err := s.getAccount(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := request.Amount
+	if request.AllFunds {
+		balance, err := s.accountRepo.GetBalance(account.ID)
+		if err != nil {
+			s.logger.WithError(err).Error("Error getting account balance")
+			return nil, fmt.Errorf("erro interno do servidor")
+		}
+		blocked, err := s.accountRepo.GetBlockedAmount(account.ID)
+		if err != nil {
+			s.logger.WithError(err).Error("Error getting blocked amount")
+			return nil, fmt.Errorf("erro interno do servidor")
+		}
+		held, err := s.accountRepo.GetHeldAmount(account.ID)
+		if err != nil {
+			s.logger.WithError(err).Error("Error getting held amount")
+			return nil, fmt.Errorf("erro interno do servidor")
+		}
+		amount = balance - blocked - held
+	}
+
+	if amount <= 0 {
+		return nil, fmt.Errorf("valor do bloqueio deve ser positivo")
+	}
+
+	block := domain.NewBalanceBlock(account.ID, blockType, request.ReferenceNumber, amount, request.Reason, actor)
+
+	if err := s.repo.Create(block); err != nil {
+		s.logger.WithError(err).Error("Error creating balance block")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"blockId":         block.ID,
+		"accountId":       account.ID,
+		"type":            block.Type,
+		"referenceNumber": block.ReferenceNumber,
+		"amount":          block.Amount,
+		"actor":           actor,
+	}).Info("Balance block created")
+
+	return block, nil
+}
+
+// ReleaseBlock libera o valor informado; sem valor, libera todo o remanescente.
+func (s *blockService) ReleaseBlock(accountNumber, blockID string, request ReleaseBlockRequest, actor string) (*domain.BalanceBlock, error) {
+	account, err := s.getAccount(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := s.repo.GetByID(blockID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("bloqueio não encontrado")
+		}
+		s.logger.WithError(err).Error("Error getting balance block")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if block.AccountID != account.ID {
+		return nil, fmt.Errorf("bloqueio não encontrado")
+	}
+
+	amount := request.Amount
+	if amount == 0 {
+		amount = block.RemainingAmount()
+	}
+
+	if err := block.Release(amount); err != nil {
+		return nil, err
+	}
+
+	release := domain.NewBlockRelease(block.ID, amount, request.ReferenceNumber, request.Reason, actor)
+
+	released, err := s.repo.Release(block, release)
+	if err != nil {
+		s.logger.WithError(err).Error("Error releasing balance block")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if !released {
+		return nil, s.concurrentlyReleased(blockID, amount)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"blockId":         block.ID,
+		"accountId":       account.ID,
+		"releasedAmount":  amount,
+		"remainingAmount": block.RemainingAmount(),
+		"referenceNumber": request.ReferenceNumber,
+		"actor":           actor,
+	}).Info("Balance block released")
+
+	return block, nil
+}
+
+// concurrentlyReleased explica a recusa de uma liberação cujo remanescente foi
+// consumido por outra liberação simultânea.
+func (s *blockService) concurrentlyReleased(blockID string, amount float64) error {
+	block, err := s.repo.GetByID(blockID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting balance block")
+		return fmt.Errorf("erro interno do servidor")
+	}
+	if err := block.Release(amount); err != nil {
+		return err
+	}
+	return fmt.Errorf("bloqueio alterado por outra liberação; tente novamente")
+}
+
+func (s *blockService) ListBlocks(accountNumber string) ([]BlockDetails, error) {
+	account, err := s.getAccount(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := s.repo.GetByAccountID(account.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing balance blocks")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	details := make([]BlockDetails, len(blocks))
+	for i, block := range blocks {
+		releases, err := s.repo.GetReleases(block.ID)
+		if err != nil {
+			s.logger.WithError(err).Error("Error listing block releases")
+			return nil, fmt.Errorf("erro interno do servidor")
+		}
+		details[i] = BlockDetails{
+			BalanceBlock:    block,
+			RemainingAmount: block.RemainingAmount(),
+			Releases:        releases,
+		}
+	}
+
+	return details, nil
+}
+
+func (s *blockService) getAccount(accountNumber string) (*domain.Account, error) {
+	account, err := s.accountRepo.GetByNumber(accountNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("conta não encontrada")
+		}
+		s.logger.WithError(err).Error("Error getting account by number")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return account, nil
+}
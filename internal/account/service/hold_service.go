@@ -0,0 +1,316 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"bankmore/internal/account/domain"
+	"bankmore/internal/account/repository"
+	ledgerDomain "bankmore/internal/ledger/domain"
+	"bankmore/internal/shared/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var ErrInsufficientBalance = errors.New("saldo insuficiente")
+
+type HoldService interface {
+	PlaceHold(request PlaceHoldRequest) (*domain.Hold, error)
+	CaptureHold(holdID string, request CaptureHoldRequest) (*domain.Hold, error)
+	VoidHold(holdID string) (*domain.Hold, error)
+	GetHold(holdID string) (*domain.Hold, error)
+	ExpireHolds() (int, error)
+}
+
+type holdService struct {
+	accountRepo repository.AccountRepository
+	repo        repository.HoldRepository
+	logger      *logrus.Logger
+	clock       utils.Clock
+	defaultTTL  time.Duration
+}
+
+func NewHoldService(accountRepo repository.AccountRepository, repo repository.HoldRepository, logger *logrus.Logger) HoldService {
+	return &holdService{
+		accountRepo: accountRepo,
+		repo:        repo,
+		logger:      logger,
+		clock:       utils.SystemClock{},
+		defaultTTL:  getDefaultHoldTTL(),
+	}
+}
+
+// PlaceHoldRequest.Category é a categoria contábil do débito gerado na captura
+// (TRANSFER por padrão).
+type PlaceHoldRequest struct {
+	RequestID        string  `json:"requestId" binding:"required"`
+	AccountNumber    string  `json:"accountNumber" binding:"required"`
+	Amount           float64 `json:"amount" binding:"required"`
+	Description      string  `json:"description"`
+	Category         string  `json:"category"`
+	ExpiresInSeconds int     `json:"expiresInSeconds"`
+}
+
+// CaptureHoldRequest sem valor captura o total reservado.
+type CaptureHoldRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+// PlaceHold reserva o valor se houver saldo disponível. A chamada é idempotente por requestId.
+func (s *holdService) PlaceHold(request PlaceHoldRequest) (*domain.Hold, error) {
+	if request.Amount <= 0 {
+		return nil, fmt.Errorf("valor deve ser positivo")
+	}
+
+	if request.Category == "" {
+		request.Category = ledgerDomain.CategoryTransfer
+	}
+	if !ledgerDomain.IsValidCategory(request.Category) {
+		return nil, fmt.Errorf("categoria de movimentação inválida")
+	}
+
+	existing, err := s.repo.GetByRequestID(request.RequestID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.WithError(err).Error("Error checking hold idempotency")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if existing != nil {
+		s.logger.WithField("requestId", request.RequestID).Info("Duplicate hold request ignored")
+		return existing, nil
+	}
+
+	account, err := s.accountRepo.GetByNumber(request.AccountNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("conta não encontrada")
+		}
+		s.logger.WithError(err).Error("Error getting account by number")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if !account.CanPlaceHold() {
+		return nil, fmt.Errorf("conta não aceita reservas na situação %s", account.CurrentStatus())
+	}
+
+	ttl := s.defaultTTL
+	if request.ExpiresInSeconds > 0 {
+		ttl = time.Duration(request.ExpiresInSeconds) * time.Second
+	}
+
+	hold := domain.NewHold(account.ID, request.RequestID, request.Amount, request.Description, request.Category, ttl)
+
+	created, err := s.repo.CreateIfAvailable(hold)
+	if errors.Is(err, domain.ErrStatusChanged) {
+		return nil, err
+	}
+	if err != nil {
+		s.logger.WithError(err).Error("Error creating hold")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if !created {
+		return nil, ErrInsufficientBalance
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"holdId":    hold.ID,
+		"accountId": account.ID,
+		"amount":    hold.Amount,
+		"expiresAt": hold.ExpiresAt,
+		"requestId": request.RequestID,
+	}).Info("Hold placed")
+
+	return hold, nil
+}
+
+// CaptureHold debita o valor capturado e libera o restante da reserva. Repetir a
+// captura de uma reserva já capturada devolve a reserva sem novo débito.
+func (s *holdService) CaptureHold(holdID string, request CaptureHoldRequest) (*domain.Hold, error) {
+	hold, err := s.GetHold(holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hold.Status == domain.HoldStatusCaptured {
+		s.logger.WithField("holdId", hold.ID).Info("Duplicate hold capture ignored")
+		return hold, nil
+	}
+
+	account, err := s.accountRepo.GetByID(hold.AccountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting account by ID")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if !account.CanDebit() {
+		return nil, fmt.Errorf("conta não aceita débitos na situação %s", account.CurrentStatus())
+	}
+
+	amount := request.Amount
+	if amount == 0 {
+		amount = hold.Amount
+	}
+
+	category := hold.Category
+	if category == "" {
+		category = ledgerDomain.CategoryTransfer
+	}
+
+	movement := domain.NewMovement(hold.AccountID, domain.MovementTypeDebit, category, amount, &hold.RequestID)
+
+	if err := hold.Capture(amount, movement.ID, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	captured, err := s.repo.Capture(hold, movement)
+	if err != nil {
+		s.logger.WithError(err).Error("Error capturing hold")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if !captured {
+		// Outra requisição concluiu a reserva depois da leitura acima.
+		return s.concurrentlyFinished(hold.ID, domain.HoldStatusCaptured, "capturada")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"holdId":         hold.ID,
+		"accountId":      hold.AccountID,
+		"heldAmount":     hold.Amount,
+		"capturedAmount": hold.CapturedAmount,
+		"movementId":     movement.ID,
+	}).Info("Hold captured")
+
+	return hold, nil
+}
+
+func (s *holdService) VoidHold(holdID string) (*domain.Hold, error) {
+	hold, err := s.GetHold(holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hold.Status == domain.HoldStatusVoided {
+		return hold, nil
+	}
+
+	if err := hold.Void(s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	voided, err := s.repo.Finish(hold)
+	if err != nil {
+		s.logger.WithError(err).Error("Error voiding hold")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if !voided {
+		return s.concurrentlyFinished(hold.ID, domain.HoldStatusVoided, "cancelada")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"holdId":    hold.ID,
+		"accountId": hold.AccountID,
+		"amount":    hold.Amount,
+	}).Info("Hold voided")
+
+	return hold, nil
+}
+
+func (s *holdService) GetHold(holdID string) (*domain.Hold, error) {
+	hold, err := s.repo.GetByID(holdID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("reserva não encontrada")
+		}
+		s.logger.WithError(err).Error("Error getting hold")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return hold, nil
+}
+
+// ExpireHolds marca como expiradas as reservas vencidas. O saldo já deixa de ser
+// reservado ao vencer; a varredura só consolida a situação.
+func (s *holdService) ExpireHolds() (int, error) {
+	now := s.clock.Now()
+
+	holds, err := s.repo.GetExpired(now)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing expired holds")
+		return 0, fmt.Errorf("erro interno do servidor")
+	}
+
+	expired := 0
+	for i := range holds {
+		hold := &holds[i]
+		hold.Expire(now)
+		finished, err := s.repo.Finish(hold)
+		if err != nil {
+			s.logger.WithError(err).WithField("holdId", hold.ID).Error("Error expiring hold")
+			continue
+		}
+		if finished {
+			expired++
+		}
+	}
+
+	if expired > 0 {
+		s.logger.WithField("count", expired).Info("Expired holds swept")
+	}
+
+	return expired, nil
+}
+
+// StartHoldSweeper executa ExpireHolds periodicamente até o canal stop ser fechado.
+func StartHoldSweeper(service HoldService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				service.ExpireHolds()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// concurrentlyFinished relê uma reserva que outra requisição concluiu. Se ela terminou na
+// situação pedida, a operação é tratada como repetição; caso contrário, a transição é
+// recusada com a mesma mensagem do domínio.
+func (s *holdService) concurrentlyFinished(holdID, wanted, action string) (*domain.Hold, error) {
+	hold, err := s.GetHold(holdID)
+	if err != nil {
+		return nil, err
+	}
+	if hold.Status != wanted {
+		return nil, fmt.Errorf("reserva na situação %s não pode ser %s", hold.Status, action)
+	}
+	s.logger.WithFields(logrus.Fields{
+		"holdId": hold.ID,
+		"status": hold.Status,
+	}).Info("Hold already finished by a concurrent request")
+	return hold, nil
+}
+
+func getDefaultHoldTTL() time.Duration {
+	if value := os.Getenv("HOLD_DEFAULT_TTL_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 7 * 24 * time.Hour
+}
+
+// GetHoldSweepInterval lê HOLD_SWEEP_INTERVAL_SECONDS (padrão de um minuto).
+func GetHoldSweepInterval() time.Duration {
+	if value := os.Getenv("HOLD_SWEEP_INTERVAL_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return time.Minute
+}
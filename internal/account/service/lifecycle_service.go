@@ -0,0 +1,242 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+
+	"bankmore/internal/account/domain"
+	"bankmore/internal/account/repository"
+	"bankmore/internal/shared/middleware"
+	"bankmore/internal/shared/models"
+	"bankmore/internal/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const systemActor = "system"
+
+type CloseAccountRequest struct {
+	Password           string `json:"password" binding:"required"`
+	Reason             string `json:"reason"`
+	SweepAccountNumber string `json:"sweepAccountNumber"`
+}
+
+type StatusChangeRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type CloseAccountResponse struct {
+	Status          string  `json:"status"`
+	SweptAmount     float64 `json:"sweptAmount"`
+	SweepTransferID string  `json:"sweepTransferId,omitempty"`
+}
+
+// CloseAccount encerra a conta do titular. Contas com saldo só são encerradas com a
+// transferência do saldo para a conta indicada; a conta fica em CLOSING enquanto isso.
+func (s *accountService) CloseAccount(accountID string, request CloseAccountRequest, actor string) (*CloseAccountResponse, error) {
+	account, err := s.getAccountByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.CurrentStatus() != domain.AccountStatusActive {
+		return nil, fmt.Errorf("somente contas ativas podem ser encerradas pelo titular")
+	}
+
+	if !utils.VerifyPassword(request.Password, account.Salt, account.PasswordHash) {
+		return nil, fmt.Errorf("senha inválida")
+	}
+
+	reason := request.Reason
+	if reason == "" {
+		reason = "Encerramento solicitado pelo titular"
+	}
+
+	// A conta passa a CLOSING antes de o saldo ser lido, na mesma transação; sem saldo,
+	// vai direto para CLOSED. Uma recusa desfaz a transação e a conta continua ativa.
+	var balance float64
+	var refusal error
+	err = s.repo.StartClosing(account, func(funds repository.ClosingFunds) (*domain.AccountStatusChange, error) {
+		balance = funds.Balance
+		switch {
+		case !isZeroAmount(funds.Blocked):
+			refusal = fmt.Errorf("conta possui bloqueios de saldo ativos e não pode ser encerrada")
+		case !isZeroAmount(funds.Held):
+			refusal = fmt.Errorf("conta possui reservas de saldo pendentes e não pode ser encerrada")
+		case balance < 0 && !isZeroAmount(balance):
+			refusal = fmt.Errorf("conta com saldo negativo não pode ser encerrada")
+		case isZeroAmount(balance):
+			return account.TransitionTo(domain.AccountStatusClosed, reason, actor)
+		case request.SweepAccountNumber == "":
+			refusal = fmt.Errorf("conta possui saldo de %.2f; informe a conta para transferência do saldo", balance)
+		default:
+			return account.TransitionTo(domain.AccountStatusClosing, reason, actor)
+		}
+		return nil, refusal
+	})
+	if refusal != nil {
+		return nil, refusal
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("accountId", account.ID).Error("Error starting account closure")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"accountId": account.ID,
+		"toStatus":  account.CurrentStatus(),
+		"actor":     actor,
+		"reason":    reason,
+	}).Info("Account status changed")
+
+	if account.CurrentStatus() == domain.AccountStatusClosed {
+		return &CloseAccountResponse{Status: account.CurrentStatus()}, nil
+	}
+
+	transferID, err := s.sweepBalance(account.ID, request.SweepAccountNumber, balance)
+	if err != nil {
+		s.logger.WithError(err).WithField("accountId", account.ID).Error("Error sweeping balance on closure")
+		rollbackReason := fmt.Sprintf("Falha na transferência do saldo: %s", err.Error())
+		if rollbackErr := s.changeStatus(account, domain.AccountStatusActive, rollbackReason, systemActor); rollbackErr != nil {
+			s.logger.WithError(rollbackErr).WithField("accountId", account.ID).Error("Error reverting account closure")
+		}
+		return nil, fmt.Errorf("não foi possível transferir o saldo: %s", err.Error())
+	}
+
+	if err := s.changeStatus(account, domain.AccountStatusClosed, reason, actor); err != nil {
+		return nil, err
+	}
+
+	return &CloseAccountResponse{
+		Status:          account.CurrentStatus(),
+		SweptAmount:     balance,
+		SweepTransferID: transferID,
+	}, nil
+}
+
+func (s *accountService) ReactivateAccount(accountNumber, reason, actor string) error {
+	account, err := s.getAccountByNumber(accountNumber)
+	if err != nil {
+		return err
+	}
+
+	if account.CurrentStatus() == domain.AccountStatusActive {
+		return fmt.Errorf("conta já está ativa")
+	}
+
+	return s.changeStatus(account, domain.AccountStatusActive, reason, actor)
+}
+
+func (s *accountService) BlockAccount(accountNumber, reason, actor string) error {
+	account, err := s.getAccountByNumber(accountNumber)
+	if err != nil {
+		return err
+	}
+
+	return s.changeStatus(account, domain.AccountStatusBlocked, reason, actor)
+}
+
+func (s *accountService) GetStatusHistory(accountNumber string) ([]domain.AccountStatusChange, error) {
+	account, err := s.getAccountByNumber(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.GetStatusHistory(account.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting account status history")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	return history, nil
+}
+
+func (s *accountService) changeStatus(account *domain.Account, status, reason, actor string) error {
+	change, err := account.TransitionTo(status, reason, actor)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateStatus(account, change); err != nil {
+		s.logger.WithError(err).Error("Error updating account status")
+		return fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"accountId":  account.ID,
+		"fromStatus": change.FromStatus,
+		"toStatus":   change.ToStatus,
+		"actor":      actor,
+		"reason":     reason,
+	}).Info("Account status changed")
+
+	return nil
+}
+
+// sweepBalance transfere o saldo remanescente pela API de transferências, usando o
+// token de serviço. Retorna o ID da transferência criada.
+func (s *accountService) sweepBalance(accountID, destinationAccountNumber string, amount float64) (string, error) {
+	transferAPIURL := os.Getenv("TRANSFER_API_URL")
+	if transferAPIURL == "" {
+		transferAPIURL = "http://localhost:8002"
+	}
+
+	request := map[string]interface{}{
+		"requestId":                uuid.New().String(),
+		"originAccountId":          accountID,
+		"destinationAccountNumber": destinationAccountNumber,
+		"amount":                   amount,
+	}
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/api/transfer/internal/sweep", transferAPIURL)
+	resp, err := middleware.DoServiceRequest("account-api", http.MethodPost, url, strings.NewReader(string(jsonData)))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResp models.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil && errorResp.Message != "" {
+			return "", errors.New(errorResp.Message)
+		}
+		return "", fmt.Errorf("transfer API returned status %d", resp.StatusCode)
+	}
+
+	var transferResp struct {
+		TransferID string `json:"transferId"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&transferResp); err != nil {
+		return "", err
+	}
+
+	return transferResp.TransferID, nil
+}
+
+func (s *accountService) getAccountByNumber(accountNumber string) (*domain.Account, error) {
+	account, err := s.repo.GetByNumber(accountNumber)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("conta não encontrada")
+		}
+		s.logger.WithError(err).Error("Error getting account by number")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return account, nil
+}
+
+func isZeroAmount(amount float64) bool {
+	return math.Abs(amount) < 0.005
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"bankmore/internal/account/domain"
+	"bankmore/internal/account/repository"
+	"bankmore/internal/shared/middleware"
+	"bankmore/internal/shared/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type OperatorService interface {
+	Login(request OperatorLoginRequest) (*OperatorLoginResponse, error)
+	Create(request CreateOperatorRequest) (*domain.Operator, error)
+	List() ([]domain.Operator, error)
+	EnsureBootstrapAdmin(login, password string) error
+}
+
+type operatorService struct {
+	repo   repository.OperatorRepository
+	logger *logrus.Logger
+}
+
+func NewOperatorService(repo repository.OperatorRepository, logger *logrus.Logger) OperatorService {
+	return &operatorService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+type OperatorLoginRequest struct {
+	Login    string `json:"login" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type OperatorLoginResponse struct {
+	Token string `json:"token"`
+	Role  string `json:"role"`
+}
+
+type CreateOperatorRequest struct {
+	Login    string `json:"login" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+func (s *operatorService) Login(request OperatorLoginRequest) (*OperatorLoginResponse, error) {
+	operator, err := s.repo.GetByLogin(strings.ToLower(request.Login))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("credenciais inválidas")
+		}
+		s.logger.WithError(err).Error("Error getting operator by login")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if !operator.Active {
+		return nil, fmt.Errorf("operador inativo")
+	}
+
+	if !utils.VerifyPassword(request.Password, operator.Salt, operator.PasswordHash) {
+		return nil, fmt.Errorf("credenciais inválidas")
+	}
+
+	token, err := middleware.GenerateOperatorJWT(operator.ID, operator.Role)
+	if err != nil {
+		s.logger.WithError(err).Error("Error generating operator JWT token")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"operatorId": operator.ID,
+		"role":       operator.Role,
+	}).Info("Operator logged in successfully")
+
+	return &OperatorLoginResponse{
+		Token: token,
+		Role:  operator.Role,
+	}, nil
+}
+
+func (s *operatorService) Create(request CreateOperatorRequest) (*domain.Operator, error) {
+	if !middleware.IsOperatorRole(request.Role) {
+		return nil, fmt.Errorf("perfil de operador inválido")
+	}
+
+	login := strings.ToLower(strings.TrimSpace(request.Login))
+
+	existing, err := s.repo.GetByLogin(login)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.WithError(err).Error("Error checking existing operator")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("login já cadastrado")
+	}
+
+	salt, err := utils.GenerateSalt()
+	if err != nil {
+		s.logger.WithError(err).Error("Error generating salt")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	operator := domain.NewOperator(login, request.Name, request.Role, utils.HashPassword(request.Password, salt), salt)
+
+	if err := s.repo.Create(operator); err != nil {
+		s.logger.WithError(err).Error("Error creating operator")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"operatorId": operator.ID,
+		"login":      operator.Login,
+		"role":       operator.Role,
+	}).Info("Operator created successfully")
+
+	return operator, nil
+}
+
+func (s *operatorService) List() ([]domain.Operator, error) {
+	operators, err := s.repo.List()
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing operators")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return operators, nil
+}
+
+// EnsureBootstrapAdmin cria o primeiro administrador quando a tabela de operadores
+// está vazia, para que os demais operadores possam ser cadastrados via API.
+func (s *operatorService) EnsureBootstrapAdmin(login, password string) error {
+	if login == "" || password == "" {
+		return nil
+	}
+
+	count, err := s.repo.Count()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	_, err = s.Create(CreateOperatorRequest{
+		Login:    login,
+		Name:     "Administrador",
+		Password: password,
+		Role:     middleware.RoleAdmin,
+	})
+	return err
+}
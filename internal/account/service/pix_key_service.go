@@ -0,0 +1,340 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"bankmore/internal/account/domain"
+	"bankmore/internal/account/repository"
+	"bankmore/internal/shared/notification"
+	"bankmore/internal/shared/utils"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var ErrPixKeyNotFound = errors.New("chave não encontrada")
+
+type PixKeyService interface {
+	RegisterKey(accountID string, request RegisterPixKeyRequest) (*domain.PixKey, error)
+	ConfirmKey(accountID, keyID string, request ConfirmPixKeyRequest) (*domain.PixKey, error)
+	ListKeys(accountID string) ([]domain.PixKey, error)
+	DeleteKey(accountID, keyID string) error
+	ResolveKey(key string) (*ResolvedPixKey, error)
+}
+
+type pixKeyService struct {
+	accountRepo repository.AccountRepository
+	repo        repository.PixKeyRepository
+	notifier    notification.Notifier
+	logger      *logrus.Logger
+	clock       utils.Clock
+}
+
+func NewPixKeyService(accountRepo repository.AccountRepository, repo repository.PixKeyRepository, notifier notification.Notifier, logger *logrus.Logger) PixKeyService {
+	return &pixKeyService{
+		accountRepo: accountRepo,
+		repo:        repo,
+		notifier:    notifier,
+		logger:      logger,
+		clock:       utils.SystemClock{},
+	}
+}
+
+// RegisterPixKeyRequest: para CPF o valor padrão é o CPF do titular; para chaves
+// aleatórias o valor é ignorado e gerado pelo servidor.
+type RegisterPixKeyRequest struct {
+	Type  string `json:"type" binding:"required"`
+	Value string `json:"value"`
+}
+
+type ConfirmPixKeyRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// ResolvedPixKey traz apenas dados mascarados do titular, para conferência antes da
+// transferência. AccountNumber só é preenchido para chamadas entre serviços.
+type ResolvedPixKey struct {
+	Type          string `json:"type"`
+	Key           string `json:"key"`
+	OwnerName     string `json:"ownerName"`
+	OwnerDocument string `json:"ownerDocument"`
+	AccountNumber string `json:"accountNumber,omitempty"`
+}
+
+func (s *pixKeyService) RegisterKey(accountID string, request RegisterPixKeyRequest) (*domain.PixKey, error) {
+	keyType := strings.ToUpper(strings.TrimSpace(request.Type))
+
+	account, err := s.getAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.CurrentStatus() != domain.AccountStatusActive {
+		return nil, fmt.Errorf("somente contas ativas podem cadastrar chaves")
+	}
+
+	value := request.Value
+	switch keyType {
+	case domain.PixKeyTypeCPF:
+		if value == "" {
+			value = account.CPF
+		}
+	case domain.PixKeyTypeRandom:
+		value = uuid.New().String()
+	}
+
+	value, err = domain.NormalizePixKey(keyType, value)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyType == domain.PixKeyTypeCPF && value != utils.CleanCPF(account.CPF) {
+		return nil, fmt.Errorf("a chave CPF deve ser o CPF do titular da conta")
+	}
+
+	if err := s.releaseValue(value); err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountByAccountID(account.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error counting pix keys")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if count >= domain.MaxPixKeysPerAccount {
+		return nil, fmt.Errorf("limite de %d chaves por conta atingido", domain.MaxPixKeysPerAccount)
+	}
+
+	key := domain.NewPixKey(account.ID, keyType, value)
+
+	var code string
+	if key.RequiresConfirmation() {
+		code, err = utils.GenerateNumericCode(6)
+		if err != nil {
+			s.logger.WithError(err).Error("Error generating pix key confirmation code")
+			return nil, fmt.Errorf("erro interno do servidor")
+		}
+		key.SetConfirmationCode(code, s.clock.Now())
+	}
+
+	if err := s.repo.Create(key); err != nil {
+		s.logger.WithError(err).Error("Error creating pix key")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if key.RequiresConfirmation() {
+		if err := s.sendConfirmationCode(key, code); err != nil {
+			s.logger.WithError(err).WithField("keyId", key.ID).Error("Error sending pix key confirmation code")
+			if deleteErr := s.repo.Delete(key); deleteErr != nil {
+				s.logger.WithError(deleteErr).WithField("keyId", key.ID).Error("Error removing unconfirmed pix key")
+			}
+			return nil, fmt.Errorf("não foi possível enviar o código de confirmação")
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"keyId":     key.ID,
+		"accountId": account.ID,
+		"type":      key.Type,
+		"status":    key.Status,
+	}).Info("Pix key registered")
+
+	return key, nil
+}
+
+func (s *pixKeyService) ConfirmKey(accountID, keyID string, request ConfirmPixKeyRequest) (*domain.PixKey, error) {
+	key, err := s.getOwnKey(accountID, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	if err := key.CheckConfirmable(now); err != nil {
+		return nil, err
+	}
+
+	// A tentativa é contada antes de o código ser conferido, mesmo que ele esteja errado.
+	reserved, err := s.repo.ReserveConfirmationAttempt(key.ID, domain.PixKeyMaxConfirmationAttempts)
+	if err != nil {
+		s.logger.WithError(err).Error("Error reserving pix key confirmation attempt")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if !reserved {
+		return nil, s.unconfirmable(accountID, keyID, now)
+	}
+
+	confirmationHash := key.ConfirmationHash
+	if err := key.Confirm(strings.TrimSpace(request.Code), now); err != nil {
+		return nil, err
+	}
+
+	activated, err := s.repo.Activate(key, confirmationHash)
+	if err != nil {
+		s.logger.WithError(err).Error("Error activating pix key")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if !activated {
+		return nil, s.unconfirmable(accountID, keyID, now)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"keyId":     key.ID,
+		"accountId": accountID,
+		"type":      key.Type,
+	}).Info("Pix key confirmed")
+
+	return key, nil
+}
+
+// unconfirmable explica a recusa de uma confirmação quando a chave mudou depois de lida:
+// outra tentativa esgotou o limite, confirmou a chave ou gerou um novo código.
+func (s *pixKeyService) unconfirmable(accountID, keyID string, now time.Time) error {
+	key, err := s.getOwnKey(accountID, keyID)
+	if err != nil {
+		return err
+	}
+	if err := key.CheckConfirmable(now); err != nil {
+		return err
+	}
+	return fmt.Errorf("código de confirmação inválido")
+}
+
+func (s *pixKeyService) ListKeys(accountID string) ([]domain.PixKey, error) {
+	keys, err := s.repo.GetByAccountID(accountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing pix keys")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return keys, nil
+}
+
+func (s *pixKeyService) DeleteKey(accountID, keyID string) error {
+	key, err := s.getOwnKey(accountID, keyID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(key); err != nil {
+		s.logger.WithError(err).Error("Error deleting pix key")
+		return fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"keyId":     key.ID,
+		"accountId": accountID,
+		"type":      key.Type,
+	}).Info("Pix key deleted")
+
+	return nil
+}
+
+// ResolveKey localiza a conta de uma chave ativa. Telefones devem vir com +55.
+func (s *pixKeyService) ResolveKey(rawKey string) (*ResolvedPixKey, error) {
+	keyType := domain.DetectPixKeyType(rawKey)
+
+	value, err := domain.NormalizePixKey(keyType, rawKey)
+	if err != nil {
+		return nil, ErrPixKeyNotFound
+	}
+
+	key, err := s.repo.GetByValue(value)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPixKeyNotFound
+		}
+		s.logger.WithError(err).Error("Error getting pix key by value")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if key.Status != domain.PixKeyStatusActive {
+		return nil, ErrPixKeyNotFound
+	}
+
+	account, err := s.accountRepo.GetByID(key.AccountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting pix key account")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if account.CurrentStatus() == domain.AccountStatusClosed {
+		return nil, ErrPixKeyNotFound
+	}
+
+	return &ResolvedPixKey{
+		Type:          key.Type,
+		Key:           key.Value,
+		OwnerName:     utils.MaskName(account.Name),
+		OwnerDocument: utils.MaskCPF(account.CPF),
+		AccountNumber: strconv.Itoa(account.Number),
+	}, nil
+}
+
+// releaseValue libera o valor se ele só estiver preso a uma chave pendente com código
+// expirado; caso contrário, a chave já está em uso.
+func (s *pixKeyService) releaseValue(value string) error {
+	existing, err := s.repo.GetByValue(value)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		s.logger.WithError(err).Error("Error getting pix key by value")
+		return fmt.Errorf("erro interno do servidor")
+	}
+
+	if !existing.IsStalePending(s.clock.Now()) {
+		return fmt.Errorf("chave já cadastrada")
+	}
+
+	if err := s.repo.Delete(existing); err != nil {
+		s.logger.WithError(err).Error("Error removing stale pix key")
+		return fmt.Errorf("erro interno do servidor")
+	}
+	return nil
+}
+
+func (s *pixKeyService) sendConfirmationCode(key *domain.PixKey, code string) error {
+	channel := notification.ChannelEmail
+	if key.Type == domain.PixKeyTypePhone {
+		channel = notification.ChannelSMS
+	}
+
+	return s.notifier.Send(notification.Message{
+		Channel:   channel,
+		Recipient: key.Value,
+		Subject:   "Confirmação de chave Pix",
+		Body:      fmt.Sprintf("Seu código de confirmação BankMore é %s. Ele expira em %d minutos.", code, int(domain.PixKeyConfirmationTTL.Minutes())),
+	})
+}
+
+func (s *pixKeyService) getOwnKey(accountID, keyID string) (*domain.PixKey, error) {
+	key, err := s.repo.GetByID(keyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPixKeyNotFound
+		}
+		s.logger.WithError(err).Error("Error getting pix key")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if key.AccountID != accountID {
+		return nil, ErrPixKeyNotFound
+	}
+	return key, nil
+}
+
+func (s *pixKeyService) getAccount(accountID string) (*domain.Account, error) {
+	account, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("conta não encontrada")
+		}
+		s.logger.WithError(err).Error("Error getting account by ID")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return account, nil
+}
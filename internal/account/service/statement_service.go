@@ -0,0 +1,264 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"bankmore/internal/account/domain"
+	"bankmore/internal/account/repository"
+	ledgerDomain "bankmore/internal/ledger/domain"
+	"bankmore/internal/shared/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	StatementFormatCSV = "csv"
+	StatementFormatOFX = "ofx"
+	StatementFormatPDF = "pdf"
+
+	// statementDefaultDays é o período do extrato quando from não é informado.
+	statementDefaultDays = 30
+	statementDateLayout  = "2006-01-02"
+)
+
+var (
+	ErrInvalidStatementFormat   = errors.New("formato inválido: use csv, ofx ou pdf")
+	ErrInvalidStatementPeriod   = errors.New("período inválido: use datas AAAA-MM-DD, com from até to e to até hoje")
+	ErrStatementAccountNotFound = errors.New("conta não encontrada")
+)
+
+type StatementService interface {
+	PrepareExport(accountID string, request StatementRequest) (*StatementExport, error)
+	WriteExport(export *StatementExport, w io.Writer) error
+}
+
+type statementService struct {
+	accountRepo repository.AccountRepository
+	repo        repository.StatementRepository
+	logger      *logrus.Logger
+}
+
+func NewStatementService(accountRepo repository.AccountRepository, repo repository.StatementRepository, logger *logrus.Logger) StatementService {
+	return &statementService{
+		accountRepo: accountRepo,
+		repo:        repo,
+		logger:      logger,
+	}
+}
+
+// StatementRequest são os parâmetros da exportação. Datas em AAAA-MM-DD, inclusive; sem
+// to, o extrato vai até hoje e, sem from, cobre os 30 dias anteriores a to. OFXVersion
+// escolhe entre OFX 1.0.2 em SGML ("1", padrão) e OFX 2.2 em XML ("2").
+type StatementRequest struct {
+	Format     string
+	From       string
+	To         string
+	OFXVersion string
+}
+
+// StatementExport é um extrato validado e pronto para ser escrito: a conta, o período e o
+// saldo de abertura. Os movimentos só são lidos durante a escrita.
+type StatementExport struct {
+	Format         string
+	OFXVersion     string
+	Account        *domain.Account
+	From           time.Time
+	To             time.Time
+	OpeningBalance float64
+	GeneratedAt    time.Time
+	BankCode       string
+}
+
+// StatementLine é um movimento do extrato com o saldo depois dele.
+type StatementLine struct {
+	Movement    domain.Movement
+	Description string
+	Balance     float64
+}
+
+// StatementSummary são os totais do extrato, conhecidos só depois do último movimento.
+type StatementSummary struct {
+	Credits        float64
+	Debits         float64
+	ClosingBalance float64
+}
+
+// ContentType devolve o tipo de mídia do arquivo do extrato.
+func (e *StatementExport) ContentType() string {
+	switch e.Format {
+	case StatementFormatOFX:
+		return "application/x-ofx"
+	case StatementFormatPDF:
+		return "application/pdf"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// FileName devolve o nome sugerido para o download, como extrato-1234-2026-10-01-2026-10-31.pdf.
+func (e *StatementExport) FileName() string {
+	return fmt.Sprintf("extrato-%d-%s-%s.%s", e.Account.Number, e.From.Format(statementDateLayout), e.To.Format(statementDateLayout), e.Format)
+}
+
+// PrepareExport valida o formato e o período e calcula o saldo de abertura. Erros aqui
+// ainda podem virar uma resposta JSON; depois que a escrita começa, não.
+func (s *statementService) PrepareExport(accountID string, request StatementRequest) (*StatementExport, error) {
+	format := request.Format
+	if format == "" {
+		format = StatementFormatPDF
+	}
+	if format != StatementFormatCSV && format != StatementFormatOFX && format != StatementFormatPDF {
+		return nil, ErrInvalidStatementFormat
+	}
+	ofxVersion := request.OFXVersion
+	if ofxVersion == "" {
+		ofxVersion = "1"
+	}
+	if ofxVersion != "1" && ofxVersion != "2" {
+		return nil, ErrInvalidStatementFormat
+	}
+
+	from, to, err := parseStatementPeriod(request.From, request.To)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStatementAccountNotFound
+		}
+		s.logger.WithError(err).Error("Error getting account for statement")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	opening, err := s.repo.GetBalanceBefore(accountID, from)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting statement opening balance")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	return &StatementExport{
+		Format:         format,
+		OFXVersion:     ofxVersion,
+		Account:        account,
+		From:           from,
+		To:             to,
+		OpeningBalance: roundCents(opening),
+		GeneratedAt:    time.Now(),
+		BankCode:       utils.GetBankCode(),
+	}, nil
+}
+
+// WriteExport escreve o extrato em w à medida que os movimentos são lidos, com o saldo
+// corrente em cada linha.
+func (s *statementService) WriteExport(export *StatementExport, w io.Writer) error {
+	var writer statementWriter
+	switch export.Format {
+	case StatementFormatOFX:
+		writer = newOFXWriter(w, export)
+	case StatementFormatPDF:
+		writer = newPDFWriter(w, export)
+	default:
+		writer = newCSVWriter(w, export)
+	}
+
+	if err := writer.begin(); err != nil {
+		return err
+	}
+
+	summary := StatementSummary{ClosingBalance: export.OpeningBalance}
+	end := export.To.AddDate(0, 0, 1)
+	err := s.repo.ForEachMovement(export.Account.ID, export.From, end, func(movement domain.Movement) error {
+		if movement.Type == domain.MovementTypeCredit {
+			summary.Credits = roundCents(summary.Credits + movement.Amount)
+			summary.ClosingBalance = roundCents(summary.ClosingBalance + movement.Amount)
+		} else {
+			summary.Debits = roundCents(summary.Debits + movement.Amount)
+			summary.ClosingBalance = roundCents(summary.ClosingBalance - movement.Amount)
+		}
+		return writer.line(StatementLine{
+			Movement:    movement,
+			Description: statementDescription(movement),
+			Balance:     summary.ClosingBalance,
+		})
+	})
+	if err != nil {
+		s.logger.WithError(err).WithField("accountId", export.Account.ID).Error("Error writing statement")
+		return err
+	}
+
+	if err := writer.end(summary); err != nil {
+		s.logger.WithError(err).WithField("accountId", export.Account.ID).Error("Error writing statement")
+		return err
+	}
+	return nil
+}
+
+func parseStatementPeriod(fromValue, toValue string) (time.Time, time.Time, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	to := today
+	if toValue != "" {
+		parsed, err := time.ParseInLocation(statementDateLayout, toValue, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidStatementPeriod
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -statementDefaultDays)
+	if fromValue != "" {
+		parsed, err := time.ParseInLocation(statementDateLayout, fromValue, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidStatementPeriod
+		}
+		from = parsed
+	}
+
+	if from.After(to) || to.After(today) {
+		return time.Time{}, time.Time{}, ErrInvalidStatementPeriod
+	}
+	return from, to, nil
+}
+
+// statementDescription descreve o movimento pela categoria, como no razão contábil.
+func statementDescription(movement domain.Movement) string {
+	credit := movement.Type == domain.MovementTypeCredit
+	switch movement.Category {
+	case ledgerDomain.CategoryCash:
+		if credit {
+			return "Depósito"
+		}
+		return "Saque"
+	case ledgerDomain.CategoryTransfer:
+		if credit {
+			return "Transferência recebida"
+		}
+		return "Transferência enviada"
+	case ledgerDomain.CategoryFee:
+		if credit {
+			return "Estorno de tarifa"
+		}
+		return "Tarifa"
+	case ledgerDomain.CategoryBoleto:
+		if credit {
+			return "Boleto recebido"
+		}
+		return "Pagamento de boleto"
+	default:
+		if credit {
+			return "Crédito em conta"
+		}
+		return "Débito em conta"
+	}
+}
+
+func roundCents(amount float64) float64 {
+	return float64(ledgerDomain.ToCents(amount)) / 100
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"bankmore/internal/account/domain"
+	"bankmore/internal/shared/pdf"
+	"bankmore/internal/shared/utils"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// statementWriter escreve um formato de extrato: o cabeçalho, uma linha por movimento e
+// o fechamento com os totais.
+type statementWriter interface {
+	begin() error
+	line(line StatementLine) error
+	end(summary StatementSummary) error
+}
+
+type csvStatementWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, _ *StatementExport) *csvStatementWriter {
+	return &csvStatementWriter{writer: csv.NewWriter(w)}
+}
+
+func (c *csvStatementWriter) begin() error {
+	return c.writer.Write([]string{"date", "id", "description", "type", "amount", "balance"})
+}
+
+func (c *csvStatementWriter) line(line StatementLine) error {
+	amount := line.Movement.Amount
+	if line.Movement.Type == domain.MovementTypeDebit {
+		amount = -amount
+	}
+	return c.writer.Write([]string{
+		line.Movement.Date.Format(time.RFC3339),
+		line.Movement.ID,
+		line.Description,
+		line.Movement.Type,
+		strconv.FormatFloat(amount, 'f', 2, 64),
+		strconv.FormatFloat(line.Balance, 'f', 2, 64),
+	})
+}
+
+func (c *csvStatementWriter) end(StatementSummary) error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// ofxStatementWriter escreve OFX 1.0.2 (SGML, em Windows-1252, sem fechar os elementos
+// simples) ou OFX 2.2 (XML, em UTF-8). O FITID é o ID do movimento sem hífens: estável
+// entre exportações, para que o software do cliente não importe o mesmo lançamento duas
+// vezes, e dentro dos 32 caracteres que alguns importadores aceitam.
+type ofxStatementWriter struct {
+	out     io.Writer
+	closer  io.Closer
+	export  *StatementExport
+	version string
+	err     error
+}
+
+func newOFXWriter(w io.Writer, export *StatementExport) *ofxStatementWriter {
+	writer := &ofxStatementWriter{out: w, export: export, version: export.OFXVersion}
+	if writer.version == "1" {
+		encoded := transform.NewWriter(w, encoding.ReplaceUnsupported(charmap.Windows1252.NewEncoder()))
+		writer.out = encoded
+		writer.closer = encoded
+	}
+	return writer
+}
+
+func (o *ofxStatementWriter) begin() error {
+	if o.version == "1" {
+		o.write("OFXHEADER:100\nDATA:OFXSGML\nVERSION:102\nSECURITY:NONE\nENCODING:USASCII\nCHARSET:1252\nCOMPRESSION:NONE\nOLDFILEUID:NONE\nNEWFILEUID:NONE\n\n")
+	} else {
+		o.write("<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"no\"?>\n")
+		o.write("<?OFX OFXHEADER=\"200\" VERSION=\"220\" SECURITY=\"NONE\" OLDFILEUID=\"NONE\" NEWFILEUID=\"NONE\"?>\n")
+	}
+
+	o.open("OFX")
+	o.open("SIGNONMSGSRSV1")
+	o.open("SONRS")
+	o.status()
+	o.leaf("DTSERVER", ofxDate(o.export.GeneratedAt))
+	o.leaf("LANGUAGE", "POR")
+	o.close("SONRS")
+	o.close("SIGNONMSGSRSV1")
+
+	o.open("BANKMSGSRSV1")
+	o.open("STMTTRNRS")
+	o.leaf("TRNUID", "0")
+	o.status()
+	o.open("STMTRS")
+	o.leaf("CURDEF", "BRL")
+	o.open("BANKACCTFROM")
+	o.leaf("BANKID", o.export.BankCode)
+	o.leaf("ACCTID", strconv.Itoa(o.export.Account.Number))
+	o.leaf("ACCTTYPE", "CHECKING")
+	o.close("BANKACCTFROM")
+	o.open("BANKTRANLIST")
+	o.leaf("DTSTART", ofxDate(o.export.From))
+	o.leaf("DTEND", ofxDate(o.export.To.AddDate(0, 0, 1).Add(-time.Second)))
+	return o.err
+}
+
+func (o *ofxStatementWriter) line(line StatementLine) error {
+	trnType := "CREDIT"
+	amount := line.Movement.Amount
+	if line.Movement.Type == domain.MovementTypeDebit {
+		trnType = "DEBIT"
+		amount = -amount
+	}
+
+	o.open("STMTTRN")
+	o.leaf("TRNTYPE", trnType)
+	o.leaf("DTPOSTED", ofxDate(line.Movement.Date))
+	o.leaf("TRNAMT", strconv.FormatFloat(amount, 'f', 2, 64))
+	o.leaf("FITID", strings.ReplaceAll(line.Movement.ID, "-", ""))
+	o.leaf("MEMO", line.Description)
+	o.close("STMTTRN")
+	return o.err
+}
+
+func (o *ofxStatementWriter) end(summary StatementSummary) error {
+	o.close("BANKTRANLIST")
+	o.open("LEDGERBAL")
+	o.leaf("BALAMT", strconv.FormatFloat(summary.ClosingBalance, 'f', 2, 64))
+	o.leaf("DTASOF", ofxDate(o.export.To.AddDate(0, 0, 1).Add(-time.Second)))
+	o.close("LEDGERBAL")
+	o.close("STMTRS")
+	o.close("STMTTRNRS")
+	o.close("BANKMSGSRSV1")
+	o.close("OFX")
+
+	if o.closer != nil && o.err == nil {
+		o.err = o.closer.Close()
+	}
+	return o.err
+}
+
+func (o *ofxStatementWriter) status() {
+	o.open("STATUS")
+	o.leaf("CODE", "0")
+	o.leaf("SEVERITY", "INFO")
+	o.close("STATUS")
+}
+
+func (o *ofxStatementWriter) open(name string) {
+	o.write("<" + name + ">\n")
+}
+
+func (o *ofxStatementWriter) close(name string) {
+	o.write("</" + name + ">\n")
+}
+
+func (o *ofxStatementWriter) leaf(name, value string) {
+	value = ofxEscape(value)
+	if o.version == "1" {
+		o.write(fmt.Sprintf("<%s>%s\n", name, value))
+		return
+	}
+	o.write(fmt.Sprintf("<%s>%s</%s>\n", name, value, name))
+}
+
+func (o *ofxStatementWriter) write(s string) {
+	if o.err != nil {
+		return
+	}
+	_, o.err = io.WriteString(o.out, s)
+}
+
+// ofxDate formata a data como AAAAMMDDHHMMSS[deslocamento:fuso], por exemplo
+// 20261018143000[-3:BRT].
+func ofxDate(t time.Time) string {
+	name, offset := t.Zone()
+	hours := strconv.FormatFloat(float64(offset)/3600, 'f', -1, 64)
+	if name == "" || strings.ContainsAny(name, "+-0123456789") {
+		return fmt.Sprintf("%s[%s]", t.Format("20060102150405"), hours)
+	}
+	return fmt.Sprintf("%s[%s:%s]", t.Format("20060102150405"), hours, name)
+}
+
+func ofxEscape(value string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(value)
+}
+
+// Layout do extrato em PDF, em pontos.
+const (
+	pdfMargin       = 40.0
+	pdfLineHeight   = 16.0
+	pdfFooterSpace  = 60.0
+	pdfDateColumn   = pdfMargin + 6
+	pdfDescColumn   = pdfMargin + 110
+	pdfAmountRight  = pdf.PageWidth - pdfMargin - 100
+	pdfBalanceRight = pdf.PageWidth - pdfMargin - 6
+)
+
+var (
+	pdfBrandColor = pdf.Color{R: 0.05, G: 0.24, B: 0.45}
+	pdfWhite      = pdf.Color{R: 1, G: 1, B: 1}
+	pdfBlack      = pdf.Color{R: 0.1, G: 0.1, B: 0.1}
+	pdfGray       = pdf.Color{R: 0.45, G: 0.45, B: 0.45}
+	pdfLightGray  = pdf.Color{R: 0.93, G: 0.94, B: 0.96}
+	pdfRed        = pdf.Color{R: 0.7, G: 0.1, B: 0.1}
+)
+
+// pdfStatementWriter monta o extrato com a marca do banco, os dados do titular, o saldo
+// anterior, uma linha por movimento com o saldo corrente e o saldo final.
+type pdfStatementWriter struct {
+	doc    *pdf.Document
+	export *StatementExport
+	y      float64
+}
+
+func newPDFWriter(w io.Writer, export *StatementExport) *pdfStatementWriter {
+	return &pdfStatementWriter{
+		doc:    pdf.New(w, fmt.Sprintf("Extrato da conta %d", export.Account.Number)),
+		export: export,
+	}
+}
+
+func (p *pdfStatementWriter) begin() error {
+	p.newPage()
+	p.row(p.export.From.Format("02/01/2006"), "Saldo anterior", "", p.export.OpeningBalance, true)
+	return nil
+}
+
+func (p *pdfStatementWriter) line(line StatementLine) error {
+	amount := line.Movement.Amount
+	if line.Movement.Type == domain.MovementTypeDebit {
+		amount = -amount
+	}
+	p.row(line.Movement.Date.Format("02/01/2006"), line.Description, utils.FormatAmount(amount), line.Balance, false)
+	return p.doc.Err()
+}
+
+func (p *pdfStatementWriter) end(summary StatementSummary) error {
+	p.row(p.export.To.Format("02/01/2006"), "Saldo final", "", summary.ClosingBalance, true)
+
+	if p.y-4*pdfLineHeight < pdfFooterSpace {
+		p.newPage()
+	}
+	p.y -= pdfLineHeight
+	p.doc.Line(pdfMargin, p.y+pdfLineHeight-4, pdf.PageWidth-pdfMargin, p.y+pdfLineHeight-4, 0.5, pdfGray)
+	p.summaryRow("Total de créditos", summary.Credits)
+	p.summaryRow("Total de débitos", summary.Debits)
+	p.summaryRow("Saldo final", summary.ClosingBalance)
+
+	p.footer()
+	return p.doc.Close()
+}
+
+func (p *pdfStatementWriter) newPage() {
+	if p.doc.PageCount() > 0 {
+		p.footer()
+	}
+	p.doc.AddPage()
+
+	top := pdf.PageHeight
+	p.doc.Rect(0, top-70, pdf.PageWidth, 70, pdfBrandColor)
+	p.doc.Text(pdfMargin, top-40, 22, true, pdfWhite, utils.BankName)
+	p.doc.Text(pdfMargin, top-58, 10, false, pdfWhite, "Extrato de conta corrente")
+	p.doc.TextRight(pdf.PageWidth-pdfMargin, top-40, 10, false, pdfWhite,
+		fmt.Sprintf("Período: %s a %s", p.export.From.Format("02/01/2006"), p.export.To.Format("02/01/2006")))
+	p.doc.TextRight(pdf.PageWidth-pdfMargin, top-58, 10, false, pdfWhite,
+		fmt.Sprintf("Emitido em %s", p.export.GeneratedAt.Format("02/01/2006 15:04")))
+
+	y := top - 95
+	if p.doc.PageCount() == 1 {
+		account := p.export.Account
+		p.doc.Text(pdfMargin, y, 10, true, pdfBlack, "Titular")
+		p.doc.Text(pdfMargin+70, y, 10, false, pdfBlack, account.Name)
+		y -= 14
+		p.doc.Text(pdfMargin, y, 10, true, pdfBlack, "CPF")
+		p.doc.Text(pdfMargin+70, y, 10, false, pdfBlack, utils.MaskCPF(account.CPF))
+		y -= 14
+		p.doc.Text(pdfMargin, y, 10, true, pdfBlack, "Conta")
+		p.doc.Text(pdfMargin+70, y, 10, false, pdfBlack, fmt.Sprintf("%d (banco %s)", account.Number, p.export.BankCode))
+		y -= 24
+	}
+
+	p.doc.Rect(pdfMargin, y-5, pdf.PageWidth-2*pdfMargin, pdfLineHeight+2, pdfLightGray)
+	p.doc.Text(pdfDateColumn, y, 9, true, pdfBlack, "Data")
+	p.doc.Text(pdfDescColumn, y, 9, true, pdfBlack, "Descrição")
+	p.doc.TextRight(pdfAmountRight, y, 9, true, pdfBlack, "Valor (R$)")
+	p.doc.TextRight(pdfBalanceRight, y, 9, true, pdfBlack, "Saldo (R$)")
+	p.y = y - pdfLineHeight - 4
+}
+
+func (p *pdfStatementWriter) row(date, description, amount string, balance float64, bold bool) {
+	if p.y < pdfFooterSpace {
+		p.newPage()
+	}
+
+	amountColor := pdfBlack
+	if strings.HasPrefix(amount, "-") {
+		amountColor = pdfRed
+	}
+	descriptionWidth := pdfAmountRight - pdfDescColumn - 80
+	p.doc.Text(pdfDateColumn, p.y, 9, bold, pdfBlack, date)
+	p.doc.Text(pdfDescColumn, p.y, 9, bold, pdfBlack, pdf.Truncate(description, 9, descriptionWidth))
+	if amount != "" {
+		p.doc.TextRight(pdfAmountRight, p.y, 9, bold, amountColor, amount)
+	}
+	p.doc.TextRight(pdfBalanceRight, p.y, 9, bold, pdfBlack, utils.FormatAmount(balance))
+	p.y -= pdfLineHeight
+}
+
+func (p *pdfStatementWriter) summaryRow(label string, amount float64) {
+	p.doc.Text(pdfDescColumn, p.y, 10, true, pdfBlack, label)
+	p.doc.TextRight(pdfBalanceRight, p.y, 10, true, pdfBlack, "R$ "+utils.FormatAmount(amount))
+	p.y -= pdfLineHeight
+}
+
+func (p *pdfStatementWriter) footer() {
+	p.doc.Line(pdfMargin, 45, pdf.PageWidth-pdfMargin, 45, 0.5, pdfGray)
+	p.doc.Text(pdfMargin, 32, 8, false, pdfGray, utils.BankName+" - documento emitido eletronicamente. Valores em reais (R$).")
+	p.doc.TextRight(pdf.PageWidth-pdfMargin, 32, 8, false, pdfGray, fmt.Sprintf("Página %d", p.doc.PageCount()))
+}
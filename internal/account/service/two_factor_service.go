@@ -0,0 +1,378 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"bankmore/internal/account/domain"
+	"bankmore/internal/shared/middleware"
+	"bankmore/internal/shared/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Com 6 dígitos e um passo de tolerância para cada lado, cada tentativa acerta com
+// chance de 3 em 1.000.000; o bloqueio limita as tentativas a maxTwoFactorAttempts por
+// twoFactorLockout.
+const (
+	totpIssuer           = "BankMore"
+	recoveryCodeCount    = 10
+	maxTwoFactorAttempts = 5
+	twoFactorLockout     = 15 * time.Minute
+)
+
+var (
+	ErrTwoFactorRequired = errors.New("código de autenticação obrigatório")
+	ErrInvalidTwoFactor  = errors.New("código de autenticação inválido")
+	ErrTwoFactorLocked   = errors.New("muitos códigos inválidos; tente novamente em alguns minutos")
+)
+
+type TwoFactorEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type TwoFactorLoginRequest struct {
+	PartialToken string `json:"partialToken" binding:"required"`
+	Code         string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+func (s *accountService) EnrollTwoFactor(accountID string) (*TwoFactorEnrollmentResponse, error) {
+	account, err := s.getAccountByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.TwoFactorEnabled {
+		return nil, fmt.Errorf("autenticação em dois fatores já habilitada")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		s.logger.WithError(err).Error("Error generating TOTP secret")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	account.StartTwoFactorEnrollment(secret)
+
+	if err := s.repo.Update(account); err != nil {
+		s.logger.WithError(err).Error("Error saving TOTP secret")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	return &TwoFactorEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(secret, totpIssuer, strconv.Itoa(account.Number)),
+	}, nil
+}
+
+func (s *accountService) ConfirmTwoFactor(accountID, code string) (*RecoveryCodesResponse, error) {
+	account, err := s.getAccountByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.TwoFactorEnabled {
+		return nil, fmt.Errorf("autenticação em dois fatores já habilitada")
+	}
+	if account.TOTPSecret == "" {
+		return nil, fmt.Errorf("cadastro de autenticação em dois fatores não iniciado")
+	}
+
+	if err := s.consumeTOTP(account, code); err != nil {
+		return nil, err
+	}
+
+	account.EnableTwoFactor()
+
+	if err := s.repo.Update(account); err != nil {
+		s.logger.WithError(err).Error("Error enabling two-factor authentication")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	codes, err := s.issueRecoveryCodes(account)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithField("accountId", account.ID).Info("Two-factor authentication enabled")
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *accountService) DisableTwoFactor(accountID string, request TwoFactorDisableRequest) error {
+	account, err := s.getAccountByID(accountID)
+	if err != nil {
+		return err
+	}
+
+	if !account.TwoFactorEnabled {
+		return fmt.Errorf("autenticação em dois fatores não habilitada")
+	}
+
+	if !utils.VerifyPassword(request.Password, account.Salt, account.PasswordHash) {
+		return fmt.Errorf("senha inválida")
+	}
+
+	if err := s.consumeTOTP(account, request.Code); err != nil {
+		return err
+	}
+
+	account.DisableTwoFactor()
+
+	if err := s.repo.Update(account); err != nil {
+		s.logger.WithError(err).Error("Error disabling two-factor authentication")
+		return fmt.Errorf("erro interno do servidor")
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(account.ID, nil); err != nil {
+		s.logger.WithError(err).Error("Error removing recovery codes")
+	}
+
+	s.logger.WithField("accountId", account.ID).Info("Two-factor authentication disabled")
+
+	return nil
+}
+
+func (s *accountService) RegenerateRecoveryCodes(accountID, code string) (*RecoveryCodesResponse, error) {
+	account, err := s.getAccountByID(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !account.TwoFactorEnabled {
+		return nil, fmt.Errorf("autenticação em dois fatores não habilitada")
+	}
+
+	if err := s.consumeTOTP(account, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.issueRecoveryCodes(account)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// VerifyTwoFactor valida um código TOTP novo para operações sensíveis. Contas sem
+// 2FA habilitado não exigem código.
+func (s *accountService) VerifyTwoFactor(accountID, code string) error {
+	account, err := s.getAccountByID(accountID)
+	if err != nil {
+		return err
+	}
+
+	if !account.TwoFactorEnabled {
+		return nil
+	}
+
+	if code == "" {
+		return ErrTwoFactorRequired
+	}
+
+	return s.consumeTOTP(account, code)
+}
+
+func (s *accountService) CompleteTwoFactorLogin(request TwoFactorLoginRequest) (*LoginResponse, error) {
+	claims, err := middleware.ParsePartialJWT(request.PartialToken)
+	if err != nil {
+		return nil, fmt.Errorf("token de autenticação inválido ou expirado")
+	}
+
+	account, err := s.getAccountByID(claims.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !account.CanLogin() {
+		return nil, fmt.Errorf("conta inativa")
+	}
+	if !account.TwoFactorEnabled {
+		return nil, ErrInvalidTwoFactor
+	}
+
+	// O código pode ser TOTP ou de recuperação; os dois contam como uma única tentativa.
+	if err := s.reserveTwoFactorAttempt(account); err != nil {
+		return nil, err
+	}
+	if err := s.acceptTOTP(account, request.Code); err != nil {
+		if !errors.Is(err, ErrInvalidTwoFactor) {
+			return nil, err
+		}
+		if err := s.consumeRecoveryCode(account, request.Code); err != nil {
+			if errors.Is(err, ErrInvalidTwoFactor) {
+				return nil, s.registerTwoFactorFailure(account)
+			}
+			return nil, err
+		}
+	}
+	s.resetTwoFactorAttempts(account)
+
+	return s.issueLoginToken(account)
+}
+
+// consumeTOTP aceita um código TOTP ainda não usado, dentro do limite de tentativas.
+func (s *accountService) consumeTOTP(account *domain.Account, code string) error {
+	if err := s.reserveTwoFactorAttempt(account); err != nil {
+		return err
+	}
+	if err := s.acceptTOTP(account, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactor) {
+			return s.registerTwoFactorFailure(account)
+		}
+		return err
+	}
+	s.resetTwoFactorAttempts(account)
+	return nil
+}
+
+// reserveTwoFactorAttempt conta a tentativa antes da verificação. Sem tentativas
+// disponíveis, aplica o bloqueio caso a falha que esgotou o limite não o tenha feito.
+func (s *accountService) reserveTwoFactorAttempt(account *domain.Account) error {
+	reserved, err := s.repo.ReserveTwoFactorAttempt(account.ID, maxTwoFactorAttempts, s.clock.Now())
+	if err != nil {
+		s.logger.WithError(err).Error("Error reserving two-factor attempt")
+		return fmt.Errorf("erro interno do servidor")
+	}
+	if !reserved {
+		s.lockTwoFactorIfExhausted(account)
+		return ErrTwoFactorLocked
+	}
+	return nil
+}
+
+// registerTwoFactorFailure bloqueia o 2FA quando a falha esgota as tentativas.
+func (s *accountService) registerTwoFactorFailure(account *domain.Account) error {
+	if s.lockTwoFactorIfExhausted(account) {
+		return ErrTwoFactorLocked
+	}
+	return ErrInvalidTwoFactor
+}
+
+func (s *accountService) lockTwoFactorIfExhausted(account *domain.Account) bool {
+	until := s.clock.Now().Add(twoFactorLockout)
+	locked, err := s.repo.LockTwoFactorIfExhausted(account.ID, maxTwoFactorAttempts, until)
+	if err != nil {
+		s.logger.WithError(err).Error("Error locking two-factor authentication")
+		return false
+	}
+	if locked {
+		s.logger.WithFields(logrus.Fields{
+			"accountId":   account.ID,
+			"lockedUntil": until,
+		}).Warn("Two-factor authentication locked after failed attempts")
+	}
+	return locked
+}
+
+func (s *accountService) resetTwoFactorAttempts(account *domain.Account) {
+	if err := s.repo.ResetTwoFactorAttempts(account.ID); err != nil {
+		s.logger.WithError(err).Error("Error resetting two-factor attempts")
+	}
+}
+
+func (s *accountService) acceptTOTP(account *domain.Account, code string) error {
+	step, ok := utils.VerifyTOTPCode(account.TOTPSecret, code, s.clock.Now())
+	if !ok || step <= account.TOTPLastStep {
+		return ErrInvalidTwoFactor
+	}
+
+	account.TOTPLastStep = step
+	if err := s.repo.Update(account); err != nil {
+		s.logger.WithError(err).Error("Error saving TOTP step")
+		return fmt.Errorf("erro interno do servidor")
+	}
+
+	return nil
+}
+
+func (s *accountService) consumeRecoveryCode(account *domain.Account, code string) error {
+	codes, err := s.repo.GetUnusedRecoveryCodes(account.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting recovery codes")
+		return fmt.Errorf("erro interno do servidor")
+	}
+
+	hash := utils.HashPassword(utils.NormalizeRecoveryCode(code), account.Salt)
+	for _, recoveryCode := range codes {
+		if recoveryCode.CodeHash != hash {
+			continue
+		}
+		if err := s.repo.MarkRecoveryCodeUsed(recoveryCode.ID); err != nil {
+			s.logger.WithError(err).Error("Error marking recovery code as used")
+			return fmt.Errorf("erro interno do servidor")
+		}
+		s.logger.WithField("accountId", account.ID).Warn("Recovery code used for login")
+		return nil
+	}
+
+	return ErrInvalidTwoFactor
+}
+
+func (s *accountService) issueRecoveryCodes(account *domain.Account) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		s.logger.WithError(err).Error("Error generating recovery codes")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	records := make([]domain.RecoveryCode, len(codes))
+	for i, code := range codes {
+		records[i] = *domain.NewRecoveryCode(account.ID, utils.HashPassword(utils.NormalizeRecoveryCode(code), account.Salt))
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(account.ID, records); err != nil {
+		s.logger.WithError(err).Error("Error saving recovery codes")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	return codes, nil
+}
+
+func (s *accountService) getAccountByID(accountID string) (*domain.Account, error) {
+	account, err := s.repo.GetByID(accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("conta não encontrada")
+		}
+		s.logger.WithError(err).WithField("accountId", accountID).Error("Error getting account by ID")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return account, nil
+}
+
+func (s *accountService) issueLoginToken(account *domain.Account) (*LoginResponse, error) {
+	token, err := middleware.GenerateJWT(account.ID, strconv.Itoa(account.Number), account.CPF)
+	if err != nil {
+		s.logger.WithError(err).Error("Error generating JWT token")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"accountId":     account.ID,
+		"accountNumber": account.Number,
+	}).Info("User logged in successfully")
+
+	return &LoginResponse{
+		Token:         token,
+		AccountNumber: strconv.Itoa(account.Number),
+	}, nil
+}
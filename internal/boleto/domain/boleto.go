@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	BoletoStatusIssued     = "ISSUED"
+	BoletoStatusProcessing = "PROCESSING"
+	BoletoStatusPaid       = "PAID"
+	BoletoStatusCancelled  = "CANCELLED"
+)
+
+// Campo livre dos boletos emitidos pelo BankMore: carteira (2 dígitos), nosso número
+// (13) e número da conta do beneficiário (10).
+const (
+	Wallet          = "09"
+	OurNumberLength = 13
+)
+
+// Boleto é um boleto de cobrança emitido por uma conta (o beneficiário) contra um
+// pagador. Fica registrado até ser pago, por um cliente ou em outro banco, ou cancelado.
+type Boleto struct {
+	ID              string     `json:"id" gorm:"column:idboleto;primaryKey"`
+	OurNumber       string     `json:"ourNumber" gorm:"column:nosso_numero;unique"`
+	AccountID       string     `json:"accountId" gorm:"column:idcontacorrente;index"`
+	AccountNumber   int        `json:"accountNumber" gorm:"column:numero_conta"`
+	BeneficiaryName string     `json:"beneficiaryName" gorm:"column:beneficiario_nome"`
+	PayerName       string     `json:"payerName" gorm:"column:pagador_nome"`
+	PayerDocument   string     `json:"payerDocument" gorm:"column:pagador_documento"`
+	Amount          float64    `json:"amount" gorm:"column:valor"`
+	DueDate         time.Time  `json:"dueDate" gorm:"column:vencimento"`
+	Description     string     `json:"description" gorm:"column:descricao"`
+	Barcode         string     `json:"barcode" gorm:"column:codigo_barras;unique"`
+	DigitableLine   string     `json:"digitableLine" gorm:"column:linha_digitavel"`
+	Status          string     `json:"status" gorm:"column:situacao"`
+	CreatedAt       time.Time  `json:"createdAt" gorm:"column:data_criacao"`
+	PaidAt          *time.Time `json:"paidAt,omitempty" gorm:"column:data_pagamento"`
+	PaymentID       *string    `json:"paymentId,omitempty" gorm:"column:idpagamento"`
+	CancelledAt     *time.Time `json:"cancelledAt,omitempty" gorm:"column:data_cancelamento"`
+}
+
+func (Boleto) TableName() string {
+	return "boleto"
+}
+
+// NewBoleto cria o boleto sem código de barras; o serviço o calcula a partir do nosso
+// número, do valor e do vencimento.
+func NewBoleto(accountID string, accountNumber int, beneficiaryName, ourNumber, payerName, payerDocument string, amount float64, dueDate time.Time, description string) *Boleto {
+	return &Boleto{
+		ID:              uuid.New().String(),
+		OurNumber:       ourNumber,
+		AccountID:       accountID,
+		AccountNumber:   accountNumber,
+		BeneficiaryName: beneficiaryName,
+		PayerName:       payerName,
+		PayerDocument:   payerDocument,
+		Amount:          amount,
+		DueDate:         dueDate,
+		Description:     description,
+		Status:          BoletoStatusIssued,
+		CreatedAt:       time.Now(),
+	}
+}
+
+// FreeField monta o campo livre do código de barras do boleto.
+func (b *Boleto) FreeField() string {
+	return fmt.Sprintf("%s%s%010d", Wallet, b.OurNumber, b.AccountNumber)
+}
+
+// CanBePaid aceita o pagamento apenas de boletos registrados e ainda em aberto. Boletos
+// vencidos continuam pagáveis, sem multa nem juros.
+func (b *Boleto) CanBePaid() error {
+	switch b.Status {
+	case BoletoStatusIssued:
+		return nil
+	case BoletoStatusPaid:
+		return fmt.Errorf("boleto já pago")
+	case BoletoStatusProcessing:
+		return fmt.Errorf("boleto com pagamento em andamento")
+	default:
+		return fmt.Errorf("boleto cancelado")
+	}
+}
+
+func (b *Boleto) MarkPaid(paymentID string, now time.Time) {
+	b.Status = BoletoStatusPaid
+	b.PaidAt = &now
+	b.PaymentID = &paymentID
+}
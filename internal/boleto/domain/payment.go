@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Situações do pagamento. PAID indica que o valor já saiu da conta do pagador (ou
+// entrou pela câmara) e falta a liquidação: o crédito ao beneficiário, se o boleto é
+// do BankMore, ou o repasse ao banco emissor.
+const (
+	PaymentStatusPending = "PENDING"
+	PaymentStatusPaid    = "PAID"
+	PaymentStatusSettled = "SETTLED"
+	PaymentStatusFailed  = "FAILED"
+)
+
+// Origem do pagamento: feito por um cliente com o saldo da conta ou recebido da câmara
+// de compensação, por um boleto do BankMore pago em outro banco.
+const (
+	PaymentOriginAccount  = "ACCOUNT"
+	PaymentOriginClearing = "CLEARING"
+)
+
+// Payment é um pagamento de boleto ou guia de arrecadação.
+type Payment struct {
+	ID        string     `json:"id" gorm:"column:idpagamento;primaryKey"`
+	RequestID string     `json:"requestId" gorm:"column:idempotencia_key;unique"`
+	Origin    string     `json:"origin" gorm:"column:origem"`
+	AccountID *string    `json:"accountId,omitempty" gorm:"column:idcontacorrente;index"`
+	Type      string     `json:"type" gorm:"column:tipo"`
+	Barcode   string     `json:"barcode" gorm:"column:codigo_barras;index"`
+	BankCode  string     `json:"bankCode,omitempty" gorm:"column:codigo_banco"`
+	BoletoID  *string    `json:"boletoId,omitempty" gorm:"column:idboleto"`
+	Amount    float64    `json:"amount" gorm:"column:valor"`
+	DueDate   *time.Time `json:"dueDate,omitempty" gorm:"column:vencimento"`
+	Status    string     `json:"status" gorm:"column:situacao;index"`
+	Error     string     `json:"error,omitempty" gorm:"column:erro"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"column:data_criacao"`
+	PaidAt    *time.Time `json:"paidAt,omitempty" gorm:"column:data_pagamento"`
+	SettledAt *time.Time `json:"settledAt,omitempty" gorm:"column:data_liquidacao"`
+}
+
+func (Payment) TableName() string {
+	return "pagamento_boleto"
+}
+
+func NewPayment(requestID, origin string, accountID *string, barcodeType, barcode, bankCode string, amount float64, dueDate *time.Time) *Payment {
+	return &Payment{
+		ID:        uuid.New().String(),
+		RequestID: requestID,
+		Origin:    origin,
+		AccountID: accountID,
+		Type:      barcodeType,
+		Barcode:   barcode,
+		BankCode:  bankCode,
+		Amount:    amount,
+		DueDate:   dueDate,
+		Status:    PaymentStatusPending,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (p *Payment) MarkPaid(now time.Time) {
+	p.Status = PaymentStatusPaid
+	p.PaidAt = &now
+	p.Error = ""
+}
+
+func (p *Payment) MarkSettled(now time.Time) {
+	p.Status = PaymentStatusSettled
+	p.SettledAt = &now
+	p.Error = ""
+}
+
+func (p *Payment) Fail(reason string) {
+	p.Status = PaymentStatusFailed
+	p.Error = reason
+}
@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"bankmore/internal/boleto/domain"
+	"bankmore/internal/boleto/service"
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type BoletoHandler struct {
+	service service.BoletoService
+	logger  *logrus.Logger
+}
+
+func NewBoletoHandler(service service.BoletoService, logger *logrus.Logger) *BoletoHandler {
+	return &BoletoHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// @Summary Emite um boleto
+// @Description Emite um boleto de cobrança em nome da conta logada contra o pagador informado (CPF ou CNPJ). A resposta traz o código de barras de 44 dígitos e a linha digitável de 47
+// @Tags Boletos
+// @Accept json
+// @Produce json
+// @Param request body service.IssueBoletoRequest true "Valor, vencimento (AAAA-MM-DD) e pagador"
+// @Success 201 {object} domain.Boleto
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/boletos [post]
+func (h *BoletoHandler) IssueBoleto(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	var request service.IssueBoletoRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	issued, err := h.service.IssueBoleto(accountID, request)
+	if err != nil {
+		respondBoletoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, issued)
+}
+
+// @Summary Lista os boletos emitidos
+// @Description Lista os boletos emitidos pela conta logada, dos mais recentes para os mais antigos
+// @Tags Boletos
+// @Produce json
+// @Success 200 {array} domain.Boleto
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/boletos [get]
+func (h *BoletoHandler) ListBoletos(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	boletos, err := h.service.ListBoletos(accountID)
+	if err != nil {
+		respondBoletoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, boletos)
+}
+
+// @Summary Consulta um boleto emitido
+// @Description Retorna um boleto emitido pela conta logada, com situação e pagamento
+// @Tags Boletos
+// @Produce json
+// @Param id path string true "ID do boleto"
+// @Success 200 {object} domain.Boleto
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/boletos/{id} [get]
+func (h *BoletoHandler) GetBoleto(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	issued, err := h.service.GetBoleto(accountID, c.Param("id"))
+	if err != nil {
+		respondBoletoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, issued)
+}
+
+// @Summary Cancela um boleto
+// @Description Cancela um boleto em aberto emitido pela conta logada. Boletos pagos ou com pagamento em andamento não podem ser cancelados
+// @Tags Boletos
+// @Produce json
+// @Param id path string true "ID do boleto"
+// @Success 200 {object} domain.Boleto
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/boletos/{id}/cancel [post]
+func (h *BoletoHandler) CancelBoleto(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	issued, err := h.service.CancelBoleto(accountID, c.Param("id"))
+	if err != nil {
+		respondBoletoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, issued)
+}
+
+// @Summary Consulta um código de boleto
+// @Description Valida o código de barras ou a linha digitável (boleto bancário ou arrecadação) e retorna banco, valor e vencimento. Em boletos do BankMore traz também beneficiário, pagador e situação. Dígitos verificadores errados são apontados pela posição
+// @Tags Boletos
+// @Produce json
+// @Param code query string true "Código de barras ou linha digitável"
+// @Success 200 {object} service.BoletoLookup
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/boletos/lookup [get]
+func (h *BoletoHandler) Lookup(c *gin.Context) {
+	if _, ok := accountIDFromToken(c); !ok {
+		return
+	}
+
+	lookup, err := h.service.Lookup(c.Query("code"))
+	if err != nil {
+		respondBoletoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lookup)
+}
+
+// @Summary Paga um boleto
+// @Description Paga um boleto bancário ou guia de arrecadação com o saldo da conta logada. O débito é imediato; o crédito ao beneficiário (boletos do BankMore) ou o repasse ao banco emissor ocorre na liquidação. O requestId torna a chamada idempotente
+// @Tags Boletos
+// @Accept json
+// @Produce json
+// @Param request body service.PayBoletoRequest true "Código e valor (quando o código não traz o valor)"
+// @Success 200 {object} domain.Payment
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/boletos/pay [post]
+func (h *BoletoHandler) PayBoleto(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	var request service.PayBoletoRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	result, err := h.service.PayBoleto(accountID, request)
+	if err != nil {
+		h.logger.WithError(err).Error("Error paying boleto")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: "Erro interno do servidor",
+		})
+		return
+	}
+
+	respondPaymentResult(c, result)
+}
+
+// @Summary Lista os pagamentos de boletos
+// @Description Lista os pagamentos de boletos e guias feitos pela conta logada
+// @Tags Boletos
+// @Produce json
+// @Success 200 {array} domain.Payment
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/boletos/payments [get]
+func (h *BoletoHandler) ListPayments(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	payments, err := h.service.ListPayments(accountID)
+	if err != nil {
+		respondBoletoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
+
+// @Summary Consulta um pagamento de boleto
+// @Description Retorna um pagamento feito pela conta logada, com a situação da liquidação
+// @Tags Boletos
+// @Produce json
+// @Param id path string true "ID do pagamento"
+// @Success 200 {object} domain.Payment
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/boletos/payments/{id} [get]
+func (h *BoletoHandler) GetPayment(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	payment, err := h.service.GetPayment(accountID, c.Param("id"))
+	if err != nil {
+		respondBoletoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+// @Summary Executa a liquidação de boletos
+// @Description Executa um ciclo do simulador da câmara de compensação: credita os beneficiários dos boletos do BankMore pagos e lança o repasse dos pagamentos de outros bancos. O mesmo ciclo roda periodicamente em segundo plano
+// @Tags Boletos
+// @Produce json
+// @Success 200 {object} service.SettlementResult
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/admin/boletos/settlement [post]
+func (h *BoletoHandler) RunSettlement(c *gin.Context) {
+	result, err := h.service.RunSettlement()
+	if err != nil {
+		respondBoletoError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Simula o pagamento de um boleto em outro banco
+// @Description Registra, como se viesse da câmara de compensação, o pagamento de um boleto do BankMore feito em outro banco. O valor entra no caixa e o beneficiário é creditado na liquidação
+// @Tags Boletos
+// @Accept json
+// @Produce json
+// @Param request body service.ClearingPaymentRequest true "Código e valor pago"
+// @Success 200 {object} domain.Payment
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/admin/boletos/clearing-payments [post]
+func (h *BoletoHandler) ReceiveClearingPayment(c *gin.Context) {
+	var request service.ClearingPaymentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	result, err := h.service.ReceiveClearingPayment(request)
+	if err != nil {
+		h.logger.WithError(err).Error("Error receiving clearing payment")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: "Erro interno do servidor",
+		})
+		return
+	}
+
+	respondPaymentResult(c, result)
+}
+
+func accountIDFromToken(c *gin.Context) (string, bool) {
+	accountID, exists := c.Get("accountId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorUserUnauthorized,
+			Message: "Token inválido",
+		})
+		return "", false
+	}
+	return accountID.(string), true
+}
+
+func respondPaymentResult(c *gin.Context, result *models.Result[domain.Payment]) {
+	if !result.IsSuccess {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    result.ErrorType,
+			Message: result.ErrorMessage,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result.Data)
+}
+
+func respondBoletoError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrBoletoNotFound), errors.Is(err, service.ErrPaymentNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Type:    models.ErrorInvalidArgument,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidBoletoCode):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidPaymentCode,
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidOperation,
+			Message: err.Error(),
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"time"
+
+	accountDomain "bankmore/internal/account/domain"
+	"bankmore/internal/boleto/domain"
+	ledgerDomain "bankmore/internal/ledger/domain"
+	ledgerRepository "bankmore/internal/ledger/repository"
+
+	"gorm.io/gorm"
+)
+
+type BoletoRepository interface {
+	GetAccount(accountID string) (*accountDomain.Account, error)
+	Create(boleto *domain.Boleto) error
+	GetByID(id string) (*domain.Boleto, error)
+	GetByBarcode(barcode string) (*domain.Boleto, error)
+	GetByAccountID(accountID string) ([]domain.Boleto, error)
+	Cancel(id string, now time.Time) (bool, error)
+	ClaimForPayment(id string) (bool, error)
+	ReleaseClaim(id string) error
+	CreatePayment(payment *domain.Payment) error
+	UpdatePayment(payment *domain.Payment) error
+	GetPaymentByID(id string) (*domain.Payment, error)
+	GetPaymentByRequestID(requestID string) (*domain.Payment, error)
+	GetPaymentsByAccountID(accountID string) ([]domain.Payment, error)
+	GetPaymentsToSettle(limit int) ([]domain.Payment, error)
+	ReceiveFromClearing(payment *domain.Payment) error
+	SettleWithClearing(payment *domain.Payment) error
+	SettleBoleto(payment *domain.Payment, boleto *domain.Boleto) error
+}
+
+type boletoRepository struct {
+	db *gorm.DB
+}
+
+func NewBoletoRepository(db *gorm.DB) BoletoRepository {
+	return &boletoRepository{db: db}
+}
+
+func (r *boletoRepository) GetAccount(accountID string) (*accountDomain.Account, error) {
+	var account accountDomain.Account
+	if err := r.db.Where("idcontacorrente = ?", accountID).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *boletoRepository) Create(boleto *domain.Boleto) error {
+	return r.db.Create(boleto).Error
+}
+
+func (r *boletoRepository) GetByID(id string) (*domain.Boleto, error) {
+	var boleto domain.Boleto
+	if err := r.db.Where("idboleto = ?", id).First(&boleto).Error; err != nil {
+		return nil, err
+	}
+	return &boleto, nil
+}
+
+func (r *boletoRepository) GetByBarcode(barcode string) (*domain.Boleto, error) {
+	var boleto domain.Boleto
+	if err := r.db.Where("codigo_barras = ?", barcode).First(&boleto).Error; err != nil {
+		return nil, err
+	}
+	return &boleto, nil
+}
+
+func (r *boletoRepository) GetByAccountID(accountID string) ([]domain.Boleto, error) {
+	var boletos []domain.Boleto
+	err := r.db.Where("idcontacorrente = ?", accountID).
+		Order("data_criacao DESC").
+		Find(&boletos).Error
+	return boletos, err
+}
+
+// Cancel cancela o boleto somente se ainda estiver em aberto, sem disputar com um
+// pagamento em andamento.
+func (r *boletoRepository) Cancel(id string, now time.Time) (bool, error) {
+	result := r.db.Model(&domain.Boleto{}).
+		Where("idboleto = ? AND situacao = ?", id, domain.BoletoStatusIssued).
+		Updates(map[string]interface{}{"situacao": domain.BoletoStatusCancelled, "data_cancelamento": now})
+	return result.RowsAffected == 1, result.Error
+}
+
+// ClaimForPayment passa o boleto de ISSUED para PROCESSING de forma atômica, para que
+// dois pagamentos simultâneos do mesmo boleto não sejam aceitos.
+func (r *boletoRepository) ClaimForPayment(id string) (bool, error) {
+	result := r.db.Model(&domain.Boleto{}).
+		Where("idboleto = ? AND situacao = ?", id, domain.BoletoStatusIssued).
+		Update("situacao", domain.BoletoStatusProcessing)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *boletoRepository) ReleaseClaim(id string) error {
+	return r.db.Model(&domain.Boleto{}).
+		Where("idboleto = ? AND situacao = ?", id, domain.BoletoStatusProcessing).
+		Update("situacao", domain.BoletoStatusIssued).Error
+}
+
+func (r *boletoRepository) CreatePayment(payment *domain.Payment) error {
+	return r.db.Create(payment).Error
+}
+
+func (r *boletoRepository) UpdatePayment(payment *domain.Payment) error {
+	return r.db.Save(payment).Error
+}
+
+func (r *boletoRepository) GetPaymentByID(id string) (*domain.Payment, error) {
+	var payment domain.Payment
+	if err := r.db.Where("idpagamento = ?", id).First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *boletoRepository) GetPaymentByRequestID(requestID string) (*domain.Payment, error) {
+	var payment domain.Payment
+	if err := r.db.Where("idempotencia_key = ?", requestID).First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *boletoRepository) GetPaymentsByAccountID(accountID string) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	err := r.db.Where("idcontacorrente = ?", accountID).
+		Order("data_criacao DESC").
+		Find(&payments).Error
+	return payments, err
+}
+
+// GetPaymentsToSettle devolve os pagamentos já recebidos e ainda não liquidados, dos
+// mais antigos para os mais novos.
+func (r *boletoRepository) GetPaymentsToSettle(limit int) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	err := r.db.Where("situacao = ?", domain.PaymentStatusPaid).
+		Order("data_pagamento ASC").
+		Limit(limit).
+		Find(&payments).Error
+	return payments, err
+}
+
+// ReceiveFromClearing grava o pagamento recebido da câmara junto com a entrada do valor
+// no caixa, na mesma transação.
+func (r *boletoRepository) ReceiveFromClearing(payment *domain.Payment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		entry, err := ledgerDomain.NewBoletoSettlementEntry(payment.Amount, true, *payment.PaidAt)
+		if err != nil {
+			return err
+		}
+		if err := ledgerRepository.PostEntry(tx, entry); err != nil {
+			return err
+		}
+		return tx.Create(payment).Error
+	})
+}
+
+// SettleWithClearing lança o repasse ao banco emissor e marca o pagamento como
+// liquidado, na mesma transação.
+func (r *boletoRepository) SettleWithClearing(payment *domain.Payment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		entry, err := ledgerDomain.NewBoletoSettlementEntry(payment.Amount, false, *payment.SettledAt)
+		if err != nil {
+			return err
+		}
+		if err := ledgerRepository.PostEntry(tx, entry); err != nil {
+			return err
+		}
+		return tx.Save(payment).Error
+	})
+}
+
+// SettleBoleto marca o pagamento como liquidado e o boleto como pago.
+func (r *boletoRepository) SettleBoleto(payment *domain.Payment, boleto *domain.Boleto) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(payment).Error; err != nil {
+			return err
+		}
+		return tx.Save(boleto).Error
+	})
+}
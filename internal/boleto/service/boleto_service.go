@@ -0,0 +1,514 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"bankmore/internal/boleto/domain"
+	"bankmore/internal/boleto/repository"
+	ledgerDomain "bankmore/internal/ledger/domain"
+	"bankmore/internal/shared/boleto"
+	"bankmore/internal/shared/middleware"
+	"bankmore/internal/shared/models"
+	"bankmore/internal/shared/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	boletoDateLayout = "2006-01-02"
+	// maxBoletoAmount é o maior valor que cabe nos 10 dígitos do código de barras.
+	maxBoletoAmount = 99999999.99
+)
+
+var (
+	ErrBoletoNotFound      = errors.New("boleto não encontrado")
+	ErrPaymentNotFound     = errors.New("pagamento não encontrado")
+	ErrInvalidBoletoCode   = errors.New("código de boleto inválido")
+	errInsufficientBalance = errors.New("saldo insuficiente")
+)
+
+type BoletoService interface {
+	IssueBoleto(accountID string, request IssueBoletoRequest) (*domain.Boleto, error)
+	ListBoletos(accountID string) ([]domain.Boleto, error)
+	GetBoleto(accountID, boletoID string) (*domain.Boleto, error)
+	CancelBoleto(accountID, boletoID string) (*domain.Boleto, error)
+	Lookup(code string) (*BoletoLookup, error)
+	PayBoleto(accountID string, request PayBoletoRequest) (*models.Result[domain.Payment], error)
+	ListPayments(accountID string) ([]domain.Payment, error)
+	GetPayment(accountID, paymentID string) (*domain.Payment, error)
+	ReceiveClearingPayment(request ClearingPaymentRequest) (*models.Result[domain.Payment], error)
+	RunSettlement() (*SettlementResult, error)
+}
+
+type boletoService struct {
+	repo   repository.BoletoRepository
+	logger *logrus.Logger
+}
+
+func NewBoletoService(repo repository.BoletoRepository, logger *logrus.Logger) BoletoService {
+	return &boletoService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// IssueBoletoRequest: dueDate em AAAA-MM-DD; payerDocument é o CPF ou CNPJ do pagador,
+// com ou sem pontuação.
+type IssueBoletoRequest struct {
+	Amount        float64 `json:"amount" binding:"required"`
+	DueDate       string  `json:"dueDate" binding:"required"`
+	PayerName     string  `json:"payerName" binding:"required"`
+	PayerDocument string  `json:"payerDocument" binding:"required"`
+	Description   string  `json:"description"`
+}
+
+// PayBoletoRequest: code é o código de barras ou a linha digitável. Amount só é
+// obrigatório quando o código não traz o valor; se trouxer, precisa ser igual.
+type PayBoletoRequest struct {
+	RequestID string  `json:"requestId" binding:"required"`
+	Code      string  `json:"code" binding:"required"`
+	Amount    float64 `json:"amount"`
+}
+
+// BoletoLookup é o que o código informa antes do pagamento. Beneficiário, pagador e
+// situação só aparecem em boletos emitidos pelo BankMore.
+type BoletoLookup struct {
+	Type            string     `json:"type"`
+	Barcode         string     `json:"barcode"`
+	DigitableLine   string     `json:"digitableLine"`
+	BankCode        string     `json:"bankCode,omitempty"`
+	Amount          float64    `json:"amount"`
+	AmountRequired  bool       `json:"amountRequired"`
+	DueDate         *time.Time `json:"dueDate,omitempty"`
+	Overdue         bool       `json:"overdue"`
+	BeneficiaryName string     `json:"beneficiaryName,omitempty"`
+	PayerName       string     `json:"payerName,omitempty"`
+	Status          string     `json:"status,omitempty"`
+}
+
+func (s *boletoService) IssueBoleto(accountID string, request IssueBoletoRequest) (*domain.Boleto, error) {
+	if request.Amount <= 0 || request.Amount > maxBoletoAmount {
+		return nil, fmt.Errorf("valor deve ser positivo e até %s", utils.FormatAmount(maxBoletoAmount))
+	}
+
+	dueDate, err := time.ParseInLocation(boletoDateLayout, request.DueDate, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("vencimento inválido: use AAAA-MM-DD")
+	}
+	now := time.Now()
+	if dueDate.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)) {
+		return nil, fmt.Errorf("vencimento não pode ser anterior a hoje")
+	}
+
+	payerName := strings.TrimSpace(request.PayerName)
+	if payerName == "" {
+		return nil, fmt.Errorf("nome do pagador é obrigatório")
+	}
+	payerDocument, err := normalizeDocument(request.PayerDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.repo.GetAccount(accountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting beneficiary account")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if !account.CanCredit() {
+		return nil, fmt.Errorf("conta não aceita créditos na situação %s", account.CurrentStatus())
+	}
+
+	ourNumber, err := utils.GenerateNumericCode(domain.OurNumberLength)
+	if err != nil {
+		s.logger.WithError(err).Error("Error generating boleto number")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	issued := domain.NewBoleto(account.ID, account.Number, account.Name, ourNumber, payerName, payerDocument, request.Amount, dueDate, strings.TrimSpace(request.Description))
+	barcode, err := boleto.NewBankBarcode(utils.GetBankCode(), dueDate, request.Amount, issued.FreeField())
+	if err != nil {
+		s.logger.WithError(err).Error("Error building boleto barcode")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	issued.Amount = barcode.Amount
+	issued.Barcode = barcode.Code
+	issued.DigitableLine = barcode.FormattedDigitableLine()
+
+	if err := s.repo.Create(issued); err != nil {
+		s.logger.WithError(err).Error("Error creating boleto")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"boletoId":  issued.ID,
+		"ourNumber": issued.OurNumber,
+		"accountId": accountID,
+		"amount":    issued.Amount,
+		"dueDate":   request.DueDate,
+	}).Info("Boleto issued")
+
+	return issued, nil
+}
+
+func (s *boletoService) ListBoletos(accountID string) ([]domain.Boleto, error) {
+	boletos, err := s.repo.GetByAccountID(accountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing boletos")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return boletos, nil
+}
+
+func (s *boletoService) GetBoleto(accountID, boletoID string) (*domain.Boleto, error) {
+	issued, err := s.repo.GetByID(boletoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBoletoNotFound
+		}
+		s.logger.WithError(err).Error("Error getting boleto")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if issued.AccountID != accountID {
+		return nil, ErrBoletoNotFound
+	}
+	return issued, nil
+}
+
+// CancelBoleto cancela um boleto em aberto; pagos ou com pagamento em andamento não
+// podem mais ser cancelados.
+func (s *boletoService) CancelBoleto(accountID, boletoID string) (*domain.Boleto, error) {
+	issued, err := s.GetBoleto(accountID, boletoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := issued.CanBePaid(); err != nil {
+		return nil, fmt.Errorf("boleto não pode ser cancelado: %s", err.Error())
+	}
+
+	cancelled, err := s.repo.Cancel(issued.ID, time.Now())
+	if err != nil {
+		s.logger.WithError(err).Error("Error cancelling boleto")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if !cancelled {
+		return nil, fmt.Errorf("boleto não pode ser cancelado: pagamento em andamento")
+	}
+
+	s.logger.WithField("boletoId", issued.ID).Info("Boleto cancelled")
+	return s.GetBoleto(accountID, boletoID)
+}
+
+// Lookup interpreta o código e, se o boleto é do BankMore, completa com o registro.
+func (s *boletoService) Lookup(code string) (*BoletoLookup, error) {
+	barcode, err := boleto.Parse(code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBoletoCode, err.Error())
+	}
+
+	lookup := &BoletoLookup{
+		Type:           barcode.Type,
+		Barcode:        barcode.Code,
+		DigitableLine:  barcode.FormattedDigitableLine(),
+		BankCode:       barcode.BankCode,
+		Amount:         barcode.Amount,
+		AmountRequired: barcode.Amount == 0 || barcode.ReferenceAmount,
+		DueDate:        barcode.DueDate,
+	}
+	if barcode.DueDate != nil {
+		now := time.Now()
+		lookup.Overdue = barcode.DueDate.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local))
+	}
+
+	if !isOwnBoleto(barcode) {
+		return lookup, nil
+	}
+
+	issued, err := s.repo.GetByBarcode(barcode.Code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: boleto não registrado no %s", ErrInvalidBoletoCode, utils.BankName)
+		}
+		s.logger.WithError(err).Error("Error getting boleto by barcode")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	lookup.BeneficiaryName = issued.BeneficiaryName
+	lookup.PayerName = issued.PayerName
+	lookup.Status = issued.Status
+	return lookup, nil
+}
+
+// PayBoleto debita da conta o valor do boleto ou da guia. O pagamento fica PAID até a
+// liquidação, que credita o beneficiário (boleto do BankMore) ou repassa o valor ao
+// banco emissor pela câmara.
+func (s *boletoService) PayBoleto(accountID string, request PayBoletoRequest) (*models.Result[domain.Payment], error) {
+	if existing, result := s.findResubmission(request.RequestID, &accountID); result != nil {
+		return result, nil
+	} else if existing != nil {
+		return paymentResult(existing), nil
+	}
+
+	barcode, err := boleto.Parse(request.Code)
+	if err != nil {
+		return failedPayment(models.ErrorInvalidPaymentCode, fmt.Sprintf("%s: %s", ErrInvalidBoletoCode.Error(), err.Error())), nil
+	}
+
+	amount, result := resolveAmount(barcode, request.Amount)
+	if result != nil {
+		return result, nil
+	}
+
+	var issued *domain.Boleto
+	if isOwnBoleto(barcode) {
+		issued, result = s.claimBoleto(barcode, amount)
+		if result != nil {
+			return result, nil
+		}
+		if issued.AccountID == accountID {
+			s.releaseClaim(issued.ID)
+			return failedPayment(models.ErrorInvalidOperation, "Não é possível pagar um boleto emitido pela própria conta"), nil
+		}
+	}
+
+	payer, err := s.repo.GetAccount(accountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting payer account")
+		s.releaseIfClaimed(issued)
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	payment := domain.NewPayment(request.RequestID, domain.PaymentOriginAccount, &accountID, barcode.Type, barcode.Code, barcode.BankCode, amount, barcode.DueDate)
+	if issued != nil {
+		payment.BoletoID = &issued.ID
+	}
+	if err := s.repo.CreatePayment(payment); err != nil {
+		s.logger.WithError(err).Error("Error creating boleto payment")
+		s.releaseIfClaimed(issued)
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if err := postMovement(payment.ID+"-debit", payer.Number, amount, ledgerDomain.SideDebit); err != nil {
+		s.releaseIfClaimed(issued)
+		payment.Fail(err.Error())
+		if updateErr := s.repo.UpdatePayment(payment); updateErr != nil {
+			s.logger.WithError(updateErr).WithField("paymentId", payment.ID).Error("Error updating boleto payment")
+		}
+		if errors.Is(err, errInsufficientBalance) {
+			return failedPayment(models.ErrorInsufficientBalance, "Saldo insuficiente"), nil
+		}
+		s.logger.WithError(err).WithField("paymentId", payment.ID).Error("Error debiting boleto payment")
+		return failedPayment(models.ErrorInvalidOperation, err.Error()), nil
+	}
+
+	payment.MarkPaid(time.Now())
+	if err := s.repo.UpdatePayment(payment); err != nil {
+		s.logger.WithError(err).WithField("paymentId", payment.ID).Error("Error updating boleto payment")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"paymentId": payment.ID,
+		"accountId": accountID,
+		"type":      payment.Type,
+		"bankCode":  payment.BankCode,
+		"amount":    amount,
+	}).Info("Boleto paid")
+
+	return paymentResult(payment), nil
+}
+
+func (s *boletoService) ListPayments(accountID string) ([]domain.Payment, error) {
+	payments, err := s.repo.GetPaymentsByAccountID(accountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing boleto payments")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return payments, nil
+}
+
+func (s *boletoService) GetPayment(accountID, paymentID string) (*domain.Payment, error) {
+	payment, err := s.repo.GetPaymentByID(paymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		s.logger.WithError(err).Error("Error getting boleto payment")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if payment.AccountID == nil || *payment.AccountID != accountID {
+		return nil, ErrPaymentNotFound
+	}
+	return payment, nil
+}
+
+// findResubmission devolve o pagamento já feito com o mesmo requestId, ou o erro se o
+// requestId pertence a outra conta.
+func (s *boletoService) findResubmission(requestID string, accountID *string) (*domain.Payment, *models.Result[domain.Payment]) {
+	existing, err := s.repo.GetPaymentByRequestID(requestID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.WithError(err).Error("Error checking boleto payment request")
+		}
+		return nil, nil
+	}
+
+	sameAccount := (existing.AccountID == nil && accountID == nil) ||
+		(existing.AccountID != nil && accountID != nil && *existing.AccountID == *accountID)
+	if !sameAccount {
+		return nil, failedPayment(models.ErrorIdempotencyKeyReused, "requestId já utilizado em outro pagamento")
+	}
+	return existing, nil
+}
+
+// claimBoleto confere o boleto do BankMore com o registro e o reserva para este pagamento.
+func (s *boletoService) claimBoleto(barcode *boleto.Barcode, amount float64) (*domain.Boleto, *models.Result[domain.Payment]) {
+	issued, err := s.repo.GetByBarcode(barcode.Code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, failedPayment(models.ErrorInvalidPaymentCode, fmt.Sprintf("Boleto não registrado no %s", utils.BankName))
+		}
+		s.logger.WithError(err).Error("Error getting boleto by barcode")
+		return nil, failedPayment(models.ErrorInternalError, "Erro interno do servidor")
+	}
+
+	if !sameAmount(issued.Amount, amount) {
+		return nil, failedPayment(models.ErrorInvalidAmount, "Valor não confere com o boleto registrado")
+	}
+	if err := issued.CanBePaid(); err != nil {
+		return nil, failedPayment(models.ErrorInvalidPaymentCode, err.Error())
+	}
+
+	claimed, err := s.repo.ClaimForPayment(issued.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error claiming boleto")
+		return nil, failedPayment(models.ErrorInternalError, "Erro interno do servidor")
+	}
+	if !claimed {
+		return nil, failedPayment(models.ErrorInvalidPaymentCode, "Boleto com pagamento em andamento")
+	}
+	return issued, nil
+}
+
+func (s *boletoService) releaseIfClaimed(issued *domain.Boleto) {
+	if issued != nil {
+		s.releaseClaim(issued.ID)
+	}
+}
+
+func (s *boletoService) releaseClaim(boletoID string) {
+	if err := s.repo.ReleaseClaim(boletoID); err != nil {
+		s.logger.WithError(err).WithField("boletoId", boletoID).Error("Error releasing boleto claim")
+	}
+}
+
+// resolveAmount decide o valor pago: o do código, quando ele traz um valor efetivo, ou o
+// informado pelo pagador.
+func resolveAmount(barcode *boleto.Barcode, informed float64) (float64, *models.Result[domain.Payment]) {
+	if barcode.Amount > 0 && !barcode.ReferenceAmount {
+		if informed != 0 && !sameAmount(informed, barcode.Amount) {
+			return 0, failedPayment(models.ErrorInvalidAmount, "Valor informado difere do valor do boleto")
+		}
+		return barcode.Amount, nil
+	}
+	if informed <= 0 || informed > maxBoletoAmount {
+		return 0, failedPayment(models.ErrorInvalidAmount, "Informe o valor a pagar")
+	}
+	return math.Round(informed*100) / 100, nil
+}
+
+// isOwnBoleto indica um boleto bancário emitido pelo BankMore, conferido com o registro.
+func isOwnBoleto(barcode *boleto.Barcode) bool {
+	return barcode.Type == boleto.TypeBank && barcode.BankCode == utils.GetBankCode()
+}
+
+func paymentResult(payment *domain.Payment) *models.Result[domain.Payment] {
+	if payment.Status == domain.PaymentStatusFailed {
+		return failedPayment(models.ErrorInvalidOperation, payment.Error)
+	}
+	return &models.Result[domain.Payment]{
+		IsSuccess: true,
+		Data:      *payment,
+	}
+}
+
+func failedPayment(errorType, message string) *models.Result[domain.Payment] {
+	return &models.Result[domain.Payment]{
+		IsSuccess:    false,
+		ErrorType:    errorType,
+		ErrorMessage: message,
+	}
+}
+
+// normalizeDocument aceita CPF (validado) ou CNPJ, com ou sem pontuação.
+func normalizeDocument(document string) (string, error) {
+	digits := strings.NewReplacer(".", "", "-", "", "/", "", " ", "").Replace(document)
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("documento do pagador deve ser CPF ou CNPJ")
+		}
+	}
+	switch len(digits) {
+	case 11:
+		if !utils.ValidateCPF(digits) {
+			return "", fmt.Errorf("CPF do pagador inválido")
+		}
+		return digits, nil
+	case 14:
+		return digits, nil
+	default:
+		return "", fmt.Errorf("documento do pagador deve ser CPF ou CNPJ")
+	}
+}
+
+// postMovement lança o débito do pagador ou o crédito do beneficiário na API de contas,
+// na categoria BOLETO. O requestId torna o lançamento idempotente entre tentativas.
+func postMovement(requestID string, accountNumber int, amount float64, movementType string) error {
+	accountAPIURL := os.Getenv("ACCOUNT_API_URL")
+	if accountAPIURL == "" {
+		accountAPIURL = "http://localhost:8001"
+	}
+
+	request := map[string]interface{}{
+		"requestId":     requestID,
+		"accountNumber": strconv.Itoa(accountNumber),
+		"amount":        amount,
+		"type":          movementType,
+		"category":      ledgerDomain.CategoryBoleto,
+	}
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/api/account/movement", accountAPIURL)
+	resp, err := middleware.DoServiceRequest("transfer-api", http.MethodPost, url, strings.NewReader(string(jsonData)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		var errorResp models.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil && errorResp.Message != "" {
+			if errorResp.Type == models.ErrorInsufficientBalance {
+				return errInsufficientBalance
+			}
+			return errors.New(errorResp.Message)
+		}
+		return fmt.Errorf("account API returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func sameAmount(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"bankmore/internal/boleto/domain"
+	ledgerDomain "bankmore/internal/ledger/domain"
+	"bankmore/internal/shared/boleto"
+	"bankmore/internal/shared/models"
+	"bankmore/internal/shared/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+const settlementBatchSize = 200
+
+// ClearingPaymentRequest simula a câmara de compensação avisando que um boleto do
+// BankMore foi pago em outro banco.
+type ClearingPaymentRequest struct {
+	RequestID string  `json:"requestId" binding:"required"`
+	Code      string  `json:"code" binding:"required"`
+	Amount    float64 `json:"amount"`
+}
+
+type SettlementResult struct {
+	Processed int `json:"processed"`
+	Settled   int `json:"settled"`
+	Failed    int `json:"failed"`
+}
+
+// RunSettlement é o simulador local da câmara de compensação. Para cada pagamento PAID:
+// se o boleto é do BankMore, credita o beneficiário e baixa o boleto; senão, lança o
+// repasse ao banco emissor. Falhas ficam registradas no pagamento e são repetidas no
+// próximo ciclo.
+func (s *boletoService) RunSettlement() (*SettlementResult, error) {
+	payments, err := s.repo.GetPaymentsToSettle(settlementBatchSize)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting boleto payments to settle")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	result := &SettlementResult{}
+	for i := range payments {
+		payment := &payments[i]
+		result.Processed++
+
+		var settleErr error
+		if payment.BoletoID != nil {
+			settleErr = s.settleOwnBoleto(payment)
+		} else {
+			settleErr = s.settleWithClearing(payment)
+		}
+
+		if settleErr != nil {
+			result.Failed++
+			s.logger.WithError(settleErr).WithField("paymentId", payment.ID).Warn("Boleto payment settlement failed, will retry")
+			payment.Error = settleErr.Error()
+			if err := s.repo.UpdatePayment(payment); err != nil {
+				s.logger.WithError(err).WithField("paymentId", payment.ID).Error("Error updating boleto payment")
+			}
+			continue
+		}
+		result.Settled++
+	}
+
+	if result.Processed > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"processed": result.Processed,
+			"settled":   result.Settled,
+			"failed":    result.Failed,
+		}).Info("Boleto settlement cycle finished")
+	}
+
+	return result, nil
+}
+
+// settleOwnBoleto credita o beneficiário. O requestId do movimento é fixo por pagamento,
+// então uma nova tentativa depois de uma falha parcial não credita duas vezes.
+func (s *boletoService) settleOwnBoleto(payment *domain.Payment) error {
+	issued, err := s.repo.GetByID(*payment.BoletoID)
+	if err != nil {
+		return err
+	}
+
+	if err := postMovement(payment.ID+"-credit", issued.AccountNumber, payment.Amount, ledgerDomain.SideCredit); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	payment.MarkSettled(now)
+	issued.MarkPaid(payment.ID, now)
+	if err := s.repo.SettleBoleto(payment, issued); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"paymentId": payment.ID,
+		"boletoId":  issued.ID,
+		"amount":    payment.Amount,
+	}).Info("Boleto settled to beneficiary")
+	return nil
+}
+
+func (s *boletoService) settleWithClearing(payment *domain.Payment) error {
+	payment.MarkSettled(time.Now())
+	if err := s.repo.SettleWithClearing(payment); err != nil {
+		payment.Status = domain.PaymentStatusPaid
+		payment.SettledAt = nil
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"paymentId": payment.ID,
+		"bankCode":  payment.BankCode,
+		"amount":    payment.Amount,
+	}).Info("Boleto payment settled with clearing house")
+	return nil
+}
+
+// ReceiveClearingPayment registra o pagamento de um boleto do BankMore feito em outro
+// banco. O valor entra no caixa agora e o beneficiário é creditado na liquidação.
+func (s *boletoService) ReceiveClearingPayment(request ClearingPaymentRequest) (*models.Result[domain.Payment], error) {
+	if existing, result := s.findResubmission(request.RequestID, nil); result != nil {
+		return result, nil
+	} else if existing != nil {
+		return paymentResult(existing), nil
+	}
+
+	barcode, err := boleto.Parse(request.Code)
+	if err != nil {
+		return failedPayment(models.ErrorInvalidPaymentCode, fmt.Sprintf("%s: %s", ErrInvalidBoletoCode.Error(), err.Error())), nil
+	}
+	if !isOwnBoleto(barcode) {
+		return failedPayment(models.ErrorInvalidPaymentCode, fmt.Sprintf("Boleto não emitido pelo %s", utils.BankName)), nil
+	}
+
+	amount, result := resolveAmount(barcode, request.Amount)
+	if result != nil {
+		return result, nil
+	}
+
+	issued, result := s.claimBoleto(barcode, amount)
+	if result != nil {
+		return result, nil
+	}
+
+	payment := domain.NewPayment(request.RequestID, domain.PaymentOriginClearing, nil, barcode.Type, barcode.Code, barcode.BankCode, amount, barcode.DueDate)
+	payment.BoletoID = &issued.ID
+	payment.MarkPaid(time.Now())
+	if err := s.repo.ReceiveFromClearing(payment); err != nil {
+		s.logger.WithError(err).Error("Error receiving boleto payment from clearing")
+		s.releaseClaim(issued.ID)
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"paymentId": payment.ID,
+		"boletoId":  issued.ID,
+		"amount":    amount,
+	}).Info("Boleto payment received from clearing")
+
+	return paymentResult(payment), nil
+}
+
+func StartSettlementWorker(service BoletoService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				service.RunSettlement()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// GetSettlementInterval lê BOLETO_SETTLEMENT_INTERVAL_SECONDS (padrão de 60 segundos).
+func GetSettlementInterval() time.Duration {
+	if value := os.Getenv("BOLETO_SETTLEMENT_INTERVAL_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 60 * time.Second
+}
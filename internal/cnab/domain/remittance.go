@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ItemStatusAccepted = "ACCEPTED"
+	ItemStatusRejected = "REJECTED"
+)
+
+// Remittance é um arquivo de remessa CNAB importado por uma conta. O sequencial do
+// arquivo (NSA) é único por conta: reenviar o mesmo arquivo devolve a remessa já
+// importada. O conteúdo original é guardado para gerar o retorno no mesmo layout.
+type Remittance struct {
+	ID            string           `json:"id" gorm:"column:idremessa;primaryKey"`
+	AccountID     string           `json:"accountId" gorm:"column:idcontacorrente;uniqueIndex:idx_remessa_cnab_conta_nsa"`
+	Sequence      int              `json:"sequence" gorm:"column:nsa;uniqueIndex:idx_remessa_cnab_conta_nsa"`
+	Layout        string           `json:"layout" gorm:"column:layout"`
+	FileName      string           `json:"fileName" gorm:"column:nome_arquivo"`
+	FileHash      string           `json:"-" gorm:"column:hash_arquivo"`
+	Content       string           `json:"-" gorm:"column:conteudo"`
+	BatchID       *string          `json:"batchId,omitempty" gorm:"column:idlote"`
+	ItemCount     int              `json:"itemCount" gorm:"column:quantidade_registros"`
+	RejectedCount int              `json:"rejectedCount" gorm:"column:registros_recusados"`
+	TotalAmount   float64          `json:"totalAmount" gorm:"column:valor_total"`
+	CreatedAt     time.Time        `json:"createdAt" gorm:"column:data_importacao"`
+	Items         []RemittanceItem `json:"items,omitempty" gorm:"-"`
+}
+
+func (Remittance) TableName() string {
+	return "remessa_cnab"
+}
+
+// RemittanceItem é um pagamento da remessa, ligado ao item do lote de transferências ou
+// ao pagamento de boleto que ele gerou. Occurrence é a ocorrência do retorno: a gravada
+// na importação, para os recusados, ou a situação atual, nas consultas.
+type RemittanceItem struct {
+	ID                    string  `json:"id" gorm:"column:iditem;primaryKey"`
+	RemittanceID          string  `json:"remittanceId" gorm:"column:idremessa;index"`
+	Line                  int     `json:"line" gorm:"column:linha"`
+	Type                  string  `json:"type" gorm:"column:tipo"`
+	YourNumber            string  `json:"yourNumber" gorm:"column:seu_numero"`
+	Amount                float64 `json:"amount" gorm:"column:valor"`
+	BatchSequence         *int    `json:"batchSequence,omitempty" gorm:"column:sequencia_lote"`
+	PaymentID             *string `json:"paymentId,omitempty" gorm:"column:idpagamento"`
+	Status                string  `json:"status" gorm:"column:situacao"`
+	Occurrence            string  `json:"occurrence" gorm:"column:ocorrencia"`
+	OccurrenceDescription string  `json:"occurrenceDescription" gorm:"-"`
+	Error                 string  `json:"error,omitempty" gorm:"column:erro"`
+}
+
+func (RemittanceItem) TableName() string {
+	return "item_remessa_cnab"
+}
+
+func NewRemittance(accountID string, sequence int, layout, fileName, fileHash, content string) *Remittance {
+	return &Remittance{
+		ID:        uuid.New().String(),
+		AccountID: accountID,
+		Sequence:  sequence,
+		Layout:    layout,
+		FileName:  fileName,
+		FileHash:  fileHash,
+		Content:   content,
+		CreatedAt: time.Now(),
+	}
+}
+
+// AddItem inclui um pagamento aceito ou recusado e atualiza os totais da remessa.
+func (r *Remittance) AddItem(item RemittanceItem) {
+	item.ID = uuid.New().String()
+	item.RemittanceID = r.ID
+	r.Items = append(r.Items, item)
+	r.ItemCount++
+	r.TotalAmount += item.Amount
+	if item.Status == ItemStatusRejected {
+		r.RejectedCount++
+	}
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"bankmore/internal/cnab/service"
+	"bankmore/internal/shared/cnab"
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// maxRemittanceSize limita o arquivo de remessa a 10 MB.
+const maxRemittanceSize = 10 << 20
+
+type RemittanceHandler struct {
+	service service.RemittanceService
+	logger  *logrus.Logger
+}
+
+func NewRemittanceHandler(service service.RemittanceService, logger *logrus.Logger) *RemittanceHandler {
+	return &RemittanceHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CNABValidationErrorResponse lista os erros de campo do arquivo, com linha e posição.
+type CNABValidationErrorResponse struct {
+	models.ErrorResponse
+	Errors []cnab.FieldError `json:"errors"`
+}
+
+// @Summary Importa um arquivo de remessa CNAB
+// @Description Recebe uma remessa CNAB 240 (segmentos A, B e J) ou CNAB 400 (layout do BankMore) em multipart, campo file, com totpCode opcional. O arquivo inteiro é validado antes de qualquer pagamento; erros apontam linha e posição. Os créditos em conta viram um lote de transferências e os boletos são pagos na hora. Reenviar o mesmo arquivo devolve a remessa já importada
+// @Tags CNAB
+// @Accept mpfd
+// @Produce json
+// @Param file formData file true "Arquivo de remessa"
+// @Param totpCode formData string false "Código TOTP, quando o total das transferências passa do limite"
+// @Success 201 {object} domain.Remittance
+// @Failure 400 {object} CNABValidationErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/cnab/remittances [post]
+func (h *RemittanceHandler) ImportRemittance(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "arquivo de remessa não enviado",
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "não foi possível ler o arquivo",
+		})
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxRemittanceSize+1))
+	if err != nil || len(content) > maxRemittanceSize {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "arquivo de remessa ilegível ou maior que 10 MB",
+		})
+		return
+	}
+
+	result, err := h.service.ImportRemittance(accountID, service.ImportRequest{
+		FileName:      fileHeader.Filename,
+		Content:       content,
+		TOTPCode:      c.PostForm("totpCode"),
+		Authorization: c.GetHeader("Authorization"),
+	})
+	if err != nil {
+		var validationErr *cnab.ValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, CNABValidationErrorResponse{
+				ErrorResponse: models.ErrorResponse{
+					Type:    models.ErrorInvalidData,
+					Message: validationErr.Error(),
+				},
+				Errors: validationErr.Errors,
+			})
+			return
+		}
+
+		h.logger.WithError(err).Error("Error importing remittance")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: "Erro interno do servidor",
+		})
+		return
+	}
+
+	if !result.IsSuccess {
+		status := http.StatusBadRequest
+		if result.ErrorType == models.ErrorTwoFactorRequired || result.ErrorType == models.ErrorInvalidTwoFactor {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, models.ErrorResponse{
+			Type:    result.ErrorType,
+			Message: result.ErrorMessage,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result.Data)
+}
+
+// @Summary Lista as remessas CNAB
+// @Description Lista as remessas importadas pela conta logada, sem os itens
+// @Tags CNAB
+// @Produce json
+// @Success 200 {array} domain.Remittance
+// @Failure 500 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/cnab/remittances [get]
+func (h *RemittanceHandler) ListRemittances(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	remittances, err := h.service.ListRemittances(accountID)
+	if err != nil {
+		respondRemittanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, remittances)
+}
+
+// @Summary Consulta uma remessa CNAB
+// @Description Retorna a remessa com cada registro, a ocorrência atual e o motivo das recusas
+// @Tags CNAB
+// @Produce json
+// @Param id path string true "ID da remessa"
+// @Success 200 {object} domain.Remittance
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/cnab/remittances/{id} [get]
+func (h *RemittanceHandler) GetRemittance(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	remittance, err := h.service.GetRemittance(accountID, c.Param("id"))
+	if err != nil {
+		respondRemittanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, remittance)
+}
+
+// @Summary Gera o arquivo de retorno
+// @Description Gera o retorno da remessa no mesmo layout, com nosso número, data de efetivação e ocorrência de cada registro (00 efetivado, BD em processamento, 01 saldo insuficiente, 02 cancelado, AN conta inválida, AR valor inválido, CA código de barras inválido, ZZ outras recusas)
+// @Tags CNAB
+// @Produce plain
+// @Param id path string true "ID da remessa"
+// @Success 200 {file} binary
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/cnab/remittances/{id}/return [get]
+func (h *RemittanceHandler) GetReturn(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	content, fileName, err := h.service.GenerateReturn(accountID, c.Param("id"))
+	if err != nil {
+		respondRemittanceError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", fileName))
+	c.Data(http.StatusOK, "text/plain; charset=us-ascii", content)
+}
+
+func accountIDFromToken(c *gin.Context) (string, bool) {
+	accountID, exists := c.Get("accountId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorUserUnauthorized,
+			Message: "Token inválido",
+		})
+		return "", false
+	}
+	return accountID.(string), true
+}
+
+func respondRemittanceError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrRemittanceNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Type:    models.ErrorInvalidArgument,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Type:    models.ErrorInternalError,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	accountDomain "bankmore/internal/account/domain"
+	"bankmore/internal/cnab/domain"
+
+	"gorm.io/gorm"
+)
+
+type RemittanceRepository interface {
+	GetAccount(accountID string) (*accountDomain.Account, error)
+	Create(remittance *domain.Remittance) error
+	GetByID(id string) (*domain.Remittance, error)
+	GetBySequence(accountID string, sequence int) (*domain.Remittance, error)
+	GetByAccountID(accountID string) ([]domain.Remittance, error)
+	GetItems(remittanceID string) ([]domain.RemittanceItem, error)
+}
+
+type remittanceRepository struct {
+	db *gorm.DB
+}
+
+func NewRemittanceRepository(db *gorm.DB) RemittanceRepository {
+	return &remittanceRepository{db: db}
+}
+
+func (r *remittanceRepository) GetAccount(accountID string) (*accountDomain.Account, error) {
+	var account accountDomain.Account
+	if err := r.db.Where("idcontacorrente = ?", accountID).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Create grava a remessa e seus itens na mesma transação.
+func (r *remittanceRepository) Create(remittance *domain.Remittance) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(remittance).Error; err != nil {
+			return err
+		}
+		if len(remittance.Items) == 0 {
+			return nil
+		}
+		return tx.Create(&remittance.Items).Error
+	})
+}
+
+func (r *remittanceRepository) GetByID(id string) (*domain.Remittance, error) {
+	var remittance domain.Remittance
+	if err := r.db.Where("idremessa = ?", id).First(&remittance).Error; err != nil {
+		return nil, err
+	}
+	return &remittance, nil
+}
+
+func (r *remittanceRepository) GetBySequence(accountID string, sequence int) (*domain.Remittance, error) {
+	var remittance domain.Remittance
+	if err := r.db.Where("idcontacorrente = ? AND nsa = ?", accountID, sequence).First(&remittance).Error; err != nil {
+		return nil, err
+	}
+	return &remittance, nil
+}
+
+func (r *remittanceRepository) GetByAccountID(accountID string) ([]domain.Remittance, error) {
+	var remittances []domain.Remittance
+	err := r.db.Where("idcontacorrente = ?", accountID).
+		Order("data_importacao DESC").
+		Find(&remittances).Error
+	return remittances, err
+}
+
+func (r *remittanceRepository) GetItems(remittanceID string) ([]domain.RemittanceItem, error) {
+	var items []domain.RemittanceItem
+	err := r.db.Where("idremessa = ?", remittanceID).
+		Order("linha ASC").
+		Find(&items).Error
+	return items, err
+}
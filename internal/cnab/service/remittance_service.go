@@ -0,0 +1,389 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	boletoService "bankmore/internal/boleto/service"
+	"bankmore/internal/cnab/domain"
+	"bankmore/internal/cnab/repository"
+	"bankmore/internal/shared/boleto"
+	"bankmore/internal/shared/cnab"
+	"bankmore/internal/shared/models"
+	"bankmore/internal/shared/utils"
+	transferDomain "bankmore/internal/transfer/domain"
+	transferService "bankmore/internal/transfer/service"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var ErrRemittanceNotFound = errors.New("remessa não encontrada")
+
+type RemittanceService interface {
+	ImportRemittance(accountID string, request ImportRequest) (*models.Result[domain.Remittance], error)
+	ListRemittances(accountID string) ([]domain.Remittance, error)
+	GetRemittance(accountID, remittanceID string) (*domain.Remittance, error)
+	GenerateReturn(accountID, remittanceID string) ([]byte, string, error)
+}
+
+type remittanceService struct {
+	repo      repository.RemittanceRepository
+	transfers transferService.TransferService
+	boletos   boletoService.BoletoService
+	logger    *logrus.Logger
+}
+
+func NewRemittanceService(repo repository.RemittanceRepository, transfers transferService.TransferService, boletos boletoService.BoletoService, logger *logrus.Logger) RemittanceService {
+	return &remittanceService{
+		repo:      repo,
+		transfers: transfers,
+		boletos:   boletos,
+		logger:    logger,
+	}
+}
+
+// ImportRequest é o arquivo de remessa enviado; o código TOTP é exigido quando o total
+// das transferências passa do limite do 2FA.
+type ImportRequest struct {
+	FileName      string
+	Content       []byte
+	TOTPCode      string
+	Authorization string
+}
+
+// ImportRemittance valida o arquivo inteiro antes de executar qualquer pagamento: erros
+// de layout ou de campo recusam o arquivo com *cnab.ValidationError. As transferências
+// viram um lote de transferências (BEST_EFFORT) e os boletos são pagos na hora; os
+// boletos recusados, por saldo ou situação do título, ficam como itens recusados.
+func (s *remittanceService) ImportRemittance(accountID string, request ImportRequest) (*models.Result[domain.Remittance], error) {
+	file, err := cnab.Parse(request.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.repo.GetAccount(accountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting remittance account")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	hash := sha256.Sum256(request.Content)
+	fileHash := hex.EncodeToString(hash[:])
+
+	existing, err := s.repo.GetBySequence(accountID, file.Sequence)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.WithError(err).Error("Error checking remittance sequence")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if existing != nil {
+		if existing.FileHash != fileHash {
+			errs := &cnab.ValidationError{}
+			errs.Add(1, cnab.FieldPosition(file.Layout, "", cnab.FieldFileSequence), cnab.FieldFileSequence,
+				fmt.Sprintf("sequencial %d já usado na remessa de %s", file.Sequence, existing.CreatedAt.Format("02/01/2006 15:04")))
+			return nil, errs
+		}
+		s.logger.WithField("remittanceId", existing.ID).Info("Duplicate remittance file ignored")
+		remittance, err := s.GetRemittance(accountID, existing.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.Result[domain.Remittance]{IsSuccess: true, Data: *remittance}, nil
+	}
+
+	if errs := validateFile(file, account.Number, account.CPF); len(errs.Errors) > 0 {
+		return nil, errs
+	}
+
+	remittance := domain.NewRemittance(accountID, file.Sequence, file.Layout, request.FileName, fileHash, string(request.Content))
+
+	var transfers, boletos []cnab.Payment
+	for _, payment := range file.Payments {
+		if payment.Type == cnab.PaymentTransfer {
+			transfers = append(transfers, payment)
+		} else {
+			boletos = append(boletos, payment)
+		}
+	}
+
+	if len(transfers) > 0 {
+		batch, result, err := s.createTransferBatch(accountID, file, transfers, request)
+		if err != nil || result != nil {
+			return result, err
+		}
+		remittance.BatchID = &batch.ID
+		for i, payment := range transfers {
+			sequence := i + 1
+			remittance.AddItem(domain.RemittanceItem{
+				Line:          payment.Line,
+				Type:          payment.Type,
+				YourNumber:    payment.YourNumber,
+				Amount:        payment.Amount,
+				BatchSequence: &sequence,
+				Status:        domain.ItemStatusAccepted,
+				Occurrence:    cnab.OccurrenceAccepted,
+			})
+		}
+	}
+
+	for _, payment := range boletos {
+		remittance.AddItem(s.payBoleto(accountID, file.Sequence, payment))
+	}
+
+	if err := s.repo.Create(remittance); err != nil {
+		s.logger.WithError(err).Error("Error creating remittance")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"remittanceId": remittance.ID,
+		"accountId":    accountID,
+		"layout":       remittance.Layout,
+		"sequence":     remittance.Sequence,
+		"items":        remittance.ItemCount,
+		"rejected":     remittance.RejectedCount,
+		"totalAmount":  remittance.TotalAmount,
+	}).Info("CNAB remittance imported")
+
+	s.refreshItems(accountID, remittance)
+	return &models.Result[domain.Remittance]{IsSuccess: true, Data: *remittance}, nil
+}
+
+func (s *remittanceService) ListRemittances(accountID string) ([]domain.Remittance, error) {
+	remittances, err := s.repo.GetByAccountID(accountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing remittances")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return remittances, nil
+}
+
+// GetRemittance devolve a remessa com a situação atual de cada pagamento.
+func (s *remittanceService) GetRemittance(accountID, remittanceID string) (*domain.Remittance, error) {
+	remittance, err := s.repo.GetByID(remittanceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRemittanceNotFound
+		}
+		s.logger.WithError(err).Error("Error getting remittance")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if remittance.AccountID != accountID {
+		return nil, ErrRemittanceNotFound
+	}
+
+	remittance.Items, err = s.repo.GetItems(remittance.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting remittance items")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.refreshItems(accountID, remittance)
+	return remittance, nil
+}
+
+// GenerateReturn gera o arquivo de retorno no layout da remessa, com a ocorrência atual
+// de cada registro. Pode ser gerado várias vezes: transferências ainda em processamento
+// saem como BD e, depois de concluídas, como 00.
+func (s *remittanceService) GenerateReturn(accountID, remittanceID string) ([]byte, string, error) {
+	remittance, err := s.GetRemittance(accountID, remittanceID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	file, err := cnab.Parse([]byte(remittance.Content))
+	if err != nil {
+		s.logger.WithError(err).WithField("remittanceId", remittance.ID).Error("Error parsing stored remittance")
+		return nil, "", fmt.Errorf("erro interno do servidor")
+	}
+
+	items := make(map[int]*itemState, len(remittance.Items))
+	for _, state := range s.itemStates(accountID, remittance) {
+		items[state.item.Line] = state
+	}
+
+	file.Kind = cnab.KindReturn
+	file.GeneratedAt = time.Now()
+	for i := range file.Payments {
+		payment := &file.Payments[i]
+		state, ok := items[payment.Line]
+		if !ok {
+			continue
+		}
+		payment.OurNumber = state.ourNumber
+		payment.EffectiveDate = state.effectiveDate
+		if state.effectiveDate != nil {
+			payment.EffectiveAmount = state.item.Amount
+		}
+		payment.Occurrences = []string{state.item.Occurrence}
+	}
+
+	content, err := cnab.Write(file)
+	if err != nil {
+		s.logger.WithError(err).Error("Error writing return file")
+		return nil, "", fmt.Errorf("erro interno do servidor")
+	}
+
+	return content, fmt.Sprintf("retorno-%06d.ret", remittance.Sequence), nil
+}
+
+// validateFile confere o header com a conta logada e os campos de cada pagamento que
+// dependem do BankMore: banco, contas, códigos de barras e datas.
+func validateFile(file *cnab.File, accountNumber int, cpf string) *cnab.ValidationError {
+	errs := &cnab.ValidationError{}
+	bankCode := utils.GetBankCode()
+	header := func(field, message string) {
+		errs.Add(1, cnab.FieldPosition(file.Layout, "", field), field, message)
+	}
+
+	if file.Kind != cnab.KindRemittance {
+		header(cnab.FieldKind, "o arquivo é um retorno; envie uma remessa")
+	}
+	if file.BankCode != bankCode {
+		header(cnab.FieldBank, fmt.Sprintf("banco %s difere do %s (%s)", file.BankCode, utils.BankName, bankCode))
+	}
+	if file.AccountNumber != strconv.Itoa(accountNumber) {
+		header(cnab.FieldAccount, fmt.Sprintf("conta %s difere da conta logada", file.AccountNumber))
+	}
+	if file.CompanyDocument != cpf {
+		header(cnab.FieldDocument, "inscrição difere do CPF do titular da conta")
+	}
+
+	today := time.Now()
+	endOfToday := time.Date(today.Year(), today.Month(), today.Day(), 23, 59, 59, 0, time.Local)
+
+	for _, payment := range file.Payments {
+		field := func(name, message string) {
+			errs.Add(payment.Line, cnab.FieldPosition(file.Layout, payment.Type, name), name, message)
+		}
+
+		if payment.Amount <= 0 {
+			field(cnab.FieldAmount, "valor deve ser positivo")
+		}
+		if payment.PaymentDate.After(endOfToday) {
+			field(cnab.FieldPaymentDate, "pagamento com data futura não é aceito; agende pela API")
+		}
+
+		switch payment.Type {
+		case cnab.PaymentTransfer:
+			if payment.DestinationBank != bankCode {
+				field(cnab.FieldDestinationBank, fmt.Sprintf("somente contas do %s (%s)", utils.BankName, bankCode))
+			}
+			if strings.Trim(payment.DestinationAccount, "0") == "" {
+				field(cnab.FieldDestinationAccount, "conta do favorecido não informada")
+			}
+		case cnab.PaymentBoleto:
+			if _, err := boleto.Parse(payment.Barcode); err != nil {
+				field(cnab.FieldBarcode, "código de barras inválido: "+err.Error())
+			}
+		}
+	}
+
+	return errs
+}
+
+// createTransferBatch cria o lote de transferências da remessa. O requestId do lote vem
+// da conta e do NSA, então uma nova importação depois de uma falha não duplica o lote.
+// Erros de validação do lote voltam como erros de campo na linha do registro.
+func (s *remittanceService) createTransferBatch(accountID string, file *cnab.File, transfers []cnab.Payment, request ImportRequest) (*transferDomain.TransferBatch, *models.Result[domain.Remittance], error) {
+	items := make([]transferService.BatchItemRequest, 0, len(transfers))
+	for _, payment := range transfers {
+		description := payment.Description
+		if description == "" && payment.YourNumber != "" {
+			description = "CNAB " + payment.YourNumber
+		}
+		items = append(items, transferService.BatchItemRequest{
+			DestinationAccountNumber: payment.DestinationAccount,
+			Amount:                   payment.Amount,
+			Description:              description,
+		})
+	}
+
+	result, err := s.transfers.CreateBatch(accountID, transferService.CreateBatchRequest{
+		RequestID:     fmt.Sprintf("cnab-%s-%d", accountID, file.Sequence),
+		Mode:          transferDomain.BatchModeBestEffort,
+		Items:         items,
+		TOTPCode:      request.TOTPCode,
+		Authorization: request.Authorization,
+	})
+	if err != nil {
+		var validationErr *transferService.BatchValidationError
+		if errors.As(err, &validationErr) {
+			errs := &cnab.ValidationError{}
+			for _, item := range validationErr.Items {
+				payment := transfers[item.Item-1]
+				errs.Add(payment.Line, cnab.FieldPosition(file.Layout, payment.Type, cnab.FieldDestinationAccount), cnab.FieldDestinationAccount, item.Message)
+			}
+			return nil, nil, errs
+		}
+		return nil, nil, err
+	}
+
+	if !result.IsSuccess {
+		return nil, &models.Result[domain.Remittance]{
+			IsSuccess:    false,
+			ErrorType:    result.ErrorType,
+			ErrorMessage: result.ErrorMessage,
+		}, nil
+	}
+
+	return &result.Data, nil, nil
+}
+
+// payBoleto paga um boleto da remessa; o requestId vem da conta, do NSA e da linha.
+func (s *remittanceService) payBoleto(accountID string, sequence int, payment cnab.Payment) domain.RemittanceItem {
+	item := domain.RemittanceItem{
+		Line:       payment.Line,
+		Type:       payment.Type,
+		YourNumber: payment.YourNumber,
+		Amount:     payment.Amount,
+	}
+
+	result, err := s.boletos.PayBoleto(accountID, boletoService.PayBoletoRequest{
+		RequestID: fmt.Sprintf("cnab-%s-%d-%d", accountID, sequence, payment.Line),
+		Code:      payment.Barcode,
+		Amount:    payment.Amount,
+	})
+	switch {
+	case err != nil:
+		s.logger.WithError(err).WithField("line", payment.Line).Error("Error paying remittance boleto")
+		item.Status = domain.ItemStatusRejected
+		item.Occurrence = cnab.OccurrenceOther
+		item.Error = "Erro interno do servidor"
+	case !result.IsSuccess:
+		item.Status = domain.ItemStatusRejected
+		item.Occurrence = rejectionOccurrence(result.ErrorType)
+		item.Error = result.ErrorMessage
+	default:
+		item.Status = domain.ItemStatusAccepted
+		item.Occurrence = cnab.OccurrenceAccepted
+		item.PaymentID = &result.Data.ID
+	}
+	return item
+}
+
+func rejectionOccurrence(errorType string) string {
+	switch errorType {
+	case models.ErrorInsufficientBalance:
+		return cnab.OccurrenceInsufficientFunds
+	case models.ErrorInvalidAmount:
+		return cnab.OccurrenceInvalidAmount
+	case models.ErrorInvalidPaymentCode:
+		return cnab.OccurrenceInvalidBarcode
+	case models.ErrorInvalidAccount, models.ErrorInactiveAccount, models.ErrorAccountNotFound:
+		return cnab.OccurrenceInvalidAccount
+	default:
+		return cnab.OccurrenceOther
+	}
+}
+
+func (s *remittanceService) refreshItems(accountID string, remittance *domain.Remittance) {
+	for _, state := range s.itemStates(accountID, remittance) {
+		state.item.OccurrenceDescription = cnab.OccurrenceDescription(state.item.Occurrence)
+	}
+}
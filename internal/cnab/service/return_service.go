@@ -0,0 +1,104 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	boletoDomain "bankmore/internal/boleto/domain"
+	"bankmore/internal/cnab/domain"
+	"bankmore/internal/shared/cnab"
+	transferDomain "bankmore/internal/transfer/domain"
+)
+
+// itemState é a situação de um item da remessa no retorno.
+type itemState struct {
+	item          *domain.RemittanceItem
+	ourNumber     string
+	effectiveDate *time.Time
+}
+
+// itemStates atualiza a ocorrência dos itens aceitos com a situação atual do item do lote
+// ou do pagamento de boleto. Itens recusados mantêm a ocorrência da importação.
+func (s *remittanceService) itemStates(accountID string, remittance *domain.Remittance) []*itemState {
+	var batch *transferDomain.TransferBatch
+	batchItems := make(map[int]transferDomain.TransferBatchItem)
+	if remittance.BatchID != nil {
+		var err error
+		batch, err = s.transfers.GetBatch(accountID, *remittance.BatchID)
+		if err != nil {
+			s.logger.WithError(err).WithField("batchId", *remittance.BatchID).Warn("Error getting remittance transfer batch")
+		} else {
+			for _, batchItem := range batch.Items {
+				batchItems[batchItem.Sequence] = batchItem
+			}
+		}
+	}
+
+	states := make([]*itemState, 0, len(remittance.Items))
+	for i := range remittance.Items {
+		item := &remittance.Items[i]
+		state := &itemState{item: item}
+		states = append(states, state)
+		if item.Status != domain.ItemStatusAccepted {
+			continue
+		}
+
+		switch {
+		case item.BatchSequence != nil:
+			batchItem, ok := batchItems[*item.BatchSequence]
+			if !ok {
+				continue
+			}
+			if batchItem.TransferID != nil {
+				state.ourNumber = compactID(*batchItem.TransferID)
+			}
+			switch batchItem.Status {
+			case transferDomain.BatchItemStatusCompleted:
+				item.Occurrence = cnab.OccurrencePaid
+				state.effectiveDate = batch.CompletionDate
+				if state.effectiveDate == nil {
+					state.effectiveDate = batch.HeartbeatAt
+				}
+			case transferDomain.BatchItemStatusFailed:
+				item.Occurrence = cnab.OccurrenceOther
+				if batchItem.Error == "Saldo insuficiente" {
+					item.Occurrence = cnab.OccurrenceInsufficientFunds
+				}
+				item.Error = batchItem.Error
+			case transferDomain.BatchItemStatusCancelled:
+				item.Occurrence = cnab.OccurrenceCancelled
+				item.Error = batchItem.Error
+			default:
+				item.Occurrence = cnab.OccurrenceAccepted
+			}
+
+		case item.PaymentID != nil:
+			payment, err := s.boletos.GetPayment(accountID, *item.PaymentID)
+			if err != nil {
+				s.logger.WithError(err).WithField("paymentId", *item.PaymentID).Warn("Error getting remittance boleto payment")
+				continue
+			}
+			state.ourNumber = compactID(payment.ID)
+			switch payment.Status {
+			case boletoDomain.PaymentStatusPaid, boletoDomain.PaymentStatusSettled:
+				item.Occurrence = cnab.OccurrencePaid
+				state.effectiveDate = payment.PaidAt
+			case boletoDomain.PaymentStatusFailed:
+				item.Occurrence = cnab.OccurrenceOther
+				item.Error = payment.Error
+			default:
+				item.Occurrence = cnab.OccurrenceAccepted
+			}
+		}
+	}
+	return states
+}
+
+// compactID cabe o ID no nosso número de 20 posições.
+func compactID(id string) string {
+	id = strings.ReplaceAll(id, "-", "")
+	if len(id) > 20 {
+		return id[:20]
+	}
+	return id
+}
@@ -3,30 +3,58 @@ package domain
 import (
 	"time"
 
+	"bankmore/internal/shared/calendar"
+
 	"github.com/google/uuid"
 )
 
 type Fee struct {
-	ID          string    `json:"id" gorm:"column:idtarifa;primaryKey"`
-	AccountID   string    `json:"accountId" gorm:"column:idcontacorrente"`
-	Date        time.Time `json:"date" gorm:"column:datamovimento"`
-	Amount      float64   `json:"amount" gorm:"column:valor"`
-	Type        string    `json:"type" gorm:"-"`
-	Description string    `json:"description" gorm:"-"`
-	RequestID   string    `json:"requestId" gorm:"-"`
+	ID             string     `json:"id" gorm:"column:idtarifa;primaryKey"`
+	AccountID      string     `json:"accountId" gorm:"column:idcontacorrente"`
+	Date           time.Time  `json:"date" gorm:"column:datamovimento"`
+	Amount         float64    `json:"amount" gorm:"column:valor"`
+	Type           string     `json:"type" gorm:"-"`
+	Description    string     `json:"description" gorm:"-"`
+	RequestID      string     `json:"requestId" gorm:"column:idempotencia_key"`
+	TransferID     *string    `json:"transferId,omitempty" gorm:"column:idtransferencia;index"`
+	AccountingDate *time.Time `json:"accountingDate,omitempty" gorm:"column:data_contabil"`
+	BillingDate    *time.Time `json:"billingDate,omitempty" gorm:"column:data_cobranca"`
+	ChargedAt      *time.Time `json:"chargedAt,omitempty" gorm:"column:data_debito"`
 }
 
 func (Fee) TableName() string {
 	return "tarifa"
 }
 
-func NewFee(accountID string, amount float64) *Fee {
-	return &Fee{
-		ID:        uuid.New().String(),
-		AccountID: accountID,
-		Date:      time.Now(),
-		Amount:    amount,
+// NewFee cria a tarifa vinculada à transferência que a gerou. O requestId é a base da
+// chave do débito na conta, o que permite à conciliação conferir a cobrança. A
+// cobrança é imediata em dia útil; em fim de semana ou feriado, fica para o início do
+// próximo dia útil.
+func NewFee(accountID string, amount float64, transferID, requestID string) *Fee {
+	now := time.Now()
+	billingDate := now
+	if !calendar.IsBusinessDay(now) {
+		billingDate = calendar.NextBusinessDay(now)
+	}
+
+	fee := &Fee{
+		ID:          uuid.New().String(),
+		AccountID:   accountID,
+		Date:        now,
+		Amount:      amount,
+		RequestID:   requestID,
+		BillingDate: &billingDate,
 	}
+	if transferID != "" {
+		fee.TransferID = &transferID
+	}
+	return fee
+}
+
+// IsDue indica se a tarifa já pode ser debitada em now. Tarifas sem data de cobrança
+// são anteriores ao calendário e sempre foram debitadas na hora.
+func (f *Fee) IsDue(now time.Time) bool {
+	return f.BillingDate == nil || !f.BillingDate.After(now)
 }
 
 const (
@@ -22,13 +22,36 @@ func NewFeeHandler(service service.FeeService, logger *logrus.Logger) *FeeHandle
 	}
 }
 
+// @Summary Consulta as tarifas da conta logada
+// @Description Consulta todas as tarifas cobradas da conta do token
+// @Tags Fee
+// @Produce json
+// @Success 200 {array} domain.Fee
+// @Failure 500 {string} string
+// @Security BearerAuth
+// @Router /api/fee [get]
+func (h *FeeHandler) GetOwnFees(c *gin.Context) {
+	accountNumber := c.GetString("accountNumber")
+
+	fees, err := h.service.GetFeesByAccountNumber(accountNumber)
+	if err != nil {
+		h.logger.WithError(err).Error("Error getting fees for account")
+		c.JSON(http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	c.JSON(http.StatusOK, fees)
+}
+
 // @Summary Consulta tarifas por número da conta
-// @Description Consulta todas as tarifas de uma conta pelo número
+// @Description Consulta todas as tarifas de uma conta pelo número (requer escopo fees:read: suporte, admin ou serviços internos)
 // @Tags Fee
 // @Produce json
 // @Param accountNumber path string true "Número da conta"
 // @Success 200 {array} domain.Fee
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 500 {string} string
+// @Security BearerAuth
 // @Router /api/fee/{accountNumber} [get]
 func (h *FeeHandler) GetFeesByAccount(c *gin.Context) {
 	accountNumber := c.Param("accountNumber")
@@ -44,13 +67,15 @@ func (h *FeeHandler) GetFeesByAccount(c *gin.Context) {
 }
 
 // @Summary Consulta tarifa específica por ID
-// @Description Consulta uma tarifa específica pelo ID
+// @Description Consulta uma tarifa específica pelo ID (requer escopo fees:read)
 // @Tags Fee
 // @Produce json
 // @Param id path int true "ID da tarifa"
 // @Success 200 {object} domain.Fee
+// @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {string} string
 // @Failure 500 {string} string
+// @Security BearerAuth
 // @Router /api/fee/fee/{id} [get]
 func (h *FeeHandler) GetFeeByID(c *gin.Context) {
 	idStr := c.Param("id")
@@ -69,3 +94,16 @@ func (h *FeeHandler) GetFeeByID(c *gin.Context) {
 
 	c.JSON(http.StatusOK, fee)
 }
+
+// @Summary Cota a tarifa de uma transferência
+// @Description Retorna a tarifa que seria cobrada hoje por uma transferência do tipo informado (TRANSFER, SWEEP ou REFUND; padrão TRANSFER). Usado pela API de transferências nas cotações (requer escopo fees:read)
+// @Tags Fee
+// @Produce json
+// @Param type query string false "Tipo da transferência"
+// @Success 200 {object} service.FeeQuote
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/fee/quote [get]
+func (h *FeeHandler) GetFeeQuote(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.QuoteFee(c.Query("type")))
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"bankmore/internal/fee/domain"
+	ledgerRepository "bankmore/internal/ledger/repository"
 	"strconv"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,6 +13,11 @@ type FeeRepository interface {
 	Create(fee *domain.Fee) error
 	GetByID(id int) (*domain.Fee, error)
 	GetByAccountNumber(accountNumber string) ([]domain.Fee, error)
+	GetByTransferID(transferID string) (*domain.Fee, error)
+	GetAccountNumberByID(accountID string) (string, error)
+	GetAccountingDate(at time.Time) (time.Time, error)
+	GetDueUncharged(now time.Time, limit int) ([]domain.Fee, error)
+	MarkCharged(id string, at time.Time) (bool, error)
 }
 
 type feeRepository struct {
@@ -42,7 +49,7 @@ func (r *feeRepository) GetByAccountNumber(accountNumber string) ([]domain.Fee,
 	var fees []domain.Fee
 	
 	err := r.db.Table("tarifa t").
-		Select("t.idtarifa, t.idcontacorrente, t.datamovimento, t.valor").
+		Select("t.idtarifa, t.idcontacorrente, t.datamovimento, t.valor, t.idempotencia_key, t.idtransferencia, t.data_contabil, t.data_cobranca, t.data_debito").
 		Joins("JOIN contacorrente c ON t.idcontacorrente = c.idcontacorrente").
 		Where("c.numero = ?", accountNumber).
 		Order("t.datamovimento DESC").
@@ -59,3 +66,50 @@ func (r *feeRepository) GetByAccountNumber(accountNumber string) ([]domain.Fee,
 
 	return fees, nil
 }
+
+// GetByTransferID devolve a tarifa já cobrada pela transferência, ou nil se não houver.
+func (r *feeRepository) GetByTransferID(transferID string) (*domain.Fee, error) {
+	var fees []domain.Fee
+	err := r.db.Where("idtransferencia = ?", transferID).Limit(1).Find(&fees).Error
+	if err != nil || len(fees) == 0 {
+		return nil, err
+	}
+	return &fees[0], nil
+}
+
+func (r *feeRepository) GetAccountNumberByID(accountID string) (string, error) {
+	var accountNumber int
+	err := r.db.Table("contacorrente").
+		Select("numero").
+		Where("idcontacorrente = ?", accountID).
+		Scan(&accountNumber).Error
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(accountNumber), nil
+}
+
+// GetAccountingDate devolve a data contábil de uma tarifa cobrada em at: cobranças em
+// fim de semana, feriado ou depois do corte entram no próximo dia útil.
+func (r *feeRepository) GetAccountingDate(at time.Time) (time.Time, error) {
+	return ledgerRepository.AccountingDate(r.db, at)
+}
+
+// GetDueUncharged devolve as tarifas com cobrança adiada cujo dia útil já chegou.
+func (r *feeRepository) GetDueUncharged(now time.Time, limit int) ([]domain.Fee, error) {
+	var fees []domain.Fee
+	err := r.db.Where("data_debito IS NULL AND data_cobranca <= ?", now).
+		Order("data_cobranca ASC").
+		Limit(limit).
+		Find(&fees).Error
+	return fees, err
+}
+
+// MarkCharged registra o débito da tarifa. Retorna true apenas para quem o registrou,
+// para que o evento de cobrança seja publicado uma única vez.
+func (r *feeRepository) MarkCharged(id string, at time.Time) (bool, error) {
+	result := r.db.Model(&domain.Fee{}).
+		Where("idtarifa = ? AND data_debito IS NULL", id).
+		Update("data_debito", at)
+	return result.RowsAffected == 1, result.Error
+}
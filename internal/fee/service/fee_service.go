@@ -7,10 +7,12 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"bankmore/internal/fee/domain"
 	"bankmore/internal/fee/repository"
 	"bankmore/internal/shared/kafka"
+	"bankmore/internal/shared/middleware"
 
 	"github.com/sirupsen/logrus"
 )
@@ -19,17 +21,29 @@ type FeeService interface {
 	GetFeesByAccountNumber(accountNumber string) ([]domain.Fee, error)
 	GetFeeByID(id int) (*domain.Fee, error)
 	HandleTransferEvent(event kafka.TransferEvent) error
+	QuoteFee(transferType string) FeeQuote
+	BillDueFees() (int, error)
+}
+
+const billingBatchSize = 100
+
+// FeeQuote é a tarifa que seria cobrada hoje por uma transferência do tipo informado.
+type FeeQuote struct {
+	Type   string  `json:"type"`
+	Amount float64 `json:"amount"`
 }
 
 type feeService struct {
-	repo   repository.FeeRepository
-	logger *logrus.Logger
+	repo     repository.FeeRepository
+	producer *kafka.Producer
+	logger   *logrus.Logger
 }
 
-func NewFeeService(repo repository.FeeRepository, logger *logrus.Logger) FeeService {
+func NewFeeService(repo repository.FeeRepository, producer *kafka.Producer, logger *logrus.Logger) FeeService {
 	return &feeService{
-		repo:   repo,
-		logger: logger,
+		repo:     repo,
+		producer: producer,
+		logger:   logger,
 	}
 }
 
@@ -63,18 +77,53 @@ func (s *feeService) GetFeeByID(id int) (*domain.Fee, error) {
 }
 
 func (s *feeService) HandleTransferEvent(event kafka.TransferEvent) error {
+	if event.IsFeeExempt() {
+		s.logger.WithFields(logrus.Fields{
+			"transferId": event.TransferID,
+			"type":       event.Type,
+		}).Info("Transfer exempt from fee")
+		return nil
+	}
+
+	if event.TransferID != "" {
+		existing, err := s.repo.GetByTransferID(event.TransferID)
+		if err != nil {
+			s.logger.WithError(err).Error("Error checking existing transfer fee")
+			return fmt.Errorf("erro ao criar tarifa")
+		}
+		if existing != nil {
+			return s.retryFeeDebit(existing)
+		}
+	}
+
 	feeAmount := s.getTransferFeeAmount()
+	if event.FeeAmount != nil {
+		feeAmount = *event.FeeAmount
+	}
 
-	fee := domain.NewFee(event.OriginAccountID, feeAmount)
+	fee := domain.NewFee(event.OriginAccountID, feeAmount, event.TransferID, event.RequestID)
+	if accountingDate, err := s.repo.GetAccountingDate(fee.Date); err != nil {
+		s.logger.WithError(err).Error("Error resolving fee accounting date")
+	} else {
+		fee.AccountingDate = &accountingDate
+	}
 
 	if err := s.repo.Create(fee); err != nil {
 		s.logger.WithError(err).Error("Error creating fee")
 		return fmt.Errorf("erro ao criar tarifa")
 	}
 
-	if err := s.debitFeeFromAccount(event.OriginAccountID, feeAmount, event.RequestID); err != nil {
-		s.logger.WithError(err).Error("Error debiting fee from account")
-		return fmt.Errorf("erro ao debitar tarifa da conta")
+	if !fee.IsDue(time.Now()) {
+		s.logger.WithFields(logrus.Fields{
+			"feeId":       fee.ID,
+			"transferId":  event.TransferID,
+			"billingDate": fee.BillingDate,
+		}).Info("Fee billing deferred to next business day")
+		return nil
+	}
+
+	if err := s.charge(fee); err != nil {
+		return err
 	}
 
 	s.logger.WithFields(logrus.Fields{
@@ -88,6 +137,125 @@ func (s *feeService) HandleTransferEvent(event kafka.TransferEvent) error {
 	return nil
 }
 
+// charge debita a tarifa na conta e publica o evento de cobrança. O débito usa a chave
+// de idempotência da tarifa, então repetir a cobrança não debita duas vezes.
+func (s *feeService) charge(fee *domain.Fee) error {
+	if err := s.debitFeeFromAccount(fee.AccountID, fee.Amount, fee.RequestID); err != nil {
+		s.logger.WithError(err).Error("Error debiting fee from account")
+		return fmt.Errorf("erro ao debitar tarifa da conta")
+	}
+
+	chargedAt := time.Now()
+	marked, err := s.repo.MarkCharged(fee.ID, chargedAt)
+	if err != nil {
+		s.logger.WithError(err).WithField("feeId", fee.ID).Error("Error recording fee charge")
+		return nil
+	}
+	if !marked {
+		return nil
+	}
+	fee.ChargedAt = &chargedAt
+
+	feeEvent := kafka.FeeEvent{
+		FeeID:     fee.ID,
+		AccountID: fee.AccountID,
+		Amount:    fee.Amount,
+		RequestID: fee.RequestID,
+		ChargedAt: chargedAt,
+	}
+	if fee.TransferID != nil {
+		feeEvent.TransferID = *fee.TransferID
+	}
+	if err := s.producer.PublishFeeEvent(feeEvent); err != nil {
+		s.logger.WithError(err).Error("Error publishing fee event")
+	}
+	return nil
+}
+
+// retryFeeDebit trata um evento reenviado (pelo Kafka ou pela conciliação) de uma
+// transferência já tarifada: a tarifa não é criada de novo e o débito, que usa a mesma
+// chave de idempotência, só tem efeito se da primeira vez não chegou à conta. Tarifa
+// com cobrança adiada continua à espera do dia útil.
+func (s *feeService) retryFeeDebit(fee *domain.Fee) error {
+	if !fee.IsDue(time.Now()) {
+		return nil
+	}
+
+	if err := s.charge(fee); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"feeId":      fee.ID,
+		"transferId": *fee.TransferID,
+	}).Info("Transfer fee already charged")
+
+	return nil
+}
+
+// QuoteFee aplica as mesmas regras de HandleTransferEvent sem cobrar nada. Tipo vazio
+// é tratado como transferência comum.
+func (s *feeService) QuoteFee(transferType string) FeeQuote {
+	if transferType == "" {
+		transferType = kafka.TransferTypeStandard
+	}
+
+	if (kafka.TransferEvent{Type: transferType}).IsFeeExempt() {
+		return FeeQuote{Type: transferType, Amount: 0}
+	}
+
+	return FeeQuote{Type: transferType, Amount: s.getTransferFeeAmount()}
+}
+
+// BillDueFees debita as tarifas adiadas de fim de semana e feriado quando chega o dia
+// útil. Retorna quantas foram cobradas.
+func (s *feeService) BillDueFees() (int, error) {
+	fees, err := s.repo.GetDueUncharged(time.Now(), billingBatchSize)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing due fees")
+		return 0, fmt.Errorf("erro interno do servidor")
+	}
+
+	charged := 0
+	for i := range fees {
+		if err := s.charge(&fees[i]); err != nil {
+			continue
+		}
+		charged++
+	}
+
+	if charged > 0 {
+		s.logger.WithField("count", charged).Info("Deferred fees charged")
+	}
+	return charged, nil
+}
+
+// StartBillingWorker executa BillDueFees periodicamente até o canal stop ser fechado.
+func StartBillingWorker(service FeeService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				service.BillDueFees()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// GetBillingInterval lê FEE_BILLING_INTERVAL_SECONDS (padrão de um minuto).
+func GetBillingInterval() time.Duration {
+	if value := os.Getenv("FEE_BILLING_INTERVAL_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return time.Minute
+}
+
 func (s *feeService) getTransferFeeAmount() float64 {
 	feeAmountStr := os.Getenv("TRANSFER_FEE_AMOUNT")
 	if feeAmountStr == "" {
@@ -109,7 +277,7 @@ func (s *feeService) debitFeeFromAccount(accountID string, amount float64, reque
 		accountAPIURL = "http://localhost:8001"
 	}
 
-	accountNumber, err := s.getAccountNumberByID(accountID)
+	accountNumber, err := s.repo.GetAccountNumberByID(accountID)
 	if err != nil {
 		return err
 	}
@@ -119,6 +287,7 @@ func (s *feeService) debitFeeFromAccount(accountID string, amount float64, reque
 		"accountNumber": accountNumber,
 		"amount":        amount,
 		"type":          "D",
+		"category":      "FEE",
 	}
 
 	jsonData, err := json.Marshal(request)
@@ -127,7 +296,7 @@ func (s *feeService) debitFeeFromAccount(accountID string, amount float64, reque
 	}
 
 	url := fmt.Sprintf("%s/api/account/movement", accountAPIURL)
-	resp, err := http.Post(url, "application/json", strings.NewReader(string(jsonData)))
+	resp, err := middleware.DoServiceRequest("fee-api", http.MethodPost, url, strings.NewReader(string(jsonData)))
 	if err != nil {
 		return err
 	}
@@ -139,19 +308,3 @@ func (s *feeService) debitFeeFromAccount(accountID string, amount float64, reque
 
 	return nil
 }
-
-func (s *feeService) getAccountNumberByID(accountID string) (string, error) {
-	var accountNumber int
-	err := s.repo.(*feeRepository).db.Table("contacorrente").
-		Select("numero").
-		Where("idcontacorrente = ?", accountID).
-		Scan(&accountNumber).Error
-	if err != nil {
-		return "", err
-	}
-	return strconv.Itoa(accountNumber), nil
-}
-
-type feeRepository struct {
-	db interface{}
-}
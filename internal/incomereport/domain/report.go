@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IncomeReport é o informe de rendimentos de uma conta no ano-calendário: os saldos em
+// 31/12 do ano anterior e do ano, para a declaração de bens, e as tarifas pagas no ano.
+// Conta corrente não rende juros, então não há rendimentos a informar.
+type IncomeReport struct {
+	ID              string    `json:"id" gorm:"column:idinforme;primaryKey"`
+	AccountID       string    `json:"accountId" gorm:"column:idcontacorrente;uniqueIndex:idx_informe_conta_ano"`
+	Year            int       `json:"year" gorm:"column:ano;uniqueIndex:idx_informe_conta_ano"`
+	AccountNumber   int       `json:"accountNumber" gorm:"column:numero_conta"`
+	Name            string    `json:"name" gorm:"column:nome"`
+	CPF             string    `json:"cpf" gorm:"column:cpf"`
+	PreviousBalance float64   `json:"previousBalance" gorm:"column:saldo_anterior"`
+	Balance         float64   `json:"balance" gorm:"column:saldo"`
+	FeesPaid        float64   `json:"feesPaid" gorm:"column:tarifas_pagas"`
+	BankCode        string    `json:"bankCode" gorm:"column:codigo_banco"`
+	GeneratedAt     time.Time `json:"generatedAt" gorm:"column:data_geracao"`
+}
+
+func (IncomeReport) TableName() string {
+	return "informe_rendimentos"
+}
+
+func NewIncomeReport(accountID string, accountNumber int, name, cpf string, year int, bankCode string) *IncomeReport {
+	return &IncomeReport{
+		ID:            uuid.New().String(),
+		AccountID:     accountID,
+		Year:          year,
+		AccountNumber: accountNumber,
+		Name:          name,
+		CPF:           cpf,
+		BankCode:      bankCode,
+		GeneratedAt:   time.Now(),
+	}
+}
+
+// YearEnd devolve o início do dia seguinte a 31/12 do ano, no fuso do servidor: os
+// saldos do informe somam tudo o que foi lançado antes desse instante.
+func YearEnd(year int) time.Time {
+	return time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.Local)
+}
+
+// IsEmpty indica se a conta não teve saldo em nenhuma das datas nem pagou tarifas.
+func (r *IncomeReport) IsEmpty() bool {
+	return r.PreviousBalance == 0 && r.Balance == 0 && r.FeesPaid == 0
+}
+
+// FormattedCPF devolve o CPF no formato 123.456.789-09.
+func (r *IncomeReport) FormattedCPF() string {
+	if len(r.CPF) != 11 {
+		return r.CPF
+	}
+	return fmt.Sprintf("%s.%s.%s-%s", r.CPF[0:3], r.CPF[3:6], r.CPF[6:9], r.CPF[9:])
+}
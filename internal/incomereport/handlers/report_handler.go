@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"bankmore/internal/incomereport/service"
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type IncomeReportHandler struct {
+	service service.IncomeReportService
+	logger  *logrus.Logger
+}
+
+func NewIncomeReportHandler(service service.IncomeReportService, logger *logrus.Logger) *IncomeReportHandler {
+	return &IncomeReportHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// @Summary Lista os informes de rendimentos
+// @Description Retorna os informes já gerados para a conta logada, do ano mais recente para o mais antigo
+// @Tags IncomeReport
+// @Produce json
+// @Success 200 {array} domain.IncomeReport
+// @Failure 401 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/income-report [get]
+func (h *IncomeReportHandler) ListReports(c *gin.Context) {
+	accountID, exists := c.Get("accountId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorUserUnauthorized,
+			Message: "Token inválido",
+		})
+		return
+	}
+
+	reports, err := h.service.ListReports(accountID.(string))
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// @Summary Baixa o informe de rendimentos do ano
+// @Description Retorna o informe de rendimentos da conta logada para a declaração de imposto de renda: saldos em 31/12 do ano anterior e do ano e tarifas pagas no ano. Se o lote anual ainda não gerou o informe, ele é calculado na hora. Só vale para anos encerrados
+// @Tags IncomeReport
+// @Produce application/pdf
+// @Produce json
+// @Param year path int true "Ano-calendário"
+// @Param format query string false "pdf (padrão) ou json"
+// @Success 200 {file} file
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/income-report/{year} [get]
+func (h *IncomeReportHandler) GetReport(c *gin.Context) {
+	accountID, exists := c.Get("accountId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorUserUnauthorized,
+			Message: "Token inválido",
+		})
+		return
+	}
+
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		respondReportError(c, service.ErrInvalidYear)
+		return
+	}
+	format, err := service.ValidateFormat(c.Query("format"))
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	report, err := h.service.GetReport(accountID.(string), year)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", service.ContentType(format))
+	if format == service.ReportFormatPDF {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", service.FileName(report, format)))
+	}
+	c.Status(http.StatusOK)
+
+	if err := h.service.WriteReport(report, format, c.Writer); err != nil {
+		h.logger.WithError(err).WithField("accountId", accountID).Warn("Income report download interrupted")
+	}
+}
+
+func respondReportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidYear), errors.Is(err, service.ErrYearNotClosed), errors.Is(err, service.ErrInvalidReportFormat):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrReportAccountNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Type:    models.ErrorAccountNotFound,
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: err.Error(),
+		})
+	}
+}
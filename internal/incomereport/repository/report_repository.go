@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"time"
+
+	accountDomain "bankmore/internal/account/domain"
+	"bankmore/internal/incomereport/domain"
+	ledgerDomain "bankmore/internal/ledger/domain"
+	ledgerRepository "bankmore/internal/ledger/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IncomeReportRepository interface {
+	GetAccounts(afterID string, limit int) ([]accountDomain.Account, error)
+	GetAccount(accountID string) (*accountDomain.Account, error)
+	GetBalanceAt(accountID string, end time.Time) (float64, error)
+	GetFeesPaid(accountID string, from, to time.Time) (float64, error)
+	Save(report *domain.IncomeReport) error
+	GetReport(accountID string, year int) (*domain.IncomeReport, error)
+	GetReportsByAccount(accountID string) ([]domain.IncomeReport, error)
+}
+
+type incomeReportRepository struct {
+	db *gorm.DB
+}
+
+func NewIncomeReportRepository(db *gorm.DB) IncomeReportRepository {
+	return &incomeReportRepository{db: db}
+}
+
+// GetAccounts devolve até limit contas com ID maior que afterID, para percorrer todas as
+// contas em lotes.
+func (r *incomeReportRepository) GetAccounts(afterID string, limit int) ([]accountDomain.Account, error) {
+	var accounts []accountDomain.Account
+	err := r.db.Where("idcontacorrente > ?", afterID).
+		Order("idcontacorrente ASC").
+		Limit(limit).
+		Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *incomeReportRepository) GetAccount(accountID string) (*accountDomain.Account, error) {
+	var account accountDomain.Account
+	if err := r.db.Where("idcontacorrente = ?", accountID).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *incomeReportRepository) GetBalanceAt(accountID string, end time.Time) (float64, error) {
+	return ledgerRepository.CustomerBalanceAt(r.db, accountID, end)
+}
+
+// GetFeesPaid soma as tarifas debitadas da conta em [from, to), descontados os estornos.
+func (r *incomeReportRepository) GetFeesPaid(accountID string, from, to time.Time) (float64, error) {
+	var paid float64
+	err := r.db.Model(&accountDomain.Movement{}).
+		Select("COALESCE(SUM(CASE WHEN tipomovimento = ? THEN valor ELSE -valor END), 0)", accountDomain.MovementTypeDebit).
+		Where("idcontacorrente = ? AND categoria = ? AND datamovimento >= ? AND datamovimento < ?",
+			accountID, ledgerDomain.CategoryFee, from, to).
+		Scan(&paid).Error
+	return paid, err
+}
+
+// Save grava o informe, substituindo o já gerado para a mesma conta e ano.
+func (r *incomeReportRepository) Save(report *domain.IncomeReport) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "idcontacorrente"}, {Name: "ano"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"numero_conta", "nome", "cpf", "saldo_anterior", "saldo", "tarifas_pagas", "codigo_banco", "data_geracao",
+		}),
+	}).Create(report).Error
+}
+
+func (r *incomeReportRepository) GetReport(accountID string, year int) (*domain.IncomeReport, error) {
+	var report domain.IncomeReport
+	if err := r.db.Where("idcontacorrente = ? AND ano = ?", accountID, year).First(&report).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *incomeReportRepository) GetReportsByAccount(accountID string) ([]domain.IncomeReport, error) {
+	var reports []domain.IncomeReport
+	err := r.db.Where("idcontacorrente = ?", accountID).Order("ano DESC").Find(&reports).Error
+	return reports, err
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"fmt"
+	"io"
+
+	"bankmore/internal/incomereport/domain"
+	"bankmore/internal/shared/pdf"
+	"bankmore/internal/shared/utils"
+)
+
+// Layout do informe em PDF, em pontos.
+const (
+	pdfMargin      = 40.0
+	pdfLineHeight  = 18.0
+	pdfLabelColumn = pdfMargin + 10
+	pdfValueColumn = pdfMargin + 130
+	pdfAmountRight = pdf.PageWidth - pdfMargin - 10
+)
+
+var (
+	pdfBrandColor = pdf.Color{R: 0.05, G: 0.24, B: 0.45}
+	pdfWhite      = pdf.Color{R: 1, G: 1, B: 1}
+	pdfBlack      = pdf.Color{R: 0.1, G: 0.1, B: 0.1}
+	pdfGray       = pdf.Color{R: 0.45, G: 0.45, B: 0.45}
+	pdfLightGray  = pdf.Color{R: 0.93, G: 0.94, B: 0.96}
+)
+
+// writePDF monta o informe em uma página, com as seções do modelo usado pelos bancos:
+// fonte pagadora, beneficiário, saldos para a declaração de bens e tarifas pagas.
+func writePDF(report *domain.IncomeReport, w io.Writer) error {
+	doc := pdf.New(w, fmt.Sprintf("Informe de rendimentos %d - conta %d", report.Year, report.AccountNumber))
+	doc.AddPage()
+
+	top := pdf.PageHeight
+	doc.Rect(0, top-70, pdf.PageWidth, 70, pdfBrandColor)
+	doc.Text(pdfMargin, top-40, 22, true, pdfWhite, utils.BankName)
+	doc.Text(pdfMargin, top-58, 10, false, pdfWhite, "Informe de rendimentos financeiros")
+	doc.TextRight(pdf.PageWidth-pdfMargin, top-40, 10, false, pdfWhite, fmt.Sprintf("Ano-calendário %d", report.Year))
+	doc.TextRight(pdf.PageWidth-pdfMargin, top-58, 10, false, pdfWhite,
+		fmt.Sprintf("Emitido em %s", report.GeneratedAt.Format("02/01/2006 15:04")))
+
+	y := top - 100
+	y = section(doc, y, "1. Fonte pagadora")
+	y = field(doc, y, "Nome", utils.BankName)
+	y = field(doc, y, "Código do banco", report.BankCode)
+
+	y = section(doc, y-10, "2. Beneficiário")
+	y = field(doc, y, "Nome", report.Name)
+	y = field(doc, y, "CPF", report.FormattedCPF())
+	y = field(doc, y, "Conta corrente", fmt.Sprintf("%d", report.AccountNumber))
+
+	y = section(doc, y-10, "3. Saldos em conta corrente (declaração de bens e direitos)")
+	y = amount(doc, y, fmt.Sprintf("Saldo em 31/12/%d", report.Year-1), report.PreviousBalance)
+	y = amount(doc, y, fmt.Sprintf("Saldo em 31/12/%d", report.Year), report.Balance)
+
+	y = section(doc, y-10, "4. Rendimentos")
+	y = amount(doc, y, "Rendimentos tributáveis, isentos ou de tributação exclusiva", 0)
+	doc.Text(pdfLabelColumn, y, 8, false, pdfGray, "Conta corrente não é remunerada e não gera rendimentos a informar.")
+	y -= pdfLineHeight
+
+	y = section(doc, y-10, "5. Tarifas pagas no ano")
+	y = amount(doc, y, "Tarifas bancárias, descontados os estornos", report.FeesPaid)
+
+	doc.Text(pdfMargin, y-20, 9, false, pdfGray,
+		"Os saldos consideram todos os lançamentos até 23:59 de 31 de dezembro de cada ano.")
+
+	doc.Line(pdfMargin, 45, pdf.PageWidth-pdfMargin, 45, 0.5, pdfGray)
+	doc.Text(pdfMargin, 32, 8, false, pdfGray, utils.BankName+" - documento emitido eletronicamente. Valores em reais (R$).")
+	return doc.Close()
+}
+
+func section(doc *pdf.Document, y float64, title string) float64 {
+	doc.Rect(pdfMargin, y-5, pdf.PageWidth-2*pdfMargin, pdfLineHeight, pdfLightGray)
+	doc.Text(pdfMargin+4, y, 10, true, pdfBlack, title)
+	return y - pdfLineHeight - 4
+}
+
+func field(doc *pdf.Document, y float64, label, value string) float64 {
+	doc.Text(pdfLabelColumn, y, 10, true, pdfBlack, label)
+	doc.Text(pdfValueColumn, y, 10, false, pdfBlack, value)
+	return y - pdfLineHeight
+}
+
+func amount(doc *pdf.Document, y float64, label string, value float64) float64 {
+	doc.Text(pdfLabelColumn, y, 10, false, pdfBlack, label)
+	doc.TextRight(pdfAmountRight, y, 10, true, pdfBlack, "R$ "+utils.FormatAmount(value))
+	return y - pdfLineHeight
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"bankmore/internal/incomereport/domain"
+	"bankmore/internal/incomereport/repository"
+	ledgerDomain "bankmore/internal/ledger/domain"
+	"bankmore/internal/shared/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	ReportFormatPDF  = "pdf"
+	ReportFormatJSON = "json"
+
+	// firstReportYear é o primeiro ano-calendário aceito.
+	firstReportYear = 2000
+	batchSize       = 200
+)
+
+var (
+	ErrInvalidReportFormat   = errors.New("formato inválido: use pdf ou json")
+	ErrInvalidYear           = errors.New("ano inválido")
+	ErrYearNotClosed         = errors.New("o informe só fica disponível depois de encerrado o ano-calendário")
+	ErrReportAccountNotFound = errors.New("conta não encontrada")
+)
+
+type IncomeReportService interface {
+	Generate(accountID string, year int) (*domain.IncomeReport, error)
+	RunBatch(year int, each func(report *domain.IncomeReport) error) (*BatchResult, error)
+	GetReport(accountID string, year int) (*domain.IncomeReport, error)
+	ListReports(accountID string) ([]domain.IncomeReport, error)
+	WriteReport(report *domain.IncomeReport, format string, w io.Writer) error
+}
+
+// BatchResult resume uma geração em lote: contas percorridas, informes gravados e
+// contas sem saldo nem tarifas no ano, que não recebem informe.
+type BatchResult struct {
+	Year      int `json:"year"`
+	Accounts  int `json:"accounts"`
+	Generated int `json:"generated"`
+	Skipped   int `json:"skipped"`
+	Failed    int `json:"failed"`
+}
+
+type incomeReportService struct {
+	repo   repository.IncomeReportRepository
+	logger *logrus.Logger
+}
+
+func NewIncomeReportService(repo repository.IncomeReportRepository, logger *logrus.Logger) IncomeReportService {
+	return &incomeReportService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Generate calcula e grava o informe da conta no ano, substituindo o anterior.
+func (s *incomeReportService) Generate(accountID string, year int) (*domain.IncomeReport, error) {
+	if err := validateYear(year); err != nil {
+		return nil, err
+	}
+
+	account, err := s.repo.GetAccount(accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportAccountNotFound
+		}
+		s.logger.WithError(err).Error("Error getting account for income report")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	report := domain.NewIncomeReport(account.ID, account.Number, account.Name, account.CPF, year, utils.GetBankCode())
+	if err := s.calculate(report); err != nil {
+		s.logger.WithError(err).WithField("accountId", accountID).Error("Error calculating income report")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if err := s.repo.Save(report); err != nil {
+		s.logger.WithError(err).WithField("accountId", accountID).Error("Error saving income report")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return report, nil
+}
+
+// RunBatch gera o informe de todas as contas com saldo ou tarifas no ano, chamando each
+// para cada informe gravado. Uma conta com erro não interrompe as demais.
+func (s *incomeReportService) RunBatch(year int, each func(report *domain.IncomeReport) error) (*BatchResult, error) {
+	if err := validateYear(year); err != nil {
+		return nil, err
+	}
+
+	result := &BatchResult{Year: year}
+	bankCode := utils.GetBankCode()
+	afterID := ""
+	for {
+		accounts, err := s.repo.GetAccounts(afterID, batchSize)
+		if err != nil {
+			s.logger.WithError(err).Error("Error listing accounts for income reports")
+			return nil, fmt.Errorf("erro interno do servidor")
+		}
+		if len(accounts) == 0 {
+			break
+		}
+
+		for _, account := range accounts {
+			afterID = account.ID
+			result.Accounts++
+
+			report := domain.NewIncomeReport(account.ID, account.Number, account.Name, account.CPF, year, bankCode)
+			if err := s.calculate(report); err != nil {
+				s.logger.WithError(err).WithField("accountId", account.ID).Error("Error calculating income report")
+				result.Failed++
+				continue
+			}
+			if report.IsEmpty() {
+				result.Skipped++
+				continue
+			}
+			if err := s.repo.Save(report); err != nil {
+				s.logger.WithError(err).WithField("accountId", account.ID).Error("Error saving income report")
+				result.Failed++
+				continue
+			}
+			if each != nil {
+				if err := each(report); err != nil {
+					s.logger.WithError(err).WithField("accountId", account.ID).Error("Error writing income report")
+					result.Failed++
+					continue
+				}
+			}
+			result.Generated++
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"year":      year,
+		"accounts":  result.Accounts,
+		"generated": result.Generated,
+		"skipped":   result.Skipped,
+		"failed":    result.Failed,
+	}).Info("Income reports generated")
+	return result, nil
+}
+
+// GetReport devolve o informe gravado da conta ou, se o lote ainda não o gerou, calcula
+// na hora.
+func (s *incomeReportService) GetReport(accountID string, year int) (*domain.IncomeReport, error) {
+	if err := validateYear(year); err != nil {
+		return nil, err
+	}
+
+	report, err := s.repo.GetReport(accountID, year)
+	if err == nil {
+		return report, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.WithError(err).Error("Error getting income report")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return s.Generate(accountID, year)
+}
+
+func (s *incomeReportService) ListReports(accountID string) ([]domain.IncomeReport, error) {
+	reports, err := s.repo.GetReportsByAccount(accountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing income reports")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return reports, nil
+}
+
+// WriteReport escreve o informe em PDF ou JSON.
+func (s *incomeReportService) WriteReport(report *domain.IncomeReport, format string, w io.Writer) error {
+	switch format {
+	case ReportFormatPDF:
+		return writePDF(report, w)
+	case ReportFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	default:
+		return ErrInvalidReportFormat
+	}
+}
+
+// ValidateFormat confere o formato pedido; vazio vale pdf.
+func ValidateFormat(format string) (string, error) {
+	if format == "" {
+		return ReportFormatPDF, nil
+	}
+	if format != ReportFormatPDF && format != ReportFormatJSON {
+		return "", ErrInvalidReportFormat
+	}
+	return format, nil
+}
+
+// ContentType devolve o tipo de mídia do informe no formato.
+func ContentType(format string) string {
+	if format == ReportFormatJSON {
+		return "application/json; charset=utf-8"
+	}
+	return "application/pdf"
+}
+
+// FileName devolve o nome sugerido para o arquivo, como informe-2025-1234.pdf.
+func FileName(report *domain.IncomeReport, format string) string {
+	return fmt.Sprintf("informe-%d-%d.%s", report.Year, report.AccountNumber, format)
+}
+
+// calculate preenche os saldos em 31/12 do ano anterior e do ano e as tarifas pagas no ano.
+func (s *incomeReportService) calculate(report *domain.IncomeReport) error {
+	previous, err := s.repo.GetBalanceAt(report.AccountID, domain.YearEnd(report.Year-1))
+	if err != nil {
+		return err
+	}
+	balance, err := s.repo.GetBalanceAt(report.AccountID, domain.YearEnd(report.Year))
+	if err != nil {
+		return err
+	}
+	fees, err := s.repo.GetFeesPaid(report.AccountID, domain.YearEnd(report.Year-1), domain.YearEnd(report.Year))
+	if err != nil {
+		return err
+	}
+
+	report.PreviousBalance = roundCents(previous)
+	report.Balance = roundCents(balance)
+	report.FeesPaid = roundCents(fees)
+	return nil
+}
+
+// validateYear aceita apenas anos-calendário encerrados: o saldo de 31/12 do ano em
+// curso ainda pode mudar.
+func validateYear(year int) error {
+	if year < firstReportYear {
+		return ErrInvalidYear
+	}
+	if year >= time.Now().Year() {
+		return ErrYearNotClosed
+	}
+	return nil
+}
+
+func roundCents(amount float64) float64 {
+	return float64(ledgerDomain.ToCents(amount)) / 100
+}
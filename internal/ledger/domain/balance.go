@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DateLayout é o formato das datas de consulta de saldo (fim do dia, no fuso do servidor).
+const DateLayout = "2006-01-02"
+
+// AccountBalanceRecord é o saldo materializado de uma conta do plano, atualizado na mesma
+// transação de cada lançamento para que a leitura do saldo não percorra o histórico.
+type AccountBalanceRecord struct {
+	AccountCode string    `json:"accountCode" gorm:"column:codigo_conta;primaryKey"`
+	Debits      float64   `json:"debits" gorm:"column:debitos"`
+	Credits     float64   `json:"credits" gorm:"column:creditos"`
+	UpdatedAt   time.Time `json:"updatedAt" gorm:"column:data_atualizacao"`
+}
+
+func (AccountBalanceRecord) TableName() string {
+	return "saldo_conta_contabil"
+}
+
+// BalanceSnapshot guarda os totais acumulados de uma conta no fim de um dia. As consultas
+// de saldo em uma data partem do fechamento mais próximo e somam só as partidas seguintes.
+type BalanceSnapshot struct {
+	AccountCode string    `json:"accountCode" gorm:"column:codigo_conta;primaryKey"`
+	Date        time.Time `json:"date" gorm:"column:data;primaryKey"`
+	Debits      float64   `json:"debits" gorm:"column:debitos"`
+	Credits     float64   `json:"credits" gorm:"column:creditos"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:data_criacao"`
+}
+
+func (BalanceSnapshot) TableName() string {
+	return "saldo_diario"
+}
+
+// PostingTotals soma débitos e créditos de uma conta em um intervalo.
+type PostingTotals struct {
+	AccountCode string  `gorm:"column:codigo_conta"`
+	Debits      float64 `gorm:"column:debitos"`
+	Credits     float64 `gorm:"column:creditos"`
+}
+
+// Verificações do conferidor de saldos.
+const (
+	DriftCheckMaterialized = "MATERIALIZED_BALANCE"
+	DriftCheckMovements    = "MOVEMENTS"
+)
+
+// BalanceDrift registra uma divergência encontrada pelo conferidor: o saldo materializado
+// diferente da soma das partidas, ou o razão do cliente diferente da soma dos movimentos.
+type BalanceDrift struct {
+	ID          string    `json:"id" gorm:"column:iddivergencia;primaryKey"`
+	AccountCode string    `json:"accountCode" gorm:"column:codigo_conta"`
+	Check       string    `json:"check" gorm:"column:verificacao"`
+	Expected    float64   `json:"expected" gorm:"column:valor_esperado"`
+	Actual      float64   `json:"actual" gorm:"column:valor_encontrado"`
+	DetectedAt  time.Time `json:"detectedAt" gorm:"column:data_deteccao"`
+}
+
+func (BalanceDrift) TableName() string {
+	return "divergencia_saldo"
+}
+
+func NewBalanceDrift(accountCode, check string, expected, actual float64, now time.Time) *BalanceDrift {
+	return &BalanceDrift{
+		ID:          uuid.New().String(),
+		AccountCode: accountCode,
+		Check:       check,
+		Expected:    expected,
+		Actual:      actual,
+		DetectedAt:  now,
+	}
+}
+
+// StartOfDay devolve o início do dia de t no fuso do servidor.
+func StartOfDay(t time.Time) time.Time {
+	local := t.In(time.Local)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// SignedBalance aplica a natureza da conta: débitos menos créditos no ativo e na
+// despesa, créditos menos débitos no passivo e na receita.
+func SignedBalance(accountType string, debits, credits float64) float64 {
+	account := LedgerAccount{Type: accountType}
+	if account.NormalSide() == SideDebit {
+		return float64(ToCents(debits)-ToCents(credits)) / 100
+	}
+	return float64(ToCents(credits)-ToCents(debits)) / 100
+}
@@ -0,0 +1,291 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Natureza das contas do plano de contas.
+const (
+	AccountTypeAsset     = "ASSET"
+	AccountTypeLiability = "LIABILITY"
+	AccountTypeRevenue   = "REVENUE"
+	AccountTypeExpense   = "EXPENSE"
+)
+
+// Contas do próprio banco. As contas dos clientes ficam abaixo de AccountCustomerDeposits,
+// uma por conta corrente.
+const (
+	AccountCash               = "1.1.01"
+	AccountTransferClearing   = "1.1.02"
+	AccountBoletoClearing     = "1.1.03"
+	AccountCustomerDeposits   = "2.1.01"
+	AccountFeeRevenue         = "3.1.01"
+	AccountSuspense           = "9.9.01"
+	customerAccountCodePrefix = AccountCustomerDeposits + "."
+)
+
+const (
+	SideDebit  = "D"
+	SideCredit = "C"
+)
+
+// Categorias de movimentação de conta corrente. A categoria define a contrapartida do
+// lançamento; movimentos sem categoria (anteriores ao razão) vão para a conta transitória.
+const (
+	CategoryCash     = "CASH"
+	CategoryTransfer = "TRANSFER"
+	CategoryFee      = "FEE"
+	CategoryBoleto   = "BOLETO"
+)
+
+// LedgerAccount é uma conta do plano de contas. Contas de cliente têm o idcontacorrente.
+type LedgerAccount struct {
+	Code              string    `json:"code" gorm:"column:codigo;primaryKey"`
+	Name              string    `json:"name" gorm:"column:nome"`
+	Type              string    `json:"type" gorm:"column:natureza"`
+	CustomerAccountID *string   `json:"customerAccountId,omitempty" gorm:"column:idcontacorrente;unique"`
+	CreatedAt         time.Time `json:"createdAt" gorm:"column:data_criacao"`
+}
+
+func (LedgerAccount) TableName() string {
+	return "conta_contabil"
+}
+
+// NormalSide é o lado que aumenta o saldo da conta: débito para ativo e despesa,
+// crédito para passivo e receita.
+func (a *LedgerAccount) NormalSide() string {
+	if a.Type == AccountTypeAsset || a.Type == AccountTypeExpense {
+		return SideDebit
+	}
+	return SideCredit
+}
+
+// ChartOfAccounts devolve as contas do banco criadas na inicialização.
+func ChartOfAccounts() []LedgerAccount {
+	now := time.Now()
+	return []LedgerAccount{
+		{Code: AccountCash, Name: "Caixa", Type: AccountTypeAsset, CreatedAt: now},
+		{Code: AccountTransferClearing, Name: "Compensação de transferências", Type: AccountTypeAsset, CreatedAt: now},
+		{Code: AccountBoletoClearing, Name: "Compensação de boletos", Type: AccountTypeAsset, CreatedAt: now},
+		{Code: AccountCustomerDeposits, Name: "Depósitos à vista de clientes", Type: AccountTypeLiability, CreatedAt: now},
+		{Code: AccountFeeRevenue, Name: "Receita de tarifas", Type: AccountTypeRevenue, CreatedAt: now},
+		{Code: AccountSuspense, Name: "Conta transitória", Type: AccountTypeAsset, CreatedAt: now},
+	}
+}
+
+// CustomerAccountCode é o código da conta de passivo de uma conta corrente.
+func CustomerAccountCode(accountNumber int) string {
+	return fmt.Sprintf("%s%d", customerAccountCodePrefix, accountNumber)
+}
+
+func IsCustomerAccountCode(code string) bool {
+	return strings.HasPrefix(code, customerAccountCodePrefix)
+}
+
+func NewCustomerLedgerAccount(accountID string, accountNumber int) *LedgerAccount {
+	return &LedgerAccount{
+		Code:              CustomerAccountCode(accountNumber),
+		Name:              fmt.Sprintf("Depósitos à vista - conta %d", accountNumber),
+		Type:              AccountTypeLiability,
+		CustomerAccountID: &accountID,
+		CreatedAt:         time.Now(),
+	}
+}
+
+// JournalEntry é um lançamento contábil. Depois de gravado não é alterado nem removido:
+// correções são feitas com um novo lançamento.
+type JournalEntry struct {
+	ID             string     `json:"id" gorm:"column:idlancamento;primaryKey"`
+	Date           time.Time  `json:"date" gorm:"column:data;index"`
+	AccountingDate *time.Time `json:"accountingDate,omitempty" gorm:"column:data_contabil;index"`
+	Description    string     `json:"description" gorm:"column:historico"`
+	Category       string     `json:"category" gorm:"column:categoria"`
+	MovementID     *string    `json:"movementId,omitempty" gorm:"column:idmovimento;unique"`
+	CreatedAt      time.Time  `json:"createdAt" gorm:"column:data_criacao"`
+	Postings       []Posting  `json:"postings" gorm:"foreignKey:EntryID;references:ID"`
+}
+
+func (JournalEntry) TableName() string {
+	return "lancamento_contabil"
+}
+
+// Posting é uma partida do lançamento: um débito ou crédito em uma conta.
+type Posting struct {
+	ID          string  `json:"id" gorm:"column:idpartida;primaryKey"`
+	EntryID     string  `json:"entryId" gorm:"column:idlancamento;index"`
+	AccountCode string  `json:"accountCode" gorm:"column:codigo_conta;index"`
+	Side        string  `json:"side" gorm:"column:tipo"`
+	Amount      float64 `json:"amount" gorm:"column:valor"`
+}
+
+func (Posting) TableName() string {
+	return "partida_contabil"
+}
+
+func Debit(accountCode string, amount float64) Posting {
+	return Posting{AccountCode: accountCode, Side: SideDebit, Amount: amount}
+}
+
+func Credit(accountCode string, amount float64) Posting {
+	return Posting{AccountCode: accountCode, Side: SideCredit, Amount: amount}
+}
+
+// NewJournalEntry monta um lançamento e recusa partidas que não fecham: a soma dos
+// débitos tem de ser igual à dos créditos, em centavos.
+func NewJournalEntry(description, category string, date time.Time, postings ...Posting) (*JournalEntry, error) {
+	entry := &JournalEntry{
+		ID:          uuid.New().String(),
+		Date:        date,
+		Description: description,
+		Category:    category,
+		CreatedAt:   time.Now(),
+	}
+
+	if len(postings) < 2 {
+		return nil, fmt.Errorf("lançamento precisa de ao menos duas partidas")
+	}
+
+	var balance int64
+	for _, posting := range postings {
+		cents := ToCents(posting.Amount)
+		if cents <= 0 {
+			return nil, fmt.Errorf("valor da partida deve ser positivo")
+		}
+		if posting.AccountCode == "" {
+			return nil, fmt.Errorf("partida sem conta contábil")
+		}
+		switch posting.Side {
+		case SideDebit:
+			balance += cents
+		case SideCredit:
+			balance -= cents
+		default:
+			return nil, fmt.Errorf("tipo de partida inválido: %s", posting.Side)
+		}
+
+		posting.ID = uuid.New().String()
+		posting.EntryID = entry.ID
+		entry.Postings = append(entry.Postings, posting)
+	}
+
+	if balance != 0 {
+		return nil, fmt.Errorf("lançamento não fecha: débitos e créditos diferem em %.2f", float64(balance)/100)
+	}
+
+	return entry, nil
+}
+
+// CounterpartAccount é a conta que recebe a contrapartida de uma movimentação da categoria.
+func CounterpartAccount(category string) string {
+	switch category {
+	case CategoryCash:
+		return AccountCash
+	case CategoryTransfer:
+		return AccountTransferClearing
+	case CategoryFee:
+		return AccountFeeRevenue
+	case CategoryBoleto:
+		return AccountBoletoClearing
+	default:
+		return AccountSuspense
+	}
+}
+
+func IsValidCategory(category string) bool {
+	return category == CategoryCash || category == CategoryTransfer || category == CategoryFee || category == CategoryBoleto
+}
+
+// NewMovementEntry registra uma movimentação de conta corrente: o crédito ao cliente
+// aumenta o passivo do banco contra a contrapartida da categoria, e o débito o reduz.
+func NewMovementEntry(movementID, customerAccountCode, movementType, category string, amount float64, date time.Time) (*JournalEntry, error) {
+	counterpart := CounterpartAccount(category)
+
+	var postings []Posting
+	switch movementType {
+	case SideCredit:
+		postings = []Posting{Debit(counterpart, amount), Credit(customerAccountCode, amount)}
+	case SideDebit:
+		postings = []Posting{Debit(customerAccountCode, amount), Credit(counterpart, amount)}
+	default:
+		return nil, fmt.Errorf("tipo de movimentação inválido: %s", movementType)
+	}
+
+	entry, err := NewJournalEntry(movementDescription(movementType, category), category, date, postings...)
+	if err != nil {
+		return nil, err
+	}
+	entry.MovementID = &movementID
+	return entry, nil
+}
+
+// NewBoletoSettlementEntry registra a liquidação de um boleto com a câmara de
+// compensação: incoming é o valor de um boleto emitido aqui e pago em outro banco, que
+// entra no caixa; sem incoming, é o repasse ao banco do boleto que um cliente pagou.
+func NewBoletoSettlementEntry(amount float64, incoming bool, date time.Time) (*JournalEntry, error) {
+	if incoming {
+		return NewJournalEntry("Boleto recebido pela câmara de compensação", CategoryBoleto, date,
+			Debit(AccountCash, amount), Credit(AccountBoletoClearing, amount))
+	}
+	return NewJournalEntry("Boleto liquidado na câmara de compensação", CategoryBoleto, date,
+		Debit(AccountBoletoClearing, amount), Credit(AccountCash, amount))
+}
+
+func movementDescription(movementType, category string) string {
+	credit := movementType == SideCredit
+	switch category {
+	case CategoryCash:
+		if credit {
+			return "Depósito em conta corrente"
+		}
+		return "Saque de conta corrente"
+	case CategoryTransfer:
+		if credit {
+			return "Transferência recebida"
+		}
+		return "Transferência enviada"
+	case CategoryFee:
+		if credit {
+			return "Estorno de tarifa"
+		}
+		return "Cobrança de tarifa"
+	case CategoryBoleto:
+		if credit {
+			return "Boleto recebido"
+		}
+		return "Pagamento de boleto"
+	default:
+		return "Movimentação anterior ao razão contábil"
+	}
+}
+
+// ToCents converte para centavos com arredondamento, para comparar valores sem erro de ponto flutuante.
+func ToCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// AccountBalance é o saldo de uma conta do plano no balancete.
+type AccountBalance struct {
+	Code    string  `json:"code"`
+	Name    string  `json:"name"`
+	Type    string  `json:"type"`
+	Debits  float64 `json:"debits"`
+	Credits float64 `json:"credits"`
+	Balance float64 `json:"balance"`
+}
+
+// MovementRecord é a movimentação de conta corrente a ser registrada no razão.
+type MovementRecord struct {
+	MovementID     string     `gorm:"column:idmovimento"`
+	AccountID      string     `gorm:"column:idcontacorrente"`
+	AccountNumber  int        `gorm:"column:numero"`
+	Type           string     `gorm:"column:tipomovimento"`
+	Category       string     `gorm:"column:categoria"`
+	Amount         float64    `gorm:"column:valor"`
+	Date           time.Time  `gorm:"column:datamovimento"`
+	AccountingDate *time.Time `gorm:"column:data_contabil"`
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrPeriodClosed é devolvido ao lançar em uma data contábil já encerrada.
+var ErrPeriodClosed = errors.New("período contábil encerrado: lançamentos retroativos não são permitidos")
+
+// AccountingPeriod é o fechamento de um dia útil. Depois dele nenhum lançamento pode
+// ter aquela data contábil, nem uma anterior.
+type AccountingPeriod struct {
+	Date         time.Time            `json:"date" gorm:"column:data;primaryKey"`
+	Entries      int64                `json:"entries" gorm:"column:lancamentos"`
+	TotalDebits  float64              `json:"totalDebits" gorm:"column:total_debitos"`
+	TotalCredits float64              `json:"totalCredits" gorm:"column:total_creditos"`
+	ClosedAt     time.Time            `json:"closedAt" gorm:"column:data_fechamento"`
+	Totals       []PeriodAccountTotal `json:"totals,omitempty" gorm:"-"`
+}
+
+func (AccountingPeriod) TableName() string {
+	return "periodo_contabil"
+}
+
+// PeriodAccountTotal guarda, para uma conta do plano, o movimento do dia contábil e os
+// totais acumulados no fechamento.
+type PeriodAccountTotal struct {
+	Date           time.Time `json:"-" gorm:"column:data;primaryKey"`
+	AccountCode    string    `json:"accountCode" gorm:"column:codigo_conta;primaryKey"`
+	Debits         float64   `json:"debits" gorm:"column:debitos"`
+	Credits        float64   `json:"credits" gorm:"column:creditos"`
+	ClosingDebits  float64   `json:"closingDebits" gorm:"column:debitos_acumulados"`
+	ClosingCredits float64   `json:"closingCredits" gorm:"column:creditos_acumulados"`
+	Balance        float64   `json:"balance" gorm:"column:saldo"`
+}
+
+func (PeriodAccountTotal) TableName() string {
+	return "total_periodo_contabil"
+}
+
+// NewAccountingPeriod monta o fechamento de date a partir das partidas do dia e das
+// acumuladas até ele. Contas sem saldo e sem movimento ficam de fora.
+func NewAccountingPeriod(date time.Time, accounts []LedgerAccount, day, cumulative []PostingTotals, entries int64, now time.Time) *AccountingPeriod {
+	period := &AccountingPeriod{
+		Date:     date,
+		Entries:  entries,
+		ClosedAt: now,
+	}
+
+	dayTotals := make(map[string]PostingTotals)
+	var debits, credits int64
+	for _, totals := range day {
+		dayTotals[totals.AccountCode] = totals
+		debits += ToCents(totals.Debits)
+		credits += ToCents(totals.Credits)
+	}
+	period.TotalDebits = float64(debits) / 100
+	period.TotalCredits = float64(credits) / 100
+
+	closing := make(map[string]PostingTotals)
+	for _, totals := range cumulative {
+		closing[totals.AccountCode] = totals
+	}
+
+	for _, account := range accounts {
+		closingTotals, hasBalance := closing[account.Code]
+		dayTotal, moved := dayTotals[account.Code]
+		if !hasBalance && !moved {
+			continue
+		}
+		period.Totals = append(period.Totals, PeriodAccountTotal{
+			Date:           date,
+			AccountCode:    account.Code,
+			Debits:         dayTotal.Debits,
+			Credits:        dayTotal.Credits,
+			ClosingDebits:  closingTotals.Debits,
+			ClosingCredits: closingTotals.Credits,
+			Balance:        SignedBalance(account.Type, closingTotals.Debits, closingTotals.Credits),
+		})
+	}
+
+	return period
+}
+
+// Balanced indica se os débitos do dia são iguais aos créditos, em centavos.
+func (p *AccountingPeriod) Balanced() bool {
+	return ToCents(p.TotalDebits) == ToCents(p.TotalCredits)
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"bankmore/internal/ledger/service"
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type LedgerHandler struct {
+	service service.LedgerService
+	logger  *logrus.Logger
+}
+
+func NewLedgerHandler(service service.LedgerService, logger *logrus.Logger) *LedgerHandler {
+	return &LedgerHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// @Summary Balancete do razão contábil
+// @Description Lista as contas do plano (caixa, compensação, depósitos de clientes, receita de tarifas e transitória) com débitos, créditos e saldo. Em um razão íntegro o total de débitos é igual ao de créditos
+// @Tags Ledger
+// @Produce json
+// @Success 200 {object} service.TrialBalanceResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/ledger/trial-balance [get]
+func (h *LedgerHandler) GetTrialBalance(c *gin.Context) {
+	response, err := h.service.GetTrialBalance()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Consulta um lançamento contábil
+// @Description Retorna o lançamento com suas partidas
+// @Tags Ledger
+// @Produce json
+// @Param entryId path string true "ID do lançamento"
+// @Success 200 {object} domain.JournalEntry
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/ledger/entries/{entryId} [get]
+func (h *LedgerHandler) GetEntry(c *gin.Context) {
+	entry, err := h.service.GetEntry(c.Param("entryId"))
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// @Summary Lista os lançamentos de uma conta contábil
+// @Description Retorna os 100 lançamentos mais recentes que movimentaram a conta do plano
+// @Tags Ledger
+// @Produce json
+// @Param code path string true "Código da conta contábil"
+// @Success 200 {array} domain.JournalEntry
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/ledger/accounts/{code}/entries [get]
+func (h *LedgerHandler) GetAccountEntries(c *gin.Context) {
+	entries, err := h.service.GetAccountEntries(c.Param("code"))
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// @Summary Saldo de uma conta contábil no fim de um dia
+// @Description Calcula o saldo no fim do dia a partir do fechamento diário mais próximo, somando apenas as partidas posteriores a ele
+// @Tags Ledger
+// @Produce json
+// @Param code path string true "Código da conta contábil"
+// @Param date query string true "Data (AAAA-MM-DD)"
+// @Success 200 {object} service.PointInTimeBalance
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/ledger/accounts/{code}/balance [get]
+func (h *LedgerHandler) GetAccountBalanceAt(c *gin.Context) {
+	balance, err := h.service.GetAccountBalanceAt(c.Param("code"), c.Query("date"))
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// @Summary Saldo da conta no fim de um dia
+// @Description Retorna o saldo contábil da conta logada no fim do dia informado
+// @Tags Account
+// @Produce json
+// @Param date query string true "Data (AAAA-MM-DD)"
+// @Success 200 {object} service.PointInTimeBalance
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/balance/daily [get]
+func (h *LedgerHandler) GetCustomerBalanceAt(c *gin.Context) {
+	accountID, exists := c.Get("accountId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorUserUnauthorized,
+			Message: "Token inválido",
+		})
+		return
+	}
+
+	balance, err := h.service.GetCustomerBalanceAt(accountID.(string), c.Query("date"))
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// @Summary Confere os saldos
+// @Description Recalcula o saldo materializado de cada conta contábil a partir das partidas e o razão de cada cliente a partir dos movimentos da conta corrente. As divergências são gravadas e retornadas
+// @Tags Ledger
+// @Produce json
+// @Success 200 {object} service.ConsistencyReport
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/ledger/consistency-check [post]
+func (h *LedgerHandler) CheckConsistency(c *gin.Context) {
+	report, err := h.service.CheckConsistency()
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary Lista as divergências de saldo
+// @Description Retorna as 100 divergências mais recentes encontradas pelo conferidor de saldos
+// @Tags Ledger
+// @Produce json
+// @Success 200 {array} domain.BalanceDrift
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/ledger/drifts [get]
+func (h *LedgerHandler) ListDrifts(c *gin.Context) {
+	drifts, err := h.service.ListDrifts()
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, drifts)
+}
+
+// @Summary Executa o fechamento do dia
+// @Description Encerra, em ordem, os dias úteis anteriores à data contábil corrente ainda não fechados, gravando os totais do dia e acumulados de cada conta. Depois do fechamento nenhum lançamento pode ter aquela data contábil
+// @Tags Ledger
+// @Produce json
+// @Success 200 {array} domain.AccountingPeriod
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/ledger/eod [post]
+func (h *LedgerHandler) RunEndOfDay(c *gin.Context) {
+	periods, err := h.service.RunEndOfDay()
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, periods)
+}
+
+// @Summary Lista os períodos contábeis encerrados
+// @Description Retorna os 60 fechamentos mais recentes, sem os totais por conta
+// @Tags Ledger
+// @Produce json
+// @Success 200 {array} domain.AccountingPeriod
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/ledger/periods [get]
+func (h *LedgerHandler) ListPeriods(c *gin.Context) {
+	periods, err := h.service.ListPeriods()
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, periods)
+}
+
+// @Summary Consulta um período contábil
+// @Description Retorna o fechamento da data contábil com os totais do dia e acumulados de cada conta
+// @Tags Ledger
+// @Produce json
+// @Param date path string true "Data contábil (AAAA-MM-DD)"
+// @Success 200 {object} domain.AccountingPeriod
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/ledger/periods/{date} [get]
+func (h *LedgerHandler) GetPeriod(c *gin.Context) {
+	period, err := h.service.GetPeriod(c.Param("date"))
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, period)
+}
+
+func respondLedgerError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrEntryNotFound) || errors.Is(err, service.ErrLedgerAccountNotFound) || errors.Is(err, service.ErrPeriodNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Type:    models.ErrorInvalidArgument,
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidDate) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Type:    models.ErrorInternalError,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,546 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bankmore/internal/ledger/domain"
+	"bankmore/internal/shared/calendar"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// immutableTables recebem gatilhos que recusam UPDATE e DELETE: o razão só aceita novos lançamentos.
+var immutableTables = []string{"lancamento_contabil", "partida_contabil"}
+
+type LedgerRepository interface {
+	EnsureChartOfAccounts() error
+	EnsureImmutable() error
+	Post(entry *domain.JournalEntry) error
+	GetAccount(code string) (*domain.LedgerAccount, error)
+	GetAccounts() ([]domain.LedgerAccount, error)
+	GetEntry(id string) (*domain.JournalEntry, error)
+	GetEntriesByAccount(code string, limit int) ([]domain.JournalEntry, error)
+	GetTrialBalance() ([]domain.AccountBalance, error)
+	GetUnpostedMovements(limit int) ([]domain.MovementRecord, error)
+	PostMovement(movement domain.MovementRecord) error
+	SeedBalances() error
+	GetCustomerLedgerAccount(accountID string) (*domain.LedgerAccount, error)
+	GetLatestSnapshotDate() (*time.Time, error)
+	GetFirstEntryDate() (*time.Time, error)
+	GetSnapshots(date time.Time) ([]domain.BalanceSnapshot, error)
+	GetSnapshotOnOrBefore(code string, date time.Time) (*domain.BalanceSnapshot, error)
+	CreateSnapshots(snapshots []domain.BalanceSnapshot) error
+	SumPostingsByAccount(from, to time.Time) ([]domain.PostingTotals, error)
+	SumAccountPostings(code string, from, to time.Time) (*domain.PostingTotals, error)
+	GetMaterializedBalances() ([]domain.AccountBalanceRecord, error)
+	GetPostedTotals() ([]domain.PostingTotals, error)
+	GetMovementTotals() ([]domain.PostingTotals, error)
+	CreateDrifts(drifts []domain.BalanceDrift) error
+	GetDrifts(limit int) ([]domain.BalanceDrift, error)
+	GetAccountingDate(at time.Time) (time.Time, error)
+	GetLastClosedPeriod() (*domain.AccountingPeriod, error)
+	GetFirstAccountingDate() (*time.Time, error)
+	ClosePeriod(date, now time.Time) (*domain.AccountingPeriod, error)
+	GetPeriods(limit int) ([]domain.AccountingPeriod, error)
+	GetPeriod(date time.Time) (*domain.AccountingPeriod, error)
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+func (r *ledgerRepository) EnsureChartOfAccounts() error {
+	accounts := domain.ChartOfAccounts()
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&accounts).Error
+}
+
+func (r *ledgerRepository) EnsureImmutable() error {
+	for _, table := range immutableTables {
+		for _, operation := range []string{"UPDATE", "DELETE"} {
+			statement := fmt.Sprintf(
+				"CREATE TRIGGER IF NOT EXISTS %s_imutavel_%s BEFORE %s ON %s BEGIN SELECT RAISE(ABORT, 'lançamentos contábeis são imutáveis'); END",
+				table, strings.ToLower(operation), operation, table,
+			)
+			if err := r.db.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *ledgerRepository) Post(entry *domain.JournalEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return PostEntry(tx, entry)
+	})
+}
+
+func (r *ledgerRepository) GetAccount(code string) (*domain.LedgerAccount, error) {
+	var account domain.LedgerAccount
+	err := r.db.Where("codigo = ?", code).First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *ledgerRepository) GetAccounts() ([]domain.LedgerAccount, error) {
+	var accounts []domain.LedgerAccount
+	err := r.db.Order("codigo ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *ledgerRepository) GetEntry(id string) (*domain.JournalEntry, error) {
+	var entry domain.JournalEntry
+	err := r.db.Preload("Postings").Where("idlancamento = ?", id).First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *ledgerRepository) GetEntriesByAccount(code string, limit int) ([]domain.JournalEntry, error) {
+	var entries []domain.JournalEntry
+	err := r.db.Preload("Postings").
+		Where("idlancamento IN (?)", r.db.Model(&domain.Posting{}).Select("idlancamento").Where("codigo_conta = ?", code)).
+		Order("data DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
+
+// GetTrialBalance lê os saldos materializados, sem percorrer as partidas.
+func (r *ledgerRepository) GetTrialBalance() ([]domain.AccountBalance, error) {
+	var balances []domain.AccountBalance
+	err := r.db.Table("conta_contabil c").
+		Select("c.codigo AS code, c.nome AS name, c.natureza AS type, COALESCE(s.debitos, 0) AS debits, COALESCE(s.creditos, 0) AS credits").
+		Joins("LEFT JOIN saldo_conta_contabil s ON s.codigo_conta = c.codigo").
+		Order("c.codigo ASC").
+		Scan(&balances).Error
+	return balances, err
+}
+
+// GetUnpostedMovements lista as movimentações de conta corrente ainda sem lançamento no razão.
+func (r *ledgerRepository) GetUnpostedMovements(limit int) ([]domain.MovementRecord, error) {
+	var movements []domain.MovementRecord
+	err := r.db.Table("movimento m").
+		Select("m.idmovimento, m.idcontacorrente, c.numero, m.tipomovimento, m.categoria, m.valor, m.datamovimento, m.data_contabil").
+		Joins("JOIN contacorrente c ON c.idcontacorrente = m.idcontacorrente").
+		Joins("LEFT JOIN lancamento_contabil l ON l.idmovimento = m.idmovimento").
+		Where("l.idlancamento IS NULL").
+		Order("m.datamovimento ASC").
+		Limit(limit).
+		Scan(&movements).Error
+	return movements, err
+}
+
+func (r *ledgerRepository) PostMovement(movement domain.MovementRecord) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return PostMovement(tx, movement)
+	})
+}
+
+// SeedBalances materializa o saldo das contas que têm partidas mas ainda não têm
+// registro em saldo_conta_contabil (razões gravados antes do saldo materializado).
+func (r *ledgerRepository) SeedBalances() error {
+	return r.db.Exec(`INSERT INTO saldo_conta_contabil (codigo_conta, debitos, creditos, data_atualizacao)
+		SELECT p.codigo_conta,
+			COALESCE(SUM(CASE WHEN p.tipo = 'D' THEN p.valor ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN p.tipo = 'C' THEN p.valor ELSE 0 END), 0),
+			?
+		FROM partida_contabil p
+		WHERE p.codigo_conta NOT IN (SELECT codigo_conta FROM saldo_conta_contabil)
+		GROUP BY p.codigo_conta`, time.Now()).Error
+}
+
+func (r *ledgerRepository) GetCustomerLedgerAccount(accountID string) (*domain.LedgerAccount, error) {
+	var account domain.LedgerAccount
+	err := r.db.Where("idcontacorrente = ?", accountID).First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *ledgerRepository) GetLatestSnapshotDate() (*time.Time, error) {
+	var snapshots []domain.BalanceSnapshot
+	if err := r.db.Order("data DESC").Limit(1).Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+	return &snapshots[0].Date, nil
+}
+
+func (r *ledgerRepository) GetFirstEntryDate() (*time.Time, error) {
+	var entries []domain.JournalEntry
+	if err := r.db.Order("data ASC").Limit(1).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return &entries[0].Date, nil
+}
+
+func (r *ledgerRepository) GetSnapshots(date time.Time) ([]domain.BalanceSnapshot, error) {
+	var snapshots []domain.BalanceSnapshot
+	err := r.db.Where("data = ?", date).Find(&snapshots).Error
+	return snapshots, err
+}
+
+func (r *ledgerRepository) GetSnapshotOnOrBefore(code string, date time.Time) (*domain.BalanceSnapshot, error) {
+	var snapshot domain.BalanceSnapshot
+	err := r.db.Where("codigo_conta = ? AND data <= ?", code, date).
+		Order("data DESC").
+		First(&snapshot).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+func (r *ledgerRepository) CreateSnapshots(snapshots []domain.BalanceSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&snapshots, 200).Error
+}
+
+// SumPostingsByAccount soma as partidas dos lançamentos com data em [from, to), por conta.
+func (r *ledgerRepository) SumPostingsByAccount(from, to time.Time) ([]domain.PostingTotals, error) {
+	var totals []domain.PostingTotals
+	err := r.postingTotals().
+		Joins("JOIN lancamento_contabil l ON l.idlancamento = p.idlancamento").
+		Where("l.data >= ? AND l.data < ?", from, to).
+		Group("p.codigo_conta").
+		Scan(&totals).Error
+	return totals, err
+}
+
+func (r *ledgerRepository) SumAccountPostings(code string, from, to time.Time) (*domain.PostingTotals, error) {
+	totals := domain.PostingTotals{AccountCode: code}
+	err := r.db.Table("partida_contabil p").
+		Select(`COALESCE(SUM(CASE WHEN p.tipo = 'D' THEN p.valor ELSE 0 END), 0) AS debitos,
+			COALESCE(SUM(CASE WHEN p.tipo = 'C' THEN p.valor ELSE 0 END), 0) AS creditos`).
+		Joins("JOIN lancamento_contabil l ON l.idlancamento = p.idlancamento").
+		Where("p.codigo_conta = ? AND l.data >= ? AND l.data < ?", code, from, to).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+func (r *ledgerRepository) GetMaterializedBalances() ([]domain.AccountBalanceRecord, error) {
+	var balances []domain.AccountBalanceRecord
+	err := r.db.Order("codigo_conta ASC").Find(&balances).Error
+	return balances, err
+}
+
+// GetPostedTotals recalcula os totais de cada conta a partir de todas as partidas.
+func (r *ledgerRepository) GetPostedTotals() ([]domain.PostingTotals, error) {
+	var totals []domain.PostingTotals
+	err := r.postingTotals().Group("p.codigo_conta").Scan(&totals).Error
+	return totals, err
+}
+
+// GetMovementTotals soma os movimentos de cada conta corrente, pelo código da conta
+// contábil do cliente: créditos e débitos como na conta de passivo.
+func (r *ledgerRepository) GetMovementTotals() ([]domain.PostingTotals, error) {
+	var totals []domain.PostingTotals
+	err := r.db.Table("conta_contabil c").
+		Select(`c.codigo AS codigo_conta,
+			COALESCE(SUM(CASE WHEN m.tipomovimento = 'D' THEN m.valor ELSE 0 END), 0) AS debitos,
+			COALESCE(SUM(CASE WHEN m.tipomovimento = 'C' THEN m.valor ELSE 0 END), 0) AS creditos`).
+		Joins("JOIN movimento m ON m.idcontacorrente = c.idcontacorrente").
+		Group("c.codigo").
+		Scan(&totals).Error
+	return totals, err
+}
+
+func (r *ledgerRepository) CreateDrifts(drifts []domain.BalanceDrift) error {
+	if len(drifts) == 0 {
+		return nil
+	}
+	return r.db.Create(&drifts).Error
+}
+
+func (r *ledgerRepository) GetDrifts(limit int) ([]domain.BalanceDrift, error) {
+	var drifts []domain.BalanceDrift
+	err := r.db.Order("data_deteccao DESC").Limit(limit).Find(&drifts).Error
+	return drifts, err
+}
+
+func (r *ledgerRepository) GetAccountingDate(at time.Time) (time.Time, error) {
+	return AccountingDate(r.db, at)
+}
+
+func (r *ledgerRepository) GetLastClosedPeriod() (*domain.AccountingPeriod, error) {
+	return lastClosedPeriod(r.db)
+}
+
+// GetFirstAccountingDate devolve a menor data contábil do razão, ou nil se nenhum
+// lançamento tiver data contábil.
+func (r *ledgerRepository) GetFirstAccountingDate() (*time.Time, error) {
+	var entries []domain.JournalEntry
+	err := r.db.Where("data_contabil IS NOT NULL").Order("data_contabil ASC").Limit(1).Find(&entries).Error
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return entries[0].AccountingDate, nil
+}
+
+// ClosePeriod encerra a data contábil date: soma as partidas do dia e as acumuladas até
+// ele por conta e grava o fechamento. Lançamentos anteriores à data contábil entram só
+// nos totais acumulados. A transação impede que um lançamento com essa data entre
+// depois da soma.
+func (r *ledgerRepository) ClosePeriod(date, now time.Time) (*domain.AccountingPeriod, error) {
+	var period *domain.AccountingPeriod
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		closed, err := isClosed(tx, date)
+		if err != nil {
+			return err
+		}
+		if closed {
+			return domain.ErrPeriodClosed
+		}
+
+		var accounts []domain.LedgerAccount
+		if err := tx.Order("codigo ASC").Find(&accounts).Error; err != nil {
+			return err
+		}
+
+		var day, cumulative []domain.PostingTotals
+		err = postingTotals(tx).
+			Joins("JOIN lancamento_contabil l ON l.idlancamento = p.idlancamento").
+			Where("l.data_contabil = ?", date).
+			Group("p.codigo_conta").
+			Scan(&day).Error
+		if err != nil {
+			return err
+		}
+		err = postingTotals(tx).
+			Joins("JOIN lancamento_contabil l ON l.idlancamento = p.idlancamento").
+			Where("(l.data_contabil <= ? OR l.data_contabil IS NULL)", date).
+			Group("p.codigo_conta").
+			Scan(&cumulative).Error
+		if err != nil {
+			return err
+		}
+
+		var entries int64
+		if err := tx.Model(&domain.JournalEntry{}).Where("data_contabil = ?", date).Count(&entries).Error; err != nil {
+			return err
+		}
+
+		period = domain.NewAccountingPeriod(date, accounts, day, cumulative, entries, now)
+		if err := tx.Create(period).Error; err != nil {
+			return err
+		}
+		if len(period.Totals) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&period.Totals, 200).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return period, nil
+}
+
+func (r *ledgerRepository) GetPeriods(limit int) ([]domain.AccountingPeriod, error) {
+	var periods []domain.AccountingPeriod
+	err := r.db.Order("data DESC").Limit(limit).Find(&periods).Error
+	return periods, err
+}
+
+func (r *ledgerRepository) GetPeriod(date time.Time) (*domain.AccountingPeriod, error) {
+	var period domain.AccountingPeriod
+	if err := r.db.Where("data = ?", date).First(&period).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("data = ?", date).Order("codigo_conta ASC").Find(&period.Totals).Error; err != nil {
+		return nil, err
+	}
+	return &period, nil
+}
+
+func (r *ledgerRepository) postingTotals() *gorm.DB {
+	return postingTotals(r.db)
+}
+
+func postingTotals(db *gorm.DB) *gorm.DB {
+	return db.Table("partida_contabil p").
+		Select(`p.codigo_conta,
+			COALESCE(SUM(CASE WHEN p.tipo = 'D' THEN p.valor ELSE 0 END), 0) AS debitos,
+			COALESCE(SUM(CASE WHEN p.tipo = 'C' THEN p.valor ELSE 0 END), 0) AS creditos`)
+}
+
+// PostEntry grava o lançamento e suas partidas na transação tx. Todas as contas
+// das partidas precisam existir no plano de contas. Sem data contábil, o lançamento
+// recebe a da sua data; uma data contábil já encerrada é recusada.
+func PostEntry(tx *gorm.DB, entry *domain.JournalEntry) error {
+	if entry.AccountingDate == nil {
+		accountingDate, err := AccountingDate(tx, entry.Date)
+		if err != nil {
+			return err
+		}
+		entry.AccountingDate = &accountingDate
+	} else {
+		closed, err := isClosed(tx, *entry.AccountingDate)
+		if err != nil {
+			return err
+		}
+		if closed {
+			return domain.ErrPeriodClosed
+		}
+	}
+
+	codes := make(map[string]bool)
+	var codeList []string
+	for _, posting := range entry.Postings {
+		if !codes[posting.AccountCode] {
+			codes[posting.AccountCode] = true
+			codeList = append(codeList, posting.AccountCode)
+		}
+	}
+
+	var found int64
+	if err := tx.Model(&domain.LedgerAccount{}).Where("codigo IN ?", codeList).Count(&found).Error; err != nil {
+		return err
+	}
+	if int(found) != len(codeList) {
+		return fmt.Errorf("lançamento referencia conta contábil inexistente")
+	}
+
+	if err := tx.Create(entry).Error; err != nil {
+		return err
+	}
+	return applyToBalances(tx, entry)
+}
+
+// applyToBalances soma as partidas do lançamento ao saldo materializado de cada conta.
+func applyToBalances(tx *gorm.DB, entry *domain.JournalEntry) error {
+	totals := make(map[string]*domain.AccountBalanceRecord)
+	var order []string
+	for _, posting := range entry.Postings {
+		balance, ok := totals[posting.AccountCode]
+		if !ok {
+			balance = &domain.AccountBalanceRecord{AccountCode: posting.AccountCode, UpdatedAt: time.Now()}
+			totals[posting.AccountCode] = balance
+			order = append(order, posting.AccountCode)
+		}
+		if posting.Side == domain.SideDebit {
+			balance.Debits += posting.Amount
+		} else {
+			balance.Credits += posting.Amount
+		}
+	}
+
+	for _, code := range order {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "codigo_conta"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"debitos":          gorm.Expr("saldo_conta_contabil.debitos + excluded.debitos"),
+				"creditos":         gorm.Expr("saldo_conta_contabil.creditos + excluded.creditos"),
+				"data_atualizacao": gorm.Expr("excluded.data_atualizacao"),
+			}),
+		}).Create(totals[code]).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PostMovement registra a movimentação de conta corrente na transação tx, criando a
+// conta contábil do cliente no primeiro lançamento.
+func PostMovement(tx *gorm.DB, movement domain.MovementRecord) error {
+	customerAccount := domain.NewCustomerLedgerAccount(movement.AccountID, movement.AccountNumber)
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(customerAccount).Error; err != nil {
+		return err
+	}
+
+	entry, err := domain.NewMovementEntry(movement.MovementID, customerAccount.Code, movement.Type, movement.Category, movement.Amount, movement.Date)
+	if err != nil {
+		return err
+	}
+	entry.AccountingDate = movement.AccountingDate
+	return PostEntry(tx, entry)
+}
+
+// CustomerBalance é o saldo da conta corrente no razão: créditos menos débitos na
+// conta de passivo do cliente, lidos do saldo materializado.
+func CustomerBalance(db *gorm.DB, accountID string) (float64, error) {
+	var balance domain.AccountBalanceRecord
+	err := db.Table("saldo_conta_contabil s").
+		Select("s.codigo_conta, s.debitos, s.creditos").
+		Joins("JOIN conta_contabil c ON c.codigo = s.codigo_conta").
+		Where("c.idcontacorrente = ?", accountID).
+		Scan(&balance).Error
+	if err != nil {
+		return 0, err
+	}
+	return domain.SignedBalance(domain.AccountTypeLiability, balance.Debits, balance.Credits), nil
+}
+
+// CustomerBalanceAt é o saldo da conta corrente no razão antes de end, somando as
+// partidas da conta de passivo do cliente. Conta sem lançamentos tem saldo zero.
+func CustomerBalanceAt(db *gorm.DB, accountID string, end time.Time) (float64, error) {
+	var totals domain.PostingTotals
+	err := db.Table("partida_contabil p").
+		Select(`COALESCE(SUM(CASE WHEN p.tipo = 'D' THEN p.valor ELSE 0 END), 0) AS debitos,
+			COALESCE(SUM(CASE WHEN p.tipo = 'C' THEN p.valor ELSE 0 END), 0) AS creditos`).
+		Joins("JOIN lancamento_contabil l ON l.idlancamento = p.idlancamento").
+		Joins("JOIN conta_contabil c ON c.codigo = p.codigo_conta").
+		Where("c.idcontacorrente = ? AND l.data < ?", accountID, end).
+		Scan(&totals).Error
+	if err != nil {
+		return 0, err
+	}
+	return domain.SignedBalance(domain.AccountTypeLiability, totals.Debits, totals.Credits), nil
+}
+
+// AccountingDate devolve a data contábil de um lançamento feito em at: o dia útil pelo
+// calendário e pelo horário de corte, ou o primeiro dia útil depois do último
+// fechamento, se aquele dia já estiver encerrado. Usada por todos os serviços que gravam
+// data contábil, para que movimentos, transferências e tarifas sigam a mesma regra.
+func AccountingDate(db *gorm.DB, at time.Time) (time.Time, error) {
+	date := calendar.AccountingDate(at, calendar.GetCutoff())
+
+	last, err := lastClosedPeriod(db)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if last != nil && !date.After(last.Date) {
+		date = calendar.NextBusinessDay(last.Date)
+	}
+	return date, nil
+}
+
+func lastClosedPeriod(db *gorm.DB) (*domain.AccountingPeriod, error) {
+	var periods []domain.AccountingPeriod
+	if err := db.Order("data DESC").Limit(1).Find(&periods).Error; err != nil {
+		return nil, err
+	}
+	if len(periods) == 0 {
+		return nil, nil
+	}
+	return &periods[0], nil
+}
+
+// isClosed indica se date já foi encerrada: há fechamento nela ou depois dela.
+func isClosed(db *gorm.DB, date time.Time) (bool, error) {
+	var closed int64
+	err := db.Model(&domain.AccountingPeriod{}).Where("data >= ?", date).Count(&closed).Error
+	return closed > 0, err
+}
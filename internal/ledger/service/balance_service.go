@@ -0,0 +1,370 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"bankmore/internal/ledger/domain"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const driftListLimit = 100
+
+var ErrInvalidDate = errors.New("data inválida: use o formato AAAA-MM-DD, até hoje")
+
+// PointInTimeBalance é o saldo de uma conta do plano no fim do dia informado.
+type PointInTimeBalance struct {
+	AccountCode string  `json:"accountCode"`
+	Date        string  `json:"date"`
+	Debits      float64 `json:"debits"`
+	Credits     float64 `json:"credits"`
+	Balance     float64 `json:"balance"`
+}
+
+// ConsistencyReport é o resultado de uma conferência dos saldos.
+type ConsistencyReport struct {
+	CheckedAt       time.Time             `json:"checkedAt"`
+	AccountsChecked int                   `json:"accountsChecked"`
+	Consistent      bool                  `json:"consistent"`
+	Drifts          []domain.BalanceDrift `json:"drifts"`
+}
+
+// CreateSnapshots grava o fechamento de cada dia encerrado que ainda não tem um, a partir
+// do fechamento anterior e das partidas do dia. Retorna quantos dias foram fechados.
+func (s *ledgerService) CreateSnapshots() (int, error) {
+	today := domain.StartOfDay(time.Now())
+
+	latest, err := s.repo.GetLatestSnapshotDate()
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting latest balance snapshot")
+		return 0, fmt.Errorf("erro interno do servidor")
+	}
+
+	var day time.Time
+	if latest != nil {
+		day = domain.StartOfDay(*latest).AddDate(0, 0, 1)
+	} else {
+		first, err := s.repo.GetFirstEntryDate()
+		if err != nil {
+			s.logger.WithError(err).Error("Error getting first journal entry date")
+			return 0, fmt.Errorf("erro interno do servidor")
+		}
+		if first == nil {
+			return 0, nil
+		}
+		day = domain.StartOfDay(*first)
+	}
+
+	created := 0
+	for day.Before(today) {
+		if err := s.createSnapshot(day); err != nil {
+			s.logger.WithError(err).WithField("date", day.Format(domain.DateLayout)).Error("Error creating balance snapshot")
+			return created, fmt.Errorf("erro interno do servidor")
+		}
+		created++
+		day = day.AddDate(0, 0, 1)
+	}
+
+	if created > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"days":    created,
+			"lastDay": day.AddDate(0, 0, -1).Format(domain.DateLayout),
+		}).Info("Balance snapshots created")
+	}
+	return created, nil
+}
+
+// createSnapshot soma as partidas do dia ao fechamento anterior. Contas sem movimento no
+// dia repetem o fechamento anterior, para que cada dia tenha o saldo de todas as contas.
+func (s *ledgerService) createSnapshot(day time.Time) error {
+	previous, err := s.repo.GetSnapshots(day.AddDate(0, 0, -1))
+	if err != nil {
+		return err
+	}
+	totals, err := s.repo.SumPostingsByAccount(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	snapshots := make(map[string]*domain.BalanceSnapshot)
+	var order []string
+	get := func(code string) *domain.BalanceSnapshot {
+		if snapshot, ok := snapshots[code]; ok {
+			return snapshot
+		}
+		snapshot := &domain.BalanceSnapshot{AccountCode: code, Date: day, CreatedAt: now}
+		snapshots[code] = snapshot
+		order = append(order, code)
+		return snapshot
+	}
+
+	for _, snapshot := range previous {
+		current := get(snapshot.AccountCode)
+		current.Debits = snapshot.Debits
+		current.Credits = snapshot.Credits
+	}
+	for _, total := range totals {
+		current := get(total.AccountCode)
+		current.Debits += total.Debits
+		current.Credits += total.Credits
+	}
+
+	if len(order) == 0 {
+		return nil
+	}
+
+	result := make([]domain.BalanceSnapshot, 0, len(order))
+	for _, code := range order {
+		result = append(result, *snapshots[code])
+	}
+	return s.repo.CreateSnapshots(result)
+}
+
+func (s *ledgerService) GetAccountBalanceAt(code, date string) (*PointInTimeBalance, error) {
+	account, err := s.repo.GetAccount(code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLedgerAccountNotFound
+		}
+		s.logger.WithError(err).Error("Error getting ledger account")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return s.balanceAt(account, date)
+}
+
+func (s *ledgerService) GetCustomerBalanceAt(accountID, date string) (*PointInTimeBalance, error) {
+	account, err := s.repo.GetCustomerLedgerAccount(accountID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Conta sem nenhum lançamento: saldo zero em qualquer data.
+		day, err := parseDate(date)
+		if err != nil {
+			return nil, err
+		}
+		return &PointInTimeBalance{Date: day.Format(domain.DateLayout)}, nil
+	}
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting customer ledger account")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return s.balanceAt(account, date)
+}
+
+// balanceAt parte do último fechamento até a data e soma as partidas posteriores a ele
+// até o fim do dia consultado.
+func (s *ledgerService) balanceAt(account *domain.LedgerAccount, date string) (*PointInTimeBalance, error) {
+	day, err := parseDate(date)
+	if err != nil {
+		return nil, err
+	}
+	end := day.AddDate(0, 0, 1)
+
+	result := &PointInTimeBalance{AccountCode: account.Code, Date: day.Format(domain.DateLayout)}
+	var from time.Time
+
+	snapshot, err := s.repo.GetSnapshotOnOrBefore(account.Code, day)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.WithError(err).Error("Error getting balance snapshot")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if snapshot != nil {
+		result.Debits = snapshot.Debits
+		result.Credits = snapshot.Credits
+		from = domain.StartOfDay(snapshot.Date).AddDate(0, 0, 1)
+	}
+
+	if from.Before(end) {
+		totals, err := s.repo.SumAccountPostings(account.Code, from, end)
+		if err != nil {
+			s.logger.WithError(err).Error("Error summing postings")
+			return nil, fmt.Errorf("erro interno do servidor")
+		}
+		result.Debits += totals.Debits
+		result.Credits += totals.Credits
+	}
+
+	result.Debits = float64(domain.ToCents(result.Debits)) / 100
+	result.Credits = float64(domain.ToCents(result.Credits)) / 100
+	result.Balance = domain.SignedBalance(account.Type, result.Debits, result.Credits)
+	return result, nil
+}
+
+func parseDate(date string) (time.Time, error) {
+	day, err := time.ParseInLocation(domain.DateLayout, date, time.Local)
+	if err != nil || day.After(time.Now()) {
+		return time.Time{}, ErrInvalidDate
+	}
+	return day, nil
+}
+
+// CheckConsistency recalcula os saldos e grava as divergências: o saldo materializado
+// de cada conta contra a soma das partidas, e o razão de cada cliente contra a soma
+// dos movimentos da conta corrente.
+func (s *ledgerService) CheckConsistency() (*ConsistencyReport, error) {
+	materialized, err := s.repo.GetMaterializedBalances()
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting materialized balances")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	posted, err := s.repo.GetPostedTotals()
+	if err != nil {
+		s.logger.WithError(err).Error("Error recomputing ledger totals")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	movements, err := s.repo.GetMovementTotals()
+	if err != nil {
+		s.logger.WithError(err).Error("Error recomputing movement totals")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	accounts, err := s.repo.GetAccounts()
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing ledger accounts")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	accountTypes := make(map[string]string)
+	for _, account := range accounts {
+		accountTypes[account.Code] = account.Type
+	}
+
+	now := time.Now()
+	report := &ConsistencyReport{CheckedAt: now, Drifts: []domain.BalanceDrift{}}
+
+	materializedByCode := make(map[string]domain.PostingTotals)
+	for _, balance := range materialized {
+		materializedByCode[balance.AccountCode] = domain.PostingTotals{AccountCode: balance.AccountCode, Debits: balance.Debits, Credits: balance.Credits}
+	}
+	postedByCode := make(map[string]domain.PostingTotals)
+	for _, total := range posted {
+		postedByCode[total.AccountCode] = total
+	}
+
+	codes := make(map[string]bool)
+	for code := range materializedByCode {
+		codes[code] = true
+	}
+	for code := range postedByCode {
+		codes[code] = true
+	}
+	report.AccountsChecked = len(codes)
+
+	for code := range codes {
+		expected, actual := postedByCode[code], materializedByCode[code]
+		if !sameTotals(expected, actual) {
+			accountType := accountTypes[code]
+			report.Drifts = append(report.Drifts, *domain.NewBalanceDrift(code, domain.DriftCheckMaterialized,
+				domain.SignedBalance(accountType, expected.Debits, expected.Credits),
+				domain.SignedBalance(accountType, actual.Debits, actual.Credits), now))
+		}
+	}
+
+	movementsByCode := make(map[string]domain.PostingTotals)
+	for _, total := range movements {
+		movementsByCode[total.AccountCode] = total
+	}
+	for code := range codes {
+		if !domain.IsCustomerAccountCode(code) {
+			continue
+		}
+		if _, ok := movementsByCode[code]; !ok {
+			movementsByCode[code] = domain.PostingTotals{AccountCode: code}
+		}
+	}
+	for code, expected := range movementsByCode {
+		ledger := postedByCode[code]
+		expectedBalance := domain.SignedBalance(domain.AccountTypeLiability, expected.Debits, expected.Credits)
+		ledgerBalance := domain.SignedBalance(domain.AccountTypeLiability, ledger.Debits, ledger.Credits)
+		if domain.ToCents(expectedBalance) != domain.ToCents(ledgerBalance) {
+			report.Drifts = append(report.Drifts, *domain.NewBalanceDrift(code, domain.DriftCheckMovements, expectedBalance, ledgerBalance, now))
+		}
+	}
+
+	report.Consistent = len(report.Drifts) == 0
+	if err := s.repo.CreateDrifts(report.Drifts); err != nil {
+		s.logger.WithError(err).Error("Error saving balance drifts")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	for _, drift := range report.Drifts {
+		s.logger.WithFields(logrus.Fields{
+			"accountCode": drift.AccountCode,
+			"check":       drift.Check,
+			"expected":    drift.Expected,
+			"actual":      drift.Actual,
+		}).Warn("Balance drift detected")
+	}
+	s.logger.WithFields(logrus.Fields{
+		"accounts": report.AccountsChecked,
+		"drifts":   len(report.Drifts),
+	}).Info("Balance consistency check finished")
+
+	return report, nil
+}
+
+func sameTotals(a, b domain.PostingTotals) bool {
+	return domain.ToCents(a.Debits) == domain.ToCents(b.Debits) && domain.ToCents(a.Credits) == domain.ToCents(b.Credits)
+}
+
+func (s *ledgerService) ListDrifts() ([]domain.BalanceDrift, error) {
+	drifts, err := s.repo.GetDrifts(driftListLimit)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing balance drifts")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return drifts, nil
+}
+
+// StartSnapshotWorker grava periodicamente os fechamentos diários até o canal stop ser fechado.
+func StartSnapshotWorker(service LedgerService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				service.CreateSnapshots()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// StartConsistencyChecker confere periodicamente os saldos até o canal stop ser fechado.
+func StartConsistencyChecker(service LedgerService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				service.CheckConsistency()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// GetSnapshotInterval lê LEDGER_SNAPSHOT_INTERVAL_MINUTES (padrão de 60 minutos).
+func GetSnapshotInterval() time.Duration {
+	return minutesFromEnv("LEDGER_SNAPSHOT_INTERVAL_MINUTES", 60)
+}
+
+// GetConsistencyInterval lê LEDGER_CONSISTENCY_INTERVAL_MINUTES (padrão de 6 horas).
+func GetConsistencyInterval() time.Duration {
+	return minutesFromEnv("LEDGER_CONSISTENCY_INTERVAL_MINUTES", 360)
+}
+
+func minutesFromEnv(name string, defaultMinutes int) time.Duration {
+	if value := os.Getenv(name); value != "" {
+		if minutes, err := strconv.Atoi(value); err == nil && minutes > 0 {
+			return time.Duration(minutes) * time.Minute
+		}
+	}
+	return time.Duration(defaultMinutes) * time.Minute
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"bankmore/internal/ledger/domain"
+	"bankmore/internal/ledger/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	backfillBatchSize = 500
+	entryListLimit    = 100
+)
+
+var (
+	ErrLedgerAccountNotFound = errors.New("conta contábil não encontrada")
+	ErrEntryNotFound         = errors.New("lançamento não encontrado")
+)
+
+type LedgerService interface {
+	Initialize() error
+	BackfillMovements() (int, error)
+	GetTrialBalance() (*TrialBalanceResponse, error)
+	GetEntry(id string) (*domain.JournalEntry, error)
+	GetAccountEntries(code string) ([]domain.JournalEntry, error)
+	CreateSnapshots() (int, error)
+	GetAccountBalanceAt(code, date string) (*PointInTimeBalance, error)
+	GetCustomerBalanceAt(accountID, date string) (*PointInTimeBalance, error)
+	CheckConsistency() (*ConsistencyReport, error)
+	ListDrifts() ([]domain.BalanceDrift, error)
+	RunEndOfDay() ([]domain.AccountingPeriod, error)
+	ListPeriods() ([]domain.AccountingPeriod, error)
+	GetPeriod(date string) (*domain.AccountingPeriod, error)
+}
+
+type ledgerService struct {
+	repo   repository.LedgerRepository
+	logger *logrus.Logger
+}
+
+func NewLedgerService(repo repository.LedgerRepository, logger *logrus.Logger) LedgerService {
+	return &ledgerService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// TrialBalanceResponse é o balancete: em um razão íntegro o total de débitos é igual ao de créditos.
+type TrialBalanceResponse struct {
+	Accounts     []domain.AccountBalance `json:"accounts"`
+	TotalDebits  float64                 `json:"totalDebits"`
+	TotalCredits float64                 `json:"totalCredits"`
+	Balanced     bool                    `json:"balanced"`
+}
+
+// Initialize cria o plano de contas do banco, protege o razão contra alterações,
+// materializa os saldos que faltam e registra as movimentações anteriores ao razão.
+func (s *ledgerService) Initialize() error {
+	if err := s.repo.EnsureChartOfAccounts(); err != nil {
+		return err
+	}
+	if err := s.repo.EnsureImmutable(); err != nil {
+		return err
+	}
+	if err := s.repo.SeedBalances(); err != nil {
+		return err
+	}
+	_, err := s.BackfillMovements()
+	return err
+}
+
+// BackfillMovements lança as movimentações que ainda não têm lançamento, com
+// contrapartida pela categoria; as sem categoria vão para a conta transitória.
+func (s *ledgerService) BackfillMovements() (int, error) {
+	posted := 0
+	for {
+		movements, err := s.repo.GetUnpostedMovements(backfillBatchSize)
+		if err != nil {
+			return posted, err
+		}
+		if len(movements) == 0 {
+			break
+		}
+
+		for _, movement := range movements {
+			if err := s.repo.PostMovement(movement); err != nil {
+				return posted, fmt.Errorf("movement %s: %w", movement.MovementID, err)
+			}
+			posted++
+		}
+	}
+
+	if posted > 0 {
+		s.logger.WithField("movements", posted).Info("Legacy movements posted to ledger")
+	}
+	return posted, nil
+}
+
+func (s *ledgerService) GetTrialBalance() (*TrialBalanceResponse, error) {
+	balances, err := s.repo.GetTrialBalance()
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting trial balance")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	response := &TrialBalanceResponse{Accounts: balances}
+	var debits, credits int64
+	for i := range response.Accounts {
+		balance := &response.Accounts[i]
+		balance.Balance = domain.SignedBalance(balance.Type, balance.Debits, balance.Credits)
+		debits += domain.ToCents(balance.Debits)
+		credits += domain.ToCents(balance.Credits)
+	}
+
+	response.TotalDebits = float64(debits) / 100
+	response.TotalCredits = float64(credits) / 100
+	response.Balanced = debits == credits
+	return response, nil
+}
+
+func (s *ledgerService) GetEntry(id string) (*domain.JournalEntry, error) {
+	entry, err := s.repo.GetEntry(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrEntryNotFound
+	}
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting journal entry")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return entry, nil
+}
+
+func (s *ledgerService) GetAccountEntries(code string) ([]domain.JournalEntry, error) {
+	if _, err := s.repo.GetAccount(code); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLedgerAccountNotFound
+		}
+		s.logger.WithError(err).Error("Error getting ledger account")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	entries, err := s.repo.GetEntriesByAccount(code, entryListLimit)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing journal entries")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return entries, nil
+}
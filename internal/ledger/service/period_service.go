@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"bankmore/internal/ledger/domain"
+	"bankmore/internal/shared/calendar"
+
+	"gorm.io/gorm"
+)
+
+const periodListLimit = 60
+
+var ErrPeriodNotFound = errors.New("período contábil não encontrado")
+
+// RunEndOfDay encerra, em ordem, os dias úteis anteriores à data contábil corrente que
+// ainda não foram fechados. Sem fechamento anterior, começa pela primeira data contábil
+// do razão. Retorna os períodos encerrados nesta execução.
+func (s *ledgerService) RunEndOfDay() ([]domain.AccountingPeriod, error) {
+	now := time.Now()
+	current, err := s.repo.GetAccountingDate(now)
+	if err != nil {
+		s.logger.WithError(err).Error("Error resolving accounting date")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	var day time.Time
+	last, err := s.repo.GetLastClosedPeriod()
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting last closed period")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if last != nil {
+		day = calendar.NextBusinessDay(last.Date)
+	} else {
+		first, err := s.repo.GetFirstAccountingDate()
+		if err != nil {
+			s.logger.WithError(err).Error("Error getting first accounting date")
+			return nil, fmt.Errorf("erro interno do servidor")
+		}
+		if first == nil {
+			return []domain.AccountingPeriod{}, nil
+		}
+		day = calendar.StartOfDay(*first)
+	}
+
+	closed := []domain.AccountingPeriod{}
+	for day.Before(current) {
+		period, err := s.repo.ClosePeriod(day, now)
+		if errors.Is(err, domain.ErrPeriodClosed) {
+			// Outra instância encerrou o dia ao mesmo tempo.
+			break
+		}
+		if err != nil {
+			s.logger.WithError(err).WithField("date", day.Format(domain.DateLayout)).Error("Error closing accounting period")
+			return closed, fmt.Errorf("erro interno do servidor")
+		}
+
+		fields := map[string]interface{}{
+			"date":    day.Format(domain.DateLayout),
+			"entries": period.Entries,
+			"debits":  period.TotalDebits,
+			"credits": period.TotalCredits,
+		}
+		if !period.Balanced() {
+			s.logger.WithFields(fields).Warn("Accounting period closed unbalanced")
+		} else {
+			s.logger.WithFields(fields).Info("Accounting period closed")
+		}
+
+		period.Totals = nil
+		closed = append(closed, *period)
+		day = calendar.NextBusinessDay(day)
+	}
+	return closed, nil
+}
+
+func (s *ledgerService) ListPeriods() ([]domain.AccountingPeriod, error) {
+	periods, err := s.repo.GetPeriods(periodListLimit)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing accounting periods")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return periods, nil
+}
+
+func (s *ledgerService) GetPeriod(date string) (*domain.AccountingPeriod, error) {
+	day, err := parseDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := s.repo.GetPeriod(day)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPeriodNotFound
+	}
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting accounting period")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return period, nil
+}
+
+// StartEndOfDayWorker encerra periodicamente os dias úteis vencidos até o canal stop ser fechado.
+func StartEndOfDayWorker(service LedgerService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				service.RunEndOfDay()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// GetEndOfDayInterval lê LEDGER_EOD_INTERVAL_MINUTES (padrão de 15 minutos).
+func GetEndOfDayInterval() time.Duration {
+	return minutesFromEnv("LEDGER_EOD_INTERVAL_MINUTES", 15)
+}
@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata"
+
+	"bankmore/internal/shared/notification"
+
+	"github.com/google/uuid"
+)
+
+// Eventos que geram notificação ao cliente.
+const (
+	EventTransferReceived = "transfer.received"
+	EventTransferSent     = "transfer.sent"
+	EventTransferFailed   = "transfer.failed"
+	EventFeeCharged       = "fee.charged"
+)
+
+const (
+	LocalePtBR = "pt-BR"
+	LocaleEn   = "en"
+
+	DefaultTimeZone = "America/Sao_Paulo"
+)
+
+// Preference guarda os contatos do cliente, os canais ativos, o idioma e o horário de
+// silêncio, em que SMS e push ficam retidos até o fim do período.
+type Preference struct {
+	AccountID       string    `json:"-" gorm:"column:idcontacorrente;primaryKey"`
+	Locale          string    `json:"locale" gorm:"column:idioma"`
+	Email           string    `json:"email" gorm:"column:email"`
+	Phone           string    `json:"phone" gorm:"column:telefone"`
+	PushToken       string    `json:"pushToken" gorm:"column:token_push"`
+	EmailEnabled    bool      `json:"emailEnabled" gorm:"column:email_ativo"`
+	SMSEnabled      bool      `json:"smsEnabled" gorm:"column:sms_ativo"`
+	PushEnabled     bool      `json:"pushEnabled" gorm:"column:push_ativo"`
+	QuietHoursStart string    `json:"quietHoursStart,omitempty" gorm:"column:silencio_inicio"`
+	QuietHoursEnd   string    `json:"quietHoursEnd,omitempty" gorm:"column:silencio_fim"`
+	TimeZone        string    `json:"timeZone" gorm:"column:fuso_horario"`
+	UpdatedAt       time.Time `json:"updatedAt" gorm:"column:data_atualizacao"`
+}
+
+func (Preference) TableName() string {
+	return "preferencia_notificacao"
+}
+
+// DefaultPreference é a preferência de quem ainda não configurou nada: nenhum canal ativo.
+func DefaultPreference(accountID string) *Preference {
+	return &Preference{
+		AccountID: accountID,
+		Locale:    LocalePtBR,
+		TimeZone:  DefaultTimeZone,
+	}
+}
+
+// Recipients devolve o destinatário de cada canal ativo com contato cadastrado.
+func (p *Preference) Recipients() map[string]string {
+	recipients := make(map[string]string)
+	if p.EmailEnabled && p.Email != "" {
+		recipients[notification.ChannelEmail] = p.Email
+	}
+	if p.SMSEnabled && p.Phone != "" {
+		recipients[notification.ChannelSMS] = p.Phone
+	}
+	if p.PushEnabled && p.PushToken != "" {
+		recipients[notification.ChannelPush] = p.PushToken
+	}
+	return recipients
+}
+
+func (p *Preference) Location() *time.Location {
+	if location, err := time.LoadLocation(p.TimeZone); err == nil {
+		return location
+	}
+	location, _ := time.LoadLocation(DefaultTimeZone)
+	return location
+}
+
+// QuietUntil indica se now está no horário de silêncio e, nesse caso, quando ele
+// termina. O período pode atravessar a meia-noite (22:00 a 07:00).
+func (p *Preference) QuietUntil(now time.Time) (time.Time, bool) {
+	if p.QuietHoursStart == "" || p.QuietHoursEnd == "" {
+		return time.Time{}, false
+	}
+	start, err := ParseClock(p.QuietHoursStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := ParseClock(p.QuietHoursEnd)
+	if err != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := now.In(p.Location())
+	minute := local.Hour()*60 + local.Minute()
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+
+	if start < end {
+		if minute >= start && minute < end {
+			return midnight.Add(time.Duration(end) * time.Minute), true
+		}
+		return time.Time{}, false
+	}
+
+	if minute >= start {
+		return midnight.AddDate(0, 0, 1).Add(time.Duration(end) * time.Minute), true
+	}
+	if minute < end {
+		return midnight.Add(time.Duration(end) * time.Minute), true
+	}
+	return time.Time{}, false
+}
+
+// ParseClock converte "HH:MM" em minutos desde a meia-noite.
+func ParseClock(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("horário inválido: %s (use HH:MM)", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+const (
+	NotificationStatusPending = "PENDING"
+	NotificationStatusSent    = "SENT"
+	NotificationStatusFailed  = "FAILED"
+)
+
+var notificationNamespace = uuid.MustParse("9d3a7c52-1e4b-4f86-b0d9-7a2c5e8f1b34")
+
+// EventID identifica o evento de origem de forma estável: a mesma mensagem do Kafka,
+// consumida de novo, gera o mesmo ID.
+func EventID(eventType, sourceID string) string {
+	return uuid.NewSHA1(notificationNamespace, []byte(eventType+"|"+sourceID)).String()
+}
+
+// Notification é uma mensagem a enviar por um canal. O ID vem do evento e do canal,
+// então cada evento gera no máximo uma mensagem por canal.
+type Notification struct {
+	ID           string     `json:"id" gorm:"column:idnotificacao;primaryKey"`
+	EventID      string     `json:"eventId" gorm:"column:idevento"`
+	EventType    string     `json:"eventType" gorm:"column:tipo_evento"`
+	AccountID    string     `json:"accountId" gorm:"column:idcontacorrente"`
+	Channel      string     `json:"channel" gorm:"column:canal"`
+	Recipient    string     `json:"recipient" gorm:"column:destinatario"`
+	Subject      string     `json:"subject" gorm:"column:assunto"`
+	Body         string     `json:"body" gorm:"column:corpo"`
+	Status       string     `json:"status" gorm:"column:situacao"`
+	Attempts     int        `json:"attempts" gorm:"column:tentativas"`
+	ScheduledFor time.Time  `json:"scheduledFor" gorm:"column:data_envio_prevista"`
+	SentAt       *time.Time `json:"sentAt,omitempty" gorm:"column:data_envio"`
+	LastError    string     `json:"lastError,omitempty" gorm:"column:ultimo_erro"`
+	CreatedAt    time.Time  `json:"createdAt" gorm:"column:data_criacao"`
+}
+
+func (Notification) TableName() string {
+	return "notificacao"
+}
+
+func NewNotification(eventID, eventType, accountID, channel, recipient, subject, body string, scheduledFor, now time.Time) *Notification {
+	return &Notification{
+		ID:           uuid.NewSHA1(notificationNamespace, []byte(eventID+"|"+channel)).String(),
+		EventID:      eventID,
+		EventType:    eventType,
+		AccountID:    accountID,
+		Channel:      channel,
+		Recipient:    recipient,
+		Subject:      subject,
+		Body:         body,
+		Status:       NotificationStatusPending,
+		ScheduledFor: scheduledFor,
+		CreatedAt:    now,
+	}
+}
+
+func (n *Notification) MarkSent(now time.Time) {
+	n.Status = NotificationStatusSent
+	n.SentAt = &now
+	n.LastError = ""
+}
+
+// RecordFailure registra uma tentativa sem sucesso. Com nextAttempt a notificação
+// continua pendente; sem ele, falha em definitivo.
+func (n *Notification) RecordFailure(reason string, nextAttempt *time.Time) {
+	n.LastError = reason
+	if nextAttempt == nil {
+		n.Status = NotificationStatusFailed
+		return
+	}
+	n.ScheduledFor = *nextAttempt
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+
+	"bankmore/internal/notification/service"
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type PreferenceHandler struct {
+	service service.PreferenceService
+	logger  *logrus.Logger
+}
+
+func NewPreferenceHandler(service service.PreferenceService, logger *logrus.Logger) *PreferenceHandler {
+	return &PreferenceHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// @Summary Consulta as preferências de notificação
+// @Description Retorna contatos, canais ativos (e-mail, SMS e push), idioma e horário de silêncio da conta logada
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} domain.Preference
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/notifications/preferences [get]
+func (h *PreferenceHandler) GetPreferences(c *gin.Context) {
+	accountID, exists := c.Get("accountId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorUserUnauthorized,
+			Message: "Token inválido",
+		})
+		return
+	}
+
+	preference, err := h.service.GetPreferences(accountID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
+
+// @Summary Altera as preferências de notificação
+// @Description Altera os campos informados. Cada canal exige o contato correspondente; no horário de silêncio, SMS e push ficam retidos até o fim do período e o e-mail é enviado normalmente
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param request body service.UpdatePreferencesRequest true "Preferências"
+// @Success 200 {object} domain.Preference
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/account/notifications/preferences [put]
+func (h *PreferenceHandler) UpdatePreferences(c *gin.Context) {
+	accountID, exists := c.Get("accountId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorUserUnauthorized,
+			Message: "Token inválido",
+		})
+		return
+	}
+
+	var request service.UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	preference, err := h.service.UpdatePreferences(accountID.(string), request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, preference)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"time"
+
+	"bankmore/internal/notification/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository interface {
+	GetPreference(accountID string) (*domain.Preference, error)
+	SavePreference(preference *domain.Preference) error
+	CreateNotifications(notifications []domain.Notification) (int64, error)
+	GetDue(now time.Time, limit int) ([]domain.Notification, error)
+	Claim(id string, now, leaseUntil time.Time) (bool, error)
+	Update(notification *domain.Notification) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) GetPreference(accountID string) (*domain.Preference, error) {
+	var preference domain.Preference
+	err := r.db.Where("idcontacorrente = ?", accountID).First(&preference).Error
+	if err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+func (r *notificationRepository) SavePreference(preference *domain.Preference) error {
+	return r.db.Save(preference).Error
+}
+
+// CreateNotifications ignora as notificações que já existem (mesmo evento e canal) e
+// retorna quantas foram criadas.
+func (r *notificationRepository) CreateNotifications(notifications []domain.Notification) (int64, error) {
+	if len(notifications) == 0 {
+		return 0, nil
+	}
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&notifications)
+	return result.RowsAffected, result.Error
+}
+
+func (r *notificationRepository) GetDue(now time.Time, limit int) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+	err := r.db.Where("situacao = ? AND data_envio_prevista <= ?", domain.NotificationStatusPending, now).
+		Order("data_envio_prevista ASC").
+		Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// Claim adia o envio previsto até leaseUntil de forma atômica, para que só um worker
+// envie a notificação; se ele cair, ela volta a vencer depois do prazo.
+func (r *notificationRepository) Claim(id string, now, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&domain.Notification{}).
+		Where("idnotificacao = ? AND situacao = ? AND data_envio_prevista <= ?", id, domain.NotificationStatusPending, now).
+		Update("data_envio_prevista", leaseUntil)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *notificationRepository) Update(notification *domain.Notification) error {
+	return r.db.Save(notification).Error
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"bankmore/internal/notification/domain"
+	"bankmore/internal/notification/repository"
+	"bankmore/internal/shared/kafka"
+	"bankmore/internal/shared/notification"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	dispatchBatchSize = 50
+	// dispatchLease é o prazo de envio de uma notificação assumida por um worker.
+	dispatchLease  = 2 * time.Minute
+	initialBackoff = time.Minute
+	maxBackoff     = time.Hour
+	maxErrorLength = 500
+)
+
+type NotificationService interface {
+	HandleMessage(topic string, value []byte) error
+	DispatchDue() (int, error)
+}
+
+type notificationService struct {
+	repo     repository.NotificationRepository
+	notifier notification.Notifier
+	logger   *logrus.Logger
+}
+
+func NewNotificationService(repo repository.NotificationRepository, notifier notification.Notifier, logger *logrus.Logger) NotificationService {
+	return &notificationService{
+		repo:     repo,
+		notifier: notifier,
+		logger:   logger,
+	}
+}
+
+// customerEvent é um evento do Kafka já traduzido para a conta a notificar.
+type customerEvent struct {
+	ID            string
+	Type          string
+	AccountID     string
+	Amount        float64
+	TransferID    string
+	AccountNumber string
+	Reason        string
+	OccurredAt    time.Time
+}
+
+// HandleMessage converte as mensagens de transferência e tarifa em notificações.
+func (s *notificationService) HandleMessage(topic string, value []byte) error {
+	switch topic {
+	case kafka.TopicTransferEvents:
+		var event kafka.TransferEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := s.notify(customerEvent{
+			ID:            domain.EventID(domain.EventTransferSent, event.TransferID),
+			Type:          domain.EventTransferSent,
+			AccountID:     event.OriginAccountID,
+			Amount:        event.Amount,
+			TransferID:    event.TransferID,
+			AccountNumber: event.DestinationAccountNumber,
+			OccurredAt:    now,
+		}); err != nil {
+			return err
+		}
+		return s.notify(customerEvent{
+			ID:         domain.EventID(domain.EventTransferReceived, event.TransferID),
+			Type:       domain.EventTransferReceived,
+			AccountID:  event.DestinationAccountID,
+			Amount:     event.Amount,
+			TransferID: event.TransferID,
+			OccurredAt: now,
+		})
+
+	case kafka.TopicTransferStatusEvents:
+		var event kafka.TransferStatusEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		if event.Status != kafka.TransferStatusFailed {
+			return nil
+		}
+		return s.notify(customerEvent{
+			ID:         domain.EventID(domain.EventTransferFailed, event.TransferID),
+			Type:       domain.EventTransferFailed,
+			AccountID:  event.OriginAccountID,
+			TransferID: event.TransferID,
+			Reason:     event.ErrorMessage,
+			OccurredAt: event.OccurredAt,
+		})
+
+	case kafka.TopicFeeEvents:
+		var event kafka.FeeEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		return s.notify(customerEvent{
+			ID:         domain.EventID(domain.EventFeeCharged, event.FeeID),
+			Type:       domain.EventFeeCharged,
+			AccountID:  event.AccountID,
+			Amount:     event.Amount,
+			TransferID: event.TransferID,
+			OccurredAt: event.ChargedAt,
+		})
+	}
+
+	return nil
+}
+
+// notify grava uma notificação por canal ativo do cliente, no idioma dele. SMS e push
+// que caem no horário de silêncio ficam agendados para o fim do período; o e-mail sai
+// na hora. Um evento já notificado (mesmo ID) é ignorado.
+func (s *notificationService) notify(event customerEvent) error {
+	if event.AccountID == "" {
+		return nil
+	}
+
+	preference, err := s.repo.GetPreference(event.AccountID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	recipients := preference.Recipients()
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	message, err := render(preference.Locale, event.Type, templateData{
+		Amount:        formatAmount(preference.Locale, event.Amount),
+		Date:          formatDate(preference.Locale, event.OccurredAt, preference.Location()),
+		TransferID:    event.TransferID,
+		AccountNumber: event.AccountNumber,
+		Reason:        event.Reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	quietUntil, quiet := preference.QuietUntil(now)
+
+	var notifications []domain.Notification
+	for channel, recipient := range recipients {
+		scheduledFor := now
+		body := message.Short
+		if channel == notification.ChannelEmail {
+			body = message.Body
+		} else if quiet {
+			scheduledFor = quietUntil
+		}
+		notifications = append(notifications, *domain.NewNotification(event.ID, event.Type, event.AccountID, channel, recipient, message.Subject, body, scheduledFor, now))
+	}
+
+	created, err := s.repo.CreateNotifications(notifications)
+	if err != nil {
+		return err
+	}
+
+	if created > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"eventId":   event.ID,
+			"eventType": event.Type,
+			"accountId": event.AccountID,
+			"created":   created,
+			"quiet":     quiet,
+		}).Info("Notifications queued")
+	}
+	return nil
+}
+
+// DispatchDue envia as notificações vencidas. Falhas são reagendadas com backoff
+// exponencial até NOTIFICATION_MAX_ATTEMPTS. Retorna quantas foram enviadas.
+func (s *notificationService) DispatchDue() (int, error) {
+	now := time.Now()
+	due, err := s.repo.GetDue(now, dispatchBatchSize)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing due notifications")
+		return 0, fmt.Errorf("erro interno do servidor")
+	}
+
+	sent := 0
+	maxAttempts := GetMaxAttempts()
+	for i := range due {
+		item := &due[i]
+		claimed, err := s.repo.Claim(item.ID, now, time.Now().Add(dispatchLease))
+		if err != nil {
+			s.logger.WithError(err).WithField("notificationId", item.ID).Error("Error claiming notification")
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		fields := logrus.Fields{
+			"notificationId": item.ID,
+			"eventType":      item.EventType,
+			"channel":        item.Channel,
+		}
+
+		item.Attempts++
+		err = s.notifier.Send(notification.Message{
+			Channel:   item.Channel,
+			Recipient: item.Recipient,
+			Subject:   item.Subject,
+			Body:      item.Body,
+		})
+		if err == nil {
+			item.MarkSent(time.Now())
+			sent++
+			s.logger.WithFields(fields).Info("Notification sent")
+		} else {
+			var nextAttempt *time.Time
+			if item.Attempts < maxAttempts {
+				next := time.Now().Add(backoff(item.Attempts))
+				nextAttempt = &next
+			}
+			item.RecordFailure(truncate(err.Error()), nextAttempt)
+			fields["attempt"] = item.Attempts
+			fields["nextAttemptAt"] = nextAttempt
+			s.logger.WithError(err).WithFields(fields).Warn("Notification delivery failed")
+		}
+
+		if err := s.repo.Update(item); err != nil {
+			s.logger.WithError(err).WithFields(fields).Error("Error updating notification")
+		}
+	}
+
+	return sent, nil
+}
+
+// backoff dobra a espera a cada tentativa, de 1 minuto até no máximo 1 hora.
+func backoff(attempts int) time.Duration {
+	wait := initialBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+	return wait
+}
+
+func truncate(message string) string {
+	if len(message) > maxErrorLength {
+		return message[:maxErrorLength]
+	}
+	return message
+}
+
+// StartDispatcher envia periodicamente as notificações vencidas até o canal stop ser fechado.
+func StartDispatcher(service NotificationService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				service.DispatchDue()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// GetDispatchInterval lê NOTIFICATION_DISPATCH_INTERVAL_SECONDS (padrão de 10 segundos).
+func GetDispatchInterval() time.Duration {
+	if value := os.Getenv("NOTIFICATION_DISPATCH_INTERVAL_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 10 * time.Second
+}
+
+// GetMaxAttempts lê NOTIFICATION_MAX_ATTEMPTS (padrão de 5 tentativas por notificação).
+func GetMaxAttempts() int {
+	if value := os.Getenv("NOTIFICATION_MAX_ATTEMPTS"); value != "" {
+		if attempts, err := strconv.Atoi(value); err == nil && attempts > 0 {
+			return attempts
+		}
+	}
+	return 5
+}
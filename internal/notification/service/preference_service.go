@@ -0,0 +1,168 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"bankmore/internal/notification/domain"
+	"bankmore/internal/notification/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{10,15}$`)
+
+type PreferenceService interface {
+	GetPreferences(accountID string) (*domain.Preference, error)
+	UpdatePreferences(accountID string, request UpdatePreferencesRequest) (*domain.Preference, error)
+}
+
+type preferenceService struct {
+	repo   repository.NotificationRepository
+	logger *logrus.Logger
+}
+
+func NewPreferenceService(repo repository.NotificationRepository, logger *logrus.Logger) PreferenceService {
+	return &preferenceService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// UpdatePreferencesRequest altera apenas os campos informados. Um canal só pode ser
+// ativado com o contato correspondente; quietHoursStart e quietHoursEnd ("HH:MM", no
+// fuso timeZone) vêm juntos, e vazios removem o horário de silêncio.
+type UpdatePreferencesRequest struct {
+	Locale          *string `json:"locale"`
+	Email           *string `json:"email"`
+	Phone           *string `json:"phone"`
+	PushToken       *string `json:"pushToken"`
+	EmailEnabled    *bool   `json:"emailEnabled"`
+	SMSEnabled      *bool   `json:"smsEnabled"`
+	PushEnabled     *bool   `json:"pushEnabled"`
+	QuietHoursStart *string `json:"quietHoursStart"`
+	QuietHoursEnd   *string `json:"quietHoursEnd"`
+	TimeZone        *string `json:"timeZone"`
+}
+
+func (s *preferenceService) GetPreferences(accountID string) (*domain.Preference, error) {
+	preference, err := s.repo.GetPreference(accountID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.DefaultPreference(accountID), nil
+	}
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting notification preferences")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return preference, nil
+}
+
+func (s *preferenceService) UpdatePreferences(accountID string, request UpdatePreferencesRequest) (*domain.Preference, error) {
+	preference, err := s.GetPreferences(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Locale != nil {
+		if *request.Locale != domain.LocalePtBR && *request.Locale != domain.LocaleEn {
+			return nil, fmt.Errorf("idioma inválido: use %s ou %s", domain.LocalePtBR, domain.LocaleEn)
+		}
+		preference.Locale = *request.Locale
+	}
+
+	if request.Email != nil {
+		email := strings.TrimSpace(*request.Email)
+		if email != "" {
+			address, err := mail.ParseAddress(email)
+			if err != nil || address.Address != email {
+				return nil, fmt.Errorf("e-mail inválido")
+			}
+		}
+		preference.Email = email
+	}
+
+	if request.Phone != nil {
+		phone := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(*request.Phone)
+		if phone != "" && !phonePattern.MatchString(phone) {
+			return nil, fmt.Errorf("telefone inválido")
+		}
+		preference.Phone = phone
+	}
+
+	if request.PushToken != nil {
+		preference.PushToken = strings.TrimSpace(*request.PushToken)
+	}
+
+	if request.EmailEnabled != nil {
+		preference.EmailEnabled = *request.EmailEnabled
+	}
+	if request.SMSEnabled != nil {
+		preference.SMSEnabled = *request.SMSEnabled
+	}
+	if request.PushEnabled != nil {
+		preference.PushEnabled = *request.PushEnabled
+	}
+
+	if preference.EmailEnabled && preference.Email == "" {
+		return nil, fmt.Errorf("informe o e-mail para ativar notificações por e-mail")
+	}
+	if preference.SMSEnabled && preference.Phone == "" {
+		return nil, fmt.Errorf("informe o telefone para ativar notificações por SMS")
+	}
+	if preference.PushEnabled && preference.PushToken == "" {
+		return nil, fmt.Errorf("informe o token do dispositivo para ativar notificações push")
+	}
+
+	if request.TimeZone != nil {
+		if _, err := time.LoadLocation(*request.TimeZone); err != nil || *request.TimeZone == "" {
+			return nil, fmt.Errorf("fuso horário inválido")
+		}
+		preference.TimeZone = *request.TimeZone
+	}
+
+	if request.QuietHoursStart != nil || request.QuietHoursEnd != nil {
+		if request.QuietHoursStart == nil || request.QuietHoursEnd == nil {
+			return nil, fmt.Errorf("informe o início e o fim do horário de silêncio")
+		}
+		start, end := *request.QuietHoursStart, *request.QuietHoursEnd
+		if (start == "") != (end == "") {
+			return nil, fmt.Errorf("informe o início e o fim do horário de silêncio")
+		}
+		if start != "" {
+			startMinute, err := domain.ParseClock(start)
+			if err != nil {
+				return nil, err
+			}
+			endMinute, err := domain.ParseClock(end)
+			if err != nil {
+				return nil, err
+			}
+			if startMinute == endMinute {
+				return nil, fmt.Errorf("início e fim do horário de silêncio devem ser diferentes")
+			}
+		}
+		preference.QuietHoursStart = start
+		preference.QuietHoursEnd = end
+	}
+
+	preference.UpdatedAt = time.Now()
+	if err := s.repo.SavePreference(preference); err != nil {
+		s.logger.WithError(err).Error("Error saving notification preferences")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"accountId":    accountID,
+		"emailEnabled": preference.EmailEnabled,
+		"smsEnabled":   preference.SMSEnabled,
+		"pushEnabled":  preference.PushEnabled,
+		"locale":       preference.Locale,
+	}).Info("Notification preferences updated")
+
+	return preference, nil
+}
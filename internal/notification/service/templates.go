@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+	"time"
+
+	"bankmore/internal/notification/domain"
+)
+
+// messageTemplate tem o texto completo (e-mail) e o curto (SMS e push) de um evento.
+type messageTemplate struct {
+	Subject string
+	Body    string
+	Short   string
+}
+
+var messageTemplates = map[string]map[string]messageTemplate{
+	domain.LocalePtBR: {
+		domain.EventTransferReceived: {
+			Subject: "Você recebeu uma transferência",
+			Body:    "Olá!\n\nVocê recebeu uma transferência de {{.Amount}} em {{.Date}}.\n\nIdentificador: {{.TransferID}}\n\nBankMore",
+			Short:   "BankMore: você recebeu {{.Amount}} em {{.Date}}.",
+		},
+		domain.EventTransferSent: {
+			Subject: "Transferência enviada",
+			Body:    "Olá!\n\nSua transferência de {{.Amount}} para a conta {{.AccountNumber}} foi concluída em {{.Date}}.\n\nIdentificador: {{.TransferID}}\n\nBankMore",
+			Short:   "BankMore: transferência de {{.Amount}} para a conta {{.AccountNumber}} concluída.",
+		},
+		domain.EventTransferFailed: {
+			Subject: "Transferência não realizada",
+			Body:    "Olá!\n\nSua transferência não foi realizada. Motivo: {{.Reason}}\n\nIdentificador: {{.TransferID}}\n\nBankMore",
+			Short:   "BankMore: sua transferência não foi realizada. {{.Reason}}",
+		},
+		domain.EventFeeCharged: {
+			Subject: "Tarifa cobrada",
+			Body:    "Olá!\n\nFoi debitada da sua conta uma tarifa de {{.Amount}} em {{.Date}}, referente à transferência {{.TransferID}}.\n\nBankMore",
+			Short:   "BankMore: tarifa de {{.Amount}} debitada em {{.Date}}.",
+		},
+	},
+	domain.LocaleEn: {
+		domain.EventTransferReceived: {
+			Subject: "You received a transfer",
+			Body:    "Hello!\n\nYou received a transfer of {{.Amount}} on {{.Date}}.\n\nReference: {{.TransferID}}\n\nBankMore",
+			Short:   "BankMore: you received {{.Amount}} on {{.Date}}.",
+		},
+		domain.EventTransferSent: {
+			Subject: "Transfer sent",
+			Body:    "Hello!\n\nYour transfer of {{.Amount}} to account {{.AccountNumber}} was completed on {{.Date}}.\n\nReference: {{.TransferID}}\n\nBankMore",
+			Short:   "BankMore: transfer of {{.Amount}} to account {{.AccountNumber}} completed.",
+		},
+		domain.EventTransferFailed: {
+			Subject: "Transfer not completed",
+			Body:    "Hello!\n\nYour transfer could not be completed. Reason: {{.Reason}}\n\nReference: {{.TransferID}}\n\nBankMore",
+			Short:   "BankMore: your transfer could not be completed. {{.Reason}}",
+		},
+		domain.EventFeeCharged: {
+			Subject: "Fee charged",
+			Body:    "Hello!\n\nA fee of {{.Amount}} was charged to your account on {{.Date}} for transfer {{.TransferID}}.\n\nBankMore",
+			Short:   "BankMore: fee of {{.Amount}} charged on {{.Date}}.",
+		},
+	},
+}
+
+// templateData são os campos disponíveis nos templates, já formatados no idioma do cliente.
+type templateData struct {
+	Amount        string
+	Date          string
+	TransferID    string
+	AccountNumber string
+	Reason        string
+}
+
+type renderedMessage struct {
+	Subject string
+	Body    string
+	Short   string
+}
+
+func render(locale, eventType string, data templateData) (*renderedMessage, error) {
+	templates, ok := messageTemplates[locale]
+	if !ok {
+		templates = messageTemplates[domain.LocalePtBR]
+	}
+	tmpl, ok := templates[eventType]
+	if !ok {
+		return nil, fmt.Errorf("template not found for event %s", eventType)
+	}
+
+	subject, err := execute(tmpl.Subject, data)
+	if err != nil {
+		return nil, err
+	}
+	body, err := execute(tmpl.Body, data)
+	if err != nil {
+		return nil, err
+	}
+	short, err := execute(tmpl.Short, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &renderedMessage{Subject: subject, Body: body, Short: short}, nil
+}
+
+func execute(text string, data templateData) (string, error) {
+	tmpl, err := template.New("message").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// formatAmount formata o valor em reais: R$ 1.234,56 em pt-BR e R$1,234.56 em inglês.
+func formatAmount(locale string, amount float64) string {
+	cents := int64(math.Round(math.Abs(amount) * 100))
+	if locale == domain.LocaleEn {
+		return fmt.Sprintf("R$%s.%02d", groupThousands(cents/100, ","), cents%100)
+	}
+	return fmt.Sprintf("R$ %s,%02d", groupThousands(cents/100, "."), cents%100)
+}
+
+func groupThousands(value int64, separator string) string {
+	digits := fmt.Sprintf("%d", value)
+	var parts []string
+	for len(digits) > 3 {
+		parts = append([]string{digits[len(digits)-3:]}, parts...)
+		digits = digits[:len(digits)-3]
+	}
+	parts = append([]string{digits}, parts...)
+	return strings.Join(parts, separator)
+}
+
+func formatDate(locale string, date time.Time, location *time.Location) string {
+	local := date.In(location)
+	if locale == domain.LocaleEn {
+		return local.Format("Jan 2, 2006 at 3:04 PM")
+	}
+	return local.Format("02/01/2006 às 15:04")
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DateLayout é o formato das datas do intervalo conciliado (no fuso do servidor).
+const DateLayout = "2006-01-02"
+
+// Classes de quebra encontradas pela conciliação.
+const (
+	// BreakMissingLeg: falta uma perna que a transferência ou a tarifa deveria ter gerado.
+	BreakMissingLeg = "MISSING_LEG"
+	// BreakDuplicateLeg: a perna foi lançada mais de uma vez, ou foi lançada sem dever
+	// existir (estorno de transferência concluída, crédito de transferência que falhou).
+	BreakDuplicateLeg = "DUPLICATE_LEG"
+	// BreakAmountMismatch: a perna existe com valor diferente do esperado.
+	BreakAmountMismatch = "AMOUNT_MISMATCH"
+	// BreakOrphanFee: tarifa sem transferência concluída e tarifável que a justifique.
+	BreakOrphanFee = "ORPHAN_FEE"
+)
+
+// Pernas conferidas em cada transferência e tarifa.
+const (
+	LegDebit    = "DEBIT"     // captura da reserva na conta de origem
+	LegCredit   = "CREDIT"    // crédito na conta de destino
+	LegRollback = "ROLLBACK"  // estorno na origem quando o crédito falha
+	LegEvent    = "EVENT"     // evento da transferência publicado no Kafka
+	LegFee      = "FEE"       // tarifa gerada a partir do evento
+	LegFeeDebit = "FEE_DEBIT" // débito da tarifa na conta
+)
+
+// Report é o resultado de uma conciliação de um intervalo de datas.
+type Report struct {
+	ID               string    `json:"id" gorm:"column:idconciliacao;primaryKey"`
+	From             time.Time `json:"from" gorm:"column:data_inicio"`
+	To               time.Time `json:"to" gorm:"column:data_fim"`
+	TransfersChecked int       `json:"transfersChecked" gorm:"column:transferencias_conferidas"`
+	FeesChecked      int       `json:"feesChecked" gorm:"column:tarifas_conferidas"`
+	UnlinkedFees     int       `json:"unlinkedFees" gorm:"column:tarifas_sem_vinculo"`
+	BreakCount       int       `json:"breakCount" gorm:"column:quebras"`
+	CreatedAt        time.Time `json:"createdAt" gorm:"column:data_criacao"`
+	Breaks           []Break   `json:"breaks,omitempty" gorm:"foreignKey:ReportID;references:ID"`
+}
+
+func (Report) TableName() string {
+	return "conciliacao"
+}
+
+func NewReport(from, to time.Time) *Report {
+	return &Report{
+		ID:        uuid.New().String(),
+		From:      from,
+		To:        to,
+		CreatedAt: time.Now(),
+	}
+}
+
+// AddBreak registra uma quebra no relatório, já marcada como corrigível ou não.
+func (r *Report) AddBreak(b Break) {
+	b.ID = uuid.New().String()
+	b.ReportID = r.ID
+	b.Fixable = IsFixable(b.Class, b.Leg)
+	r.Breaks = append(r.Breaks, b)
+	r.BreakCount = len(r.Breaks)
+}
+
+// Break é uma quebra: uma perna faltando, duplicada, com valor errado ou uma tarifa órfã.
+type Break struct {
+	ID         string     `json:"id" gorm:"column:idquebra;primaryKey"`
+	ReportID   string     `json:"reportId" gorm:"column:idconciliacao;index"`
+	Class      string     `json:"class" gorm:"column:classe"`
+	Leg        string     `json:"leg" gorm:"column:perna"`
+	TransferID *string    `json:"transferId,omitempty" gorm:"column:idtransferencia"`
+	FeeID      *string    `json:"feeId,omitempty" gorm:"column:idtarifa"`
+	AccountID  string     `json:"accountId" gorm:"column:idcontacorrente"`
+	Expected   float64    `json:"expected" gorm:"column:valor_esperado"`
+	Actual     float64    `json:"actual" gorm:"column:valor_encontrado"`
+	Detail     string     `json:"detail" gorm:"column:descricao"`
+	Fixable    bool       `json:"fixable" gorm:"column:corrigivel"`
+	FixedAt    *time.Time `json:"fixedAt,omitempty" gorm:"column:data_correcao"`
+	FixError   string     `json:"fixError,omitempty" gorm:"column:erro_correcao"`
+}
+
+func (Break) TableName() string {
+	return "quebra_conciliacao"
+}
+
+// IsFixable indica as quebras que a conciliação pode corrigir sozinha. Todas são pernas
+// faltando cuja correção repete uma operação idempotente: o estorno e o débito de tarifa
+// usam a mesma chave do lançamento original e o consumidor de tarifas ignora eventos de
+// transferências já tarifadas. A tarifa que falta com o evento publicado não entra: o
+// reenvio do evento também repetiria notificações e webhooks ao cliente. Duplicidades,
+// diferenças de valor e tarifas órfãs exigem análise manual.
+func IsFixable(class, leg string) bool {
+	if class != BreakMissingLeg {
+		return false
+	}
+	switch leg {
+	case LegRollback, LegFeeDebit, LegEvent:
+		return true
+	}
+	return false
+}
+
+// ToCents converte um valor monetário em centavos para comparações exatas.
+func ToCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
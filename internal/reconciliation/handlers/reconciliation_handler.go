@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"bankmore/internal/reconciliation/service"
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ReconciliationHandler struct {
+	service service.ReconciliationService
+	logger  *logrus.Logger
+}
+
+func NewReconciliationHandler(service service.ReconciliationService, logger *logrus.Logger) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// @Summary Executa uma conciliação
+// @Description Cruza as transferências concluídas e falhas do período com os movimentos de débito, crédito e estorno, a publicação do evento no Kafka e as tarifas com seus débitos. As quebras são classificadas em MISSING_LEG, DUPLICATE_LEG, AMOUNT_MISMATCH e ORPHAN_FEE e o relatório é gravado
+// @Tags Reconciliation
+// @Accept json
+// @Produce json
+// @Param request body service.RunRequest true "Período (AAAA-MM-DD, datas inclusive)"
+// @Success 201 {object} domain.Report
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/admin/reconciliation [post]
+func (h *ReconciliationHandler) RunReconciliation(c *gin.Context) {
+	var request service.RunRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	report, err := h.service.Run(request.From, request.To)
+	if err != nil {
+		respondReconciliationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+// @Summary Lista as conciliações
+// @Description Retorna as 50 conciliações mais recentes, sem as quebras
+// @Tags Reconciliation
+// @Produce json
+// @Success 200 {array} domain.Report
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/admin/reconciliation [get]
+func (h *ReconciliationHandler) ListReports(c *gin.Context) {
+	reports, err := h.service.ListReports()
+	if err != nil {
+		respondReconciliationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// @Summary Consulta uma conciliação
+// @Description Retorna o relatório com as quebras em JSON ou, com format=csv, as quebras em CSV
+// @Tags Reconciliation
+// @Produce json
+// @Produce text/csv
+// @Param id path string true "ID da conciliação"
+// @Param format query string false "json (padrão) ou csv"
+// @Success 200 {object} domain.Report
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/admin/reconciliation/{id} [get]
+func (h *ReconciliationHandler) GetReport(c *gin.Context) {
+	if c.Query("format") == "csv" {
+		report, err := h.service.GetReportCSV(c.Param("id"))
+		if err != nil {
+			respondReconciliationError(c, err)
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=conciliacao-%s.csv", c.Param("id")))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", report)
+		return
+	}
+
+	report, err := h.service.GetReport(c.Param("id"))
+	if err != nil {
+		respondReconciliationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary Corrige quebras de uma conciliação
+// @Description Corrige as quebras corrigíveis ainda pendentes: estorno faltando em transferência que falhou, débito de tarifa faltando e evento de transferência não publicado. Sem breakIds, tenta todas. As demais classes exigem análise manual e são ignoradas
+// @Tags Reconciliation
+// @Accept json
+// @Produce json
+// @Param id path string true "ID da conciliação"
+// @Param request body service.FixRequest false "Quebras a corrigir"
+// @Success 200 {object} service.FixResult
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/admin/reconciliation/{id}/fix [post]
+func (h *ReconciliationHandler) FixBreaks(c *gin.Context) {
+	var request service.FixRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Type:    models.ErrorInvalidData,
+				Message: "Dados inválidos",
+			})
+			return
+		}
+	}
+
+	result, err := h.service.FixBreaks(c.Param("id"), request.BreakIDs)
+	if err != nil {
+		respondReconciliationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func respondReconciliationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrReportNotFound), errors.Is(err, service.ErrBreakNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Type:    models.ErrorInvalidArgument,
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidPeriod):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: err.Error(),
+		})
+	}
+}
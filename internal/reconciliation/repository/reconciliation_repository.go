@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"strconv"
+	"time"
+
+	accountDomain "bankmore/internal/account/domain"
+	feeDomain "bankmore/internal/fee/domain"
+	"bankmore/internal/reconciliation/domain"
+	transferDomain "bankmore/internal/transfer/domain"
+
+	"gorm.io/gorm"
+)
+
+// lookupBatchSize limita a quantidade de parâmetros de cada consulta IN.
+const lookupBatchSize = 500
+
+type ReconciliationRepository interface {
+	GetSettledTransfers(from, to time.Time) ([]transferDomain.Transfer, error)
+	GetTransfersByIDs(ids []string) ([]transferDomain.Transfer, error)
+	GetHoldsByIDs(ids []string) ([]accountDomain.Hold, error)
+	GetMovementsByIDs(ids []string) ([]accountDomain.Movement, error)
+	GetMovementsByKeys(keys []string) ([]accountDomain.Movement, error)
+	GetFees(from, to time.Time) ([]feeDomain.Fee, error)
+	GetFeesByTransferIDs(ids []string) ([]feeDomain.Fee, error)
+	GetTransfer(id string) (*transferDomain.Transfer, error)
+	GetFee(id string) (*feeDomain.Fee, error)
+	GetAccountNumber(accountID string) (string, error)
+	MarkEventPublished(transferID string, publishedAt time.Time) error
+	CreateReport(report *domain.Report) error
+	GetReport(id string) (*domain.Report, error)
+	GetReports(limit int) ([]domain.Report, error)
+	UpdateBreak(b *domain.Break) error
+}
+
+type reconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepository(db *gorm.DB) ReconciliationRepository {
+	return &reconciliationRepository{db: db}
+}
+
+// GetSettledTransfers devolve as transferências concluídas ou falhas do intervalo. As
+// pendentes, agendadas e canceladas não movimentaram dinheiro de forma definitiva.
+func (r *reconciliationRepository) GetSettledTransfers(from, to time.Time) ([]transferDomain.Transfer, error) {
+	var transfers []transferDomain.Transfer
+	err := r.db.Where("datamovimento >= ? AND datamovimento < ? AND status IN ?", from, to,
+		[]int{transferDomain.TransferStatusCompleted, transferDomain.TransferStatusFailed}).
+		Order("datamovimento ASC").
+		Find(&transfers).Error
+	return transfers, err
+}
+
+func (r *reconciliationRepository) GetTransfersByIDs(ids []string) ([]transferDomain.Transfer, error) {
+	var transfers []transferDomain.Transfer
+	err := inBatches(ids, func(batch []string) error {
+		var found []transferDomain.Transfer
+		if err := r.db.Where("idtransferencia IN ?", batch).Find(&found).Error; err != nil {
+			return err
+		}
+		transfers = append(transfers, found...)
+		return nil
+	})
+	return transfers, err
+}
+
+func (r *reconciliationRepository) GetHoldsByIDs(ids []string) ([]accountDomain.Hold, error) {
+	var holds []accountDomain.Hold
+	err := inBatches(ids, func(batch []string) error {
+		var found []accountDomain.Hold
+		if err := r.db.Where("idreserva IN ?", batch).Find(&found).Error; err != nil {
+			return err
+		}
+		holds = append(holds, found...)
+		return nil
+	})
+	return holds, err
+}
+
+func (r *reconciliationRepository) GetMovementsByIDs(ids []string) ([]accountDomain.Movement, error) {
+	var movements []accountDomain.Movement
+	err := inBatches(ids, func(batch []string) error {
+		var found []accountDomain.Movement
+		if err := r.db.Where("idmovimento IN ?", batch).Find(&found).Error; err != nil {
+			return err
+		}
+		movements = append(movements, found...)
+		return nil
+	})
+	return movements, err
+}
+
+func (r *reconciliationRepository) GetMovementsByKeys(keys []string) ([]accountDomain.Movement, error) {
+	var movements []accountDomain.Movement
+	err := inBatches(keys, func(batch []string) error {
+		var found []accountDomain.Movement
+		if err := r.db.Where("idempotencia_key IN ?", batch).Find(&found).Error; err != nil {
+			return err
+		}
+		movements = append(movements, found...)
+		return nil
+	})
+	return movements, err
+}
+
+func (r *reconciliationRepository) GetFees(from, to time.Time) ([]feeDomain.Fee, error) {
+	var fees []feeDomain.Fee
+	err := r.db.Where("datamovimento >= ? AND datamovimento < ?", from, to).
+		Order("datamovimento ASC").
+		Find(&fees).Error
+	return fees, err
+}
+
+func (r *reconciliationRepository) GetFeesByTransferIDs(ids []string) ([]feeDomain.Fee, error) {
+	var fees []feeDomain.Fee
+	err := inBatches(ids, func(batch []string) error {
+		var found []feeDomain.Fee
+		if err := r.db.Where("idtransferencia IN ?", batch).Find(&found).Error; err != nil {
+			return err
+		}
+		fees = append(fees, found...)
+		return nil
+	})
+	return fees, err
+}
+
+func (r *reconciliationRepository) GetTransfer(id string) (*transferDomain.Transfer, error) {
+	var transfer transferDomain.Transfer
+	if err := r.db.Where("idtransferencia = ?", id).First(&transfer).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *reconciliationRepository) GetFee(id string) (*feeDomain.Fee, error) {
+	var fee feeDomain.Fee
+	if err := r.db.Where("idtarifa = ?", id).First(&fee).Error; err != nil {
+		return nil, err
+	}
+	return &fee, nil
+}
+
+func (r *reconciliationRepository) GetAccountNumber(accountID string) (string, error) {
+	var accountNumber int
+	err := r.db.Table("contacorrente").
+		Select("numero").
+		Where("idcontacorrente = ?", accountID).
+		Scan(&accountNumber).Error
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(accountNumber), nil
+}
+
+func (r *reconciliationRepository) MarkEventPublished(transferID string, publishedAt time.Time) error {
+	return r.db.Model(&transferDomain.Transfer{}).
+		Where("idtransferencia = ?", transferID).
+		Update("data_publicacao_evento", publishedAt).Error
+}
+
+// CreateReport grava o relatório e suas quebras na mesma transação.
+func (r *reconciliationRepository) CreateReport(report *domain.Report) error {
+	return r.db.Create(report).Error
+}
+
+func (r *reconciliationRepository) GetReport(id string) (*domain.Report, error) {
+	var report domain.Report
+	err := r.db.Preload("Breaks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("classe ASC, perna ASC")
+	}).Where("idconciliacao = ?", id).First(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// GetReports lista os relatórios mais recentes, sem as quebras.
+func (r *reconciliationRepository) GetReports(limit int) ([]domain.Report, error) {
+	var reports []domain.Report
+	err := r.db.Order("data_criacao DESC").Limit(limit).Find(&reports).Error
+	return reports, err
+}
+
+func (r *reconciliationRepository) UpdateBreak(b *domain.Break) error {
+	return r.db.Save(b).Error
+}
+
+func inBatches(values []string, fn func(batch []string) error) error {
+	for start := 0; start < len(values); start += lookupBatchSize {
+		end := start + lookupBatchSize
+		if end > len(values) {
+			end = len(values)
+		}
+		if err := fn(values[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
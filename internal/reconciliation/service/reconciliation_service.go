@@ -0,0 +1,686 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	accountDomain "bankmore/internal/account/domain"
+	feeDomain "bankmore/internal/fee/domain"
+	"bankmore/internal/reconciliation/domain"
+	"bankmore/internal/reconciliation/repository"
+	"bankmore/internal/shared/kafka"
+	"bankmore/internal/shared/middleware"
+	"bankmore/internal/shared/models"
+	transferDomain "bankmore/internal/transfer/domain"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const reportListLimit = 50
+
+var (
+	ErrInvalidPeriod  = errors.New("período inválido: use datas AAAA-MM-DD, com início até o fim e fim até hoje")
+	ErrReportNotFound = errors.New("conciliação não encontrada")
+	ErrBreakNotFound  = errors.New("quebra não encontrada nesta conciliação")
+)
+
+type ReconciliationService interface {
+	Run(from, to string) (*domain.Report, error)
+	ListReports() ([]domain.Report, error)
+	GetReport(id string) (*domain.Report, error)
+	GetReportCSV(id string) ([]byte, error)
+	FixBreaks(reportID string, breakIDs []string) (*FixResult, error)
+}
+
+type reconciliationService struct {
+	repo     repository.ReconciliationRepository
+	producer *kafka.Producer
+	logger   *logrus.Logger
+}
+
+// NewReconciliationService recebe o produtor Kafka usado para republicar eventos
+// perdidos; sem ele a conciliação roda, mas não corrige essa quebra.
+func NewReconciliationService(repo repository.ReconciliationRepository, producer *kafka.Producer, logger *logrus.Logger) ReconciliationService {
+	return &reconciliationService{
+		repo:     repo,
+		producer: producer,
+		logger:   logger,
+	}
+}
+
+type RunRequest struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+type FixRequest struct {
+	BreakIDs []string `json:"breakIds"`
+}
+
+// FixResult resume uma rodada de correções. Skipped conta as quebras selecionadas que
+// não são corrigíveis ou já foram corrigidas.
+type FixResult struct {
+	Fixed   int            `json:"fixed"`
+	Failed  int            `json:"failed"`
+	Skipped int            `json:"skipped"`
+	Breaks  []domain.Break `json:"breaks"`
+}
+
+// Run concilia as transferências e tarifas do período, com as duas datas inclusive, e
+// grava o relatório.
+func (s *reconciliationService) Run(from, to string) (*domain.Report, error) {
+	start, err := time.ParseInLocation(domain.DateLayout, from, time.Local)
+	if err != nil {
+		return nil, ErrInvalidPeriod
+	}
+	end, err := time.ParseInLocation(domain.DateLayout, to, time.Local)
+	if err != nil || end.Before(start) || end.After(time.Now()) {
+		return nil, ErrInvalidPeriod
+	}
+
+	report, err := s.reconcile(start, end.AddDate(0, 0, 1))
+	if err != nil {
+		s.logger.WithError(err).Error("Error reconciling transfers and fees")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if err := s.repo.CreateReport(report); err != nil {
+		s.logger.WithError(err).Error("Error saving reconciliation report")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	entry := s.logger.WithFields(logrus.Fields{
+		"reportId":  report.ID,
+		"from":      from,
+		"to":        to,
+		"transfers": report.TransfersChecked,
+		"fees":      report.FeesChecked,
+		"breaks":    report.BreakCount,
+	})
+	if report.BreakCount > 0 {
+		entry.Warn("Reconciliation found breaks")
+	} else {
+		entry.Info("Reconciliation completed without breaks")
+	}
+
+	return report, nil
+}
+
+// transferLegs reúne as pernas já gravadas das transferências conciliadas.
+type transferLegs struct {
+	debits    map[string]*accountDomain.Movement  // débito da origem, por transferência
+	movements map[string][]accountDomain.Movement // movimentos por chave de idempotência
+	fees      map[string][]feeDomain.Fee          // tarifas por transferência
+}
+
+func (s *reconciliationService) reconcile(from, to time.Time) (*domain.Report, error) {
+	report := domain.NewReport(from, to)
+
+	transfers, err := s.repo.GetSettledTransfers(from, to)
+	if err != nil {
+		return nil, err
+	}
+	report.TransfersChecked = len(transfers)
+
+	legs, err := s.loadTransferLegs(transfers)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transfer := range transfers {
+		checkTransfer(report, transfer, legs)
+	}
+
+	if err := s.reconcileFees(report, from, to, transfers, legs); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// loadTransferLegs busca em lote os débitos (pela reserva capturada, ou pela chave
+// "-debit" das transferências anteriores às reservas), os créditos, os estornos e as
+// tarifas das transferências.
+func (s *reconciliationService) loadTransferLegs(transfers []transferDomain.Transfer) (*transferLegs, error) {
+	legs := &transferLegs{
+		debits:    make(map[string]*accountDomain.Movement),
+		movements: make(map[string][]accountDomain.Movement),
+		fees:      make(map[string][]feeDomain.Fee),
+	}
+
+	var holdIDs, keys, transferIDs []string
+	holdTransfers := make(map[string]string)
+	for _, transfer := range transfers {
+		transferIDs = append(transferIDs, transfer.ID)
+		keys = append(keys, transfer.ID+"-credit", transfer.ID+"-rollback")
+		if transfer.HoldID != nil {
+			holdIDs = append(holdIDs, *transfer.HoldID)
+			holdTransfers[*transfer.HoldID] = transfer.ID
+		} else {
+			keys = append(keys, transfer.ID+"-debit")
+		}
+	}
+
+	holds, err := s.repo.GetHoldsByIDs(holdIDs)
+	if err != nil {
+		return nil, err
+	}
+	var movementIDs []string
+	movementTransfers := make(map[string]string)
+	for _, hold := range holds {
+		if hold.MovementID != nil {
+			movementIDs = append(movementIDs, *hold.MovementID)
+			movementTransfers[*hold.MovementID] = holdTransfers[hold.ID]
+		}
+	}
+
+	captured, err := s.repo.GetMovementsByIDs(movementIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range captured {
+		legs.debits[movementTransfers[captured[i].ID]] = &captured[i]
+	}
+
+	movements, err := s.repo.GetMovementsByKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+	for _, movement := range movements {
+		if movement.IdempotencyKey != nil {
+			legs.movements[*movement.IdempotencyKey] = append(legs.movements[*movement.IdempotencyKey], movement)
+		}
+	}
+	for _, transfer := range transfers {
+		if debits := legs.movements[transfer.ID+"-debit"]; transfer.HoldID == nil && len(debits) > 0 {
+			legs.debits[transfer.ID] = &debits[0]
+		}
+	}
+
+	fees, err := s.repo.GetFeesByTransferIDs(transferIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, fee := range fees {
+		legs.fees[*fee.TransferID] = append(legs.fees[*fee.TransferID], fee)
+	}
+
+	return legs, nil
+}
+
+// checkTransfer confere as pernas de uma transferência. Concluída: um débito na origem,
+// um crédito no destino, nenhum estorno, o evento publicado e, se tarifável, uma tarifa.
+// Falha: nenhum crédito e, se o débito chegou a ser capturado, um estorno.
+func checkTransfer(report *domain.Report, transfer transferDomain.Transfer, legs *transferLegs) {
+	debit := legs.debits[transfer.ID]
+	credits := legs.movements[transfer.ID+"-credit"]
+	rollbacks := legs.movements[transfer.ID+"-rollback"]
+
+	if transfer.Status == transferDomain.TransferStatusFailed {
+		if len(credits) > 0 {
+			report.AddBreak(transferBreak(transfer, domain.BreakDuplicateLeg, domain.LegCredit, transfer.DestinationAccountID,
+				0, sumMovements(credits), "crédito lançado em transferência que falhou"))
+		}
+		switch {
+		case debit != nil && len(credits) == 0:
+			checkSingleLeg(report, transfer, domain.LegRollback, transfer.OriginAccountID, rollbacks, "débito capturado sem estorno")
+		case debit == nil && len(rollbacks) > 0:
+			report.AddBreak(transferBreak(transfer, domain.BreakDuplicateLeg, domain.LegRollback, transfer.OriginAccountID,
+				0, sumMovements(rollbacks), "estorno sem débito capturado"))
+		}
+		return
+	}
+
+	if debit == nil {
+		report.AddBreak(transferBreak(transfer, domain.BreakMissingLeg, domain.LegDebit, transfer.OriginAccountID,
+			transfer.Amount, 0, "débito da origem não encontrado"))
+	} else if domain.ToCents(debit.Amount) != domain.ToCents(transfer.Amount) {
+		report.AddBreak(transferBreak(transfer, domain.BreakAmountMismatch, domain.LegDebit, transfer.OriginAccountID,
+			transfer.Amount, debit.Amount, "débito da origem com valor diferente da transferência"))
+	}
+
+	checkSingleLeg(report, transfer, domain.LegCredit, transfer.DestinationAccountID, credits, "crédito no destino não encontrado")
+
+	if len(rollbacks) > 0 {
+		report.AddBreak(transferBreak(transfer, domain.BreakDuplicateLeg, domain.LegRollback, transfer.OriginAccountID,
+			0, sumMovements(rollbacks), "estorno lançado em transferência concluída"))
+	}
+
+	checkEventAndFee(report, transfer, legs.fees[transfer.ID])
+}
+
+// checkSingleLeg confere uma perna que deve existir exatamente uma vez, com o valor da
+// transferência.
+func checkSingleLeg(report *domain.Report, transfer transferDomain.Transfer, leg, accountID string, movements []accountDomain.Movement, missingDetail string) {
+	switch {
+	case len(movements) == 0:
+		report.AddBreak(transferBreak(transfer, domain.BreakMissingLeg, leg, accountID, transfer.Amount, 0, missingDetail))
+	case len(movements) > 1:
+		report.AddBreak(transferBreak(transfer, domain.BreakDuplicateLeg, leg, accountID, transfer.Amount, sumMovements(movements),
+			fmt.Sprintf("%d lançamentos para a mesma perna", len(movements))))
+	case domain.ToCents(movements[0].Amount) != domain.ToCents(transfer.Amount):
+		report.AddBreak(transferBreak(transfer, domain.BreakAmountMismatch, leg, accountID, transfer.Amount, movements[0].Amount,
+			"lançamento com valor diferente da transferência"))
+	}
+}
+
+// checkEventAndFee só vale para transferências que gravam o tipo e a publicação do
+// evento; nas anteriores não há como saber se o evento saiu nem se eram tarifáveis.
+func checkEventAndFee(report *domain.Report, transfer transferDomain.Transfer, fees []feeDomain.Fee) {
+	if transfer.Type == "" {
+		return
+	}
+
+	if transfer.EventPublishedAt == nil {
+		report.AddBreak(transferBreak(transfer, domain.BreakMissingLeg, domain.LegEvent, transfer.OriginAccountID,
+			transfer.Amount, 0, "evento da transferência não publicado no Kafka"))
+		return
+	}
+
+	if (kafka.TransferEvent{Type: transfer.Type}).IsFeeExempt() {
+		return
+	}
+
+	expected := 0.0
+	if transfer.GuaranteedFee != nil {
+		expected = *transfer.GuaranteedFee
+	}
+
+	switch {
+	case len(fees) == 0:
+		report.AddBreak(transferBreak(transfer, domain.BreakMissingLeg, domain.LegFee, transfer.OriginAccountID,
+			expected, 0, "tarifa não gerada para transferência tarifável"))
+	case len(fees) > 1:
+		var total float64
+		for _, fee := range fees {
+			total += fee.Amount
+		}
+		report.AddBreak(transferBreak(transfer, domain.BreakDuplicateLeg, domain.LegFee, transfer.OriginAccountID,
+			fees[0].Amount, total, fmt.Sprintf("%d tarifas para a mesma transferência", len(fees))))
+	case transfer.GuaranteedFee != nil && domain.ToCents(fees[0].Amount) != domain.ToCents(expected):
+		report.AddBreak(transferBreak(transfer, domain.BreakAmountMismatch, domain.LegFee, transfer.OriginAccountID,
+			expected, fees[0].Amount, "tarifa diferente da garantida na cotação"))
+	}
+}
+
+// reconcileFees confere as tarifas do período e as das transferências conciliadas. Tarifas
+// gravadas antes do vínculo com a transferência só são contadas.
+func (s *reconciliationService) reconcileFees(report *domain.Report, from, to time.Time, transfers []transferDomain.Transfer, legs *transferLegs) error {
+	fees, err := s.repo.GetFees(from, to)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for _, fee := range fees {
+		seen[fee.ID] = true
+	}
+	for _, linked := range legs.fees {
+		for _, fee := range linked {
+			if !seen[fee.ID] {
+				seen[fee.ID] = true
+				fees = append(fees, fee)
+			}
+		}
+	}
+	report.FeesChecked = len(fees)
+
+	transfersByID := make(map[string]*transferDomain.Transfer)
+	for i := range transfers {
+		transfersByID[transfers[i].ID] = &transfers[i]
+	}
+
+	var missingTransfers, debitKeys []string
+	for _, fee := range fees {
+		if fee.TransferID == nil {
+			continue
+		}
+		if _, ok := transfersByID[*fee.TransferID]; !ok {
+			missingTransfers = append(missingTransfers, *fee.TransferID)
+		}
+		debitKeys = append(debitKeys, fee.RequestID+"-fee")
+	}
+
+	others, err := s.repo.GetTransfersByIDs(missingTransfers)
+	if err != nil {
+		return err
+	}
+	for i := range others {
+		transfersByID[others[i].ID] = &others[i]
+	}
+
+	movements, err := s.repo.GetMovementsByKeys(debitKeys)
+	if err != nil {
+		return err
+	}
+	debits := make(map[string][]accountDomain.Movement)
+	for _, movement := range movements {
+		debits[*movement.IdempotencyKey] = append(debits[*movement.IdempotencyKey], movement)
+	}
+
+	for _, fee := range fees {
+		if fee.TransferID == nil {
+			report.UnlinkedFees++
+			continue
+		}
+		checkFee(report, fee, transfersByID[*fee.TransferID], debits[fee.RequestID+"-fee"])
+	}
+
+	return nil
+}
+
+// checkFee confere se a tarifa tem uma transferência concluída e tarifável e um único
+// débito na conta. O débito de uma tarifa órfã não é conferido: lançá-lo cobraria o
+// cliente por uma tarifa indevida.
+func checkFee(report *domain.Report, fee feeDomain.Fee, transfer *transferDomain.Transfer, debits []accountDomain.Movement) {
+	var orphanReason string
+	switch {
+	case transfer == nil:
+		orphanReason = "transferência da tarifa não encontrada"
+	case transfer.Status != transferDomain.TransferStatusCompleted:
+		orphanReason = "tarifa de transferência não concluída"
+	case transfer.Type != "" && (kafka.TransferEvent{Type: transfer.Type}).IsFeeExempt():
+		orphanReason = "tarifa de transferência isenta"
+	}
+	if orphanReason != "" {
+		report.AddBreak(feeBreak(fee, domain.BreakOrphanFee, domain.LegFee, 0, fee.Amount, orphanReason))
+		return
+	}
+
+	switch {
+	case len(debits) == 0 && !fee.IsDue(time.Now()):
+		// Cobrança adiada para o próximo dia útil.
+	case len(debits) == 0:
+		report.AddBreak(feeBreak(fee, domain.BreakMissingLeg, domain.LegFeeDebit, fee.Amount, 0, "tarifa sem débito na conta"))
+	case len(debits) > 1:
+		report.AddBreak(feeBreak(fee, domain.BreakDuplicateLeg, domain.LegFeeDebit, fee.Amount, sumMovements(debits),
+			fmt.Sprintf("%d débitos para a mesma tarifa", len(debits))))
+	case domain.ToCents(debits[0].Amount) != domain.ToCents(fee.Amount):
+		report.AddBreak(feeBreak(fee, domain.BreakAmountMismatch, domain.LegFeeDebit, fee.Amount, debits[0].Amount,
+			"débito com valor diferente da tarifa"))
+	}
+}
+
+func transferBreak(transfer transferDomain.Transfer, class, leg, accountID string, expected, actual float64, detail string) domain.Break {
+	transferID := transfer.ID
+	return domain.Break{
+		Class:      class,
+		Leg:        leg,
+		TransferID: &transferID,
+		AccountID:  accountID,
+		Expected:   expected,
+		Actual:     actual,
+		Detail:     detail,
+	}
+}
+
+func feeBreak(fee feeDomain.Fee, class, leg string, expected, actual float64, detail string) domain.Break {
+	feeID := fee.ID
+	return domain.Break{
+		Class:      class,
+		Leg:        leg,
+		TransferID: fee.TransferID,
+		FeeID:      &feeID,
+		AccountID:  fee.AccountID,
+		Expected:   expected,
+		Actual:     actual,
+		Detail:     detail,
+	}
+}
+
+func sumMovements(movements []accountDomain.Movement) float64 {
+	var cents int64
+	for _, movement := range movements {
+		cents += domain.ToCents(movement.Amount)
+	}
+	return float64(cents) / 100
+}
+
+func (s *reconciliationService) ListReports() ([]domain.Report, error) {
+	reports, err := s.repo.GetReports(reportListLimit)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing reconciliation reports")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return reports, nil
+}
+
+func (s *reconciliationService) GetReport(id string) (*domain.Report, error) {
+	report, err := s.repo.GetReport(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting reconciliation report")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return report, nil
+}
+
+// GetReportCSV devolve as quebras do relatório em CSV, uma por linha.
+func (s *reconciliationService) GetReportCSV(id string) ([]byte, error) {
+	report, err := s.GetReport(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"id", "class", "leg", "transferId", "feeId", "accountId", "expected", "actual", "detail", "fixable", "fixedAt", "fixError"})
+
+	for _, b := range report.Breaks {
+		fixedAt := ""
+		if b.FixedAt != nil {
+			fixedAt = b.FixedAt.Format(time.RFC3339)
+		}
+		writer.Write([]string{
+			b.ID,
+			b.Class,
+			b.Leg,
+			stringValue(b.TransferID),
+			stringValue(b.FeeID),
+			b.AccountID,
+			strconv.FormatFloat(b.Expected, 'f', 2, 64),
+			strconv.FormatFloat(b.Actual, 'f', 2, 64),
+			b.Detail,
+			strconv.FormatBool(b.Fixable),
+			fixedAt,
+			b.FixError,
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		s.logger.WithError(err).Error("Error writing reconciliation report")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// FixBreaks corrige as quebras corrigíveis do relatório ainda não corrigidas. Sem
+// breakIDs, tenta todas. A falha de uma correção fica registrada na quebra e não
+// interrompe as demais.
+func (s *reconciliationService) FixBreaks(reportID string, breakIDs []string) (*FixResult, error) {
+	report, err := s.GetReport(reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for _, b := range report.Breaks {
+		known[b.ID] = true
+	}
+	selected := make(map[string]bool)
+	for _, id := range breakIDs {
+		if !known[id] {
+			return nil, ErrBreakNotFound
+		}
+		selected[id] = true
+	}
+
+	result := &FixResult{Breaks: []domain.Break{}}
+	for i := range report.Breaks {
+		b := &report.Breaks[i]
+		if len(selected) > 0 && !selected[b.ID] {
+			continue
+		}
+		if !b.Fixable || b.FixedAt != nil {
+			result.Skipped++
+			continue
+		}
+
+		if err := s.fixBreak(b); err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"breakId": b.ID,
+				"class":   b.Class,
+				"leg":     b.Leg,
+			}).Warn("Error fixing reconciliation break")
+			b.FixError = err.Error()
+			result.Failed++
+		} else {
+			now := time.Now()
+			b.FixedAt = &now
+			b.FixError = ""
+			result.Fixed++
+		}
+
+		if err := s.repo.UpdateBreak(b); err != nil {
+			s.logger.WithError(err).Error("Error saving reconciliation break")
+			return nil, fmt.Errorf("erro interno do servidor")
+		}
+		result.Breaks = append(result.Breaks, *b)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"reportId": reportID,
+		"fixed":    result.Fixed,
+		"failed":   result.Failed,
+		"skipped":  result.Skipped,
+	}).Info("Reconciliation fixes applied")
+
+	return result, nil
+}
+
+// fixBreak repete a operação que deveria ter gerado a perna, sempre com a chave de
+// idempotência original: se a perna tiver sido lançada depois da conciliação, nada muda.
+func (s *reconciliationService) fixBreak(b *domain.Break) error {
+	switch b.Leg {
+	case domain.LegRollback:
+		transfer, err := s.repo.GetTransfer(*b.TransferID)
+		if err != nil {
+			return fmt.Errorf("transferência não encontrada")
+		}
+		if transfer.Status != transferDomain.TransferStatusFailed {
+			return fmt.Errorf("a transferência não está mais com falha")
+		}
+		return s.postMovement(transfer.ID+"-rollback", transfer.OriginAccountID, transfer.Amount, "C", "TRANSFER")
+	case domain.LegFeeDebit:
+		fee, err := s.repo.GetFee(*b.FeeID)
+		if err != nil {
+			return fmt.Errorf("tarifa não encontrada")
+		}
+		return s.postMovement(fee.RequestID+"-fee", fee.AccountID, fee.Amount, "D", "FEE")
+	case domain.LegEvent:
+		transfer, err := s.repo.GetTransfer(*b.TransferID)
+		if err != nil {
+			return fmt.Errorf("transferência não encontrada")
+		}
+		return s.republishTransferEvent(transfer)
+	}
+	return fmt.Errorf("quebra sem correção automática")
+}
+
+func (s *reconciliationService) postMovement(requestID, accountID string, amount float64, movementType, category string) error {
+	accountAPIURL := os.Getenv("ACCOUNT_API_URL")
+	if accountAPIURL == "" {
+		accountAPIURL = "http://localhost:8001"
+	}
+
+	accountNumber, err := s.repo.GetAccountNumber(accountID)
+	if err != nil {
+		return fmt.Errorf("conta não encontrada")
+	}
+
+	request := map[string]interface{}{
+		"requestId":     requestID,
+		"accountNumber": accountNumber,
+		"amount":        amount,
+		"type":          movementType,
+		"category":      category,
+	}
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/api/account/movement", accountAPIURL)
+	resp, err := middleware.DoServiceRequest("reconcile", http.MethodPost, url, strings.NewReader(string(jsonData)))
+	if err != nil {
+		return fmt.Errorf("API de contas indisponível")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		var errorResp models.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil && errorResp.Message != "" {
+			return fmt.Errorf("API de contas recusou o lançamento: %s", errorResp.Message)
+		}
+		return fmt.Errorf("API de contas retornou status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// republishTransferEvent reenvia o evento com os mesmos dados da publicação original.
+func (s *reconciliationService) republishTransferEvent(transfer *transferDomain.Transfer) error {
+	if s.producer == nil {
+		return fmt.Errorf("publicação de eventos indisponível")
+	}
+
+	requestID := transfer.ID
+	if transfer.IdempotencyKey != nil {
+		requestID = *transfer.IdempotencyKey
+	}
+
+	destinationAccountNumber, err := s.repo.GetAccountNumber(transfer.DestinationAccountID)
+	if err != nil {
+		return fmt.Errorf("conta de destino não encontrada")
+	}
+
+	event := kafka.TransferEvent{
+		RequestID:                requestID,
+		OriginAccountID:          transfer.OriginAccountID,
+		DestinationAccountID:     transfer.DestinationAccountID,
+		DestinationAccountNumber: destinationAccountNumber,
+		Amount:                   transfer.Amount,
+		TransferID:               transfer.ID,
+		Type:                     transfer.Type,
+		FeeAmount:                transfer.GuaranteedFee,
+	}
+	if err := s.producer.PublishTransferEvent(event); err != nil {
+		return fmt.Errorf("erro ao publicar o evento")
+	}
+
+	if err := s.repo.MarkEventPublished(transfer.ID, time.Now()); err != nil {
+		s.logger.WithError(err).Error("Error marking transfer event as published")
+	}
+	return nil
+}
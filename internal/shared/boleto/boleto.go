@@ -0,0 +1,388 @@
+// Package boleto gera e interpreta o código de barras (44 dígitos) e a linha digitável
+// dos boletos no padrão FEBRABAN: os de cobrança bancária (47 dígitos) e os de
+// arrecadação de concessionárias e tributos (48 dígitos).
+package boleto
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TypeBank é o boleto de cobrança emitido por um banco.
+	TypeBank = "BANK"
+	// TypeCollection é a guia de arrecadação (água, luz, telefone, tributos).
+	TypeCollection = "COLLECTION"
+
+	BarcodeLength            = 44
+	BankLineLength           = 47
+	CollectionLineLength     = 48
+	FreeFieldLength          = 25
+	currencyReal             = '9'
+	collectionProduct        = '8'
+	maxBankAmountCents       = 9999999999
+	maxCollectionAmountCents = 99999999999
+)
+
+// Início da contagem do fator de vencimento. O fator 9999 caiu em 21/02/2025 e a
+// contagem recomeçou em 1000 no dia seguinte, então o mesmo fator volta a cada 9000 dias.
+var factorBase = time.Date(1997, time.October, 7, 0, 0, 0, 0, time.Local)
+
+const (
+	factorMin   = 1000
+	factorMax   = 9999
+	factorCycle = factorMax - factorMin + 1
+)
+
+// Barcode é o conteúdo de um código de barras. Em boletos bancários, DueDate é nil
+// quando o fator é zero (sem vencimento) e Amount é zero quando o valor fica a cargo do
+// pagador. Em guias de arrecadação, ReferenceAmount indica que o valor do código é só
+// uma referência e o valor pago é informado no pagamento.
+type Barcode struct {
+	Type            string
+	Code            string
+	BankCode        string
+	DueDate         *time.Time
+	Amount          float64
+	FreeField       string
+	Segment         string
+	ReferenceAmount bool
+}
+
+// NewBankBarcode monta o código de barras de um boleto bancário. dueDate zero gera fator
+// 0000 (sem vencimento); freeField é o campo livre de 25 dígitos definido pelo banco.
+func NewBankBarcode(bankCode string, dueDate time.Time, amount float64, freeField string) (*Barcode, error) {
+	if len(bankCode) != 3 || !isDigits(bankCode) {
+		return nil, fmt.Errorf("código do banco deve ter 3 dígitos")
+	}
+	if len(freeField) != FreeFieldLength || !isDigits(freeField) {
+		return nil, fmt.Errorf("campo livre deve ter %d dígitos", FreeFieldLength)
+	}
+	cents := toCents(amount)
+	if cents < 0 || cents > maxBankAmountCents {
+		return nil, fmt.Errorf("valor fora do limite do código de barras")
+	}
+
+	factor := 0
+	if !dueDate.IsZero() {
+		var err error
+		if factor, err = DueDateFactor(dueDate); err != nil {
+			return nil, err
+		}
+	}
+
+	body := bankCode + string(currencyReal) + fmt.Sprintf("%04d%010d", factor, cents) + freeField
+	code := body[:4] + strconv.Itoa(bankCheckDigit(body)) + body[4:]
+
+	barcode := &Barcode{
+		Type:      TypeBank,
+		Code:      code,
+		BankCode:  bankCode,
+		Amount:    float64(cents) / 100,
+		FreeField: freeField,
+	}
+	if factor != 0 {
+		date := FactorDate(factor, dueDate)
+		barcode.DueDate = &date
+	}
+	return barcode, nil
+}
+
+// DigitableLine devolve a linha digitável sem formatação: 47 dígitos para boletos
+// bancários e 48 para guias de arrecadação.
+func (b *Barcode) DigitableLine() string {
+	if b.Type == TypeCollection {
+		mod := collectionModulus(b.Code[2])
+		var line strings.Builder
+		for i := 0; i < 4; i++ {
+			block := b.Code[i*11 : i*11+11]
+			line.WriteString(block)
+			line.WriteString(strconv.Itoa(mod(block)))
+		}
+		return line.String()
+	}
+
+	field1 := b.Code[0:4] + b.Code[19:24]
+	field2 := b.Code[24:34]
+	field3 := b.Code[34:44]
+	return field1 + strconv.Itoa(Mod10(field1)) +
+		field2 + strconv.Itoa(Mod10(field2)) +
+		field3 + strconv.Itoa(Mod10(field3)) +
+		b.Code[4:5] + b.Code[5:19]
+}
+
+// FormattedDigitableLine devolve a linha digitável com a pontuação impressa no boleto.
+func (b *Barcode) FormattedDigitableLine() string {
+	return FormatDigitableLine(b.DigitableLine())
+}
+
+// FormatDigitableLine pontua a linha digitável como impressa: AAAAA.AAAAA BBBBB.BBBBBB
+// CCCCC.CCCCCC D EEEEEEEEEEEEEE no boleto bancário e quatro blocos NNNNNNNNNNN-D na
+// guia de arrecadação. Linhas de outro tamanho voltam como vieram.
+func FormatDigitableLine(line string) string {
+	switch len(line) {
+	case BankLineLength:
+		return fmt.Sprintf("%s.%s %s.%s %s.%s %s %s",
+			line[0:5], line[5:10], line[10:15], line[15:21], line[21:26], line[26:32], line[32:33], line[33:47])
+	case CollectionLineLength:
+		blocks := make([]string, 4)
+		for i := range blocks {
+			blocks[i] = line[i*12:i*12+11] + "-" + line[i*12+11:i*12+12]
+		}
+		return strings.Join(blocks, " ")
+	default:
+		return line
+	}
+}
+
+// Parse interpreta um código de barras de 44 dígitos ou uma linha digitável de 47 ou 48
+// dígitos, ignorando pontos, espaços e hífens, e confere todos os dígitos
+// verificadores. O fator de vencimento é resolvido para a data mais próxima de hoje.
+func Parse(code string) (*Barcode, error) {
+	digits, err := onlyDigits(code)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(digits) {
+	case BarcodeLength:
+		if digits[0] == collectionProduct {
+			return parseCollectionBarcode(digits)
+		}
+		return parseBankBarcode(digits)
+	case BankLineLength:
+		return parseBankLine(digits)
+	case CollectionLineLength:
+		return parseCollectionLine(digits)
+	default:
+		return nil, fmt.Errorf("código deve ter 44 (código de barras), 47 ou 48 dígitos (linha digitável), recebido %d", len(digits))
+	}
+}
+
+func parseBankLine(line string) (*Barcode, error) {
+	fields := []struct {
+		start, end int
+	}{{0, 9}, {10, 20}, {21, 31}}
+	for i, field := range fields {
+		value := line[field.start:field.end]
+		if int(line[field.end]-'0') != Mod10(value) {
+			return nil, fmt.Errorf("dígito verificador do campo %d da linha digitável inválido (posição %d)", i+1, field.end+1)
+		}
+	}
+
+	code := line[0:4] + line[32:33] + line[33:47] + line[4:9] + line[10:20] + line[21:31]
+	return parseBankBarcode(code)
+}
+
+func parseBankBarcode(code string) (*Barcode, error) {
+	if code[3] != currencyReal {
+		return nil, fmt.Errorf("código de moeda %c não suportado, apenas real (9)", code[3])
+	}
+	body := code[:4] + code[5:]
+	if int(code[4]-'0') != bankCheckDigit(body) {
+		return nil, fmt.Errorf("dígito verificador geral do código de barras inválido (posição 5)")
+	}
+
+	factor, _ := strconv.Atoi(code[5:9])
+	cents, _ := strconv.ParseInt(code[9:19], 10, 64)
+
+	barcode := &Barcode{
+		Type:      TypeBank,
+		Code:      code,
+		BankCode:  code[0:3],
+		Amount:    float64(cents) / 100,
+		FreeField: code[19:44],
+	}
+	if factor != 0 {
+		if factor < factorMin {
+			return nil, fmt.Errorf("fator de vencimento %04d inválido", factor)
+		}
+		date := FactorDate(factor, time.Now())
+		barcode.DueDate = &date
+	}
+	return barcode, nil
+}
+
+func parseCollectionLine(line string) (*Barcode, error) {
+	if line[0] != collectionProduct {
+		return nil, fmt.Errorf("linha digitável de 48 dígitos deve começar com 8 (arrecadação)")
+	}
+	mod := collectionModulus(line[2])
+	if mod == nil {
+		return nil, fmt.Errorf("identificador de valor %c inválido (posição 3)", line[2])
+	}
+
+	var code strings.Builder
+	for i := 0; i < 4; i++ {
+		block := line[i*12 : i*12+11]
+		if int(line[i*12+11]-'0') != mod(block) {
+			return nil, fmt.Errorf("dígito verificador do bloco %d da linha digitável inválido (posição %d)", i+1, i*12+12)
+		}
+		code.WriteString(block)
+	}
+	return parseCollectionBarcode(code.String())
+}
+
+func parseCollectionBarcode(code string) (*Barcode, error) {
+	mod := collectionModulus(code[2])
+	if mod == nil {
+		return nil, fmt.Errorf("identificador de valor %c inválido (posição 3)", code[2])
+	}
+	if int(code[3]-'0') != mod(code[:3]+code[4:]) {
+		return nil, fmt.Errorf("dígito verificador geral do código de barras inválido (posição 4)")
+	}
+
+	cents, _ := strconv.ParseInt(code[4:15], 10, 64)
+	return &Barcode{
+		Type:            TypeCollection,
+		Code:            code,
+		Amount:          float64(cents) / 100,
+		FreeField:       code[15:44],
+		Segment:         code[1:2],
+		ReferenceAmount: code[2] == '7' || code[2] == '9',
+	}, nil
+}
+
+// NewCollectionBarcode monta o código de barras de uma guia de arrecadação com valor
+// efetivo e dígito pelo módulo 10 (identificador 6). Usado para simular guias de
+// concessionárias; o BankMore não emite guias.
+func NewCollectionBarcode(segment string, amount float64, companyAndFreeField string) (*Barcode, error) {
+	if len(segment) != 1 || !isDigits(segment) {
+		return nil, fmt.Errorf("segmento deve ter 1 dígito")
+	}
+	if len(companyAndFreeField) != 29 || !isDigits(companyAndFreeField) {
+		return nil, fmt.Errorf("identificação da empresa e campo livre devem ter 29 dígitos")
+	}
+	cents := toCents(amount)
+	if cents < 0 || cents > maxCollectionAmountCents {
+		return nil, fmt.Errorf("valor fora do limite do código de barras")
+	}
+
+	body := string(collectionProduct) + segment + "6" + fmt.Sprintf("%011d", cents) + companyAndFreeField
+	code := body[:3] + strconv.Itoa(Mod10(body)) + body[3:]
+	return parseCollectionBarcode(code)
+}
+
+// DueDateFactor devolve o fator de vencimento da data: dias desde 07/10/1997, de 1000 a
+// 9999, recomeçando em 1000 depois de 9999. Datas anteriores a 03/07/2000, o fator 1000,
+// não têm fator válido.
+func DueDateFactor(date time.Time) (int, error) {
+	days := daysBetween(factorBase, date)
+	if days < factorMin {
+		return 0, fmt.Errorf("vencimento anterior a %s não tem fator válido", factorBase.AddDate(0, 0, factorMin).Format("02/01/2006"))
+	}
+	if days <= factorMax {
+		return days, nil
+	}
+	return (days-factorMax-1)%factorCycle + factorMin, nil
+}
+
+// FactorDate devolve a data do fator mais próxima de reference, já que o mesmo fator se
+// repete a cada 9000 dias.
+func FactorDate(factor int, reference time.Time) time.Time {
+	referenceDays := daysBetween(factorBase, reference)
+	days := factor
+	for days+factorCycle/2 < referenceDays {
+		days += factorCycle
+	}
+	return factorBase.AddDate(0, 0, days)
+}
+
+// Mod10 calcula o dígito verificador pelo módulo 10 com pesos 2 e 1 a partir da direita,
+// somando os algarismos dos produtos.
+func Mod10(digits string) int {
+	sum := 0
+	weight := 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		product := int(digits[i]-'0') * weight
+		sum += product/10 + product%10
+		weight = 3 - weight
+	}
+	return (10 - sum%10) % 10
+}
+
+// Mod11 calcula o dígito verificador pelo módulo 11 com pesos de 2 a 9 a partir da
+// direita, como nas guias de arrecadação: restos 0 e 1 dão dígito 0.
+func Mod11(digits string) int {
+	remainder := mod11Sum(digits) % 11
+	if remainder <= 1 {
+		return 0
+	}
+	return 11 - remainder
+}
+
+// bankCheckDigit é o dígito geral do boleto bancário: módulo 11 sobre os 43 dígitos,
+// trocando 0, 10 e 11 por 1.
+func bankCheckDigit(body string) int {
+	digit := 11 - mod11Sum(body)%11
+	if digit == 0 || digit == 10 || digit == 11 {
+		return 1
+	}
+	return digit
+}
+
+func mod11Sum(digits string) int {
+	sum := 0
+	weight := 2
+	for i := len(digits) - 1; i >= 0; i-- {
+		sum += int(digits[i]-'0') * weight
+		weight++
+		if weight > 9 {
+			weight = 2
+		}
+	}
+	return sum
+}
+
+// collectionModulus escolhe o cálculo do dígito pelo identificador de valor: 6 e 7 usam
+// módulo 10, 8 e 9 módulo 11.
+func collectionModulus(valueID byte) func(string) int {
+	switch valueID {
+	case '6', '7':
+		return Mod10
+	case '8', '9':
+		return Mod11
+	default:
+		return nil
+	}
+}
+
+func daysBetween(from, to time.Time) int {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start).Hours() / 24)
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// onlyDigits remove a pontuação da linha digitável; qualquer outro caractere é erro.
+func onlyDigits(code string) (string, error) {
+	var digits strings.Builder
+	for i, r := range strings.TrimSpace(code) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '.' || r == ' ' || r == '-':
+		default:
+			return "", fmt.Errorf("caractere inválido %q na posição %d", r, i+1)
+		}
+	}
+	if digits.Len() == 0 {
+		return "", fmt.Errorf("código vazio")
+	}
+	return digits.String(), nil
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
@@ -0,0 +1,323 @@
+package boleto
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Boleto de exemplo do Banco do Brasil usado nas especificações de cobrança: fator 3737
+// (31/12/2007), R$ 1,00.
+const (
+	bbBarcode = "00193373700000001000500940144816060680935031"
+	bbLine    = "00190500954014481606906809350314337370000000100"
+)
+
+// Guias de arrecadação reais: a primeira com identificador de valor 6 (módulo 10), a
+// segunda com 8 (módulo 11).
+const (
+	collectionMod10Line = "846700000017435900240209024050002435842210108119"
+	collectionMod11Line = "858900004609524601791605607593050865831483000010"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// replaceDigit troca o dígito da posição (a partir de 1) por outro válido.
+func replaceDigit(code string, position int) string {
+	digit := (int(code[position-1]-'0') + 1) % 10
+	return code[:position-1] + strconv.Itoa(digit) + code[position:]
+}
+
+func TestMod10(t *testing.T) {
+	cases := []struct {
+		digits string
+		want   int
+	}{
+		// Campos da linha digitável do exemplo do Banco do Brasil.
+		{"001905009", 5},
+		{"4014481606", 9},
+		{"0680935031", 4},
+		{"0", 0},
+		{"5", 9},
+		{"8460000001435900240200240500024384221010811", 7},
+	}
+
+	for _, c := range cases {
+		if got := Mod10(c.digits); got != c.want {
+			t.Errorf("Mod10(%s) = %d, esperado %d", c.digits, got, c.want)
+		}
+	}
+}
+
+func TestMod11(t *testing.T) {
+	cases := []struct {
+		digits string
+		want   int
+	}{
+		// Blocos e corpo da guia com identificador 8.
+		{"85890000460", 9},
+		{"52460179160", 5},
+		{"83148300001", 0},
+		{"8580000460524601791606075930508683148300001", 9},
+		// Restos 0 e 1 dão dígito 0.
+		{"0", 0},
+		{"6", 0},
+		{"5", 1},
+	}
+
+	for _, c := range cases {
+		if got := Mod11(c.digits); got != c.want {
+			t.Errorf("Mod11(%s) = %d, esperado %d", c.digits, got, c.want)
+		}
+	}
+}
+
+func TestBankCheckDigit(t *testing.T) {
+	cases := []struct {
+		body string
+		want int
+	}{
+		{bbBarcode[:4] + bbBarcode[5:], 3},
+		// 0, 10 e 11 viram 1.
+		{"0", 1},
+		{"6", 1},
+		{"5", 1},
+		{"4", 3},
+	}
+
+	for _, c := range cases {
+		if got := bankCheckDigit(c.body); got != c.want {
+			t.Errorf("bankCheckDigit(%s) = %d, esperado %d", c.body, got, c.want)
+		}
+	}
+}
+
+func TestDueDateFactor(t *testing.T) {
+	cases := []struct {
+		date time.Time
+		want int
+	}{
+		{date(2000, time.July, 3), 1000},
+		{date(2000, time.July, 4), 1001},
+		{date(2007, time.December, 31), 3737},
+		{date(2025, time.February, 21), 9999},
+		// Virada do fator: recomeça em 1000 no dia seguinte ao 9999.
+		{date(2025, time.February, 22), 1000},
+		{date(2025, time.February, 23), 1001},
+		{date(2049, time.October, 13), 9999},
+		{date(2049, time.October, 14), 1000},
+	}
+
+	for _, c := range cases {
+		got, err := DueDateFactor(c.date)
+		if err != nil {
+			t.Errorf("%s: erro inesperado: %v", c.date.Format("02/01/2006"), err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%s: fator %d, esperado %d", c.date.Format("02/01/2006"), got, c.want)
+		}
+	}
+
+	for _, before := range []time.Time{date(2000, time.July, 2), date(1997, time.October, 7), date(1990, time.January, 1)} {
+		if factor, err := DueDateFactor(before); err == nil {
+			t.Errorf("%s: esperado erro, recebido fator %d", before.Format("02/01/2006"), factor)
+		}
+	}
+}
+
+func TestFactorDate(t *testing.T) {
+	cases := []struct {
+		factor    int
+		reference time.Time
+		want      time.Time
+	}{
+		{3737, date(2008, time.January, 10), date(2007, time.December, 31)},
+		{1000, date(2000, time.June, 1), date(2000, time.July, 3)},
+		{9999, date(2025, time.February, 1), date(2025, time.February, 21)},
+		// Depois da virada, o fator 1000 é o de 22/02/2025, não o de 2000.
+		{1000, date(2025, time.March, 1), date(2025, time.February, 22)},
+		{1001, date(2025, time.February, 10), date(2025, time.February, 23)},
+		// Um fator alto lido logo depois da virada ainda é do ciclo anterior.
+		{9990, date(2025, time.March, 1), date(2025, time.February, 12)},
+	}
+
+	for _, c := range cases {
+		got := FactorDate(c.factor, c.reference)
+		if !got.Equal(c.want) {
+			t.Errorf("fator %d perto de %s: %s, esperado %s", c.factor, c.reference.Format("02/01/2006"),
+				got.Format("02/01/2006"), c.want.Format("02/01/2006"))
+		}
+	}
+}
+
+func TestParseBankExample(t *testing.T) {
+	for _, code := range []string{bbBarcode, bbLine, "00190.50095 40144.816069 06809.350314 3 37370000000100"} {
+		barcode, err := Parse(code)
+		if err != nil {
+			t.Fatalf("%s: erro inesperado: %v", code, err)
+		}
+		if barcode.Type != TypeBank || barcode.Code != bbBarcode || barcode.BankCode != "001" ||
+			barcode.Amount != 1 || barcode.FreeField != "0500940144816060680935031" {
+			t.Errorf("%s: %+v", code, *barcode)
+		}
+		if barcode.DueDate == nil {
+			t.Errorf("%s: esperado vencimento", code)
+		}
+		if line := barcode.DigitableLine(); line != bbLine {
+			t.Errorf("%s: linha %s, esperada %s", code, line, bbLine)
+		}
+	}
+}
+
+func TestNewBankBarcode(t *testing.T) {
+	barcode, err := NewBankBarcode("001", date(2007, time.December, 31), 1, "0500940144816060680935031")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if barcode.Code != bbBarcode {
+		t.Errorf("código %s, esperado %s", barcode.Code, bbBarcode)
+	}
+	if barcode.FormattedDigitableLine() != "00190.50095 40144.816069 06809.350314 3 37370000000100" {
+		t.Errorf("linha formatada %s", barcode.FormattedDigitableLine())
+	}
+
+	noDueDate, err := NewBankBarcode("001", time.Time{}, 0, "0500940144816060680935031")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if noDueDate.Code[5:19] != "00000000000000" || noDueDate.DueDate != nil {
+		t.Errorf("sem vencimento e sem valor: %+v", *noDueDate)
+	}
+
+	if _, err := NewBankBarcode("001", date(2000, time.July, 2), 1, "0500940144816060680935031"); err == nil {
+		t.Error("vencimento anterior ao fator 1000: esperado erro")
+	}
+}
+
+// withValueID troca o identificador de valor de uma guia e recalcula o dígito geral,
+// devolvendo a linha digitável.
+func withValueID(code string, valueID byte) string {
+	body := code[:2] + string(valueID) + code[4:]
+	digit := collectionModulus(valueID)(body)
+	return (&Barcode{Type: TypeCollection, Code: body[:3] + strconv.Itoa(digit) + body[3:]}).DigitableLine()
+}
+
+func TestParseCollection(t *testing.T) {
+	mod10, err := Parse(collectionMod10Line)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	mod11, err := Parse(collectionMod11Line)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	// Os identificadores 7 e 9 usam o cálculo de 6 e 8, com o valor só de referência.
+	cases := []struct {
+		name      string
+		line      string
+		amount    float64
+		segment   string
+		reference bool
+	}{
+		{"identificador 6", collectionMod10Line, 143.59, "4", false},
+		{"identificador 7", withValueID(mod10.Code, '7'), 143.59, "4", true},
+		{"identificador 8", collectionMod11Line, 46052.46, "5", false},
+		{"identificador 9", withValueID(mod11.Code, '9'), 46052.46, "5", true},
+	}
+
+	for _, c := range cases {
+		barcode, err := Parse(c.line)
+		if err != nil {
+			t.Fatalf("%s: erro inesperado: %v", c.name, err)
+		}
+		if barcode.Type != TypeCollection || barcode.Amount != c.amount || barcode.Segment != c.segment || barcode.ReferenceAmount != c.reference {
+			t.Errorf("%s: %+v", c.name, *barcode)
+		}
+		if line := barcode.DigitableLine(); line != c.line {
+			t.Errorf("%s: linha %s, esperada %s", c.name, line, c.line)
+		}
+
+		fromBarcode, err := Parse(barcode.Code)
+		if err != nil || fromBarcode.Code != barcode.Code {
+			t.Errorf("%s: código de barras %s não confere: %v", c.name, barcode.Code, err)
+		}
+	}
+}
+
+func TestNewCollectionBarcode(t *testing.T) {
+	barcode, err := NewCollectionBarcode("4", 143.59, "00240200240500024384221010811")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if line := barcode.DigitableLine(); line != collectionMod10Line {
+		t.Errorf("linha %s, esperada %s", line, collectionMod10Line)
+	}
+}
+
+// TestParseRejectsCheckDigits troca um dígito verificador de cada campo e confere que o
+// erro aponta o campo certo.
+func TestParseRejectsCheckDigits(t *testing.T) {
+	// Para o dígito geral, o código adulterado é convertido em linha com os dígitos dos
+	// campos recalculados, para que só o dígito geral esteja errado.
+	badBankGeneral := (&Barcode{Type: TypeBank, Code: replaceDigit(bbBarcode, 5)}).DigitableLine()
+	collection, err := Parse(collectionMod11Line)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	badCollectionGeneral := (&Barcode{Type: TypeCollection, Code: replaceDigit(collection.Code, 4)}).DigitableLine()
+
+	cases := []struct {
+		name string
+		code string
+		want string
+	}{
+		{"código de barras bancário", replaceDigit(bbBarcode, 5), "geral"},
+		{"campo 1", replaceDigit(bbLine, 10), "campo 1"},
+		{"campo 2", replaceDigit(bbLine, 21), "campo 2"},
+		{"campo 3", replaceDigit(bbLine, 32), "campo 3"},
+		{"dígito geral na linha", badBankGeneral, "geral"},
+		{"bloco 1", replaceDigit(collectionMod11Line, 12), "bloco 1"},
+		{"bloco 2", replaceDigit(collectionMod11Line, 24), "bloco 2"},
+		{"bloco 3", replaceDigit(collectionMod10Line, 36), "bloco 3"},
+		{"bloco 4", replaceDigit(collectionMod10Line, 48), "bloco 4"},
+		{"dígito geral da guia", badCollectionGeneral, "geral"},
+		{"código de barras da guia", replaceDigit(collection.Code, 4), "geral"},
+	}
+
+	for _, c := range cases {
+		_, err := Parse(c.code)
+		if err == nil {
+			t.Errorf("%s: esperado erro para %s", c.name, c.code)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: erro %q não aponta %q", c.name, err, c.want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	lowFactor := bbBarcode[:4] + "0999" + bbBarcode[9:]
+	lowFactor = lowFactor[:4] + strconv.Itoa(bankCheckDigit(lowFactor)) + lowFactor[4:]
+
+	cases := map[string]string{
+		"vazio":                  "",
+		"tamanho":                bbBarcode[:43],
+		"caractere inválido":     strings.Replace(bbLine, "0", "O", 1),
+		"moeda":                  "0018" + bbBarcode[4:],
+		"fator abaixo de 1000":   lowFactor,
+		"identificador inválido": "845" + collectionMod10Line[3:],
+		"linha de 48 sem 8":      "7" + collectionMod10Line[1:],
+	}
+
+	for name, code := range cases {
+		if _, err := Parse(code); err == nil {
+			t.Errorf("%s: esperado erro para %s", name, code)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Package brcode gera e interpreta payloads EMV-MPM no padrão BR Code usado pelo Pix.
+package brcode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	idPayloadFormat       = "00"
+	idPointOfInitiation   = "01"
+	idMerchantAccount     = "26"
+	idMerchantCategory    = "52"
+	idTransactionCurrency = "53"
+	idTransactionAmount   = "54"
+	idCountryCode         = "58"
+	idMerchantName        = "59"
+	idMerchantCity        = "60"
+	idAdditionalData      = "62"
+	idCRC                 = "63"
+
+	idMerchantGUI         = "00"
+	idMerchantKey         = "01"
+	idMerchantDescription = "02"
+	idAdditionalTxID      = "05"
+)
+
+const (
+	pixGUI            = "br.gov.bcb.pix"
+	payloadFormat     = "01"
+	staticInitiation  = "11"
+	dynamicInitiation = "12"
+	currencyBRL       = "986"
+	countryBrazil     = "BR"
+	categoryDefault   = "0000"
+
+	// StaticTxID é o identificador usado quando a cobrança estática não tem txid.
+	StaticTxID = "***"
+
+	maxMerchantName = 25
+	maxMerchantCity = 15
+	maxTxID         = 25
+)
+
+// Payload representa os campos do BR Code usados pelo BankMore. Dynamic indica
+// cobrança de uso único (ponto de iniciação 12), conciliada pelo TxID.
+type Payload struct {
+	Key          string
+	Description  string
+	MerchantName string
+	MerchantCity string
+	Amount       float64
+	TxID         string
+	Dynamic      bool
+}
+
+// Encode monta o payload com o CRC16 ao final.
+func (p Payload) Encode() (string, error) {
+	if p.Key == "" {
+		return "", fmt.Errorf("chave é obrigatória")
+	}
+	if p.Amount < 0 {
+		return "", fmt.Errorf("valor não pode ser negativo")
+	}
+
+	txID := p.TxID
+	if txID == "" {
+		txID = StaticTxID
+	}
+	if len(txID) > maxTxID {
+		return "", fmt.Errorf("txid deve ter no máximo %d caracteres", maxTxID)
+	}
+
+	merchantAccount := field(idMerchantGUI, pixGUI) + field(idMerchantKey, p.Key)
+	if p.Description != "" {
+		merchantAccount += field(idMerchantDescription, p.Description)
+	}
+	if len(merchantAccount) > 99 {
+		return "", fmt.Errorf("chave e descrição excedem o tamanho permitido")
+	}
+
+	initiation := staticInitiation
+	if p.Dynamic {
+		initiation = dynamicInitiation
+	}
+
+	var builder strings.Builder
+	builder.WriteString(field(idPayloadFormat, payloadFormat))
+	builder.WriteString(field(idPointOfInitiation, initiation))
+	builder.WriteString(field(idMerchantAccount, merchantAccount))
+	builder.WriteString(field(idMerchantCategory, categoryDefault))
+	builder.WriteString(field(idTransactionCurrency, currencyBRL))
+	if p.Amount > 0 {
+		builder.WriteString(field(idTransactionAmount, strconv.FormatFloat(p.Amount, 'f', 2, 64)))
+	}
+	builder.WriteString(field(idCountryCode, countryBrazil))
+	builder.WriteString(field(idMerchantName, sanitize(p.MerchantName, maxMerchantName)))
+	builder.WriteString(field(idMerchantCity, sanitize(p.MerchantCity, maxMerchantCity)))
+	builder.WriteString(field(idAdditionalData, field(idAdditionalTxID, txID)))
+	builder.WriteString(idCRC + "04")
+
+	payload := builder.String()
+	return payload + CRC16(payload), nil
+}
+
+// Parse valida a estrutura TLV e o CRC do payload e extrai os campos conhecidos.
+func Parse(code string) (*Payload, error) {
+	code = strings.TrimSpace(code)
+	if len(code) < 8 {
+		return nil, fmt.Errorf("payload inválido")
+	}
+
+	body, checksum := code[:len(code)-4], code[len(code)-4:]
+	if !strings.HasSuffix(body, idCRC+"04") {
+		return nil, fmt.Errorf("payload sem CRC")
+	}
+	if !strings.EqualFold(CRC16(body), checksum) {
+		return nil, fmt.Errorf("CRC do payload inválido")
+	}
+
+	fields, err := parseFields(body[:len(body)-4])
+	if err != nil {
+		return nil, err
+	}
+
+	if fields[idPayloadFormat] != payloadFormat {
+		return nil, fmt.Errorf("formato de payload não suportado")
+	}
+	if fields[idTransactionCurrency] != currencyBRL {
+		return nil, fmt.Errorf("moeda não suportada")
+	}
+	if fields[idCountryCode] != countryBrazil {
+		return nil, fmt.Errorf("país não suportado")
+	}
+
+	merchantAccount, err := parseFields(fields[idMerchantAccount])
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(merchantAccount[idMerchantGUI], pixGUI) || merchantAccount[idMerchantKey] == "" {
+		return nil, fmt.Errorf("payload não contém chave Pix")
+	}
+
+	payload := &Payload{
+		Key:          merchantAccount[idMerchantKey],
+		Description:  merchantAccount[idMerchantDescription],
+		MerchantName: fields[idMerchantName],
+		MerchantCity: fields[idMerchantCity],
+		Dynamic:      fields[idPointOfInitiation] == dynamicInitiation,
+	}
+
+	if amount := fields[idTransactionAmount]; amount != "" {
+		payload.Amount, err = strconv.ParseFloat(amount, 64)
+		if err != nil || payload.Amount <= 0 {
+			return nil, fmt.Errorf("valor do payload inválido")
+		}
+	}
+
+	if additional := fields[idAdditionalData]; additional != "" {
+		additionalFields, err := parseFields(additional)
+		if err != nil {
+			return nil, err
+		}
+		if txID := additionalFields[idAdditionalTxID]; txID != StaticTxID {
+			payload.TxID = txID
+		}
+	}
+
+	return payload, nil
+}
+
+// CRC16 calcula o CRC-16/CCITT-FALSE (polinômio 0x1021, valor inicial 0xFFFF)
+// exigido pelo BR Code, em quatro dígitos hexadecimais maiúsculos.
+func CRC16(data string) string {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return fmt.Sprintf("%04X", crc)
+}
+
+func field(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+func parseFields(data string) (map[string]string, error) {
+	fields := make(map[string]string)
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("payload inválido")
+		}
+		size, err := strconv.Atoi(data[2:4])
+		if err != nil || len(data) < 4+size {
+			return nil, fmt.Errorf("payload inválido")
+		}
+		fields[data[:2]] = data[4 : 4+size]
+		data = data[4+size:]
+	}
+	return fields, nil
+}
+
+// sanitize remove acentos e caracteres fora do ASCII, como exigem os leitores de BR Code.
+func sanitize(value string, maxLength int) string {
+	var builder strings.Builder
+	for _, r := range norm.NFD.String(value) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if r > unicode.MaxASCII {
+			continue
+		}
+		builder.WriteRune(unicode.ToUpper(r))
+	}
+
+	result := strings.TrimSpace(builder.String())
+	if len(result) > maxLength {
+		result = strings.TrimSpace(result[:maxLength])
+	}
+	return result
+}
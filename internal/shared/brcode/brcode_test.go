@@ -0,0 +1,178 @@
+package brcode
+
+import (
+	"strings"
+	"testing"
+)
+
+// bcbExample é o BR Code estático de exemplo do Manual de Padrões para Iniciação do Pix
+// do Banco Central, com CRC 1D3D.
+const bcbExample = "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D"
+
+// withCRC completa um payload montado à mão com o campo 63 e o CRC correto, para que a
+// validação chegue à estrutura TLV.
+func withCRC(body string) string {
+	body += idCRC + "04"
+	return body + CRC16(body)
+}
+
+func TestCRC16(t *testing.T) {
+	cases := []struct {
+		data string
+		want string
+	}{
+		// Valor de conferência do CRC-16/CCITT-FALSE.
+		{"123456789", "29B1"},
+		{bcbExample[:len(bcbExample)-4], "1D3D"},
+		{"", "FFFF"},
+	}
+
+	for _, c := range cases {
+		if got := CRC16(c.data); got != c.want {
+			t.Errorf("CRC16(%q) = %s, esperado %s", c.data, got, c.want)
+		}
+	}
+}
+
+func TestParseBCBExample(t *testing.T) {
+	payload, err := Parse(bcbExample)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	want := Payload{
+		Key:          "123e4567-e12b-12d1-a456-426655440000",
+		MerchantName: "Fulano de Tal",
+		MerchantCity: "BRASILIA",
+	}
+	if *payload != want {
+		t.Errorf("payload %+v, esperado %+v", *payload, want)
+	}
+}
+
+func TestParseAcceptsLowercaseCRC(t *testing.T) {
+	code := bcbExample[:len(bcbExample)-4] + "1d3d"
+	if _, err := Parse(code); err != nil {
+		t.Errorf("erro inesperado: %v", err)
+	}
+}
+
+func TestEncodeParseRoundTrip(t *testing.T) {
+	cases := []Payload{
+		{Key: "fulano@example.com", MerchantName: "FULANO DE TAL", MerchantCity: "SAO PAULO"},
+		{Key: "+5511999990000", Description: "Aluguel", MerchantName: "FULANO DE TAL", MerchantCity: "SAO PAULO", Amount: 1234.5},
+		{Key: "12345678909", MerchantName: "FULANO DE TAL", MerchantCity: "SAO PAULO", Amount: 10, TxID: "ABC123DEF456GHI789JKL0123", Dynamic: true},
+	}
+
+	for _, want := range cases {
+		code, err := want.Encode()
+		if err != nil {
+			t.Fatalf("%+v: erro inesperado: %v", want, err)
+		}
+
+		got, err := Parse(code)
+		if err != nil {
+			t.Fatalf("%s: erro inesperado: %v", code, err)
+		}
+		if *got != want {
+			t.Errorf("%s: payload %+v, esperado %+v", code, *got, want)
+		}
+	}
+}
+
+func TestEncodeStaticAndDynamic(t *testing.T) {
+	static, err := Payload{Key: "12345678909", MerchantName: "FULANO", MerchantCity: "BRASILIA"}.Encode()
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if !strings.HasPrefix(static, "000201010211") {
+		t.Errorf("%s: esperado ponto de iniciação 11", static)
+	}
+	if !strings.Contains(static, "62070503***") {
+		t.Errorf("%s: esperado txid ***", static)
+	}
+	if strings.Contains(static, idTransactionAmount+"0") {
+		t.Errorf("%s: QR Code sem valor não deve ter o campo 54", static)
+	}
+
+	dynamic, err := Payload{Key: "12345678909", MerchantName: "FULANO", MerchantCity: "BRASILIA", Amount: 0.5, TxID: "TX1", Dynamic: true}.Encode()
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if !strings.HasPrefix(dynamic, "000201010212") {
+		t.Errorf("%s: esperado ponto de iniciação 12", dynamic)
+	}
+	if !strings.Contains(dynamic, "54040.50") {
+		t.Errorf("%s: esperado valor com duas casas", dynamic)
+	}
+
+	for _, code := range []string{static, dynamic} {
+		body := code[:len(code)-4]
+		if !strings.HasSuffix(body, "6304") || code[len(code)-4:] != CRC16(body) {
+			t.Errorf("%s: CRC ausente ou incorreto", code)
+		}
+	}
+}
+
+func TestEncodeSanitizesMerchant(t *testing.T) {
+	code, err := Payload{Key: "12345678909", MerchantName: "José da Conceição Pereira Júnior", MerchantCity: "São João del-Rei"}.Encode()
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	payload, err := Parse(code)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if payload.MerchantName != "JOSE DA CONCEICAO PEREIRA" {
+		t.Errorf("nome %q", payload.MerchantName)
+	}
+	if payload.MerchantCity != "SAO JOAO DEL-RE" {
+		t.Errorf("cidade %q", payload.MerchantCity)
+	}
+}
+
+func TestEncodeRejects(t *testing.T) {
+	cases := map[string]Payload{
+		"sem chave":      {MerchantName: "FULANO", MerchantCity: "BRASILIA"},
+		"valor negativo": {Key: "12345678909", Amount: -1},
+		"txid longo":     {Key: "12345678909", TxID: strings.Repeat("A", 26)},
+		"conta longa":    {Key: "12345678909", Description: strings.Repeat("A", 80)},
+	}
+
+	for name, payload := range cases {
+		if _, err := payload.Encode(); err == nil {
+			t.Errorf("%s: esperado erro", name)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	merchant := "26330014br.gov.bcb.pix0111123456789095204000053039865802BR5906FULANO6008BRASILIA62070503***"
+
+	cases := map[string]string{
+		"CRC errado":           bcbExample[:len(bcbExample)-4] + "1D3E",
+		"sem CRC":              bcbExample[:len(bcbExample)-8] + "1D3D",
+		"curto":                "0002",
+		"TLV truncado":         withCRC("000201" + "2699" + "0014br.gov.bcb.pix"),
+		"TLV sem tamanho":      withCRC("000201" + "52"),
+		"tamanho não numérico": withCRC("000201" + "52XX0000"),
+		"subcampo truncado":    withCRC("000201" + "26200014br.gov.bcb.pix01" + "5303986" + "5802BR"),
+		"formato errado":       withCRC("000202" + merchant),
+		"moeda errada":         withCRC("000201" + strings.Replace(merchant, "5303986", "5303840", 1)),
+		"país errado":          withCRC("000201" + strings.Replace(merchant, "5802BR", "5802US", 1)),
+		"sem chave Pix":        withCRC("000201" + strings.Replace(merchant, "br.gov.bcb.pix", "br.gov.bcb.xyz", 1)),
+		"valor inválido":       withCRC("000201" + strings.Replace(merchant, "5802BR", "54030.05802BR", 1)),
+		"valor não numérico":   withCRC("000201" + strings.Replace(merchant, "5802BR", "5403abc5802BR", 1)),
+	}
+
+	if _, err := Parse(withCRC("000201" + merchant)); err != nil {
+		t.Fatalf("payload base inválido: %v", err)
+	}
+
+	for name, code := range cases {
+		if _, err := Parse(code); err == nil {
+			t.Errorf("%s: esperado erro para %s", name, code)
+		}
+	}
+}
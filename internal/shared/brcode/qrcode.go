@@ -0,0 +1,15 @@
+package brcode
+
+import (
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const DefaultQRCodeSize = 256
+
+// RenderPNG desenha o payload como QR Code PNG com tamanho em pixels.
+func RenderPNG(payload string, size int) ([]byte, error) {
+	if size <= 0 {
+		size = DefaultQRCodeSize
+	}
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
@@ -0,0 +1,139 @@
+package calendar
+
+import (
+	"os"
+	"sort"
+	"time"
+)
+
+// defaultCutoff é o horário de corte do dia contábil quando EOD_CUTOFF não é informado.
+const defaultCutoff = 18 * time.Hour
+
+// Holiday é um feriado bancário nacional.
+type Holiday struct {
+	Date time.Time `json:"date"`
+	Name string    `json:"name"`
+}
+
+// Holidays devolve os dias sem expediente bancário em todo o país no ano: os feriados
+// nacionais, a segunda e a terça de Carnaval e Corpus Christi, que seguem a Páscoa. O
+// Dia da Consciência Negra é feriado nacional a partir de 2024.
+func Holidays(year int) []Holiday {
+	easter := easterSunday(year)
+	holidays := []Holiday{
+		{date(year, time.January, 1), "Confraternização Universal"},
+		{easter.AddDate(0, 0, -48), "Carnaval"},
+		{easter.AddDate(0, 0, -47), "Carnaval"},
+		{easter.AddDate(0, 0, -2), "Sexta-feira Santa"},
+		{date(year, time.April, 21), "Tiradentes"},
+		{date(year, time.May, 1), "Dia do Trabalho"},
+		{easter.AddDate(0, 0, 60), "Corpus Christi"},
+		{date(year, time.September, 7), "Independência do Brasil"},
+		{date(year, time.October, 12), "Nossa Senhora Aparecida"},
+		{date(year, time.November, 2), "Finados"},
+		{date(year, time.November, 15), "Proclamação da República"},
+		{date(year, time.December, 25), "Natal"},
+	}
+	if year >= 2024 {
+		holidays = append(holidays, Holiday{date(year, time.November, 20), "Dia Nacional de Zumbi e da Consciência Negra"})
+	}
+
+	sort.Slice(holidays, func(i, j int) bool {
+		return holidays[i].Date.Before(holidays[j].Date)
+	})
+	return holidays
+}
+
+// IsHoliday indica se o dia de t (no fuso do servidor) é feriado bancário nacional.
+func IsHoliday(t time.Time) bool {
+	day := StartOfDay(t)
+	for _, holiday := range Holidays(day.Year()) {
+		if holiday.Date.Equal(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBusinessDay indica se o dia de t é dia útil: de segunda a sexta, fora dos feriados.
+func IsBusinessDay(t time.Time) bool {
+	weekday := t.In(time.Local).Weekday()
+	if weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+	return !IsHoliday(t)
+}
+
+// NextBusinessDay devolve o início do primeiro dia útil depois do dia de t.
+func NextBusinessDay(t time.Time) time.Time {
+	day := StartOfDay(t).AddDate(0, 0, 1)
+	for !IsBusinessDay(day) {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+// AdjustToBusinessDay mantém t se for dia útil; senão, leva o mesmo horário para o
+// próximo dia útil.
+func AdjustToBusinessDay(t time.Time) time.Time {
+	if IsBusinessDay(t) {
+		return t
+	}
+	local := t.In(time.Local)
+	next := NextBusinessDay(local)
+	return time.Date(next.Year(), next.Month(), next.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.Local)
+}
+
+// AccountingDate devolve a data contábil de um lançamento feito em t: o próprio dia se
+// for útil e t for anterior ao corte; senão, o próximo dia útil.
+func AccountingDate(t time.Time, cutoff time.Duration) time.Time {
+	day := StartOfDay(t)
+	if IsBusinessDay(day) && t.Before(day.Add(cutoff)) {
+		return day
+	}
+	return NextBusinessDay(day)
+}
+
+// StartOfDay devolve o início do dia de t no fuso do servidor.
+func StartOfDay(t time.Time) time.Time {
+	local := t.In(time.Local)
+	return date(local.Year(), local.Month(), local.Day())
+}
+
+// GetCutoff lê EOD_CUTOFF no formato HH:MM (padrão 18:00): lançamentos a partir desse
+// horário pertencem ao próximo dia útil.
+func GetCutoff() time.Duration {
+	value := os.Getenv("EOD_CUTOFF")
+	if value == "" {
+		return defaultCutoff
+	}
+
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return defaultCutoff
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// easterSunday calcula o domingo de Páscoa pelo algoritmo anônimo do calendário gregoriano.
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return date(year, time.Month(month), day)
+}
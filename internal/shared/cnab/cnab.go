@@ -0,0 +1,239 @@
+// Package cnab lê e escreve arquivos de remessa e retorno de pagamentos nos layouts
+// CNAB 240 (FEBRABAN, segmentos A, B e J) e CNAB 400 (layout do BankMore). Os dois
+// layouts são convertidos no mesmo File; erros de campo apontam a linha e a posição
+// (1 é o primeiro caractere da linha).
+package cnab
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	Layout240 = "240"
+	Layout400 = "400"
+)
+
+const (
+	KindRemittance = "REMESSA"
+	KindReturn     = "RETORNO"
+)
+
+const (
+	PaymentTransfer = "TRANSFER"
+	PaymentBoleto   = "BOLETO"
+)
+
+// Formas de lançamento (nota G014 da FEBRABAN) aceitas nos lotes e nos detalhes do
+// CNAB 400: crédito em conta e liquidação de títulos do próprio banco ou de outros.
+const (
+	MethodAccountCredit = "01"
+	MethodOwnBoleto     = "30"
+	MethodOtherBoleto   = "31"
+)
+
+// Ocorrências do retorno (subconjunto da nota G059 da FEBRABAN). OccurrenceOther é do
+// BankMore: o motivo fica no relatório da remessa.
+const (
+	OccurrencePaid              = "00"
+	OccurrenceInsufficientFunds = "01"
+	OccurrenceCancelled         = "02"
+	OccurrenceInvalidAccount    = "AN"
+	OccurrenceInvalidAmount     = "AR"
+	OccurrenceAccepted          = "BD"
+	OccurrenceInvalidBarcode    = "CA"
+	OccurrenceOther             = "ZZ"
+)
+
+var occurrenceDescriptions = map[string]string{
+	OccurrencePaid:              "Crédito ou débito efetivado",
+	OccurrenceInsufficientFunds: "Insuficiência de fundos",
+	OccurrenceCancelled:         "Crédito ou débito cancelado",
+	OccurrenceInvalidAccount:    "Conta do favorecido inválida",
+	OccurrenceInvalidAmount:     "Valor do lançamento inválido",
+	OccurrenceAccepted:          "Inclusão efetuada com sucesso",
+	OccurrenceInvalidBarcode:    "Código de barras inválido ou título não pagável",
+	OccurrenceOther:             "Recusado; consulte o motivo na remessa",
+}
+
+// OccurrenceDescription devolve a descrição do código de ocorrência.
+func OccurrenceDescription(code string) string {
+	return occurrenceDescriptions[code]
+}
+
+// Nomes dos campos usados nos erros e em FieldPosition.
+const (
+	FieldRecord             = "registro"
+	FieldRecordType         = "tipo_registro"
+	FieldBank               = "banco"
+	FieldDocument           = "numero_inscricao"
+	FieldAccount            = "conta"
+	FieldFileSequence       = "sequencial_arquivo"
+	FieldGeneratedAt        = "data_geracao"
+	FieldKind               = "codigo_remessa"
+	FieldBatch              = "lote"
+	FieldMethod             = "forma_lancamento"
+	FieldSequence           = "sequencial_registro"
+	FieldSegment            = "segmento"
+	FieldYourNumber         = "seu_numero"
+	FieldDestinationBank    = "banco_favorecido"
+	FieldDestinationAccount = "conta_favorecido"
+	FieldBarcode            = "codigo_barras"
+	FieldDueDate            = "data_vencimento"
+	FieldPaymentDate        = "data_pagamento"
+	FieldAmount             = "valor_pagamento"
+	FieldRecordCount        = "quantidade_registros"
+	FieldBatchCount         = "quantidade_lotes"
+	FieldTotalAmount        = "valor_total"
+)
+
+// FieldError é um erro de validação de um campo do arquivo.
+type FieldError struct {
+	Line     int    `json:"line"`
+	Position int    `json:"position"`
+	Field    string `json:"field"`
+	Message  string `json:"message"`
+}
+
+func (e FieldError) String() string {
+	return fmt.Sprintf("linha %d, posição %d (%s): %s", e.Line, e.Position, e.Field, e.Message)
+}
+
+// ValidationError reúne os erros de campo de um arquivo; um arquivo com erros é recusado
+// por inteiro.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%d erro(s) no arquivo CNAB", len(e.Errors))
+}
+
+func (e *ValidationError) Add(line, position int, field, message string) {
+	e.Errors = append(e.Errors, FieldError{Line: line, Position: position, Field: field, Message: message})
+}
+
+// File é um arquivo de remessa ou retorno, independente do layout.
+type File struct {
+	Layout          string
+	Kind            string
+	BankCode        string
+	CompanyDocument string
+	CompanyName     string
+	AccountNumber   string
+	Sequence        int
+	GeneratedAt     time.Time
+	Payments        []Payment
+}
+
+// Payment é um registro de pagamento: uma transferência (segmento A ou forma 01) ou um
+// boleto (segmento J ou formas 30 e 31). Nosso número, efetivação e ocorrências só são
+// preenchidos no retorno.
+type Payment struct {
+	Line               int
+	Type               string
+	YourNumber         string
+	OurNumber          string
+	DestinationBank    string
+	DestinationAccount string
+	DestinationName    string
+	Barcode            string
+	DueDate            *time.Time
+	PaymentDate        time.Time
+	Amount             float64
+	Description        string
+	EffectiveDate      *time.Time
+	EffectiveAmount    float64
+	Occurrences        []string
+}
+
+// Parse lê um arquivo CNAB 240 ou 400; o layout é identificado pelo tamanho da primeira
+// linha. Linhas podem terminar em CRLF ou LF.
+func Parse(content []byte) (*File, error) {
+	lines := splitLines(content)
+	if len(lines) == 0 {
+		errs := &ValidationError{}
+		errs.Add(1, 1, FieldRecord, "arquivo vazio")
+		return nil, errs
+	}
+
+	switch len(lines[0]) {
+	case 240:
+		return parse240(lines)
+	case 400:
+		return parse400(lines)
+	default:
+		errs := &ValidationError{}
+		errs.Add(1, 1, FieldRecord, fmt.Sprintf("registro com %d posições; esperado 240 ou 400", len(lines[0])))
+		return nil, errs
+	}
+}
+
+// Write escreve o arquivo no layout de file.Layout, com linhas terminadas em CRLF.
+func Write(file *File) ([]byte, error) {
+	switch file.Layout {
+	case Layout240:
+		return write240(file), nil
+	case Layout400:
+		return write400(file), nil
+	default:
+		return nil, fmt.Errorf("layout CNAB %q não suportado", file.Layout)
+	}
+}
+
+// FieldPosition devolve a posição inicial do campo no layout; paymentType é vazio para os
+// campos do header de arquivo. Devolve 1 para campos desconhecidos.
+func FieldPosition(layout, paymentType, field string) int {
+	var positions map[string]int
+	switch {
+	case layout == Layout240 && paymentType == "":
+		positions = header240Positions
+	case layout == Layout240 && paymentType == PaymentTransfer:
+		positions = segmentAPositions
+	case layout == Layout240 && paymentType == PaymentBoleto:
+		positions = segmentJPositions
+	case layout == Layout400 && paymentType == "":
+		positions = header400Positions
+	case layout == Layout400:
+		positions = detail400Positions
+	}
+	if position, ok := positions[field]; ok {
+		return position
+	}
+	return 1
+}
+
+// TotalAmount soma os valores dos pagamentos em centavos, para não acumular erro de ponto
+// flutuante.
+func (f *File) TotalAmount() float64 {
+	var cents int64
+	for _, payment := range f.Payments {
+		cents += toCents(payment.Amount)
+	}
+	return float64(cents) / 100
+}
+
+func splitLines(content []byte) []string {
+	content = bytes.TrimRight(content, "\r\n\x1a")
+	if len(content) == 0 {
+		return nil
+	}
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+func joinLines(lines []string) []byte {
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+func toCents(amount float64) int64 {
+	if amount < 0 {
+		return int64(amount*100 - 0.5)
+	}
+	return int64(amount*100 + 0.5)
+}
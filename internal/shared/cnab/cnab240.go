@@ -0,0 +1,491 @@
+package cnab
+
+import (
+	"fmt"
+	"time"
+)
+
+// Posições do CNAB 240 (FEBRABAN): header de arquivo, segmento A (crédito em conta) e
+// segmento J (liquidação de títulos).
+var (
+	header240Positions = map[string]int{
+		FieldBank:         1,
+		FieldDocument:     19,
+		FieldAccount:      59,
+		FieldKind:         143,
+		FieldGeneratedAt:  144,
+		FieldFileSequence: 158,
+	}
+	segmentAPositions = map[string]int{
+		FieldSequence:           9,
+		FieldDestinationBank:    21,
+		FieldDestinationAccount: 30,
+		FieldYourNumber:         74,
+		FieldPaymentDate:        94,
+		FieldAmount:             120,
+	}
+	segmentJPositions = map[string]int{
+		FieldSequence:    9,
+		FieldBarcode:     18,
+		FieldDueDate:     92,
+		FieldPaymentDate: 145,
+		FieldAmount:      153,
+		FieldYourNumber:  183,
+	}
+)
+
+const (
+	fileLayoutVersion240      = "103"
+	transferBatchVersion240   = "045"
+	boletoBatchVersion240     = "040"
+	paymentServiceType240     = "20"
+	fileHeaderBatch240        = "0000"
+	fileTrailerBatch240       = "9999"
+	maxOccurrences240         = 5
+	occurrencesStart240       = 231
+	occurrencesEnd240         = 240
+	recordTypeFileHeader      = '0'
+	recordTypeBatchHeader     = '1'
+	recordTypeDetail          = '3'
+	recordTypeBatchTrailer    = '5'
+	recordTypeFileTrailer     = '9'
+	remittanceCode            = "1"
+	returnCode                = "2"
+	segmentTransfer           = 'A'
+	segmentTransferComplement = 'B'
+	segmentBoleto             = 'J'
+)
+
+type batch240 struct {
+	number  int
+	method  string
+	records int
+	cents   int64
+	details int
+}
+
+func parse240(lines []string) (*File, error) {
+	errs := &ValidationError{}
+	file := &File{Layout: Layout240}
+
+	var current *batch240
+	batches := 0
+	trailerSeen := false
+
+	for i, line := range lines {
+		number := i + 1
+		if len(line) != 240 {
+			errs.Add(number, 1, FieldRecord, fmt.Sprintf("registro com %d posições; esperado 240", len(line)))
+			continue
+		}
+		if trailerSeen {
+			errs.Add(number, 1, FieldRecord, "registro depois do trailer de arquivo")
+			continue
+		}
+
+		r := &recordReader{line: line, lineNumber: number, errs: errs}
+		recordType := line[7]
+		if number == 1 && recordType != recordTypeFileHeader {
+			r.fail(8, FieldRecordType, "o arquivo deve começar pelo header de arquivo (tipo 0)")
+			continue
+		}
+
+		switch recordType {
+		case recordTypeFileHeader:
+			if number != 1 {
+				r.fail(8, FieldRecordType, "header de arquivo fora da primeira linha")
+				continue
+			}
+			parseFileHeader240(r, file)
+
+		case recordTypeBatchHeader:
+			if current != nil {
+				r.fail(8, FieldRecordType, "header de lote antes do trailer do lote %d", current.number)
+			}
+			current = parseBatchHeader240(r)
+
+		case recordTypeDetail:
+			if current == nil {
+				r.fail(8, FieldRecordType, "detalhe fora de lote")
+				continue
+			}
+			current.records++
+			if batchNumber, ok := r.number(FieldBatch, 4, 7); ok && batchNumber != current.number {
+				r.fail(4, FieldBatch, "lote %d difere do header de lote (%d)", batchNumber, current.number)
+			}
+			if sequence, ok := r.number(FieldSequence, 9, 13); ok && sequence != current.records {
+				r.fail(9, FieldSequence, "sequencial %d fora de ordem; esperado %d", sequence, current.records)
+			}
+			if payment, ok := parseDetail240(r, current); ok {
+				current.cents += toCents(payment.Amount)
+				current.details++
+				file.Payments = append(file.Payments, *payment)
+			}
+
+		case recordTypeBatchTrailer:
+			if current == nil {
+				r.fail(8, FieldRecordType, "trailer de lote sem header de lote")
+				continue
+			}
+			parseBatchTrailer240(r, current)
+			if current.details == 0 {
+				r.fail(8, FieldRecordType, "lote %d sem detalhes", current.number)
+			}
+			batches++
+			current = nil
+
+		case recordTypeFileTrailer:
+			if current != nil {
+				r.fail(8, FieldRecordType, "trailer de arquivo antes do trailer do lote %d", current.number)
+			}
+			r.expect(FieldBatch, 4, 7, fileTrailerBatch240)
+			if count, ok := r.number(FieldBatchCount, 18, 23); ok && count != batches {
+				r.fail(18, FieldBatchCount, "quantidade de lotes %d; o arquivo tem %d", count, batches)
+			}
+			if count, ok := r.number(FieldRecordCount, 24, 29); ok && count != len(lines) {
+				r.fail(24, FieldRecordCount, "quantidade de registros %d; o arquivo tem %d", count, len(lines))
+			}
+			trailerSeen = true
+
+		default:
+			r.fail(8, FieldRecordType, "tipo de registro %q inválido", recordType)
+		}
+	}
+
+	if !trailerSeen {
+		errs.Add(len(lines), 1, FieldRecord, "arquivo sem trailer de arquivo (tipo 9)")
+	}
+	if len(errs.Errors) == 0 && len(file.Payments) == 0 {
+		errs.Add(1, 1, FieldRecord, "arquivo sem pagamentos")
+	}
+	if len(errs.Errors) > 0 {
+		return nil, errs
+	}
+	return file, nil
+}
+
+func parseFileHeader240(r *recordReader, file *File) {
+	r.expect(FieldBatch, 4, 7, fileHeaderBatch240)
+	if bank, ok := r.digits(FieldBank, 1, 3); ok {
+		file.BankCode = bank
+	}
+	if document, ok := r.digits(FieldDocument, 19, 32); ok {
+		file.CompanyDocument = trimDocument(r.raw(18, 18), document)
+	}
+	if account, ok := r.digits(FieldAccount, 59, 70); ok {
+		file.AccountNumber = trimLeadingZeros(account)
+	}
+	file.CompanyName = r.alpha(73, 102)
+
+	switch r.raw(143, 143) {
+	case remittanceCode:
+		file.Kind = KindRemittance
+	case returnCode:
+		file.Kind = KindReturn
+	default:
+		r.fail(143, FieldKind, "código %q inválido; use 1 (remessa) ou 2 (retorno)", r.raw(143, 143))
+	}
+
+	if generatedAt, ok := r.date(FieldGeneratedAt, 144, 151, dateLayout240, false); ok {
+		file.GeneratedAt = *generatedAt
+	}
+	if sequence, ok := r.number(FieldFileSequence, 158, 163); ok {
+		if sequence == 0 {
+			r.fail(158, FieldFileSequence, "sequencial do arquivo deve ser maior que zero")
+		}
+		file.Sequence = sequence
+	}
+}
+
+func parseBatchHeader240(r *recordReader) *batch240 {
+	current := &batch240{}
+	if batchNumber, ok := r.number(FieldBatch, 4, 7); ok {
+		current.number = batchNumber
+	}
+
+	current.method = r.raw(12, 13)
+	switch current.method {
+	case MethodAccountCredit, MethodOwnBoleto, MethodOtherBoleto:
+	default:
+		r.fail(12, FieldMethod, "forma de lançamento %q não suportada; use 01, 30 ou 31", current.method)
+	}
+	return current
+}
+
+func parseDetail240(r *recordReader, current *batch240) (*Payment, bool) {
+	segment := r.line[13]
+	switch segment {
+	case segmentTransfer:
+		if current.method != MethodAccountCredit {
+			r.fail(14, FieldSegment, "segmento A em lote de forma %s", current.method)
+			return nil, false
+		}
+		return parseSegmentA(r)
+	case segmentBoleto:
+		if current.method == MethodAccountCredit {
+			r.fail(14, FieldSegment, "segmento J em lote de crédito em conta")
+			return nil, false
+		}
+		return parseSegmentJ(r)
+	case segmentTransferComplement:
+		// O segmento B traz endereço e inscrição do favorecido, que não são usados em
+		// créditos entre contas do mesmo banco.
+		return nil, false
+	default:
+		r.fail(14, FieldSegment, "segmento %q não suportado; use A, B ou J", segment)
+		return nil, false
+	}
+}
+
+func parseSegmentA(r *recordReader) (*Payment, bool) {
+	before := len(r.errs.Errors)
+	payment := &Payment{Line: r.lineNumber, Type: PaymentTransfer}
+
+	payment.DestinationBank, _ = r.digits(FieldDestinationBank, 21, 23)
+	if account, ok := r.digits(FieldDestinationAccount, 30, 41); ok {
+		payment.DestinationAccount = trimLeadingZeros(account)
+	}
+	payment.DestinationName = r.alpha(44, 73)
+	payment.YourNumber = r.alpha(74, 93)
+	if date, ok := r.date(FieldPaymentDate, 94, 101, dateLayout240, false); ok && date != nil {
+		payment.PaymentDate = *date
+	}
+	payment.Amount, _ = r.amount(FieldAmount, 120, 134)
+	payment.OurNumber = r.alpha(135, 154)
+	payment.EffectiveDate, _ = r.date("data_efetivacao", 155, 162, dateLayout240, true)
+	payment.EffectiveAmount, _ = r.amount("valor_efetivacao", 163, 177)
+	payment.Description = r.alpha(178, 217)
+	payment.Occurrences = splitOccurrences(r.raw(occurrencesStart240, occurrencesEnd240))
+
+	return payment, len(r.errs.Errors) == before
+}
+
+func parseSegmentJ(r *recordReader) (*Payment, bool) {
+	before := len(r.errs.Errors)
+	payment := &Payment{Line: r.lineNumber, Type: PaymentBoleto}
+
+	payment.Barcode, _ = r.digits(FieldBarcode, 18, 61)
+	payment.DestinationName = r.alpha(62, 91)
+	payment.DueDate, _ = r.date(FieldDueDate, 92, 99, dateLayout240, true)
+	if date, ok := r.date(FieldPaymentDate, 145, 152, dateLayout240, false); ok && date != nil {
+		payment.PaymentDate = *date
+	}
+	payment.Amount, _ = r.amount(FieldAmount, 153, 167)
+	payment.YourNumber = r.alpha(183, 202)
+	payment.OurNumber = r.alpha(203, 222)
+	payment.Occurrences = splitOccurrences(r.raw(occurrencesStart240, occurrencesEnd240))
+
+	return payment, len(r.errs.Errors) == before
+}
+
+// parseBatchTrailer240 confere a quantidade de registros (header e trailer incluídos) e
+// a soma dos valores do lote.
+func parseBatchTrailer240(r *recordReader, current *batch240) {
+	if batchNumber, ok := r.number(FieldBatch, 4, 7); ok && batchNumber != current.number {
+		r.fail(4, FieldBatch, "lote %d difere do header de lote (%d)", batchNumber, current.number)
+	}
+	if count, ok := r.number(FieldRecordCount, 18, 23); ok && count != current.records+2 {
+		r.fail(18, FieldRecordCount, "quantidade de registros %d; o lote tem %d", count, current.records+2)
+	}
+	if total, ok := r.amount(FieldTotalAmount, 24, 41); ok && toCents(total) != current.cents {
+		r.fail(24, FieldTotalAmount, "somatório %.2f difere da soma dos detalhes (%.2f)", total, float64(current.cents)/100)
+	}
+}
+
+func write240(file *File) []byte {
+	var lines []string
+	lines = append(lines, fileHeader240(file))
+
+	groups := []struct {
+		method   string
+		payments []Payment
+	}{
+		{method: MethodAccountCredit},
+		{method: MethodOwnBoleto},
+		{method: MethodOtherBoleto},
+	}
+	for _, payment := range file.Payments {
+		switch {
+		case payment.Type == PaymentTransfer:
+			groups[0].payments = append(groups[0].payments, payment)
+		case len(payment.Barcode) >= 3 && payment.Barcode[:3] == file.BankCode:
+			groups[1].payments = append(groups[1].payments, payment)
+		default:
+			groups[2].payments = append(groups[2].payments, payment)
+		}
+	}
+
+	batchNumber := 0
+	for _, group := range groups {
+		if len(group.payments) == 0 {
+			continue
+		}
+		batchNumber++
+		lines = append(lines, batchHeader240(file, batchNumber, group.method))
+
+		var cents int64
+		for i, payment := range group.payments {
+			if payment.Type == PaymentTransfer {
+				lines = append(lines, segmentA240(file, batchNumber, i+1, payment))
+			} else {
+				lines = append(lines, segmentJ240(file, batchNumber, i+1, payment))
+			}
+			cents += toCents(payment.Amount)
+		}
+
+		w := newRecordWriter(240)
+		w.digits(1, 3, file.BankCode)
+		w.number(4, 7, int64(batchNumber))
+		w.digits(8, 8, string(recordTypeBatchTrailer))
+		w.number(18, 23, int64(len(group.payments)+2))
+		w.number(24, 41, cents)
+		w.number(42, 59, 0)
+		w.number(60, 65, 0)
+		lines = append(lines, w.String())
+	}
+
+	w := newRecordWriter(240)
+	w.digits(1, 3, file.BankCode)
+	w.digits(4, 7, fileTrailerBatch240)
+	w.digits(8, 8, string(recordTypeFileTrailer))
+	w.number(18, 23, int64(batchNumber))
+	w.number(24, 29, int64(len(lines)+1))
+	w.number(30, 35, 0)
+	lines = append(lines, w.String())
+
+	return joinLines(lines)
+}
+
+func fileHeader240(file *File) string {
+	w := newRecordWriter(240)
+	w.digits(1, 3, file.BankCode)
+	w.digits(4, 7, fileHeaderBatch240)
+	w.digits(8, 8, string(recordTypeFileHeader))
+	w.digits(18, 18, documentType(file.CompanyDocument))
+	w.digits(19, 32, file.CompanyDocument)
+	w.digits(53, 58, "")
+	w.digits(59, 70, file.AccountNumber)
+	w.alpha(73, 102, file.CompanyName)
+	w.alpha(103, 132, "BANKMORE")
+	w.digits(143, 143, kindCode(file.Kind))
+	generatedAt := file.GeneratedAt
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+	w.date(144, 151, &generatedAt, dateLayout240)
+	w.digits(152, 157, generatedAt.Format("150405"))
+	w.number(158, 163, int64(file.Sequence))
+	w.digits(164, 166, fileLayoutVersion240)
+	w.number(167, 171, 0)
+	return w.String()
+}
+
+func batchHeader240(file *File, batchNumber int, method string) string {
+	version := boletoBatchVersion240
+	if method == MethodAccountCredit {
+		version = transferBatchVersion240
+	}
+
+	w := newRecordWriter(240)
+	w.digits(1, 3, file.BankCode)
+	w.number(4, 7, int64(batchNumber))
+	w.digits(8, 8, string(recordTypeBatchHeader))
+	w.alpha(9, 9, "C")
+	w.digits(10, 11, paymentServiceType240)
+	w.digits(12, 13, method)
+	w.digits(14, 16, version)
+	w.digits(18, 18, documentType(file.CompanyDocument))
+	w.digits(19, 32, file.CompanyDocument)
+	w.digits(53, 58, "")
+	w.digits(59, 70, file.AccountNumber)
+	w.alpha(73, 102, file.CompanyName)
+	return w.String()
+}
+
+func segmentA240(file *File, batchNumber, sequence int, payment Payment) string {
+	w := newRecordWriter(240)
+	w.digits(1, 3, file.BankCode)
+	w.number(4, 7, int64(batchNumber))
+	w.digits(8, 8, string(recordTypeDetail))
+	w.number(9, 13, int64(sequence))
+	w.alpha(14, 14, string(segmentTransfer))
+	w.digits(15, 17, "")
+	w.digits(18, 20, "")
+	w.digits(21, 23, payment.DestinationBank)
+	w.digits(24, 29, "")
+	w.digits(30, 41, payment.DestinationAccount)
+	w.alpha(44, 73, payment.DestinationName)
+	w.alpha(74, 93, payment.YourNumber)
+	w.date(94, 101, &payment.PaymentDate, dateLayout240)
+	w.alpha(102, 104, "BRL")
+	w.number(105, 119, 0)
+	w.amount(120, 134, payment.Amount)
+	w.alpha(135, 154, payment.OurNumber)
+	w.date(155, 162, payment.EffectiveDate, dateLayout240)
+	w.amount(163, 177, payment.EffectiveAmount)
+	w.alpha(178, 217, payment.Description)
+	w.occurrences(occurrencesStart240, occurrencesEnd240, limitOccurrences(payment.Occurrences, maxOccurrences240))
+	return w.String()
+}
+
+func segmentJ240(file *File, batchNumber, sequence int, payment Payment) string {
+	w := newRecordWriter(240)
+	w.digits(1, 3, file.BankCode)
+	w.number(4, 7, int64(batchNumber))
+	w.digits(8, 8, string(recordTypeDetail))
+	w.number(9, 13, int64(sequence))
+	w.alpha(14, 14, string(segmentBoleto))
+	w.digits(15, 17, "")
+	w.digits(18, 61, payment.Barcode)
+	w.alpha(62, 91, payment.DestinationName)
+	w.date(92, 99, payment.DueDate, dateLayout240)
+	w.amount(100, 114, payment.Amount)
+	w.number(115, 129, 0)
+	w.number(130, 144, 0)
+	w.date(145, 152, &payment.PaymentDate, dateLayout240)
+	w.amount(153, 167, payment.Amount)
+	w.number(168, 182, 0)
+	w.alpha(183, 202, payment.YourNumber)
+	w.alpha(203, 222, payment.OurNumber)
+	w.digits(223, 224, "09")
+	w.occurrences(occurrencesStart240, occurrencesEnd240, limitOccurrences(payment.Occurrences, maxOccurrences240))
+	return w.String()
+}
+
+func kindCode(kind string) string {
+	if kind == KindReturn {
+		return returnCode
+	}
+	return remittanceCode
+}
+
+// documentType é o tipo de inscrição da empresa: 1 para CPF e 2 para CNPJ.
+func documentType(document string) string {
+	if len(document) == 14 {
+		return "2"
+	}
+	return "1"
+}
+
+// trimDocument tira os zeros à esquerda do campo de 14 posições quando a inscrição é um
+// CPF.
+func trimDocument(documentType, document string) string {
+	if documentType == "1" && len(document) > 11 {
+		return document[len(document)-11:]
+	}
+	return document
+}
+
+func trimLeadingZeros(value string) string {
+	for len(value) > 1 && value[0] == '0' {
+		value = value[1:]
+	}
+	return value
+}
+
+func limitOccurrences(codes []string, max int) []string {
+	if len(codes) > max {
+		return codes[:max]
+	}
+	return codes
+}
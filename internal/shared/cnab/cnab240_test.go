@@ -0,0 +1,82 @@
+package cnab
+
+import (
+	"testing"
+	"time"
+)
+
+// remessa240.rem: header de arquivo, lote 1 de crédito em conta (A, B, A), lote 2 de
+// boletos de outros bancos (J) e trailer de arquivo, em 10 linhas.
+func TestParse240Sample(t *testing.T) {
+	file, err := Parse(joinLines(readSample(t, "remessa240.rem")))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if file.Layout != Layout240 || file.Kind != KindRemittance || file.BankCode != "999" ||
+		file.CompanyDocument != "12345678000195" || file.CompanyName != "ACME COMERCIO LTDA" ||
+		file.AccountNumber != "123456" || file.Sequence != 42 || !file.GeneratedAt.Equal(day(2025, time.March, 15)) {
+		t.Errorf("header %+v", *file)
+	}
+
+	if len(file.Payments) != 3 {
+		t.Fatalf("%d pagamentos, esperados 3", len(file.Payments))
+	}
+
+	first := file.Payments[0]
+	if first.Line != 3 || first.Type != PaymentTransfer || first.DestinationBank != "999" || first.DestinationAccount != "7654321" ||
+		first.DestinationName != "MARIA DA SILVA" || first.YourNumber != "FOLHA-0001" || first.Amount != 2500 ||
+		first.Description != "SALARIO MARCO" || !first.PaymentDate.Equal(day(2025, time.March, 17)) ||
+		first.EffectiveDate != nil || len(first.Occurrences) != 0 {
+		t.Errorf("segmento A %+v", first)
+	}
+
+	if second := file.Payments[1]; second.Line != 5 || second.DestinationAccount != "1002" || second.Amount != 1234.56 {
+		t.Errorf("segmento A depois do B %+v", second)
+	}
+
+	boleto := file.Payments[2]
+	if boleto.Line != 8 || boleto.Type != PaymentBoleto || boleto.Barcode != "00193373700000001000500940144816060680935031" ||
+		boleto.Amount != 1 || boleto.YourNumber != "BOLETO-0001" || boleto.DueDate == nil || !boleto.DueDate.Equal(day(2007, time.December, 31)) {
+		t.Errorf("segmento J %+v", boleto)
+	}
+
+	if total := file.TotalAmount(); total != 3735.56 {
+		t.Errorf("total %.2f, esperado 3735.56", total)
+	}
+}
+
+func TestParse240Malformed(t *testing.T) {
+	lines := readSample(t, "remessa240.rem")
+
+	cases := []struct {
+		name     string
+		content  []byte
+		line     int
+		position int
+		field    string
+	}{
+		{"linha curta", withLine(lines, 3, func(l string) string { return l[:239] }), 3, 1, FieldRecord},
+		{"linha longa", withLine(lines, 3, func(l string) string { return l + " " }), 3, 1, FieldRecord},
+		{"tipo de registro inválido", withLine(lines, 3, func(l string) string { return replaceAt(l, 8, "7") }), 3, 8, FieldRecordType},
+		{"começa sem header", withLine(lines, 1, func(l string) string { return replaceAt(l, 8, "1") }), 1, 8, FieldRecordType},
+		{"detalhe fora de lote", withLine(lines, 2, func(l string) string { return replaceAt(l, 8, "5") }), 2, 8, FieldRecordType},
+		{"código de remessa", withLine(lines, 1, func(l string) string { return replaceAt(l, 143, "3") }), 1, 143, FieldKind},
+		{"forma de lançamento", withLine(lines, 7, func(l string) string { return replaceAt(l, 12, "41") }), 7, 12, FieldMethod},
+		{"sequencial fora de ordem", withLine(lines, 5, func(l string) string { return replaceAt(l, 9, "00004") }), 5, 9, FieldSequence},
+		{"lote do detalhe", withLine(lines, 3, func(l string) string { return replaceAt(l, 4, "0002") }), 3, 4, FieldBatch},
+		{"segmento A em lote de boletos", withLine(lines, 8, func(l string) string { return replaceAt(l, 14, "A") }), 8, 14, FieldSegment},
+		{"valor não numérico", withLine(lines, 3, func(l string) string { return replaceAt(l, 125, "X") }), 3, 125, FieldAmount},
+		{"data inválida", withLine(lines, 3, func(l string) string { return replaceAt(l, 94, "32032025") }), 3, 94, FieldPaymentDate},
+		{"registros do lote", withLine(lines, 6, func(l string) string { return replaceAt(l, 18, "000004") }), 6, 18, FieldRecordCount},
+		{"somatório do lote", withLine(lines, 6, func(l string) string { return replaceAt(l, 24, "000000000000373457") }), 6, 24, FieldTotalAmount},
+		{"lotes do trailer", withLine(lines, 10, func(l string) string { return replaceAt(l, 18, "000003") }), 10, 18, FieldBatchCount},
+		{"registros do trailer", withLine(lines, 10, func(l string) string { return replaceAt(l, 24, "000011") }), 10, 24, FieldRecordCount},
+		{"sem trailer de arquivo", joinLines(lines[:9]), 9, 1, FieldRecord},
+		{"registro depois do trailer", joinLines(append(append([]string(nil), lines...), lines[9])), 11, 1, FieldRecord},
+	}
+
+	for _, c := range cases {
+		expectFieldError(t, c.name, c.content, c.line, c.position, c.field)
+	}
+}
@@ -0,0 +1,252 @@
+package cnab
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// O CNAB 400 não tem layout FEBRABAN para pagamentos; cada banco define o seu. O do
+// BankMore tem header (tipo 0), um detalhe (tipo 1) por pagamento e trailer (tipo 9),
+// com o sequencial do registro nas posições 395 a 400. O tipo de inscrição da empresa
+// (1 para CPF, 2 para CNPJ) fica na posição 26 do header.
+var (
+	header400Positions = map[string]int{
+		FieldKind:         2,
+		FieldDocument:     27,
+		FieldAccount:      41,
+		FieldBank:         83,
+		FieldGeneratedAt:  101,
+		FieldFileSequence: 107,
+	}
+	detail400Positions = map[string]int{
+		FieldMethod:             2,
+		FieldYourNumber:         4,
+		FieldDestinationBank:    29,
+		FieldDestinationAccount: 32,
+		FieldBarcode:            74,
+		FieldDueDate:            118,
+		FieldPaymentDate:        124,
+		FieldAmount:             130,
+		FieldSequence:           395,
+	}
+)
+
+const (
+	paymentServiceCode400 = "20"
+	paymentServiceName400 = "PAGAMENTOS"
+	maxOccurrences400     = 5
+	occurrencesStart400   = 385
+	occurrencesEnd400     = 394
+	recordTypeDetail400   = '1'
+)
+
+func parse400(lines []string) (*File, error) {
+	errs := &ValidationError{}
+	file := &File{Layout: Layout400}
+
+	var cents int64
+	trailerSeen := false
+
+	for i, line := range lines {
+		number := i + 1
+		if len(line) != 400 {
+			errs.Add(number, 1, FieldRecord, fmt.Sprintf("registro com %d posições; esperado 400", len(line)))
+			continue
+		}
+		if trailerSeen {
+			errs.Add(number, 1, FieldRecord, "registro depois do trailer")
+			continue
+		}
+
+		r := &recordReader{line: line, lineNumber: number, errs: errs}
+		if sequence, ok := r.number(FieldSequence, 395, 400); ok && sequence != number {
+			r.fail(395, FieldSequence, "sequencial %d difere da linha %d", sequence, number)
+		}
+
+		recordType := line[0]
+		if number == 1 && recordType != recordTypeFileHeader {
+			r.fail(1, FieldRecordType, "o arquivo deve começar pelo header (tipo 0)")
+			continue
+		}
+
+		switch recordType {
+		case recordTypeFileHeader:
+			if number != 1 {
+				r.fail(1, FieldRecordType, "header fora da primeira linha")
+				continue
+			}
+			parseHeader400(r, file)
+
+		case recordTypeDetail400:
+			if payment, ok := parseDetail400(r); ok {
+				cents += toCents(payment.Amount)
+				file.Payments = append(file.Payments, *payment)
+			}
+
+		case recordTypeFileTrailer:
+			if count, ok := r.number(FieldRecordCount, 2, 7); ok && count != len(lines)-2 {
+				r.fail(2, FieldRecordCount, "quantidade de detalhes %d; o arquivo tem %d", count, len(lines)-2)
+			}
+			if total, ok := r.amount(FieldTotalAmount, 8, 24); ok && toCents(total) != cents {
+				r.fail(8, FieldTotalAmount, "valor total %.2f difere da soma dos detalhes (%.2f)", total, float64(cents)/100)
+			}
+			trailerSeen = true
+
+		default:
+			r.fail(1, FieldRecordType, "tipo de registro %q inválido", recordType)
+		}
+	}
+
+	if !trailerSeen {
+		errs.Add(len(lines), 1, FieldRecord, "arquivo sem trailer (tipo 9)")
+	}
+	if len(errs.Errors) == 0 && len(file.Payments) == 0 {
+		errs.Add(1, 1, FieldRecord, "arquivo sem pagamentos")
+	}
+	if len(errs.Errors) > 0 {
+		return nil, errs
+	}
+	return file, nil
+}
+
+func parseHeader400(r *recordReader, file *File) {
+	switch r.raw(2, 2) {
+	case remittanceCode:
+		file.Kind = KindRemittance
+		r.expect(FieldKind, 3, 9, KindRemittance)
+	case returnCode:
+		file.Kind = KindReturn
+		r.expect(FieldKind, 3, 9, KindReturn)
+	default:
+		r.fail(2, FieldKind, "código %q inválido; use 1 (remessa) ou 2 (retorno)", r.raw(2, 2))
+	}
+	r.expect("codigo_servico", 10, 11, paymentServiceCode400)
+
+	if document, ok := r.digits(FieldDocument, 27, 40); ok {
+		file.CompanyDocument = trimDocument(r.raw(26, 26), document)
+	}
+	if account, ok := r.digits(FieldAccount, 41, 52); ok {
+		file.AccountNumber = trimLeadingZeros(account)
+	}
+	file.CompanyName = r.alpha(53, 82)
+	if bank, ok := r.digits(FieldBank, 83, 85); ok {
+		file.BankCode = bank
+	}
+	if generatedAt, ok := r.date(FieldGeneratedAt, 101, 106, dateLayout400, false); ok {
+		file.GeneratedAt = *generatedAt
+	}
+	if sequence, ok := r.number(FieldFileSequence, 107, 113); ok {
+		if sequence == 0 {
+			r.fail(107, FieldFileSequence, "sequencial do arquivo deve ser maior que zero")
+		}
+		file.Sequence = sequence
+	}
+}
+
+func parseDetail400(r *recordReader) (*Payment, bool) {
+	before := len(r.errs.Errors)
+	payment := &Payment{Line: r.lineNumber}
+
+	switch method := r.raw(2, 3); method {
+	case MethodAccountCredit:
+		payment.Type = PaymentTransfer
+		payment.DestinationBank, _ = r.digits(FieldDestinationBank, 29, 31)
+		if account, ok := r.digits(FieldDestinationAccount, 32, 43); ok {
+			payment.DestinationAccount = trimLeadingZeros(account)
+		}
+	case MethodOwnBoleto, MethodOtherBoleto:
+		payment.Type = PaymentBoleto
+		payment.Barcode, _ = r.digits(FieldBarcode, 74, 117)
+		payment.DueDate, _ = r.date(FieldDueDate, 118, 123, dateLayout400, true)
+	default:
+		r.fail(2, FieldMethod, "forma de lançamento %q não suportada; use 01, 30 ou 31", method)
+		return nil, false
+	}
+
+	payment.YourNumber = r.alpha(4, 28)
+	payment.DestinationName = r.alpha(44, 73)
+	if date, ok := r.date(FieldPaymentDate, 124, 129, dateLayout400, false); ok && date != nil {
+		payment.PaymentDate = *date
+	}
+	payment.Amount, _ = r.amount(FieldAmount, 130, 142)
+	payment.Description = r.alpha(143, 182)
+	payment.OurNumber = r.alpha(183, 202)
+	payment.EffectiveDate, _ = r.date("data_efetivacao", 203, 208, dateLayout400, true)
+	payment.EffectiveAmount, _ = r.amount("valor_efetivacao", 209, 221)
+	payment.Occurrences = splitOccurrences(r.raw(occurrencesStart400, occurrencesEnd400))
+
+	return payment, len(r.errs.Errors) == before
+}
+
+func write400(file *File) []byte {
+	lines := make([]string, 0, len(file.Payments)+2)
+
+	w := newRecordWriter(400)
+	w.digits(1, 1, string(recordTypeFileHeader))
+	w.digits(2, 2, kindCode(file.Kind))
+	w.alpha(3, 9, kindName(file.Kind))
+	w.digits(10, 11, paymentServiceCode400)
+	w.alpha(12, 25, paymentServiceName400)
+	w.digits(26, 26, documentType(file.CompanyDocument))
+	w.digits(27, 40, file.CompanyDocument)
+	w.digits(41, 52, file.AccountNumber)
+	w.alpha(53, 82, file.CompanyName)
+	w.digits(83, 85, file.BankCode)
+	w.alpha(86, 100, "BANKMORE")
+	generatedAt := file.GeneratedAt
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+	w.date(101, 106, &generatedAt, dateLayout400)
+	w.number(107, 113, int64(file.Sequence))
+	w.number(395, 400, 1)
+	lines = append(lines, w.String())
+
+	var cents int64
+	for _, payment := range file.Payments {
+		w := newRecordWriter(400)
+		w.digits(1, 1, string(recordTypeDetail400))
+		w.alpha(4, 28, payment.YourNumber)
+		if payment.Type == PaymentTransfer {
+			w.digits(2, 3, MethodAccountCredit)
+			w.digits(29, 31, payment.DestinationBank)
+			w.digits(32, 43, payment.DestinationAccount)
+		} else {
+			method := MethodOtherBoleto
+			if strings.HasPrefix(payment.Barcode, file.BankCode) {
+				method = MethodOwnBoleto
+			}
+			w.digits(2, 3, method)
+			w.digits(74, 117, payment.Barcode)
+			w.date(118, 123, payment.DueDate, dateLayout400)
+		}
+		w.alpha(44, 73, payment.DestinationName)
+		w.date(124, 129, &payment.PaymentDate, dateLayout400)
+		w.amount(130, 142, payment.Amount)
+		w.alpha(143, 182, payment.Description)
+		w.alpha(183, 202, payment.OurNumber)
+		w.date(203, 208, payment.EffectiveDate, dateLayout400)
+		w.amount(209, 221, payment.EffectiveAmount)
+		w.occurrences(occurrencesStart400, occurrencesEnd400, limitOccurrences(payment.Occurrences, maxOccurrences400))
+		w.number(395, 400, int64(len(lines)+1))
+		lines = append(lines, w.String())
+		cents += toCents(payment.Amount)
+	}
+
+	w = newRecordWriter(400)
+	w.digits(1, 1, string(recordTypeFileTrailer))
+	w.number(2, 7, int64(len(file.Payments)))
+	w.number(8, 24, cents)
+	w.number(395, 400, int64(len(lines)+1))
+	lines = append(lines, w.String())
+
+	return joinLines(lines)
+}
+
+func kindName(kind string) string {
+	if kind == KindReturn {
+		return KindReturn
+	}
+	return KindRemittance
+}
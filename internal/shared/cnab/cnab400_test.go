@@ -0,0 +1,69 @@
+package cnab
+
+import (
+	"testing"
+	"time"
+)
+
+// remessa400.rem: header, um crédito em conta, um boleto de outro banco e trailer.
+func TestParse400Sample(t *testing.T) {
+	file, err := Parse(joinLines(readSample(t, "remessa400.rem")))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if file.Layout != Layout400 || file.Kind != KindRemittance || file.BankCode != "999" ||
+		file.CompanyDocument != "12345678909" || file.CompanyName != "JOSE DOS SANTOS" ||
+		file.AccountNumber != "123456" || file.Sequence != 7 || !file.GeneratedAt.Equal(day(2025, time.March, 15)) {
+		t.Errorf("header %+v", *file)
+	}
+
+	if len(file.Payments) != 2 {
+		t.Fatalf("%d pagamentos, esperados 2", len(file.Payments))
+	}
+
+	transfer := file.Payments[0]
+	if transfer.Line != 2 || transfer.Type != PaymentTransfer || transfer.DestinationBank != "999" ||
+		transfer.DestinationAccount != "7654321" || transfer.YourNumber != "ALUGUEL-03" || transfer.Amount != 1800 ||
+		transfer.Description != "ALUGUEL MARCO" || !transfer.PaymentDate.Equal(day(2025, time.March, 17)) {
+		t.Errorf("crédito em conta %+v", transfer)
+	}
+
+	boleto := file.Payments[1]
+	if boleto.Line != 3 || boleto.Type != PaymentBoleto || boleto.Barcode != "00193373700000001000500940144816060680935031" ||
+		boleto.Amount != 1 || boleto.DueDate == nil || !boleto.DueDate.Equal(day(2007, time.December, 31)) {
+		t.Errorf("boleto %+v", boleto)
+	}
+
+	if total := file.TotalAmount(); total != 1801 {
+		t.Errorf("total %.2f, esperado 1801.00", total)
+	}
+}
+
+func TestParse400Malformed(t *testing.T) {
+	lines := readSample(t, "remessa400.rem")
+
+	cases := []struct {
+		name     string
+		content  []byte
+		line     int
+		position int
+		field    string
+	}{
+		{"linha curta", withLine(lines, 2, func(l string) string { return l[:399] }), 2, 1, FieldRecord},
+		{"tipo de registro inválido", withLine(lines, 2, func(l string) string { return replaceAt(l, 1, "5") }), 2, 1, FieldRecordType},
+		{"começa sem header", withLine(lines, 1, func(l string) string { return replaceAt(l, 1, "1") }), 1, 1, FieldRecordType},
+		{"header repetido", withLine(lines, 2, func(l string) string { return replaceAt(l, 1, "0") }), 2, 1, FieldRecordType},
+		{"código de remessa", withLine(lines, 1, func(l string) string { return replaceAt(l, 2, "3") }), 1, 2, FieldKind},
+		{"forma de lançamento", withLine(lines, 3, func(l string) string { return replaceAt(l, 2, "41") }), 3, 2, FieldMethod},
+		{"conta não numérica", withLine(lines, 2, func(l string) string { return replaceAt(l, 40, "X") }), 2, 40, FieldDestinationAccount},
+		{"sequencial", withLine(lines, 3, func(l string) string { return replaceAt(l, 395, "000004") }), 3, 395, FieldSequence},
+		{"detalhes do trailer", withLine(lines, 4, func(l string) string { return replaceAt(l, 2, "000003") }), 4, 2, FieldRecordCount},
+		{"total do trailer", withLine(lines, 4, func(l string) string { return replaceAt(l, 8, "00000000000180101") }), 4, 8, FieldTotalAmount},
+		{"sem trailer", joinLines(lines[:3]), 3, 1, FieldRecord},
+	}
+
+	for _, c := range cases {
+		expectFieldError(t, c.name, c.content, c.line, c.position, c.field)
+	}
+}
@@ -0,0 +1,126 @@
+package cnab
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readSample lê um arquivo de testdata e devolve as linhas, sem o CRLF.
+func readSample(t *testing.T, name string) []string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("erro ao ler %s: %v", name, err)
+	}
+	return splitLines(content)
+}
+
+// replaceAt troca o conteúdo da linha a partir da posição (a partir de 1).
+func replaceAt(line string, position int, value string) string {
+	return line[:position-1] + value + line[position-1+len(value):]
+}
+
+// withLine devolve o arquivo com a linha number (a partir de 1) trocada por change(linha).
+func withLine(lines []string, number int, change func(string) string) []byte {
+	changed := append([]string(nil), lines...)
+	changed[number-1] = change(changed[number-1])
+	return joinLines(changed)
+}
+
+// expectFieldError confere que Parse recusa o arquivo apontando a linha, a posição e o
+// campo esperados.
+func expectFieldError(t *testing.T, name string, content []byte, line, position int, field string) {
+	t.Helper()
+
+	file, err := Parse(content)
+	if err == nil {
+		t.Errorf("%s: esperado erro, arquivo aceito com %d pagamentos", name, len(file.Payments))
+		return
+	}
+
+	var validation *ValidationError
+	if !errors.As(err, &validation) {
+		t.Errorf("%s: erro %v não é de validação", name, err)
+		return
+	}
+	for _, fieldErr := range validation.Errors {
+		if fieldErr.Line == line && fieldErr.Position == position && fieldErr.Field == field {
+			return
+		}
+	}
+	t.Errorf("%s: esperado erro na linha %d, posição %d (%s), recebido %v", name, line, position, field, validation.Errors)
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.Local)
+}
+
+func TestParseRejectsUnknownLayout(t *testing.T) {
+	expectFieldError(t, "vazio", nil, 1, 1, FieldRecord)
+	expectFieldError(t, "300 posições", []byte(strings.Repeat("0", 300)+"\r\n"), 1, 1, FieldRecord)
+}
+
+// TestReturnRoundTrip gera o retorno de cada remessa de exemplo como GenerateReturn faz
+// e confere que a leitura devolve o mesmo arquivo, com as ocorrências e a efetivação.
+func TestReturnRoundTrip(t *testing.T) {
+	for _, sample := range []string{"remessa240.rem", "remessa400.rem"} {
+		remittance, err := Parse(joinLines(readSample(t, sample)))
+		if err != nil {
+			t.Fatalf("%s: erro inesperado: %v", sample, err)
+		}
+
+		returned := *remittance
+		returned.Kind = KindReturn
+		returned.GeneratedAt = day(2025, time.March, 18)
+		returned.Payments = append([]Payment(nil), remittance.Payments...)
+		effectiveDate := day(2025, time.March, 17)
+		for i := range returned.Payments {
+			payment := &returned.Payments[i]
+			payment.OurNumber = "BM" + strings.Repeat("0", 5) + string(rune('1'+i))
+			payment.Occurrences = []string{OccurrencePaid}
+			if i == len(returned.Payments)-1 {
+				payment.Occurrences = []string{OccurrenceInsufficientFunds, OccurrenceOther}
+				continue
+			}
+			payment.EffectiveDate = &effectiveDate
+			payment.EffectiveAmount = payment.Amount
+		}
+
+		content, err := Write(&returned)
+		if err != nil {
+			t.Fatalf("%s: erro inesperado: %v", sample, err)
+		}
+		if !strings.HasSuffix(string(content), "\r\n") {
+			t.Errorf("%s: retorno sem CRLF no fim", sample)
+		}
+
+		parsed, err := Parse(content)
+		if err != nil {
+			t.Fatalf("%s: retorno gerado não é lido: %v", sample, err)
+		}
+		if parsed.Kind != KindReturn {
+			t.Errorf("%s: tipo %s, esperado %s", sample, parsed.Kind, KindReturn)
+		}
+
+		// As linhas mudam: o retorno não repete o segmento B.
+		for i := range parsed.Payments {
+			parsed.Payments[i].Line = 0
+			returned.Payments[i].Line = 0
+		}
+		if !reflect.DeepEqual(*parsed, returned) {
+			t.Errorf("%s: retorno lido\n%+v\nesperado\n%+v", sample, *parsed, returned)
+		}
+
+		// Gerar de novo a partir do retorno lido dá o mesmo arquivo.
+		again, err := Write(parsed)
+		if err != nil || string(again) != string(content) {
+			t.Errorf("%s: segunda geração difere da primeira (%v)", sample, err)
+		}
+	}
+}
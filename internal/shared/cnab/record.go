@@ -0,0 +1,175 @@
+package cnab
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Formatos de data: CNAB 240 usa DDMMAAAA e CNAB 400, DDMMAA.
+const (
+	dateLayout240 = "02012006"
+	dateLayout400 = "020106"
+)
+
+// recordReader lê os campos de uma linha pelas posições do layout (inclusive, a partir
+// de 1) e acumula os erros em errs.
+type recordReader struct {
+	line       string
+	lineNumber int
+	errs       *ValidationError
+}
+
+func (r *recordReader) fail(position int, field, format string, args ...interface{}) {
+	r.errs.Add(r.lineNumber, position, field, fmt.Sprintf(format, args...))
+}
+
+func (r *recordReader) raw(start, end int) string {
+	return r.line[start-1 : end]
+}
+
+func (r *recordReader) alpha(start, end int) string {
+	return strings.TrimSpace(r.raw(start, end))
+}
+
+// digits exige um campo só de dígitos e aponta o primeiro caractere inválido.
+func (r *recordReader) digits(field string, start, end int) (string, bool) {
+	value := r.raw(start, end)
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			r.fail(start+i, field, "campo numérico com caractere inválido %q", value[i])
+			return "", false
+		}
+	}
+	return value, true
+}
+
+func (r *recordReader) number(field string, start, end int) (int, bool) {
+	value, ok := r.digits(field, start, end)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		r.fail(start, field, "número inválido")
+		return 0, false
+	}
+	return n, true
+}
+
+func (r *recordReader) amount(field string, start, end int) (float64, bool) {
+	value, ok := r.digits(field, start, end)
+	if !ok {
+		return 0, false
+	}
+	cents, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		r.fail(start, field, "valor inválido")
+		return 0, false
+	}
+	return float64(cents) / 100, true
+}
+
+// date lê uma data; com optional, um campo zerado ou em branco devolve nil.
+func (r *recordReader) date(field string, start, end int, layout string, optional bool) (*time.Time, bool) {
+	value := r.raw(start, end)
+	if optional && (strings.Trim(value, "0") == "" || strings.TrimSpace(value) == "") {
+		return nil, true
+	}
+	if _, ok := r.digits(field, start, end); !ok {
+		return nil, false
+	}
+	date, err := time.ParseInLocation(layout, value, time.Local)
+	if err != nil {
+		r.fail(start, field, "data inválida %s", value)
+		return nil, false
+	}
+	return &date, true
+}
+
+func (r *recordReader) expect(field string, start, end int, want string) bool {
+	if value := r.raw(start, end); value != want {
+		r.fail(start, field, "esperado %q, encontrado %q", want, value)
+		return false
+	}
+	return true
+}
+
+// recordWriter monta uma linha de tamanho fixo preenchida com brancos.
+type recordWriter struct {
+	buf []byte
+}
+
+func newRecordWriter(length int) *recordWriter {
+	return &recordWriter{buf: []byte(strings.Repeat(" ", length))}
+}
+
+// alpha escreve texto em maiúsculas, sem acentos, alinhado à esquerda e truncado.
+func (w *recordWriter) alpha(start, end int, value string) {
+	value = sanitize(value)
+	size := end - start + 1
+	if len(value) > size {
+		value = value[:size]
+	}
+	copy(w.buf[start-1:end], value+strings.Repeat(" ", size-len(value)))
+}
+
+// digits escreve um campo numérico alinhado à direita e completado com zeros.
+func (w *recordWriter) digits(start, end int, value string) {
+	size := end - start + 1
+	if len(value) > size {
+		value = value[len(value)-size:]
+	}
+	copy(w.buf[start-1:end], strings.Repeat("0", size-len(value))+value)
+}
+
+func (w *recordWriter) number(start, end int, value int64) {
+	w.digits(start, end, strconv.FormatInt(value, 10))
+}
+
+func (w *recordWriter) amount(start, end int, value float64) {
+	w.number(start, end, toCents(value))
+}
+
+func (w *recordWriter) date(start, end int, value *time.Time, layout string) {
+	if value == nil {
+		w.digits(start, end, "")
+		return
+	}
+	w.digits(start, end, value.Format(layout))
+}
+
+func (w *recordWriter) occurrences(start, end int, codes []string) {
+	w.alpha(start, end, strings.Join(codes, ""))
+}
+
+func (w *recordWriter) String() string {
+	return string(w.buf)
+}
+
+// splitOccurrences separa o campo de ocorrências em códigos de dois caracteres.
+func splitOccurrences(value string) []string {
+	var codes []string
+	for i := 0; i+2 <= len(value); i += 2 {
+		if code := strings.TrimSpace(value[i : i+2]); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// sanitize remove acentos e caracteres fora do ASCII, como pedem os leitores de CNAB.
+func sanitize(value string) string {
+	var builder strings.Builder
+	for _, r := range norm.NFD.String(value) {
+		if unicode.Is(unicode.Mn, r) || r > unicode.MaxASCII || unicode.IsControl(r) {
+			continue
+		}
+		builder.WriteRune(unicode.ToUpper(r))
+	}
+	return strings.TrimSpace(builder.String())
+}
@@ -0,0 +1,354 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	// lockTimeout é o tempo após o qual uma requisição em andamento é considerada
+	// abandonada e um reenvio pode assumi-la.
+	lockTimeout = time.Minute
+)
+
+// replayedHeaders são os cabeçalhos da resposta original devolvidos nos reenvios.
+var replayedHeaders = []string{"Content-Type", "Content-Disposition", "Location"}
+
+// Middleware torna idempotentes as requisições que alteram estado (POST, PUT, PATCH e
+// DELETE). A chave vem do cabeçalho Idempotency-Key ou, sem ele, do requestId do corpo
+// JSON, e vale por conta (ou operador/serviço) dentro de service. Reenvios com o mesmo
+// corpo recebem a resposta gravada; com outro corpo, 422; enquanto a original não
+// termina, 409. Deve ser registrado depois do JWTMiddleware; requisições sem chave ou
+// sem identidade passam direto.
+func Middleware(store Store, service string, logger *logrus.Logger) gin.HandlerFunc {
+	ttl := GetTTL()
+
+	return func(c *gin.Context) {
+		if !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		identity := requestIdentity(c)
+		if identity == "" {
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			read, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				abort(c, http.StatusBadRequest, models.ErrorInvalidData, "Não foi possível ler a requisição")
+				return
+			}
+			body = read
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		key := strings.TrimSpace(c.GetHeader(HeaderKey))
+		if key == "" {
+			key = bodyRequestID(c, body)
+		}
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			abort(c, http.StatusBadRequest, models.ErrorInvalidData, "Idempotency-Key muito longa")
+			return
+		}
+
+		now := time.Now()
+		record := &Record{
+			Scope:       service + ":" + identity,
+			Key:         key,
+			RequestHash: requestHash(c, body),
+			Status:      StatusInProgress,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		}
+
+		if !acquire(c, store, record, logger) {
+			return
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				if err := store.Delete(record.Scope, record.Key); err != nil {
+					logger.WithError(err).WithField("idempotencyKey", key).Error("Error releasing idempotency key")
+				}
+				panic(r)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if !isReplayable(status) {
+			if err := store.Delete(record.Scope, record.Key); err != nil {
+				logger.WithError(err).WithField("idempotencyKey", key).Error("Error releasing idempotency key")
+			}
+			return
+		}
+
+		record.StatusCode = status
+		record.Headers = encodeHeaders(recorder.Header())
+		record.Body = recorder.body.Bytes()
+		if err := store.Complete(record); err != nil {
+			logger.WithError(err).WithField("idempotencyKey", key).Error("Error storing idempotent response")
+		}
+	}
+}
+
+// acquire reserva a chave para esta requisição. Quando a chave já existe, responde com
+// a resposta gravada, 422 ou 409 e retorna false.
+func acquire(c *gin.Context, store Store, record *Record, logger *logrus.Logger) bool {
+	for attempt := 0; attempt < 2; attempt++ {
+		started, err := store.Begin(record)
+		if err != nil {
+			logger.WithError(err).Error("Error storing idempotency key")
+			abort(c, http.StatusInternalServerError, models.ErrorInternalError, "Erro interno do servidor")
+			return false
+		}
+		if started {
+			return true
+		}
+
+		existing, err := store.Get(record.Scope, record.Key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			logger.WithError(err).Error("Error getting idempotency key")
+			abort(c, http.StatusInternalServerError, models.ErrorInternalError, "Erro interno do servidor")
+			return false
+		}
+
+		if existing.ExpiresAt.Before(record.CreatedAt) {
+			if err := store.Delete(existing.Scope, existing.Key); err != nil {
+				logger.WithError(err).Error("Error deleting expired idempotency key")
+			}
+			continue
+		}
+
+		if existing.RequestHash != record.RequestHash {
+			abort(c, http.StatusUnprocessableEntity, models.ErrorIdempotencyKeyReused, "Chave de idempotência já utilizada com outros dados")
+			return false
+		}
+
+		if existing.Status == StatusCompleted {
+			replay(c, existing)
+			return false
+		}
+
+		taken, err := store.TakeOver(record.Scope, record.Key, record.CreatedAt.Add(-lockTimeout), record.CreatedAt)
+		if err != nil {
+			logger.WithError(err).Error("Error taking over idempotency key")
+			abort(c, http.StatusInternalServerError, models.ErrorInternalError, "Erro interno do servidor")
+			return false
+		}
+		if taken {
+			return true
+		}
+		break
+	}
+
+	abort(c, http.StatusConflict, models.ErrorRequestInProgress, "Requisição com esta chave ainda em processamento")
+	return false
+}
+
+func replay(c *gin.Context, record *Record) {
+	var headers map[string]string
+	if record.Headers != "" {
+		json.Unmarshal([]byte(record.Headers), &headers)
+	}
+	for name, value := range headers {
+		c.Header(name, value)
+	}
+	c.Header(HeaderReplayed, "true")
+
+	if len(record.Body) == 0 {
+		c.AbortWithStatus(record.StatusCode)
+		return
+	}
+	c.Data(record.StatusCode, headers["Content-Type"], record.Body)
+	c.Abort()
+}
+
+func abort(c *gin.Context, status int, errorType, message string) {
+	c.AbortWithStatusJSON(status, models.ErrorResponse{
+		Type:    errorType,
+		Message: message,
+	})
+}
+
+// isReplayable indica respostas definitivas. Erros de servidor e respostas que
+// dependem de algo que o cliente pode corrigir no reenvio (autenticação, 2FA,
+// conflito, limite de requisições) liberam a chave.
+func isReplayable(status int) bool {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict, http.StatusTooManyRequests:
+		return false
+	}
+	return status < http.StatusInternalServerError
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestIdentity é a conta do token ou, para operadores e serviços, o sujeito.
+func requestIdentity(c *gin.Context) string {
+	if accountID := c.GetString("accountId"); accountID != "" {
+		return "account:" + accountID
+	}
+	if subject := c.GetString("subject"); subject != "" {
+		return "subject:" + subject
+	}
+	return ""
+}
+
+func bodyRequestID(c *gin.Context, body []byte) string {
+	if c.ContentType() != "application/json" || len(body) == 0 {
+		return ""
+	}
+	var payload struct {
+		RequestID string `json:"requestId"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(payload.RequestID)
+}
+
+// requestHash identifica método, caminho e corpo. O corpo multipart entra pelos campos
+// e pelo conteúdo dos arquivos, não pelos bytes: o boundary muda a cada envio, mesmo
+// com o mesmo conteúdo.
+func requestHash(c *gin.Context, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	if form, ok := multipartDigest(c.GetHeader("Content-Type"), body); ok {
+		hash.Write([]byte(form))
+	} else {
+		hash.Write(body)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// multipartDigest descreve um corpo multipart de forma independente do boundary e da
+// ordem das partes: uma linha por parte com o nome do campo, o nome do arquivo e o
+// SHA-256 do conteúdo. Retorna false para corpos que não são multipart ou estão
+// malformados, que então entram no hash como bytes.
+func multipartDigest(contentType string, body []byte) (string, bool) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return "", false
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", false
+		}
+
+		content := sha256.New()
+		if _, err := io.Copy(content, part); err != nil {
+			return "", false
+		}
+		parts = append(parts, part.FormName()+"\x00"+part.FileName()+"\x00"+hex.EncodeToString(content.Sum(nil)))
+	}
+
+	sort.Strings(parts)
+	return strings.Join(parts, "\n"), true
+}
+
+func encodeHeaders(header http.Header) string {
+	headers := make(map[string]string)
+	for _, name := range replayedHeaders {
+		if value := header.Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+	data, _ := json.Marshal(headers)
+	return string(data)
+}
+
+// responseRecorder guarda uma cópia do corpo da resposta enquanto ele é enviado.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// StartCleanup apaga periodicamente os registros vencidos até o canal stop ser fechado.
+func StartCleanup(store Store, logger *logrus.Logger, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				deleted, err := store.DeleteExpired(time.Now())
+				if err != nil {
+					logger.WithError(err).Error("Error deleting expired idempotency keys")
+				} else if deleted > 0 {
+					logger.WithField("deleted", deleted).Info("Expired idempotency keys deleted")
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// GetTTL lê IDEMPOTENCY_TTL_HOURS (padrão de 24 horas).
+func GetTTL() time.Duration {
+	if value := os.Getenv("IDEMPOTENCY_TTL_HOURS"); value != "" {
+		if hours, err := strconv.Atoi(value); err == nil && hours > 0 {
+			return time.Duration(hours) * time.Hour
+		}
+	}
+	return 24 * time.Hour
+}
@@ -0,0 +1,98 @@
+package idempotency
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	StatusInProgress = "IN_PROGRESS"
+	StatusCompleted  = "COMPLETED"
+)
+
+// Record guarda uma requisição com Idempotency-Key e, depois de concluída, a resposta
+// completa, para que reenvios idênticos recebam a mesma resposta.
+type Record struct {
+	Scope       string    `gorm:"column:escopo;primaryKey"`
+	Key         string    `gorm:"column:chave;primaryKey"`
+	RequestHash string    `gorm:"column:hash_requisicao"`
+	Status      string    `gorm:"column:situacao"`
+	StatusCode  int       `gorm:"column:status_http"`
+	Headers     string    `gorm:"column:cabecalhos"`
+	Body        []byte    `gorm:"column:resposta"`
+	CreatedAt   time.Time `gorm:"column:data_criacao"`
+	ExpiresAt   time.Time `gorm:"column:data_expiracao"`
+}
+
+func (Record) TableName() string {
+	return "requisicao_idempotente"
+}
+
+var ErrNotFound = errors.New("idempotency record not found")
+
+type Store interface {
+	// Begin grava o registro em andamento. Retorna false se a chave já existe no escopo.
+	Begin(record *Record) (bool, error)
+	Get(scope, key string) (*Record, error)
+	Complete(record *Record) error
+	Delete(scope, key string) error
+	// TakeOver assume um registro em andamento iniciado antes de startedBefore (a
+	// requisição original não terminou, por queda da instância).
+	TakeOver(scope, key string, startedBefore, now time.Time) (bool, error)
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+func (s *gormStore) Begin(record *Record) (bool, error) {
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (s *gormStore) Get(scope, key string) (*Record, error) {
+	var record Record
+	err := s.db.Where("escopo = ? AND chave = ?", scope, key).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (s *gormStore) Complete(record *Record) error {
+	return s.db.Model(&Record{}).
+		Where("escopo = ? AND chave = ?", record.Scope, record.Key).
+		Updates(map[string]interface{}{
+			"situacao":    StatusCompleted,
+			"status_http": record.StatusCode,
+			"cabecalhos":  record.Headers,
+			"resposta":    record.Body,
+		}).Error
+}
+
+func (s *gormStore) Delete(scope, key string) error {
+	return s.db.Where("escopo = ? AND chave = ?", scope, key).Delete(&Record{}).Error
+}
+
+func (s *gormStore) TakeOver(scope, key string, startedBefore, now time.Time) (bool, error) {
+	result := s.db.Model(&Record{}).
+		Where("escopo = ? AND chave = ? AND situacao = ? AND data_criacao < ?", scope, key, StatusInProgress, startedBefore).
+		Update("data_criacao", now)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (s *gormStore) DeleteExpired(now time.Time) (int64, error) {
+	result := s.db.Where("data_expiracao < ?", now).Delete(&Record{})
+	return result.RowsAffected, result.Error
+}
@@ -13,14 +13,61 @@ import (
 type Consumer struct {
 	consumer sarama.ConsumerGroup
 	logger   *logrus.Logger
-	handler  ConsumerHandler
+	topics   []string
+	handle   func(message *sarama.ConsumerMessage)
 }
 
 type ConsumerHandler interface {
 	HandleTransferEvent(event TransferEvent) error
 }
 
+// MessageHandler recebe as mensagens de qualquer tópico assinado, ainda serializadas.
+type MessageHandler interface {
+	HandleMessage(topic string, value []byte) error
+}
+
+// NewConsumer consome o tópico de transferências e entrega cada evento ao handler.
 func NewConsumer(groupID string, handler ConsumerHandler, logger *logrus.Logger) (*Consumer, error) {
+	consumer, err := newConsumerGroup(groupID, []string{TopicTransferEvents}, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer.handle = func(message *sarama.ConsumerMessage) {
+		var event TransferEvent
+		if err := json.Unmarshal(message.Value, &event); err != nil {
+			logger.WithError(err).Error("Error unmarshaling transfer event")
+			return
+		}
+
+		if err := handler.HandleTransferEvent(event); err != nil {
+			logger.WithError(err).Error("Error handling transfer event")
+		} else {
+			logger.WithField("requestId", event.RequestID).Info("Transfer event processed successfully")
+		}
+	}
+
+	return consumer, nil
+}
+
+// NewTopicConsumer consome vários tópicos e entrega as mensagens brutas ao handler,
+// que decide como decodificar cada uma.
+func NewTopicConsumer(groupID string, topics []string, handler MessageHandler, logger *logrus.Logger) (*Consumer, error) {
+	consumer, err := newConsumerGroup(groupID, topics, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	consumer.handle = func(message *sarama.ConsumerMessage) {
+		if err := handler.HandleMessage(message.Topic, message.Value); err != nil {
+			logger.WithError(err).WithField("topic", message.Topic).Error("Error handling message")
+		}
+	}
+
+	return consumer, nil
+}
+
+func newConsumerGroup(groupID string, topics []string, logger *logrus.Logger) (*Consumer, error) {
 	brokers := os.Getenv("KAFKA_BROKERS")
 	if brokers == "" {
 		brokers = "localhost:9092"
@@ -38,19 +85,17 @@ func NewConsumer(groupID string, handler ConsumerHandler, logger *logrus.Logger)
 	return &Consumer{
 		consumer: consumer,
 		logger:   logger,
-		handler:  handler,
+		topics:   topics,
 	}, nil
 }
 
 func (c *Consumer) Start(ctx context.Context) error {
-	topics := []string{"transfer-events"}
-
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		default:
-			if err := c.consumer.Consume(ctx, topics, c); err != nil {
+			if err := c.consumer.Consume(ctx, c.topics, c); err != nil {
 				c.logger.WithError(err).Error("Error consuming messages")
 				return err
 			}
@@ -74,19 +119,7 @@ func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim saram
 				return nil
 			}
 
-			var event TransferEvent
-			if err := json.Unmarshal(message.Value, &event); err != nil {
-				c.logger.WithError(err).Error("Error unmarshaling transfer event")
-				session.MarkMessage(message, "")
-				continue
-			}
-
-			if err := c.handler.HandleTransferEvent(event); err != nil {
-				c.logger.WithError(err).Error("Error handling transfer event")
-			} else {
-				c.logger.WithField("requestId", event.RequestID).Info("Transfer event processed successfully")
-			}
-
+			c.handle(message)
 			session.MarkMessage(message, "")
 
 		case <-session.Context().Done():
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/shopify/sarama"
 	"github.com/sirupsen/logrus"
@@ -14,13 +15,73 @@ type Producer struct {
 	logger   *logrus.Logger
 }
 
+const (
+	TopicTransferEvents       = "transfer-events"
+	TopicTransferStatusEvents = "transfer-status-events"
+	TopicFeeEvents            = "fee-events"
+	TopicAccountEvents        = "account-events"
+)
+
 type TransferEvent struct {
-	RequestID               string  `json:"requestId"`
-	OriginAccountID         string  `json:"originAccountId"`
-	DestinationAccountID    string  `json:"destinationAccountId"`
+	RequestID                string  `json:"requestId"`
+	OriginAccountID          string  `json:"originAccountId"`
+	DestinationAccountID     string  `json:"destinationAccountId"`
 	DestinationAccountNumber string  `json:"destinationAccountNumber"`
-	Amount                  float64 `json:"amount"`
-	TransferID              string  `json:"transferId"`
+	Amount                   float64 `json:"amount"`
+	TransferID               string  `json:"transferId"`
+	Type                     string  `json:"type,omitempty"`
+	// FeeAmount é a tarifa garantida por uma cotação; sem ela vale a tarifa vigente.
+	FeeAmount *float64 `json:"feeAmount,omitempty"`
+}
+
+// TransferStatusEvent informa o resultado de uma transferência assíncrona, concluída
+// ou falha, para quem não quer consultar o status.
+type TransferStatusEvent struct {
+	TransferID      string    `json:"transferId"`
+	RequestID       string    `json:"requestId"`
+	OriginAccountID string    `json:"originAccountId"`
+	Status          string    `json:"status"`
+	ErrorType       string    `json:"errorType,omitempty"`
+	ErrorMessage    string    `json:"errorMessage,omitempty"`
+	OccurredAt      time.Time `json:"occurredAt"`
+}
+
+// AccountEvent replica entre as instâncias da account-api as notificações em tempo real
+// geradas por uma delas. Origin identifica a instância que publicou.
+type AccountEvent struct {
+	ID         string          `json:"id"`
+	AccountID  string          `json:"accountId"`
+	Type       string          `json:"type"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Origin     string          `json:"origin"`
+}
+
+// FeeEvent é publicado pela API de tarifas depois de debitar uma tarifa.
+type FeeEvent struct {
+	FeeID      string    `json:"feeId"`
+	AccountID  string    `json:"accountId"`
+	Amount     float64   `json:"amount"`
+	TransferID string    `json:"transferId"`
+	RequestID  string    `json:"requestId"`
+	ChargedAt  time.Time `json:"chargedAt"`
+}
+
+const (
+	TransferStatusCompleted = "COMPLETED"
+	TransferStatusFailed    = "FAILED"
+)
+
+const (
+	TransferTypeStandard = "TRANSFER"
+	TransferTypeSweep    = "SWEEP"
+	TransferTypeRefund   = "REFUND"
+)
+
+// IsFeeExempt indica transferências que não geram tarifa. Eventos antigos, sem tipo,
+// são tratados como transferências comuns.
+func (e TransferEvent) IsFeeExempt() bool {
+	return e.Type == TransferTypeSweep || e.Type == TransferTypeRefund
 }
 
 func NewProducer(logger *logrus.Logger) (*Producer, error) {
@@ -53,7 +114,7 @@ func (p *Producer) PublishTransferEvent(event TransferEvent) error {
 	}
 
 	msg := &sarama.ProducerMessage{
-		Topic: "transfer-events",
+		Topic: TopicTransferEvents,
 		Value: sarama.StringEncoder(data),
 	}
 
@@ -72,6 +133,76 @@ func (p *Producer) PublishTransferEvent(event TransferEvent) error {
 	return nil
 }
 
+func (p *Producer) PublishTransferStatusEvent(event TransferStatusEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		p.logger.WithError(err).Error("Failed to marshal transfer status event")
+		return err
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: TopicTransferStatusEvents,
+		Key:   sarama.StringEncoder(event.TransferID),
+		Value: sarama.StringEncoder(data),
+	}
+
+	if _, _, err := p.producer.SendMessage(msg); err != nil {
+		p.logger.WithError(err).Error("Failed to send transfer status event to Kafka")
+		return err
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"transferId": event.TransferID,
+		"status":     event.Status,
+	}).Info("Transfer status event published successfully")
+
+	return nil
+}
+
+func (p *Producer) PublishFeeEvent(event FeeEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		p.logger.WithError(err).Error("Failed to marshal fee event")
+		return err
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: TopicFeeEvents,
+		Key:   sarama.StringEncoder(event.AccountID),
+		Value: sarama.StringEncoder(data),
+	}
+
+	if _, _, err := p.producer.SendMessage(msg); err != nil {
+		p.logger.WithError(err).Error("Failed to send fee event to Kafka")
+		return err
+	}
+
+	p.logger.WithField("feeId", event.FeeID).Info("Fee event published successfully")
+
+	return nil
+}
+
+func (p *Producer) PublishAccountEvent(event AccountEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		p.logger.WithError(err).Error("Failed to marshal account event")
+		return err
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: TopicAccountEvents,
+		Key:   sarama.StringEncoder(event.AccountID),
+		Value: sarama.StringEncoder(data),
+	}
+
+	if _, _, err := p.producer.SendMessage(msg); err != nil {
+		p.logger.WithError(err).Error("Failed to send account event to Kafka")
+		return err
+	}
+
+	return nil
+}
+
 func (p *Producer) Close() error {
 	return p.producer.Close()
 }
@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
 	"net/http"
 	"os"
 	"strings"
@@ -13,30 +16,143 @@ import (
 )
 
 type Claims struct {
-	AccountID     string `json:"accountId"`
-	AccountNumber string `json:"accountNumber"`
-	CPF           string `json:"cpf"`
+	AccountID        string   `json:"accountId"`
+	AccountNumber    string   `json:"accountNumber"`
+	CPF              string   `json:"cpf"`
+	TwoFactorPending bool     `json:"twoFactorPending,omitempty"`
+	Role             string   `json:"role,omitempty"`
+	Scopes           []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(accountID, accountNumber, cpf string) (string, error) {
+func jwtSecret() string {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		secret = "your-secret-key-here-change-in-production"
 	}
+	return secret
+}
 
+func GenerateJWT(accountID, accountNumber, cpf string) (string, error) {
 	claims := Claims{
 		AccountID:     accountID,
 		AccountNumber: accountNumber,
 		CPF:           cpf,
+		Role:          RoleCustomer,
+		Scopes:        ScopesForRole(RoleCustomer),
 		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   accountID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return token.SignedString([]byte(jwtSecret()))
+}
+
+// GenerateOperatorJWT emite o token de um operador do back-office. O token não
+// carrega conta corrente e por isso não acessa as rotas de cliente.
+func GenerateOperatorJWT(operatorID, role string) (string, error) {
+	claims := Claims{
+		Role:   role,
+		Scopes: ScopesForRole(role),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   operatorID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(8 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(jwtSecret()))
+}
+
+// GenerateServiceJWT emite o token de curta duração usado nas chamadas entre serviços.
+func GenerateServiceJWT(serviceName string) (string, error) {
+	claims := Claims{
+		Role:   RoleService,
+		Scopes: ScopesForRole(RoleService),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   serviceName,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(jwtSecret()))
+}
+
+// GeneratePartialJWT emite o token intermediário do login com 2FA. Ele só é aceito
+// por ParsePartialJWT e é recusado pelo JWTMiddleware.
+func GeneratePartialJWT(accountID string) (string, error) {
+	claims := Claims{
+		AccountID:        accountID,
+		TwoFactorPending: true,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(jwtSecret()))
+}
+
+func parseJWT(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(jwtSecret()), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// purposeKey deriva do segredo dos tokens uma chave própria para cada finalidade, para
+// que um token assinado para outro fim (uma cotação, por exemplo) nunca valha como acesso.
+func purposeKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(jwtSecret()))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// SignPurposeToken assina claims de uma finalidade específica com a chave derivada dela.
+func SignPurposeToken(purpose string, claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(purposeKey(purpose))
+}
+
+// ParsePurposeToken valida assinatura e validade de um token gerado por SignPurposeToken
+// e preenche claims.
+func ParsePurposeToken(purpose, tokenString string, claims jwt.Claims) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return purposeKey(purpose), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return errors.New("invalid token")
+	}
+	return nil
+}
+
+func ParsePartialJWT(tokenString string) (*Claims, error) {
+	claims, err := parseJWT(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if !claims.TwoFactorPending {
+		return nil, errors.New("token is not a two-factor challenge")
+	}
+	return claims, nil
 }
 
 func JWTMiddleware() gin.HandlerFunc {
@@ -61,16 +177,8 @@ func JWTMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			secret = "your-secret-key-here-change-in-production"
-		}
-
-		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := parseJWT(tokenString)
+		if err != nil || claims.TwoFactorPending {
 			c.JSON(http.StatusForbidden, models.ErrorResponse{
 				Type:    models.ErrorUserUnauthorized,
 				Message: "Token inválido ou expirado",
@@ -79,12 +187,18 @@ func JWTMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if claims, ok := token.Claims.(*Claims); ok {
-			c.Set("accountId", claims.AccountID)
-			c.Set("accountNumber", claims.AccountNumber)
-			c.Set("cpf", claims.CPF)
+		role := claims.Role
+		if role == "" {
+			role = RoleCustomer
 		}
 
+		c.Set("accountId", claims.AccountID)
+		c.Set("accountNumber", claims.AccountNumber)
+		c.Set("cpf", claims.CPF)
+		c.Set("subject", claims.Subject)
+		c.Set("role", role)
+		c.Set("scopes", claims.Scopes)
+
 		c.Next()
 	}
 }
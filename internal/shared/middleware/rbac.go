@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+
+	"bankmore/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RoleCustomer = "customer"
+	RoleSupport  = "support"
+	RoleAdmin    = "admin"
+	RoleService  = "service"
+)
+
+const (
+	ScopeAccountsRead         = "accounts:read"
+	ScopeAccountsManage       = "accounts:manage"
+	ScopeBlocksManage         = "blocks:manage"
+	ScopeBoletosManage        = "boletos:manage"
+	ScopeFeesRead             = "fees:read"
+	ScopeHoldsManage          = "holds:manage"
+	ScopeLedgerRead           = "ledger:read"
+	ScopeLedgerManage         = "ledger:manage"
+	ScopeMovementsWrite       = "movements:write"
+	ScopeOperatorsManage      = "operators:manage"
+	ScopeReconciliationRead   = "reconciliation:read"
+	ScopeReconciliationManage = "reconciliation:manage"
+)
+
+var roleScopes = map[string][]string{
+	RoleCustomer: {},
+	RoleSupport:  {ScopeAccountsRead, ScopeFeesRead, ScopeLedgerRead, ScopeReconciliationRead},
+	RoleAdmin:    {ScopeAccountsRead, ScopeAccountsManage, ScopeBlocksManage, ScopeBoletosManage, ScopeFeesRead, ScopeLedgerRead, ScopeLedgerManage, ScopeOperatorsManage, ScopeReconciliationRead, ScopeReconciliationManage},
+	RoleService:  {ScopeAccountsRead, ScopeFeesRead, ScopeHoldsManage, ScopeMovementsWrite},
+}
+
+func ScopesForRole(role string) []string {
+	scopes := roleScopes[role]
+	result := make([]string, len(scopes))
+	copy(result, scopes)
+	return result
+}
+
+func IsOperatorRole(role string) bool {
+	return role == RoleSupport || role == RoleAdmin
+}
+
+// RequireRole deve ser registrado depois do JWTMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Type:    models.ErrorForbidden,
+			Message: "Acesso não permitido para este perfil",
+		})
+		c.Abort()
+	}
+}
+
+// RequireScope exige que o token tenha ao menos um dos escopos informados.
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted, _ := c.Get("scopes")
+		grantedScopes, _ := granted.([]string)
+
+		for _, scope := range scopes {
+			for _, grantedScope := range grantedScopes {
+				if scope == grantedScope {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Type:    models.ErrorForbidden,
+			Message: "Escopo insuficiente para esta operação",
+		})
+		c.Abort()
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+)
+
+// DoServiceRequest executa uma chamada entre serviços autenticada com um token de
+// perfil service, aceito pelas rotas restritas a chamadores internos.
+func DoServiceRequest(serviceName, method, url string, body io.Reader) (*http.Response, error) {
+	token, err := GenerateServiceJWT(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return http.DefaultClient.Do(req)
+}
@@ -6,18 +6,29 @@ type ErrorResponse struct {
 }
 
 const (
-	ErrorInvalidDocument     = "INVALID_DOCUMENT"
-	ErrorUserUnauthorized    = "USER_UNAUTHORIZED"
-	ErrorInvalidAccount      = "INVALID_ACCOUNT"
-	ErrorInactiveAccount     = "INACTIVE_ACCOUNT"
-	ErrorInvalidValue        = "INVALID_VALUE"
-	ErrorInvalidType         = "INVALID_TYPE"
-	ErrorInsufficientBalance = "INSUFFICIENT_BALANCE"
-	ErrorInvalidAmount       = "INVALID_AMOUNT"
-	ErrorInvalidTransfer     = "INVALID_TRANSFER"
-	ErrorAccountNotFound     = "ACCOUNT_NOT_FOUND"
-	ErrorInvalidOperation    = "INVALID_OPERATION"
-	ErrorInvalidArgument     = "INVALID_ARGUMENT"
-	ErrorInternalError       = "INTERNAL_ERROR"
-	ErrorInvalidData         = "INVALID_DATA"
+	ErrorInvalidDocument      = "INVALID_DOCUMENT"
+	ErrorUserUnauthorized     = "USER_UNAUTHORIZED"
+	ErrorInvalidAccount       = "INVALID_ACCOUNT"
+	ErrorInactiveAccount      = "INACTIVE_ACCOUNT"
+	ErrorInvalidValue         = "INVALID_VALUE"
+	ErrorInvalidType          = "INVALID_TYPE"
+	ErrorInsufficientBalance  = "INSUFFICIENT_BALANCE"
+	ErrorInvalidAmount        = "INVALID_AMOUNT"
+	ErrorInvalidTransfer      = "INVALID_TRANSFER"
+	ErrorAccountNotFound      = "ACCOUNT_NOT_FOUND"
+	ErrorInvalidOperation     = "INVALID_OPERATION"
+	ErrorInvalidArgument      = "INVALID_ARGUMENT"
+	ErrorInternalError        = "INTERNAL_ERROR"
+	ErrorInvalidData          = "INVALID_DATA"
+	ErrorTwoFactorRequired    = "TWO_FACTOR_REQUIRED"
+	ErrorInvalidTwoFactor     = "INVALID_TWO_FACTOR_CODE"
+	ErrorTwoFactorLocked      = "TWO_FACTOR_LOCKED"
+	ErrorForbidden            = "FORBIDDEN"
+	ErrorPixKeyNotFound       = "PIX_KEY_NOT_FOUND"
+	ErrorInvalidPaymentCode   = "INVALID_PAYMENT_CODE"
+	ErrorInvalidQuote         = "INVALID_QUOTE"
+	ErrorDailyLimitExceeded   = "DAILY_LIMIT_EXCEEDED"
+	ErrorIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+	ErrorRequestInProgress    = "REQUEST_IN_PROGRESS"
+	ErrorTooManyConnections   = "TOO_MANY_CONNECTIONS"
 )
@@ -0,0 +1,52 @@
+package notification
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ChannelNotifier encaminha cada mensagem ao Notifier do seu canal.
+type ChannelNotifier struct {
+	notifiers map[string]Notifier
+}
+
+func NewChannelNotifier(notifiers map[string]Notifier) *ChannelNotifier {
+	return &ChannelNotifier{notifiers: notifiers}
+}
+
+func (n *ChannelNotifier) Send(message Message) error {
+	notifier, ok := n.notifiers[message.Channel]
+	if !ok {
+		return fmt.Errorf("canal %s não configurado", message.Channel)
+	}
+	return notifier.Send(message)
+}
+
+// NewNotifierFromEnv monta um Notifier com SMTP, gateway de SMS e gateway de push,
+// cada um quando configurado. Canais sem provedor usam o arquivo de
+// NOTIFICATION_FILE_PATH ou, sem ele, o log.
+func NewNotifierFromEnv(logger *logrus.Logger) *ChannelNotifier {
+	var fallback Notifier = NewLogNotifier(logger)
+	if path := os.Getenv("NOTIFICATION_FILE_PATH"); path != "" {
+		fallback = NewFileNotifier(path)
+	}
+
+	notifiers := map[string]Notifier{
+		ChannelEmail: fallback,
+		ChannelSMS:   fallback,
+		ChannelPush:  fallback,
+	}
+	if smtpNotifier := NewSMTPNotifierFromEnv(); smtpNotifier != nil {
+		notifiers[ChannelEmail] = smtpNotifier
+	}
+	if smsNotifier := NewSMSNotifierFromEnv(); smsNotifier != nil {
+		notifiers[ChannelSMS] = smsNotifier
+	}
+	if pushNotifier := NewPushNotifierFromEnv(); pushNotifier != nil {
+		notifiers[ChannelPush] = pushNotifier
+	}
+
+	return NewChannelNotifier(notifiers)
+}
@@ -0,0 +1,44 @@
+package notification
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileNotifier grava cada mensagem como uma linha JSON em um arquivo, para conferir em
+// execuções locais o que seria enviado.
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+func (n *FileNotifier) Send(message Message) error {
+	data, err := json.Marshal(struct {
+		Channel   string    `json:"channel"`
+		Recipient string    `json:"recipient"`
+		Subject   string    `json:"subject,omitempty"`
+		Body      string    `json:"body"`
+		SentAt    time.Time `json:"sentAt"`
+	}{message.Channel, message.Recipient, message.Subject, message.Body, time.Now()})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
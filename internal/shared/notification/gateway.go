@@ -0,0 +1,107 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// httpGateway envia a mensagem em JSON para um provedor HTTP (gateway de SMS ou de
+// push), autenticando com um token Bearer.
+type httpGateway struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func newHTTPGateway(url, token string) httpGateway {
+	return httpGateway{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (g httpGateway) post(payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, g.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if g.token != "" {
+		request.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	response, err := g.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("provedor respondeu HTTP %d", response.StatusCode)
+	}
+	return nil
+}
+
+// SMSNotifier envia SMS por um gateway HTTP: POST {"to", "message"}.
+type SMSNotifier struct {
+	gateway httpGateway
+}
+
+func NewSMSNotifier(url, token string) *SMSNotifier {
+	return &SMSNotifier{gateway: newHTTPGateway(url, token)}
+}
+
+// NewSMSNotifierFromEnv lê SMS_GATEWAY_URL e SMS_GATEWAY_TOKEN. Retorna nil sem a URL.
+func NewSMSNotifierFromEnv() *SMSNotifier {
+	url := os.Getenv("SMS_GATEWAY_URL")
+	if url == "" {
+		return nil
+	}
+	return NewSMSNotifier(url, os.Getenv("SMS_GATEWAY_TOKEN"))
+}
+
+func (n *SMSNotifier) Send(message Message) error {
+	return n.gateway.post(map[string]string{
+		"to":      message.Recipient,
+		"message": message.Body,
+	})
+}
+
+// PushNotifier envia notificações push por um gateway HTTP: POST {"token", "title",
+// "body"}, onde token identifica o dispositivo do cliente.
+type PushNotifier struct {
+	gateway httpGateway
+}
+
+func NewPushNotifier(url, token string) *PushNotifier {
+	return &PushNotifier{gateway: newHTTPGateway(url, token)}
+}
+
+// NewPushNotifierFromEnv lê PUSH_GATEWAY_URL e PUSH_GATEWAY_TOKEN. Retorna nil sem a URL.
+func NewPushNotifierFromEnv() *PushNotifier {
+	url := os.Getenv("PUSH_GATEWAY_URL")
+	if url == "" {
+		return nil
+	}
+	return NewPushNotifier(url, os.Getenv("PUSH_GATEWAY_TOKEN"))
+}
+
+func (n *PushNotifier) Send(message Message) error {
+	return n.gateway.post(map[string]string{
+		"token": message.Recipient,
+		"title": message.Subject,
+		"body":  message.Body,
+	})
+}
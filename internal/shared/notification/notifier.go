@@ -0,0 +1,44 @@
+package notification
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ChannelEmail = "EMAIL"
+	ChannelSMS   = "SMS"
+	ChannelPush  = "PUSH"
+)
+
+type Message struct {
+	Channel   string
+	Recipient string
+	Subject   string
+	Body      string
+}
+
+// Notifier entrega mensagens ao cliente. Implementações podem usar SMTP, gateways
+// de SMS ou qualquer outro canal; o serviço só conhece esta interface.
+type Notifier interface {
+	Send(message Message) error
+}
+
+// LogNotifier apenas registra a mensagem no log. Útil em desenvolvimento, quando
+// não há provedor de e-mail ou SMS configurado.
+type LogNotifier struct {
+	logger *logrus.Logger
+}
+
+func NewLogNotifier(logger *logrus.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Send(message Message) error {
+	n.logger.WithFields(logrus.Fields{
+		"channel":   message.Channel,
+		"recipient": message.Recipient,
+		"subject":   message.Subject,
+		"body":      message.Body,
+	}).Info("Notification sent")
+	return nil
+}
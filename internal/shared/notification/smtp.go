@@ -0,0 +1,69 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"time"
+)
+
+// SMTPNotifier envia e-mails por um servidor SMTP. Com usuário configurado, autentica
+// com PLAIN, que o net/smtp só permite sobre TLS ou em localhost.
+type SMTPNotifier struct {
+	addr string
+	host string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPNotifier(host, port, username, password, from string) *SMTPNotifier {
+	notifier := &SMTPNotifier{
+		addr: net.JoinHostPort(host, port),
+		host: host,
+		from: from,
+	}
+	if username != "" {
+		notifier.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return notifier
+}
+
+// NewSMTPNotifierFromEnv lê SMTP_HOST, SMTP_PORT (padrão 587), SMTP_USERNAME,
+// SMTP_PASSWORD e SMTP_FROM. Retorna nil sem SMTP_HOST.
+func NewSMTPNotifierFromEnv() *SMTPNotifier {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "nao-responda@bankmore.com.br"
+	}
+	return NewSMTPNotifier(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from)
+}
+
+func (n *SMTPNotifier) Send(message Message) error {
+	if message.Channel != ChannelEmail {
+		return fmt.Errorf("canal %s não suportado pelo SMTP", message.Channel)
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", n.from)
+	fmt.Fprintf(&body, "To: %s\r\n", message.Recipient)
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	body.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	body.WriteString(message.Body)
+	body.WriteString("\r\n")
+
+	return smtp.SendMail(n.addr, n.auth, n.from, []string{message.Recipient}, body.Bytes())
+}
@@ -0,0 +1,256 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Tamanho A4 em pontos.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Objetos reservados; os demais são numerados na ordem em que são escritos.
+const (
+	catalogObject = iota + 1
+	pagesObject
+	regularFontObject
+	boldFontObject
+	infoObject
+	firstFreeObject
+)
+
+// Color é uma cor RGB com componentes de 0 a 1.
+type Color struct {
+	R, G, B float64
+}
+
+// Document escreve um PDF 1.4 de texto e formas simples, com as fontes Helvetica padrão
+// (sem embutir arquivos de fonte). Cada página é enviada ao destino assim que a próxima
+// começa, então documentos longos não ficam inteiros em memória. Os erros de escrita
+// ficam guardados e são devolvidos por Close.
+type Document struct {
+	out     *countingWriter
+	title   string
+	offsets map[int]int64
+	next    int
+	pages   []int
+	content *bytes.Buffer
+	err     error
+}
+
+// New começa um documento em w. O título vai para as propriedades do arquivo.
+func New(w io.Writer, title string) *Document {
+	d := &Document{
+		out:     &countingWriter{w: w},
+		title:   title,
+		offsets: make(map[int]int64),
+		next:    firstFreeObject,
+	}
+	d.write("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	d.object(regularFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	d.object(boldFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	return d
+}
+
+// AddPage encerra a página corrente, se houver, e começa uma nova.
+func (d *Document) AddPage() {
+	d.flushPage()
+	d.content = &bytes.Buffer{}
+}
+
+// PageCount devolve o número de páginas começadas até agora.
+func (d *Document) PageCount() int {
+	count := len(d.pages)
+	if d.content != nil {
+		count++
+	}
+	return count
+}
+
+// Text escreve s com a base em (x, y), medidos a partir do canto inferior esquerdo.
+func (d *Document) Text(x, y, size float64, bold bool, color Color, s string) {
+	if d.content == nil {
+		d.AddPage()
+	}
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.content, "BT /%s %.2f Tf %.3f %.3f %.3f rg %.2f %.2f Td (%s) Tj ET\n",
+		font, size, color.R, color.G, color.B, x, y, escape(encode(s)))
+}
+
+// TextRight escreve s terminando em right, para alinhar valores à direita.
+func (d *Document) TextRight(right, y, size float64, bold bool, color Color, s string) {
+	d.Text(right-TextWidth(s, size), y, size, bold, color, s)
+}
+
+// Rect preenche um retângulo com canto inferior esquerdo em (x, y).
+func (d *Document) Rect(x, y, width, height float64, color Color) {
+	if d.content == nil {
+		d.AddPage()
+	}
+	fmt.Fprintf(d.content, "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n",
+		color.R, color.G, color.B, x, y, width, height)
+}
+
+// Line traça uma linha de (x1, y1) a (x2, y2).
+func (d *Document) Line(x1, y1, x2, y2, width float64, color Color) {
+	if d.content == nil {
+		d.AddPage()
+	}
+	fmt.Fprintf(d.content, "%.3f %.3f %.3f RG %.2f w %.2f %.2f m %.2f %.2f l S\n",
+		color.R, color.G, color.B, width, x1, y1, x2, y2)
+}
+
+// Err devolve o primeiro erro de escrita, para interromper documentos longos cujo
+// destino já falhou.
+func (d *Document) Err() error {
+	return d.err
+}
+
+// Close encerra a última página e escreve a árvore de páginas, o catálogo e a tabela
+// de referências cruzadas.
+func (d *Document) Close() error {
+	if d.PageCount() == 0 {
+		d.AddPage()
+	}
+	d.flushPage()
+
+	kids := make([]string, len(d.pages))
+	for i, page := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	d.object(pagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %.2f %.2f] >>",
+		strings.Join(kids, " "), len(d.pages), PageWidth, PageHeight))
+	d.object(catalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObject))
+	d.object(infoObject, fmt.Sprintf("<< /Title (%s) /Producer (BankMore) /CreationDate (D:%s) >>",
+		escape(encode(d.title)), time.Now().Format("20060102150405")))
+
+	xref := d.out.count
+	d.write(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", d.next))
+	for id := 1; id < d.next; id++ {
+		d.write(fmt.Sprintf("%010d 00000 n \n", d.offsets[id]))
+	}
+	d.write(fmt.Sprintf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		d.next, catalogObject, infoObject, xref))
+	return d.err
+}
+
+func (d *Document) flushPage() {
+	if d.content == nil {
+		return
+	}
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(d.content.Bytes())
+	zw.Close()
+
+	contentID := d.allocate()
+	d.offsets[contentID] = d.out.count
+	d.write(fmt.Sprintf("%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", contentID, compressed.Len()))
+	d.write(compressed.String())
+	d.write("\nendstream\nendobj\n")
+
+	pageID := d.allocate()
+	d.object(pageID, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+		pagesObject, regularFontObject, boldFontObject, contentID))
+	d.pages = append(d.pages, pageID)
+	d.content = nil
+}
+
+func (d *Document) allocate() int {
+	id := d.next
+	d.next++
+	return id
+}
+
+func (d *Document) object(id int, body string) {
+	d.offsets[id] = d.out.count
+	d.write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", id, body))
+}
+
+func (d *Document) write(s string) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = io.WriteString(d.out, s)
+}
+
+// TextWidth estima a largura de s em pontos pelas métricas da Helvetica. A Helvetica-Bold
+// é um pouco mais larga nas letras, mas tem os mesmos algarismos, suficiente para
+// alinhar valores.
+func TextWidth(s string, size float64) float64 {
+	var width int
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			width += helveticaWidths[r-32]
+		} else {
+			width += 556
+		}
+	}
+	return float64(width) * size / 1000
+}
+
+// Truncate corta s com reticências para caber em maxWidth.
+func Truncate(s string, size, maxWidth float64) string {
+	if TextWidth(s, size) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && TextWidth(string(runes)+"...", size) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// encode converte para WinAnsi (Windows-1252), a codificação das fontes padrão;
+// caracteres fora dela viram "?".
+func encode(s string) string {
+	encoder := charmap.Windows1252.NewEncoder()
+	var out strings.Builder
+	for _, r := range s {
+		encoded, err := encoder.String(string(r))
+		if err != nil {
+			out.WriteByte('?')
+			continue
+		}
+		out.WriteString(encoded)
+	}
+	return out.String()
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`, "\r", "", "\n", " ").Replace(s)
+}
+
+type countingWriter struct {
+	w     io.Writer
+	count int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.count += int64(n)
+	return n, err
+}
+
+// helveticaWidths são as larguras (em milésimos do tamanho da fonte) dos caracteres
+// ASCII de 32 a 126 na Helvetica.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
@@ -0,0 +1,282 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Tipos de evento enviados aos clientes conectados.
+const (
+	EventMovementPosted   = "MovementPosted"
+	EventTransferSent     = "TransferSent"
+	EventTransferReceived = "TransferReceived"
+	EventFeeCharged       = "FeeCharged"
+)
+
+const (
+	historySize = 100
+	// historyTTL é por quanto tempo o histórico de uma conta sem novos eventos fica
+	// disponível para retomada com Last-Event-ID.
+	historyTTL       = 15 * time.Minute
+	subscriberBuffer = 32
+)
+
+var (
+	ErrTooManyConnections        = errors.New("limite de conexões do servidor atingido")
+	ErrAccountConnectionsReached = errors.New("limite de conexões simultâneas da conta atingido")
+	ErrBrokerClosed              = errors.New("servidor em desligamento")
+)
+
+// eventNamespace gera IDs determinísticos para eventos derivados do Kafka, iguais em
+// todas as instâncias, para que Last-Event-ID funcione em qualquer uma delas.
+var eventNamespace = uuid.MustParse("0b6f4e0a-8f2d-4d3c-a1b7-5c9e2f8d7a61")
+
+// Event é uma notificação para os clientes conectados de uma conta.
+type Event struct {
+	ID         string          `json:"id"`
+	AccountID  string          `json:"-"`
+	Type       string          `json:"type"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurredAt"`
+}
+
+// NewEvent monta um evento com ID fixo; data é serializado em JSON.
+func NewEvent(id, accountID, eventType string, data interface{}, occurredAt time.Time) (Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		ID:         id,
+		AccountID:  accountID,
+		Type:       eventType,
+		Data:       payload,
+		OccurredAt: occurredAt,
+	}, nil
+}
+
+// DerivedEventID é o ID de um evento gerado a partir de outro recurso (transferência ou tarifa).
+func DerivedEventID(eventType, sourceID string) string {
+	return uuid.NewSHA1(eventNamespace, []byte(eventType+"|"+sourceID)).String()
+}
+
+// Publisher recebe os eventos gerados pelos serviços.
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Subscription é uma conexão de um cliente. Events é fechado quando a conexão deve
+// terminar: cliente lento demais, desligamento do servidor ou Unsubscribe.
+type Subscription struct {
+	accountID string
+	events    chan Event
+}
+
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+type accountHistory struct {
+	events    []Event
+	updatedAt time.Time
+}
+
+// Broker distribui os eventos desta instância aos clientes conectados e guarda os
+// últimos eventos de cada conta para a retomada.
+type Broker struct {
+	mu            sync.Mutex
+	subscribers   map[string]map[*Subscription]struct{}
+	history       map[string]*accountHistory
+	connections   int
+	maxTotal      int
+	maxPerAccount int
+	closed        bool
+	logger        *logrus.Logger
+}
+
+func NewBroker(maxTotal, maxPerAccount int, logger *logrus.Logger) *Broker {
+	return &Broker{
+		subscribers:   make(map[string]map[*Subscription]struct{}),
+		history:       make(map[string]*accountHistory),
+		maxTotal:      maxTotal,
+		maxPerAccount: maxPerAccount,
+		logger:        logger,
+	}
+}
+
+// Subscribe registra uma conexão da conta. Com lastEventID, devolve os eventos
+// posteriores a ele; resumed é false quando o ID não está mais no histórico e o
+// cliente precisa recarregar saldo e extrato.
+func (b *Broker) Subscribe(accountID, lastEventID string) (sub *Subscription, replay []Event, resumed bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, nil, false, ErrBrokerClosed
+	}
+	if b.connections >= b.maxTotal {
+		return nil, nil, false, ErrTooManyConnections
+	}
+	if len(b.subscribers[accountID]) >= b.maxPerAccount {
+		return nil, nil, false, ErrAccountConnectionsReached
+	}
+
+	resumed = true
+	if lastEventID != "" {
+		resumed = false
+		if history := b.history[accountID]; history != nil {
+			for i, event := range history.events {
+				if event.ID == lastEventID {
+					replay = append(replay, history.events[i+1:]...)
+					resumed = true
+					break
+				}
+			}
+		}
+	}
+
+	sub = &Subscription{
+		accountID: accountID,
+		events:    make(chan Event, subscriberBuffer),
+	}
+	if b.subscribers[accountID] == nil {
+		b.subscribers[accountID] = make(map[*Subscription]struct{})
+	}
+	b.subscribers[accountID][sub] = struct{}{}
+	b.connections++
+
+	return sub, replay, resumed, nil
+}
+
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remove(sub)
+}
+
+// remove deve ser chamado com o lock.
+func (b *Broker) remove(sub *Subscription) {
+	subs := b.subscribers[sub.accountID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subscribers, sub.accountID)
+	}
+	b.connections--
+	close(sub.events)
+}
+
+// Deliver entrega o evento às conexões da conta nesta instância. Eventos já recebidos
+// (mesmo ID) são ignorados. Uma conexão com o buffer cheio é encerrada, e o cliente
+// retoma do último evento que recebeu.
+func (b *Broker) Deliver(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	history := b.history[event.AccountID]
+	if history == nil {
+		history = &accountHistory{}
+		b.history[event.AccountID] = history
+	}
+	for _, existing := range history.events {
+		if existing.ID == event.ID {
+			return
+		}
+	}
+	history.events = append(history.events, event)
+	if len(history.events) > historySize {
+		history.events = history.events[len(history.events)-historySize:]
+	}
+	history.updatedAt = time.Now()
+
+	for sub := range b.subscribers[event.AccountID] {
+		select {
+		case sub.events <- event:
+		default:
+			b.logger.WithField("accountId", event.AccountID).Warn("Realtime subscriber too slow, closing connection")
+			b.remove(sub)
+		}
+	}
+}
+
+// PruneHistory descarta o histórico das contas sem eventos há mais de historyTTL.
+func (b *Broker) PruneHistory(now time.Time) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pruned := 0
+	for accountID, history := range b.history {
+		if now.Sub(history.updatedAt) > historyTTL {
+			delete(b.history, accountID)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// Close encerra todas as conexões e recusa novas. Deve ser chamado antes do
+// desligamento do servidor HTTP, que espera as conexões abertas terminarem.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, subs := range b.subscribers {
+		for sub := range subs {
+			b.remove(sub)
+		}
+	}
+}
+
+// StartHistoryCleanup descarta periodicamente o histórico antigo até o canal stop ser fechado.
+func StartHistoryCleanup(broker *Broker, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				broker.PruneHistory(time.Now())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// GetMaxConnections lê REALTIME_MAX_CONNECTIONS (padrão de 1000 conexões por instância).
+func GetMaxConnections() int {
+	return positiveInt("REALTIME_MAX_CONNECTIONS", 1000)
+}
+
+// GetMaxConnectionsPerAccount lê REALTIME_MAX_CONNECTIONS_PER_ACCOUNT (padrão de 5).
+func GetMaxConnectionsPerAccount() int {
+	return positiveInt("REALTIME_MAX_CONNECTIONS_PER_ACCOUNT", 5)
+}
+
+// GetHeartbeatInterval lê REALTIME_HEARTBEAT_SECONDS (padrão de 15 segundos).
+func GetHeartbeatInterval() time.Duration {
+	return time.Duration(positiveInt("REALTIME_HEARTBEAT_SECONDS", 15)) * time.Second
+}
+
+func positiveInt(name string, fallback int) int {
+	if value := os.Getenv(name); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,149 @@
+package realtime
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"bankmore/internal/shared/kafka"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Relay é o Publisher da account-api: entrega cada evento nesta instância e o replica
+// às demais pelo tópico account-events. Como MessageHandler, recebe as réplicas e os
+// eventos de transferência e tarifa, que todas as instâncias consomem.
+type Relay struct {
+	broker   *Broker
+	producer *kafka.Producer
+	instance string
+	logger   *logrus.Logger
+}
+
+func NewRelay(broker *Broker, producer *kafka.Producer, instance string, logger *logrus.Logger) *Relay {
+	return &Relay{
+		broker:   broker,
+		producer: producer,
+		instance: instance,
+		logger:   logger,
+	}
+}
+
+// Topics são os tópicos que cada instância deve consumir, em um grupo próprio.
+func Topics() []string {
+	return []string{kafka.TopicAccountEvents, kafka.TopicTransferEvents, kafka.TopicFeeEvents}
+}
+
+func (r *Relay) Publish(event Event) {
+	r.broker.Deliver(event)
+
+	if err := r.producer.PublishAccountEvent(kafka.AccountEvent{
+		ID:         event.ID,
+		AccountID:  event.AccountID,
+		Type:       event.Type,
+		Data:       event.Data,
+		OccurredAt: event.OccurredAt,
+		Origin:     r.instance,
+	}); err != nil {
+		// Os clientes conectados a esta instância já receberam o evento; os das demais
+		// não o recebem, mas o saldo consultado por eles continua correto.
+		r.logger.WithError(err).WithField("eventId", event.ID).Warn("Could not relay account event")
+	}
+}
+
+type transferSentData struct {
+	TransferID               string  `json:"transferId"`
+	Amount                   float64 `json:"amount"`
+	DestinationAccountNumber string  `json:"destinationAccountNumber"`
+	TransferType             string  `json:"transferType,omitempty"`
+}
+
+type transferReceivedData struct {
+	TransferID   string  `json:"transferId"`
+	Amount       float64 `json:"amount"`
+	TransferType string  `json:"transferType,omitempty"`
+}
+
+type feeChargedData struct {
+	FeeID      string  `json:"feeId"`
+	Amount     float64 `json:"amount"`
+	TransferID string  `json:"transferId"`
+}
+
+func (r *Relay) HandleMessage(topic string, value []byte) error {
+	switch topic {
+	case kafka.TopicAccountEvents:
+		var event kafka.AccountEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		if event.Origin == r.instance {
+			return nil
+		}
+		r.broker.Deliver(Event{
+			ID:         event.ID,
+			AccountID:  event.AccountID,
+			Type:       event.Type,
+			Data:       event.Data,
+			OccurredAt: event.OccurredAt,
+		})
+
+	case kafka.TopicTransferEvents:
+		var event kafka.TransferEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		// O evento é publicado depois da conclusão, então o horário de consumo é
+		// suficiente para o cliente.
+		now := time.Now()
+		sent, err := NewEvent(DerivedEventID(EventTransferSent, event.TransferID), event.OriginAccountID, EventTransferSent, transferSentData{
+			TransferID:               event.TransferID,
+			Amount:                   event.Amount,
+			DestinationAccountNumber: event.DestinationAccountNumber,
+			TransferType:             event.Type,
+		}, now)
+		if err != nil {
+			return err
+		}
+		received, err := NewEvent(DerivedEventID(EventTransferReceived, event.TransferID), event.DestinationAccountID, EventTransferReceived, transferReceivedData{
+			TransferID:   event.TransferID,
+			Amount:       event.Amount,
+			TransferType: event.Type,
+		}, now)
+		if err != nil {
+			return err
+		}
+		r.broker.Deliver(sent)
+		r.broker.Deliver(received)
+
+	case kafka.TopicFeeEvents:
+		var event kafka.FeeEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		charged, err := NewEvent(DerivedEventID(EventFeeCharged, event.FeeID), event.AccountID, EventFeeCharged, feeChargedData{
+			FeeID:      event.FeeID,
+			Amount:     event.Amount,
+			TransferID: event.TransferID,
+		}, event.ChargedAt)
+		if err != nil {
+			return err
+		}
+		r.broker.Deliver(charged)
+	}
+
+	return nil
+}
+
+// GetInstanceID lê REALTIME_INSTANCE_ID; sem ele usa o hostname. O ID compõe o grupo
+// de consumo, que precisa ser único por instância para que todas recebam todos os eventos.
+func GetInstanceID() string {
+	if value := os.Getenv("REALTIME_INSTANCE_ID"); value != "" {
+		return value
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return uuid.New().String()
+}
@@ -0,0 +1,17 @@
+package utils
+
+import "os"
+
+const (
+	// BankName é o nome do banco nos documentos emitidos aos clientes.
+	BankName        = "BankMore"
+	defaultBankCode = "999"
+)
+
+// GetBankCode lê BANK_CODE, o código do banco nos documentos emitidos (padrão 999).
+func GetBankCode() string {
+	if code := os.Getenv("BANK_CODE"); code != "" {
+		return code
+	}
+	return defaultBankCode
+}
@@ -0,0 +1,26 @@
+package utils
+
+import "time"
+
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock devolve sempre o mesmo instante; útil para testes que dependem de tempo.
+type FixedClock struct {
+	Time time.Time
+}
+
+func (c *FixedClock) Now() time.Time {
+	return c.Time
+}
+
+func (c *FixedClock) Advance(d time.Duration) {
+	c.Time = c.Time.Add(d)
+}
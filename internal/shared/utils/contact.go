@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var emailPattern = regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}$`)
+
+// NormalizeEmail devolve o e-mail em minúsculas e sem espaços, ou erro se inválido.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if len(email) > 77 || !emailPattern.MatchString(email) {
+		return "", fmt.Errorf("e-mail inválido")
+	}
+	return email, nil
+}
+
+// NormalizePhone converte celulares brasileiros para o formato E.164 (+55DDNNNNNNNNN).
+// Aceita o número com ou sem o código do país e com pontuação.
+func NormalizePhone(phone string) (string, error) {
+	digits := onlyDigits(phone)
+	if strings.HasPrefix(strings.TrimSpace(phone), "+") || len(digits) == 13 {
+		if !strings.HasPrefix(digits, "55") {
+			return "", fmt.Errorf("somente telefones brasileiros são aceitos")
+		}
+		digits = digits[2:]
+	}
+
+	if len(digits) != 11 || digits[0] == '0' || digits[2] != '9' {
+		return "", fmt.Errorf("telefone celular inválido")
+	}
+
+	return "+55" + digits, nil
+}
+
+// MaskName mantém o primeiro nome e mostra apenas a inicial dos demais, como
+// "Maria S**** O*******". Partículas curtas (da, de, dos) ficam visíveis.
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		if i == 0 || (utf8.RuneCountInString(word) <= 3 && strings.ToLower(word) == word) {
+			continue
+		}
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(first) + strings.Repeat("*", utf8.RuneCountInString(word[size:]))
+	}
+	return strings.Join(words, " ")
+}
+
+// MaskCPF mostra apenas os dígitos centrais, no formato ***.456.789-**.
+func MaskCPF(cpf string) string {
+	cpf = CleanCPF(cpf)
+	if len(cpf) != 11 {
+		return "***.***.***-**"
+	}
+	return fmt.Sprintf("***.%s.%s-**", cpf[3:6], cpf[6:9])
+}
+
+// GenerateNumericCode gera um código numérico aleatório com o número de dígitos informado.
+func GenerateNumericCode(digits int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+func onlyDigits(value string) string {
+	var builder strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
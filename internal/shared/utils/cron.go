@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule é uma expressão cron de cinco campos (minuto, hora, dia do mês, mês e
+// dia da semana). Aceita *, listas, intervalos e passos; nomes de mês e dia não.
+type CronSchedule struct {
+	Minute     uint64
+	Hour       uint64
+	DayOfMonth uint64
+	Month      uint64
+	DayOfWeek  uint64
+
+	// Como no cron tradicional, se dia do mês e dia da semana forem restritos, basta
+	// um dos dois coincidir.
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minuto", 0, 59},
+	{"hora", 0, 23},
+	{"dia do mês", 1, 31},
+	{"mês", 1, 12},
+	{"dia da semana", 0, 7},
+}
+
+func ParseCron(expression string) (*CronSchedule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("expressão cron deve ter 5 campos")
+	}
+
+	bits := make([]uint64, len(parts))
+	for i, part := range parts {
+		value, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = value
+	}
+
+	// 7 também representa domingo.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &CronSchedule{
+		Minute:        bits[0],
+		Hour:          bits[1],
+		DayOfMonth:    bits[2],
+		Month:         bits[3],
+		DayOfWeek:     bits[4],
+		anyDayOfMonth: strings.HasPrefix(parts[2], "*"),
+		anyDayOfWeek:  strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(value, ",") {
+		rangePart, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			rangePart = item[:i]
+			parsed, err := strconv.Atoi(item[i+1:])
+			if err != nil || parsed < 1 {
+				return 0, fmt.Errorf("passo inválido no campo %s", field.name)
+			}
+			step = parsed
+		}
+
+		start, end := field.min, field.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("intervalo inválido no campo %s", field.name)
+			}
+			if end, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, fmt.Errorf("intervalo inválido no campo %s", field.name)
+			}
+		default:
+			parsed, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("valor inválido no campo %s", field.name)
+			}
+			start = parsed
+			if step == 1 {
+				end = parsed
+			}
+		}
+
+		if start < field.min || end > field.max || start > end {
+			return 0, fmt.Errorf("campo %s fora do intervalo %d-%d", field.name, field.min, field.max)
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next devolve o primeiro horário estritamente posterior a after que satisfaz a
+// expressão, ou o tempo zero se não houver nenhum nos próximos cinco anos.
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.Month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.Hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.Minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// AtMostDaily indica se a expressão fixa um único minuto e uma única hora, ou seja,
+// dispara no máximo uma vez por dia.
+func (c *CronSchedule) AtMostDaily() bool {
+	return singleBit(c.Minute) && singleBit(c.Hour)
+}
+
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := c.DayOfMonth&(1<<uint(t.Day())) != 0
+	dowMatch := c.DayOfWeek&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case c.anyDayOfMonth && c.anyDayOfWeek:
+		return true
+	case c.anyDayOfMonth:
+		return dowMatch
+	case c.anyDayOfWeek:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+func singleBit(bits uint64) bool {
+	return bits != 0 && bits&(bits-1) == 0
+}
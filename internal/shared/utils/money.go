@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// FormatAmount formata o valor no padrão brasileiro, sem o símbolo da moeda: -1.234,56.
+func FormatAmount(amount float64) string {
+	cents := int64(math.Round(math.Abs(amount) * 100))
+	sign := ""
+	if amount < 0 && cents > 0 {
+		sign = "-"
+	}
+
+	digits := fmt.Sprintf("%d", cents/100)
+	var groups []string
+	for len(digits) > 3 {
+		groups = append([]string{digits[len(digits)-3:]}, groups...)
+		digits = digits[:len(digits)-3]
+	}
+	groups = append([]string{digits}, groups...)
+	return fmt.Sprintf("%s%s,%02d", sign, strings.Join(groups, "."), cents%100)
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret é a chave SHA-1 dos vetores de teste da RFC 6238 ("12345678901234567890")
+// em base32.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// Os vetores da RFC têm 8 dígitos; com 6, o código são os 6 últimos.
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestGenerateTOTPCodeRFC6238(t *testing.T) {
+	for _, vector := range rfc6238Vectors {
+		clock := &FixedClock{Time: time.Unix(vector.unix, 0)}
+
+		code, err := GenerateTOTPCode(rfc6238Secret, TOTPStep(clock.Now()))
+		if err != nil {
+			t.Fatalf("T=%d: erro inesperado: %v", vector.unix, err)
+		}
+		if code != vector.code {
+			t.Errorf("T=%d: código %s, esperado %s", vector.unix, code, vector.code)
+		}
+	}
+}
+
+func TestVerifyTOTPCodeRFC6238(t *testing.T) {
+	for _, vector := range rfc6238Vectors {
+		clock := &FixedClock{Time: time.Unix(vector.unix, 0)}
+
+		step, ok := VerifyTOTPCode(rfc6238Secret, vector.code, clock.Now())
+		if !ok {
+			t.Errorf("T=%d: código %s recusado", vector.unix, vector.code)
+			continue
+		}
+		if step != TOTPStep(clock.Now()) {
+			t.Errorf("T=%d: passo %d, esperado %d", vector.unix, step, TOTPStep(clock.Now()))
+		}
+	}
+}
+
+func TestVerifyTOTPCodeSkew(t *testing.T) {
+	clock := &FixedClock{Time: time.Unix(1111111111, 0)}
+	code, err := GenerateTOTPCode(rfc6238Secret, TOTPStep(clock.Now()))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	issuedStep := TOTPStep(clock.Now())
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		valid   bool
+	}{
+		{"mesmo passo", 0, true},
+		{"um passo depois", TOTPPeriod * time.Second, true},
+		{"um passo antes", -TOTPPeriod * time.Second, true},
+		{"dois passos depois", 2 * TOTPPeriod * time.Second, false},
+		{"dois passos antes", -2 * TOTPPeriod * time.Second, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := &FixedClock{Time: clock.Now()}
+			at.Advance(tt.advance)
+
+			step, ok := VerifyTOTPCode(rfc6238Secret, code, at.Now())
+			if ok != tt.valid {
+				t.Fatalf("válido = %v, esperado %v", ok, tt.valid)
+			}
+			if ok && step != issuedStep {
+				t.Errorf("passo %d, esperado o da emissão %d", step, issuedStep)
+			}
+		})
+	}
+}
+
+func TestVerifyTOTPCodeRejectsMalformed(t *testing.T) {
+	clock := &FixedClock{Time: time.Unix(59, 0)}
+
+	for _, code := range []string{"", "28708", "2870820", "abcdef", "94287082"} {
+		if _, ok := VerifyTOTPCode(rfc6238Secret, code, clock.Now()); ok {
+			t.Errorf("código %q aceito", code)
+		}
+	}
+	if _, ok := VerifyTOTPCode("não é base32!", "287082", clock.Now()); ok {
+		t.Error("segredo inválido aceito")
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(1)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	issued := codes[0]
+	want := NormalizeRecoveryCode(issued)
+
+	typed := []string{
+		issued,
+		strings.ReplaceAll(issued, "-", ""),
+		strings.ToUpper(issued),
+		" " + issued[:4] + " " + issued[5:] + " ",
+		issued[:2] + "-" + issued[2:4] + issued[5:],
+	}
+	for _, code := range typed {
+		if got := NormalizeRecoveryCode(code); got != want {
+			t.Errorf("%q: normalizado %q, esperado %q", code, got, want)
+		}
+	}
+
+	if strings.ContainsAny(want, "- ") {
+		t.Errorf("código normalizado %q ainda tem separadores", want)
+	}
+}
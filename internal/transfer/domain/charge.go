@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ChargeStatusActive     = "ACTIVE"
+	ChargeStatusProcessing = "PROCESSING"
+	ChargeStatusPaid       = "PAID"
+	ChargeStatusExpired    = "EXPIRED"
+)
+
+// Charge é uma cobrança dinâmica: um BR Code de uso único, com valor fixo e validade,
+// identificado pelo txid e conciliado com a transferência que a pagou.
+type Charge struct {
+	ID                string     `json:"id" gorm:"column:idcobranca;primaryKey"`
+	TxID              string     `json:"txid" gorm:"column:txid;unique"`
+	AccountID         string     `json:"accountId" gorm:"column:idcontacorrente"`
+	Key               string     `json:"key" gorm:"column:chave"`
+	Amount            float64    `json:"amount" gorm:"column:valor"`
+	Description       string     `json:"description" gorm:"column:descricao"`
+	Status            string     `json:"status" gorm:"column:situacao"`
+	Payload           string     `json:"payload" gorm:"column:payload"`
+	CreatedAt         time.Time  `json:"createdAt" gorm:"column:data_criacao"`
+	ExpiresAt         time.Time  `json:"expiresAt" gorm:"column:data_expiracao"`
+	PaidAt            *time.Time `json:"paidAt" gorm:"column:data_pagamento"`
+	PaymentTransferID *string    `json:"paymentTransferId" gorm:"column:idtransferencia"`
+}
+
+func (Charge) TableName() string {
+	return "cobranca"
+}
+
+func NewCharge(accountID, key string, amount float64, description string, ttl time.Duration) *Charge {
+	now := time.Now()
+	return &Charge{
+		ID:          uuid.New().String(),
+		TxID:        NewChargeTxID(),
+		AccountID:   accountID,
+		Key:         key,
+		Amount:      amount,
+		Description: description,
+		Status:      ChargeStatusActive,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+}
+
+// NewChargeTxID gera um txid alfanumérico de 25 caracteres, o máximo aceito no BR Code.
+func NewChargeTxID() string {
+	return strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", ""))[:25]
+}
+
+// RefreshStatus marca como expirada a cobrança ativa vencida. Retorna true se mudou.
+func (c *Charge) RefreshStatus(now time.Time) bool {
+	if c.Status == ChargeStatusActive && now.After(c.ExpiresAt) {
+		c.Status = ChargeStatusExpired
+		return true
+	}
+	return false
+}
+
+func (c *Charge) CanBePaid(now time.Time) error {
+	c.RefreshStatus(now)
+	switch c.Status {
+	case ChargeStatusActive:
+		return nil
+	case ChargeStatusPaid:
+		return fmt.Errorf("cobrança já paga")
+	case ChargeStatusProcessing:
+		return fmt.Errorf("cobrança com pagamento em andamento")
+	default:
+		return fmt.Errorf("cobrança expirada")
+	}
+}
+
+func (c *Charge) MarkPaid(transferID string, now time.Time) {
+	c.Status = ChargeStatusPaid
+	c.PaidAt = &now
+	c.PaymentTransferID = &transferID
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Códigos de motivo de devolução, os mesmos usados nas devoluções Pix.
+const (
+	RefundReasonBankError        = "BE08"
+	RefundReasonFraud            = "FR01"
+	RefundReasonCustomerRequest  = "MD06"
+	RefundReasonRecipientRequest = "SL02"
+)
+
+var RefundReasons = map[string]string{
+	RefundReasonBankError:        "Erro bancário",
+	RefundReasonFraud:            "Fraude",
+	RefundReasonCustomerRequest:  "Devolução solicitada pelo pagador",
+	RefundReasonRecipientRequest: "Motivo definido pelo recebedor",
+}
+
+// NewRefund cria a devolução de parte ou de todo o valor de uma transferência recebida:
+// uma nova transferência, do destino de volta para a origem, vinculada à original.
+func NewRefund(original *Transfer, amount float64, reasonCode, description, requestID string) *Transfer {
+	refund := NewTransfer(original.DestinationAccountID, original.OriginAccountID, amount, description, &requestID)
+	refund.OriginalTransferID = &original.ID
+	refund.RefundReason = reasonCode
+	return refund
+}
+
+func (t *Transfer) IsRefund() bool {
+	return t.OriginalTransferID != nil
+}
+
+// RefundableAmount é o valor da transferência que ainda pode ser devolvido.
+func (t *Transfer) RefundableAmount() float64 {
+	remaining := t.Amount - t.RefundedAmount
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// CanBeRefunded verifica se a transferência está concluída, não é ela mesma uma
+// devolução e foi concluída há no máximo window.
+func (t *Transfer) CanBeRefunded(now time.Time, window time.Duration) error {
+	if t.IsRefund() {
+		return fmt.Errorf("uma devolução não pode ser devolvida")
+	}
+	if t.Status != TransferStatusCompleted {
+		return fmt.Errorf("somente transferências concluídas podem ser devolvidas")
+	}
+
+	completedAt := t.Date
+	if t.CompletionDate != nil {
+		completedAt = *t.CompletionDate
+	}
+	if now.After(completedAt.Add(window)) {
+		return fmt.Errorf("prazo para devolução encerrado")
+	}
+
+	if t.RefundableAmount() < 0.01 {
+		return fmt.Errorf("transferência já devolvida integralmente")
+	}
+	return nil
+}
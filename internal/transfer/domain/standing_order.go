@@ -0,0 +1,245 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"bankmore/internal/shared/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	StandingOrderFrequencyWeekly  = "WEEKLY"
+	StandingOrderFrequencyMonthly = "MONTHLY"
+	StandingOrderFrequencyCron    = "CRON"
+)
+
+const (
+	StandingOrderStatusActive    = "ACTIVE"
+	StandingOrderStatusPaused    = "PAUSED"
+	StandingOrderStatusCancelled = "CANCELLED"
+	StandingOrderStatusCompleted = "COMPLETED"
+)
+
+// standingOrderNamespace deriva IDs determinísticos das ocorrências: a mesma ordem no
+// mesmo período sempre gera a mesma transferência.
+var standingOrderNamespace = uuid.MustParse("5b0e7c1e-8a51-4f57-9d0f-3c1f2d6a9e44")
+
+// StandingOrder é uma transferência recorrente. Cada ocorrência vira uma transferência
+// agendada comum. WEEKLY repete no dia da semana e horário da data de início; MONTHLY
+// no dia DayOfMonth (ou no último dia de meses mais curtos), no horário da data de
+// início; CRON segue a expressão, no horário do servidor.
+type StandingOrder struct {
+	ID                       string     `json:"id" gorm:"column:idordem;primaryKey"`
+	OriginAccountID          string     `json:"originAccountId" gorm:"column:idcontacorrente_origem"`
+	DestinationAccountID     string     `json:"destinationAccountId" gorm:"column:idcontacorrente_destino"`
+	DestinationAccountNumber string     `json:"destinationAccountNumber" gorm:"column:numero_conta_destino"`
+	Amount                   float64    `json:"amount" gorm:"column:valor"`
+	Description              string     `json:"description" gorm:"column:descricao"`
+	Frequency                string     `json:"frequency" gorm:"column:frequencia"`
+	DayOfMonth               int        `json:"dayOfMonth,omitempty" gorm:"column:dia_mes"`
+	CronExpression           string     `json:"cronExpression,omitempty" gorm:"column:expressao_cron"`
+	StartDate                time.Time  `json:"startDate" gorm:"column:data_inicio"`
+	EndDate                  *time.Time `json:"endDate,omitempty" gorm:"column:data_fim"`
+	MaxOccurrences           int        `json:"maxOccurrences,omitempty" gorm:"column:max_ocorrencias"`
+	Occurrences              int        `json:"occurrences" gorm:"column:ocorrencias"`
+	NextRunAt                *time.Time `json:"nextRunAt,omitempty" gorm:"column:data_proxima_execucao"`
+	LastRunAt                *time.Time `json:"lastRunAt,omitempty" gorm:"column:data_ultima_execucao"`
+	Status                   string     `json:"status" gorm:"column:situacao"`
+	CreatedAt                time.Time  `json:"createdAt" gorm:"column:data_criacao"`
+}
+
+func (StandingOrder) TableName() string {
+	return "ordem_recorrente"
+}
+
+func NewStandingOrder(originAccountID, destinationAccountID, destinationAccountNumber string, amount float64, description, frequency string, dayOfMonth int, cronExpression string, startDate time.Time, endDate *time.Time, maxOccurrences int, now time.Time) (*StandingOrder, error) {
+	order := &StandingOrder{
+		ID:                       uuid.New().String(),
+		OriginAccountID:          originAccountID,
+		DestinationAccountID:     destinationAccountID,
+		DestinationAccountNumber: destinationAccountNumber,
+		Amount:                   amount,
+		Description:              description,
+		Frequency:                frequency,
+		DayOfMonth:               dayOfMonth,
+		CronExpression:           cronExpression,
+		StartDate:                startDate,
+		EndDate:                  endDate,
+		MaxOccurrences:           maxOccurrences,
+		Status:                   StandingOrderStatusActive,
+		CreatedAt:                now,
+	}
+
+	if err := order.validate(); err != nil {
+		return nil, err
+	}
+
+	order.scheduleFrom(now)
+	if order.Status == StandingOrderStatusCompleted {
+		return nil, fmt.Errorf("a ordem não tem nenhuma ocorrência dentro do período informado")
+	}
+
+	return order, nil
+}
+
+func (o *StandingOrder) validate() error {
+	switch o.Frequency {
+	case StandingOrderFrequencyWeekly:
+	case StandingOrderFrequencyMonthly:
+		if o.DayOfMonth < 1 || o.DayOfMonth > 31 {
+			return fmt.Errorf("dia do mês deve estar entre 1 e 31")
+		}
+	case StandingOrderFrequencyCron:
+		schedule, err := utils.ParseCron(o.CronExpression)
+		if err != nil {
+			return err
+		}
+		if !schedule.AtMostDaily() {
+			return fmt.Errorf("a expressão cron deve fixar minuto e hora (no máximo uma execução por dia)")
+		}
+	default:
+		return fmt.Errorf("frequência inválida: use WEEKLY, MONTHLY ou CRON")
+	}
+
+	if o.MaxOccurrences < 0 {
+		return fmt.Errorf("quantidade máxima de ocorrências não pode ser negativa")
+	}
+	if o.EndDate != nil && o.EndDate.Before(o.StartDate) {
+		return fmt.Errorf("data de término deve ser posterior à data de início")
+	}
+	return nil
+}
+
+// nextOccurrence devolve a primeira ocorrência em from ou depois, nunca antes da data
+// de início.
+func (o *StandingOrder) nextOccurrence(from time.Time) time.Time {
+	if from.Before(o.StartDate) {
+		from = o.StartDate
+	}
+
+	switch o.Frequency {
+	case StandingOrderFrequencyWeekly:
+		weeks := int(from.Sub(o.StartDate) / (7 * 24 * time.Hour))
+		if weeks > 0 {
+			weeks--
+		}
+		next := o.StartDate.AddDate(0, 0, 7*weeks)
+		for next.Before(from) {
+			weeks++
+			next = o.StartDate.AddDate(0, 0, 7*weeks)
+		}
+		return next
+
+	case StandingOrderFrequencyMonthly:
+		year, month := from.Year(), from.Month()
+		for {
+			next := o.monthlyDate(year, month)
+			if !next.Before(from) {
+				return next
+			}
+			month++
+			if month > time.December {
+				year, month = year+1, time.January
+			}
+		}
+
+	default:
+		schedule, err := utils.ParseCron(o.CronExpression)
+		if err != nil {
+			return time.Time{}
+		}
+		return schedule.Next(from.Add(-time.Nanosecond))
+	}
+}
+
+func (o *StandingOrder) monthlyDate(year int, month time.Month) time.Time {
+	day := o.DayOfMonth
+	if last := time.Date(year, month+1, 0, 0, 0, 0, 0, o.StartDate.Location()).Day(); day > last {
+		day = last
+	}
+	return time.Date(year, month, day, o.StartDate.Hour(), o.StartDate.Minute(), 0, 0, o.StartDate.Location())
+}
+
+// scheduleFrom define a próxima execução a partir de from e conclui a ordem quando o
+// limite de ocorrências ou a data de término foram atingidos.
+func (o *StandingOrder) scheduleFrom(from time.Time) {
+	if o.MaxOccurrences > 0 && o.Occurrences >= o.MaxOccurrences {
+		o.complete()
+		return
+	}
+
+	next := o.nextOccurrence(from)
+	if next.IsZero() || (o.EndDate != nil && next.After(*o.EndDate)) {
+		o.complete()
+		return
+	}
+	o.NextRunAt = &next
+}
+
+func (o *StandingOrder) complete() {
+	o.Status = StandingOrderStatusCompleted
+	o.NextRunAt = nil
+}
+
+// Period identifica a ocorrência que cai em runAt: o mês para ordens mensais, a data
+// para semanais e o minuto para expressões cron.
+func (o *StandingOrder) Period(runAt time.Time) string {
+	switch o.Frequency {
+	case StandingOrderFrequencyMonthly:
+		return runAt.Format("2006-01")
+	case StandingOrderFrequencyWeekly:
+		return runAt.Format("2006-01-02")
+	default:
+		return runAt.Format("2006-01-02T15:04")
+	}
+}
+
+// NewOccurrence cria a transferência agendada da ocorrência em runAt. O ID e a chave de
+// idempotência derivam da ordem e do período, de modo que materializar a mesma
+// ocorrência duas vezes gera sempre a mesma transferência.
+func (o *StandingOrder) NewOccurrence(runAt time.Time) *Transfer {
+	key := uuid.NewSHA1(standingOrderNamespace, []byte(o.ID+"|"+o.Period(runAt))).String()
+
+	transfer := NewScheduledTransfer(o.OriginAccountID, o.DestinationAccountID, o.Amount, o.Description, &key, runAt)
+	transfer.ID = key
+	transfer.StandingOrderID = &o.ID
+	return transfer
+}
+
+// Advance registra a ocorrência em runAt e calcula a seguinte.
+func (o *StandingOrder) Advance(runAt time.Time) {
+	o.Occurrences++
+	o.LastRunAt = &runAt
+	o.scheduleFrom(runAt.Add(time.Minute))
+}
+
+func (o *StandingOrder) Pause() error {
+	if o.Status != StandingOrderStatusActive {
+		return fmt.Errorf("somente ordens ativas podem ser pausadas")
+	}
+	o.Status = StandingOrderStatusPaused
+	o.NextRunAt = nil
+	return nil
+}
+
+// Resume reativa a ordem a partir de now; as ocorrências perdidas durante a pausa não
+// são executadas.
+func (o *StandingOrder) Resume(now time.Time) error {
+	if o.Status != StandingOrderStatusPaused {
+		return fmt.Errorf("somente ordens pausadas podem ser retomadas")
+	}
+	o.Status = StandingOrderStatusActive
+	o.scheduleFrom(now)
+	return nil
+}
+
+func (o *StandingOrder) Cancel() error {
+	if o.Status != StandingOrderStatusActive && o.Status != StandingOrderStatusPaused {
+		return fmt.Errorf("ordem já encerrada")
+	}
+	o.Status = StandingOrderStatusCancelled
+	o.NextRunAt = nil
+	return nil
+}
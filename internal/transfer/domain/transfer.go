@@ -1,21 +1,40 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
+	"bankmore/internal/shared/calendar"
+
 	"github.com/google/uuid"
 )
 
 type Transfer struct {
-	ID                    string     `json:"id" gorm:"column:idtransferencia;primaryKey"`
-	OriginAccountID       string     `json:"originAccountId" gorm:"column:idcontacorrente_origem"`
-	DestinationAccountID  string     `json:"destinationAccountId" gorm:"column:idcontacorrente_destino"`
-	Date                  time.Time  `json:"date" gorm:"column:datamovimento"`
-	Amount                float64    `json:"amount" gorm:"column:valor"`
-	Status                int        `json:"status" gorm:"column:status"`
-	CompletionDate        *time.Time `json:"completionDate" gorm:"column:data_conclusao"`
-	Description           string     `json:"description" gorm:"column:descricao"`
-	IdempotencyKey        *string    `json:"idempotencyKey" gorm:"column:idempotencia_key"`
+	ID                   string     `json:"id" gorm:"column:idtransferencia;primaryKey"`
+	OriginAccountID      string     `json:"originAccountId" gorm:"column:idcontacorrente_origem"`
+	DestinationAccountID string     `json:"destinationAccountId" gorm:"column:idcontacorrente_destino"`
+	Date                 time.Time  `json:"date" gorm:"column:datamovimento"`
+	Amount               float64    `json:"amount" gorm:"column:valor"`
+	Status               int        `json:"status" gorm:"column:status"`
+	CompletionDate       *time.Time `json:"completionDate" gorm:"column:data_conclusao"`
+	Description          string     `json:"description" gorm:"column:descricao"`
+	IdempotencyKey       *string    `json:"idempotencyKey" gorm:"column:idempotencia_key"`
+	HoldID               *string    `json:"holdId" gorm:"column:idreserva"`
+	ScheduledFor         *time.Time `json:"scheduledFor,omitempty" gorm:"column:data_agendamento"`
+	Attempts             int        `json:"attempts" gorm:"column:tentativas"`
+	NextAttemptAt        *time.Time `json:"nextAttemptAt,omitempty" gorm:"column:data_proxima_tentativa"`
+	LastError            string     `json:"lastError,omitempty" gorm:"column:ultimo_erro"`
+	StandingOrderID      *string    `json:"standingOrderId,omitempty" gorm:"column:idordem"`
+	OriginalTransferID   *string    `json:"originalTransferId,omitempty" gorm:"column:idtransferencia_original"`
+	RefundReason         string     `json:"refundReason,omitempty" gorm:"column:motivo_devolucao"`
+	RefundedAmount       float64    `json:"refundedAmount" gorm:"column:valor_devolvido"`
+	Async                bool       `json:"async" gorm:"column:assincrona"`
+	ClaimedAt            *time.Time `json:"-" gorm:"column:data_processamento"`
+	GuaranteedFee        *float64   `json:"guaranteedFee,omitempty" gorm:"column:tarifa_garantida"`
+	Type                 string     `json:"type,omitempty" gorm:"column:tipo"`
+	EventPublishedAt     *time.Time `json:"-" gorm:"column:data_publicacao_evento"`
+	AccountingDate       *time.Time `json:"accountingDate,omitempty" gorm:"column:data_contabil"`
+	ExecutedAt           *time.Time `json:"executedAt,omitempty" gorm:"column:data_execucao"`
 }
 
 func (Transfer) TableName() string {
@@ -35,6 +54,25 @@ func NewTransfer(originAccountID, destinationAccountID string, amount float64, d
 	}
 }
 
+// NewScheduledTransfer cria uma transferência que só será executada na data agendada.
+// Agendamentos para fim de semana ou feriado passam para o próximo dia útil, no mesmo horário.
+func NewScheduledTransfer(originAccountID, destinationAccountID string, amount float64, description string, idempotencyKey *string, scheduledFor time.Time) *Transfer {
+	transfer := NewTransfer(originAccountID, destinationAccountID, amount, description, idempotencyKey)
+	transfer.Status = TransferStatusScheduled
+	scheduledFor = calendar.AdjustToBusinessDay(scheduledFor)
+	transfer.ScheduledFor = &scheduledFor
+	return transfer
+}
+
+// NewAsyncTransfer cria uma transferência aceita para execução em segundo plano. Ela
+// fica pendente, sem reserva, até um worker assumi-la.
+func NewAsyncTransfer(originAccountID, destinationAccountID string, amount float64, description string, idempotencyKey *string, guaranteedFee *float64) *Transfer {
+	transfer := NewTransfer(originAccountID, destinationAccountID, amount, description, idempotencyKey)
+	transfer.Async = true
+	transfer.GuaranteedFee = guaranteedFee
+	return transfer
+}
+
 func (t *Transfer) Complete() {
 	t.Status = TransferStatusCompleted
 	now := time.Now()
@@ -47,8 +85,34 @@ func (t *Transfer) Fail() {
 	t.CompletionDate = &now
 }
 
+func (t *Transfer) Cancel() error {
+	if t.Status != TransferStatusScheduled {
+		return fmt.Errorf("somente transferências agendadas podem ser canceladas")
+	}
+	t.Status = TransferStatusCancelled
+	now := time.Now()
+	t.CompletionDate = &now
+	t.NextAttemptAt = nil
+	return nil
+}
+
+// RecordFailedAttempt registra a falha de uma execução agendada. Com nextAttempt a
+// transferência volta a ficar agendada; sem ele, falha em definitivo.
+func (t *Transfer) RecordFailedAttempt(reason string, nextAttempt *time.Time) {
+	t.LastError = reason
+	if nextAttempt != nil {
+		t.Status = TransferStatusScheduled
+		t.NextAttemptAt = nextAttempt
+		return
+	}
+	t.NextAttemptAt = nil
+	t.Fail()
+}
+
 const (
 	TransferStatusPending   = 0
 	TransferStatusCompleted = 1
 	TransferStatusFailed    = 2
+	TransferStatusScheduled = 3
+	TransferStatusCancelled = 4
 )
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Modos de um lote: em ALL_OR_NOTHING o valor de todos os itens é reservado antes da
+// primeira transferência e, se algum item não puder ser reservado, nenhum é executado;
+// em BEST_EFFORT cada item é executado de forma independente.
+const (
+	BatchModeAllOrNothing = "ALL_OR_NOTHING"
+	BatchModeBestEffort   = "BEST_EFFORT"
+)
+
+const (
+	BatchStatusPending            = "PENDING"
+	BatchStatusProcessing         = "PROCESSING"
+	BatchStatusCompleted          = "COMPLETED"
+	BatchStatusPartiallyCompleted = "PARTIALLY_COMPLETED"
+	BatchStatusFailed             = "FAILED"
+)
+
+const (
+	BatchItemStatusPending   = "PENDING"
+	BatchItemStatusReserved  = "RESERVED"
+	BatchItemStatusCompleted = "COMPLETED"
+	BatchItemStatusFailed    = "FAILED"
+	BatchItemStatusCancelled = "CANCELLED"
+)
+
+// TransferBatch é um lote de transferências (por exemplo, uma folha de pagamento)
+// processado de forma assíncrona a partir de uma única conta de origem.
+type TransferBatch struct {
+	ID              string              `json:"id" gorm:"column:idlote;primaryKey"`
+	AccountID       string              `json:"accountId" gorm:"column:idcontacorrente"`
+	RequestID       string              `json:"requestId" gorm:"column:idempotencia_key;unique"`
+	Mode            string              `json:"mode" gorm:"column:modo"`
+	Status          string              `json:"status" gorm:"column:situacao"`
+	ItemCount       int                 `json:"itemCount" gorm:"column:quantidade_itens"`
+	TotalAmount     float64             `json:"totalAmount" gorm:"column:valor_total"`
+	CompletedCount  int                 `json:"completedCount" gorm:"column:itens_concluidos"`
+	FailedCount     int                 `json:"failedCount" gorm:"column:itens_falha"`
+	CompletedAmount float64             `json:"completedAmount" gorm:"column:valor_concluido"`
+	CreatedAt       time.Time           `json:"createdAt" gorm:"column:data_criacao"`
+	HeartbeatAt     *time.Time          `json:"-" gorm:"column:data_processamento"`
+	CompletionDate  *time.Time          `json:"completionDate,omitempty" gorm:"column:data_conclusao"`
+	Items           []TransferBatchItem `json:"items,omitempty" gorm:"-"`
+}
+
+func (TransferBatch) TableName() string {
+	return "lote_transferencia"
+}
+
+type TransferBatchItem struct {
+	ID                       string  `json:"id" gorm:"column:iditem;primaryKey"`
+	BatchID                  string  `json:"batchId" gorm:"column:idlote"`
+	Sequence                 int     `json:"sequence" gorm:"column:sequencia"`
+	DestinationAccountID     string  `json:"-" gorm:"column:idcontacorrente_destino"`
+	DestinationAccountNumber string  `json:"destinationAccountNumber" gorm:"column:numero_conta_destino"`
+	DestinationKey           string  `json:"destinationKey,omitempty" gorm:"column:chave_destino"`
+	Amount                   float64 `json:"amount" gorm:"column:valor"`
+	Description              string  `json:"description" gorm:"column:descricao"`
+	Status                   string  `json:"status" gorm:"column:situacao"`
+	HoldID                   *string `json:"-" gorm:"column:idreserva"`
+	TransferID               *string `json:"transferId,omitempty" gorm:"column:idtransferencia"`
+	Error                    string  `json:"error,omitempty" gorm:"column:erro"`
+}
+
+func (TransferBatchItem) TableName() string {
+	return "item_lote_transferencia"
+}
+
+func NewTransferBatch(accountID, requestID, mode string, items []TransferBatchItem) (*TransferBatch, error) {
+	if mode != BatchModeAllOrNothing && mode != BatchModeBestEffort {
+		return nil, fmt.Errorf("modo inválido: use ALL_OR_NOTHING ou BEST_EFFORT")
+	}
+
+	batch := &TransferBatch{
+		ID:        uuid.New().String(),
+		AccountID: accountID,
+		RequestID: requestID,
+		Mode:      mode,
+		Status:    BatchStatusPending,
+		ItemCount: len(items),
+		CreatedAt: time.Now(),
+		Items:     items,
+	}
+
+	for i := range batch.Items {
+		item := &batch.Items[i]
+		item.ID = uuid.New().String()
+		item.BatchID = batch.ID
+		item.Sequence = i + 1
+		item.Status = BatchItemStatusPending
+		batch.TotalAmount += item.Amount
+	}
+
+	return batch, nil
+}
+
+func (i *TransferBatchItem) IsFinal() bool {
+	return i.Status == BatchItemStatusCompleted || i.Status == BatchItemStatusFailed || i.Status == BatchItemStatusCancelled
+}
+
+func (i *TransferBatchItem) Reserve(holdID string) {
+	i.Status = BatchItemStatusReserved
+	i.HoldID = &holdID
+}
+
+func (i *TransferBatchItem) Complete(transferID string) {
+	i.Status = BatchItemStatusCompleted
+	i.TransferID = &transferID
+	i.Error = ""
+}
+
+func (i *TransferBatchItem) Fail(reason string) {
+	i.Status = BatchItemStatusFailed
+	i.Error = reason
+}
+
+func (i *TransferBatchItem) Cancel(reason string) {
+	i.Status = BatchItemStatusCancelled
+	i.Error = reason
+}
+
+// Finish consolida os totais a partir dos itens e define a situação final do lote.
+func (b *TransferBatch) Finish(items []TransferBatchItem) {
+	b.CompletedCount, b.FailedCount, b.CompletedAmount = 0, 0, 0
+	for _, item := range items {
+		if item.Status == BatchItemStatusCompleted {
+			b.CompletedCount++
+			b.CompletedAmount += item.Amount
+		} else {
+			b.FailedCount++
+		}
+	}
+
+	switch {
+	case b.FailedCount == 0:
+		b.Status = BatchStatusCompleted
+	case b.CompletedCount == 0:
+		b.Status = BatchStatusFailed
+	default:
+		b.Status = BatchStatusPartiallyCompleted
+	}
+
+	now := time.Now()
+	b.CompletionDate = &now
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"bankmore/internal/shared/models"
+	"bankmore/internal/transfer/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type BatchHandler struct {
+	service service.TransferService
+	logger  *logrus.Logger
+}
+
+func NewBatchHandler(service service.TransferService, logger *logrus.Logger) *BatchHandler {
+	return &BatchHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// BatchValidationErrorResponse lista os itens recusados na validação do lote.
+type BatchValidationErrorResponse struct {
+	models.ErrorResponse
+	Items []service.BatchItemError `json:"items"`
+}
+
+// @Summary Envia um lote de transferências
+// @Description Recebe um lote em JSON ou em CSV (multipart, campo file, com requestId, mode e totpCode como campos do formulário). Todos os itens são validados antes do aceite; o processamento é assíncrono
+// @Tags Batch
+// @Accept json
+// @Accept mpfd
+// @Produce json
+// @Param request body service.CreateBatchRequest true "Dados do lote"
+// @Success 202 {object} domain.TransferBatch
+// @Failure 400 {object} BatchValidationErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/batch [post]
+func (h *BatchHandler) CreateBatch(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	request, err := h.bindBatchRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	request.Authorization = c.GetHeader("Authorization")
+
+	result, err := h.service.CreateBatch(accountID, *request)
+	if err != nil {
+		var validationErr *service.BatchValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, BatchValidationErrorResponse{
+				ErrorResponse: models.ErrorResponse{
+					Type:    models.ErrorInvalidData,
+					Message: validationErr.Error(),
+				},
+				Items: validationErr.Items,
+			})
+			return
+		}
+
+		h.logger.WithError(err).Error("Error creating transfer batch")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: "Erro interno do servidor",
+		})
+		return
+	}
+
+	if !result.IsSuccess {
+		status := http.StatusBadRequest
+		if result.ErrorType == models.ErrorTwoFactorRequired || result.ErrorType == models.ErrorInvalidTwoFactor {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, models.ErrorResponse{
+			Type:    result.ErrorType,
+			Message: result.ErrorMessage,
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, result.Data)
+}
+
+// @Summary Lista os lotes de transferências
+// @Description Lista os lotes da conta logada com situação e totais, sem os itens
+// @Tags Batch
+// @Produce json
+// @Success 200 {array} domain.TransferBatch
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/batch [get]
+func (h *BatchHandler) ListBatches(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	batches, err := h.service.ListBatches(accountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, batches)
+}
+
+// @Summary Consulta um lote de transferências
+// @Description Retorna o lote com a situação geral e a situação de cada item
+// @Tags Batch
+// @Produce json
+// @Param id path string true "ID do lote"
+// @Success 200 {object} domain.TransferBatch
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/batch/{id} [get]
+func (h *BatchHandler) GetBatch(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	batch, err := h.service.GetBatch(accountID, c.Param("id"))
+	if err != nil {
+		respondBatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, batch)
+}
+
+// @Summary Relatório de um lote de transferências
+// @Description Baixa o relatório do lote em CSV, com a situação, a transferência e o erro de cada item
+// @Tags Batch
+// @Produce text/csv
+// @Param id path string true "ID do lote"
+// @Success 200 {file} binary
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/batch/{id}/report [get]
+func (h *BatchHandler) GetBatchReport(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetBatchReport(accountID, c.Param("id"))
+	if err != nil {
+		respondBatchError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=lote-%s.csv", c.Param("id")))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", report)
+}
+
+// bindBatchRequest lê o lote do corpo JSON ou do CSV enviado como multipart.
+func (h *BatchHandler) bindBatchRequest(c *gin.Context) (*service.CreateBatchRequest, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		var request service.CreateBatchRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			return nil, fmt.Errorf("Dados inválidos")
+		}
+		return &request, nil
+	}
+
+	requestID := c.PostForm("requestId")
+	if requestID == "" {
+		return nil, fmt.Errorf("requestId é obrigatório")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("arquivo CSV não enviado")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível ler o arquivo")
+	}
+	defer file.Close()
+
+	items, err := service.ParseBatchCSV(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.CreateBatchRequest{
+		RequestID: requestID,
+		Mode:      c.PostForm("mode"),
+		Items:     items,
+		TOTPCode:  c.PostForm("totpCode"),
+	}, nil
+}
+
+func respondBatchError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, service.ErrBatchNotFound) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, models.ErrorResponse{
+		Type:    models.ErrorInvalidData,
+		Message: err.Error(),
+	})
+}
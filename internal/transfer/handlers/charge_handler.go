@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"bankmore/internal/shared/brcode"
+	"bankmore/internal/shared/models"
+	"bankmore/internal/transfer/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ChargeHandler struct {
+	service service.ChargeService
+	logger  *logrus.Logger
+}
+
+func NewChargeHandler(service service.ChargeService, logger *logrus.Logger) *ChargeHandler {
+	return &ChargeHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// @Summary Gera um QR Code estático
+// @Description Gera um BR Code reutilizável para uma chave Pix da conta logada, com valor opcional. A resposta traz o payload (copia e cola) e o PNG em base64
+// @Tags Charges
+// @Accept json
+// @Produce json
+// @Param request body service.StaticChargeRequest true "Chave e valor"
+// @Success 200 {object} service.StaticChargeResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/charges/static [post]
+func (h *ChargeHandler) CreateStaticCharge(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	var request service.StaticChargeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	response, err := h.service.CreateStaticCharge(accountID, request)
+	if err != nil {
+		h.logger.WithError(err).Error("Error creating static charge")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidOperation,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Cria uma cobrança dinâmica
+// @Description Cria uma cobrança de uso único com valor, validade e txid. O pagamento marca a cobrança como paga
+// @Tags Charges
+// @Accept json
+// @Produce json
+// @Param request body service.DynamicChargeRequest true "Dados da cobrança"
+// @Success 201 {object} service.DynamicChargeResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/charges [post]
+func (h *ChargeHandler) CreateDynamicCharge(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	var request service.DynamicChargeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	response, err := h.service.CreateDynamicCharge(accountID, request)
+	if err != nil {
+		h.logger.WithError(err).Error("Error creating dynamic charge")
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidOperation,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary Lista as cobranças dinâmicas
+// @Description Lista as cobranças dinâmicas da conta logada com a situação atual
+// @Tags Charges
+// @Produce json
+// @Success 200 {array} domain.Charge
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/charges [get]
+func (h *ChargeHandler) ListCharges(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	charges, err := h.service.ListCharges(accountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, charges)
+}
+
+// @Summary Consulta uma cobrança dinâmica
+// @Description Retorna a cobrança pelo txid, com situação e transferência de pagamento
+// @Tags Charges
+// @Produce json
+// @Param txid path string true "txid da cobrança"
+// @Success 200 {object} domain.Charge
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/charges/{txid} [get]
+func (h *ChargeHandler) GetCharge(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	charge, err := h.service.GetCharge(accountID, c.Param("txid"))
+	if err != nil {
+		h.respondChargeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, charge)
+}
+
+// @Summary QR Code de uma cobrança dinâmica
+// @Description Retorna o QR Code da cobrança em PNG
+// @Tags Charges
+// @Produce png
+// @Param txid path string true "txid da cobrança"
+// @Success 200 {file} binary
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/charges/{txid}/qrcode [get]
+func (h *ChargeHandler) GetChargeQRCode(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	charge, err := h.service.GetCharge(accountID, c.Param("txid"))
+	if err != nil {
+		h.respondChargeError(c, err)
+		return
+	}
+
+	png, err := brcode.RenderPNG(charge.Payload, brcode.DefaultQRCodeSize)
+	if err != nil {
+		h.logger.WithError(err).Error("Error rendering QR code")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: "Erro interno do servidor",
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// @Summary Paga um QR Code
+// @Description Interpreta o BR Code (copia e cola), valida o CRC e, em cobranças dinâmicas, txid, valor e validade, e transfere para a chave do recebedor
+// @Tags Charges
+// @Accept json
+// @Produce json
+// @Param request body service.PayByPayloadRequest true "Payload do QR Code"
+// @Success 200 {object} service.TransferResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/pay [post]
+func (h *ChargeHandler) PayByPayload(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	var request service.PayByPayloadRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	request.Authorization = c.GetHeader("Authorization")
+
+	result, err := h.service.PayByPayload(request, accountID)
+	if err != nil {
+		h.logger.WithError(err).Error("Error paying QR code")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: "Erro interno do servidor",
+		})
+		return
+	}
+
+	respondTransferResult(c, result)
+}
+
+func (h *ChargeHandler) respondChargeError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrChargeNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Type:    models.ErrorInvalidOperation,
+		Message: err.Error(),
+	})
+}
+
+func accountIDFromToken(c *gin.Context) (string, bool) {
+	accountID, exists := c.Get("accountId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorUserUnauthorized,
+			Message: "Token inválido",
+		})
+		return "", false
+	}
+	return accountID.(string), true
+}
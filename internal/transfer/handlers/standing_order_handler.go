@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"bankmore/internal/shared/models"
+	"bankmore/internal/transfer/domain"
+	"bankmore/internal/transfer/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type StandingOrderHandler struct {
+	service service.StandingOrderService
+	logger  *logrus.Logger
+}
+
+func NewStandingOrderHandler(service service.StandingOrderService, logger *logrus.Logger) *StandingOrderHandler {
+	return &StandingOrderHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// @Summary Cria uma transferência recorrente
+// @Description Cria uma ordem recorrente semanal, mensal (dayOfMonth) ou por expressão cron (cronExpression), com data de início, término e quantidade máxima opcionais. Cada ocorrência gera uma transferência agendada
+// @Tags Standing Orders
+// @Accept json
+// @Produce json
+// @Param request body service.CreateStandingOrderRequest true "Dados da ordem recorrente"
+// @Success 201 {object} domain.StandingOrder
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/standing-orders [post]
+func (h *StandingOrderHandler) CreateStandingOrder(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	var request service.CreateStandingOrderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	request.Authorization = c.GetHeader("Authorization")
+
+	result, err := h.service.CreateStandingOrder(accountID, request)
+	if err != nil {
+		h.logger.WithError(err).Error("Error creating standing order")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: "Erro interno do servidor",
+		})
+		return
+	}
+
+	if !result.IsSuccess {
+		status := http.StatusBadRequest
+		if result.ErrorType == models.ErrorTwoFactorRequired || result.ErrorType == models.ErrorInvalidTwoFactor {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, models.ErrorResponse{
+			Type:    result.ErrorType,
+			Message: result.ErrorMessage,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result.Data)
+}
+
+// @Summary Lista as transferências recorrentes
+// @Description Lista as ordens recorrentes da conta logada com situação, ocorrências e próxima execução
+// @Tags Standing Orders
+// @Produce json
+// @Success 200 {array} domain.StandingOrder
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/standing-orders [get]
+func (h *StandingOrderHandler) ListStandingOrders(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	orders, err := h.service.ListStandingOrders(accountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+// @Summary Consulta uma transferência recorrente
+// @Description Retorna a ordem recorrente e as transferências geradas por ela
+// @Tags Standing Orders
+// @Produce json
+// @Param id path string true "ID da ordem"
+// @Success 200 {object} service.StandingOrderDetails
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/standing-orders/{id} [get]
+func (h *StandingOrderHandler) GetStandingOrder(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	details, err := h.service.GetStandingOrder(accountID, c.Param("id"))
+	if err != nil {
+		respondStandingOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, details)
+}
+
+// @Summary Pausa uma transferência recorrente
+// @Description Suspende a geração de ocorrências e cancela as que ainda aguardam execução
+// @Tags Standing Orders
+// @Produce json
+// @Param id path string true "ID da ordem"
+// @Success 200 {object} domain.StandingOrder
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/standing-orders/{id}/pause [put]
+func (h *StandingOrderHandler) PauseStandingOrder(c *gin.Context) {
+	h.changeStatus(c, h.service.PauseStandingOrder)
+}
+
+// @Summary Retoma uma transferência recorrente
+// @Description Reativa a ordem a partir da próxima ocorrência; as ocorrências do período pausado não são executadas
+// @Tags Standing Orders
+// @Produce json
+// @Param id path string true "ID da ordem"
+// @Success 200 {object} domain.StandingOrder
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/standing-orders/{id}/resume [put]
+func (h *StandingOrderHandler) ResumeStandingOrder(c *gin.Context) {
+	h.changeStatus(c, h.service.ResumeStandingOrder)
+}
+
+// @Summary Cancela uma transferência recorrente
+// @Description Encerra a ordem em definitivo e cancela as ocorrências que ainda aguardam execução
+// @Tags Standing Orders
+// @Produce json
+// @Param id path string true "ID da ordem"
+// @Success 200 {object} domain.StandingOrder
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/standing-orders/{id} [delete]
+func (h *StandingOrderHandler) CancelStandingOrder(c *gin.Context) {
+	h.changeStatus(c, h.service.CancelStandingOrder)
+}
+
+func (h *StandingOrderHandler) changeStatus(c *gin.Context, change func(accountID, orderID string) (*domain.StandingOrder, error)) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	order, err := change(accountID, c.Param("id"))
+	if err != nil {
+		respondStandingOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+func respondStandingOrderError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, service.ErrStandingOrderNotFound) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, models.ErrorResponse{
+		Type:    models.ErrorInvalidOperation,
+		Message: err.Error(),
+	})
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"bankmore/internal/shared/models"
 	"bankmore/internal/transfer/service"
@@ -23,13 +25,15 @@ func NewTransferHandler(service service.TransferService, logger *logrus.Logger)
 }
 
 // @Summary Realiza transferência entre contas
-// @Description Realiza transferência entre contas da mesma instituição
+// @Description Realiza transferência entre contas da mesma instituição. O destino é informado pelo número da conta ou por uma chave Pix (destinationKey). Com scheduledFor a transferência é agendada. Transferências imediatas respeitam o limite diário; com quoteId a tarifa cobrada é a da cotação. Com async (ou Prefer: respond-async) retorna 202 com a URL de status e executa em segundo plano
 // @Tags Transfer
 // @Accept json
 // @Produce json
 // @Param request body service.CreateTransferRequest true "Dados da transferência"
 // @Success 200 {object} service.TransferResponse
+// @Success 202 {object} service.TransferResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
 // @Security BearerAuth
 // @Router /api/transfer [post]
 func (h *TransferHandler) CreateTransfer(c *gin.Context) {
@@ -51,6 +55,11 @@ func (h *TransferHandler) CreateTransfer(c *gin.Context) {
 		return
 	}
 
+	request.Authorization = c.GetHeader("Authorization")
+	if strings.Contains(c.GetHeader("Prefer"), "respond-async") {
+		request.Async = true
+	}
+
 	result, err := h.service.CreateTransfer(request, accountID.(string))
 	if err != nil {
 		h.logger.WithError(err).Error("Error creating transfer")
@@ -61,6 +70,93 @@ func (h *TransferHandler) CreateTransfer(c *gin.Context) {
 		return
 	}
 
+	if result.IsSuccess && result.Data.StatusURL != "" {
+		c.Header("Location", result.Data.StatusURL)
+		c.JSON(http.StatusAccepted, result.Data)
+		return
+	}
+
+	respondTransferResult(c, result)
+}
+
+// @Summary Cota uma transferência antes da confirmação
+// @Description Valida o destino e retorna o titular mascarado, a tarifa, o saldo resultante e o limite diário restante, com um quoteId de curta duração. Enviado em POST /api/transfer, o quoteId garante a tarifa cotada
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Param request body service.QuoteRequest true "Dados da transferência"
+// @Success 200 {object} service.TransferQuote
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/quote [post]
+func (h *TransferHandler) QuoteTransfer(c *gin.Context) {
+	var request service.QuoteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.QuoteTransfer(accountID, request)
+	if err != nil {
+		h.logger.WithError(err).Error("Error quoting transfer")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: "Erro interno do servidor",
+		})
+		return
+	}
+
+	if !result.IsSuccess {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    result.ErrorType,
+			Message: result.ErrorMessage,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result.Data)
+}
+
+// @Summary Transfere o saldo de uma conta em encerramento
+// @Description Uso interno da API de contas no encerramento com transferência do saldo remanescente. Não gera tarifa
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Param request body service.SweepTransferRequest true "Dados da transferência de saldo"
+// @Success 200 {object} service.TransferResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/internal/sweep [post]
+func (h *TransferHandler) CreateSweepTransfer(c *gin.Context) {
+	var request service.SweepTransferRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	result, err := h.service.CreateSweepTransfer(request)
+	if err != nil {
+		h.logger.WithError(err).Error("Error creating sweep transfer")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: "Erro interno do servidor",
+		})
+		return
+	}
+
 	if !result.IsSuccess {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Type:    result.ErrorType,
@@ -71,3 +167,184 @@ func (h *TransferHandler) CreateTransfer(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result.Data)
 }
+
+// @Summary Lista as transferências agendadas
+// @Description Lista as transferências agendadas da conta logada, com situação, tentativas, próxima tentativa e último erro
+// @Tags Transfer
+// @Produce json
+// @Success 200 {array} domain.Transfer
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/scheduled [get]
+func (h *TransferHandler) ListScheduledTransfers(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	transfers, err := h.service.ListScheduledTransfers(accountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+// @Summary Cancela uma transferência agendada
+// @Description Cancela uma transferência agendada da conta logada que ainda não foi executada
+// @Tags Transfer
+// @Param id path string true "ID da transferência"
+// @Success 204
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/scheduled/{id} [delete]
+func (h *TransferHandler) CancelScheduledTransfer(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CancelScheduledTransfer(accountID, c.Param("id")); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrTransferNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, models.ErrorResponse{
+			Type:    models.ErrorInvalidTransfer,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Consulta uma transferência
+// @Description Retorna uma transferência enviada ou recebida pela conta logada, com o total já devolvido (refundedAmount)
+// @Tags Transfer
+// @Produce json
+// @Param id path string true "ID da transferência"
+// @Success 200 {object} domain.Transfer
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/{id} [get]
+func (h *TransferHandler) GetTransfer(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.service.GetTransfer(accountID, c.Param("id"))
+	if err != nil {
+		respondTransferLookupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// @Summary Devolve uma transferência recebida
+// @Description Devolve ao pagador todo ou parte do valor de uma transferência recebida, dentro do prazo de devolução. reasonCode: BE08 (erro bancário), FR01 (fraude), MD06 (solicitada pelo pagador) ou SL02 (motivo do recebedor). Sem amount, devolve o saldo ainda não devolvido
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Param id path string true "ID da transferência recebida"
+// @Param request body service.RefundRequest true "Dados da devolução"
+// @Success 200 {object} service.TransferResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/{id}/refund [post]
+func (h *TransferHandler) RefundTransfer(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	var request service.RefundRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	result, err := h.service.RefundTransfer(accountID, c.Param("id"), request)
+	if err != nil {
+		if errors.Is(err, service.ErrTransferNotFound) {
+			respondTransferLookupError(c, err)
+			return
+		}
+		h.logger.WithError(err).Error("Error refunding transfer")
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Type:    models.ErrorInternalError,
+			Message: "Erro interno do servidor",
+		})
+		return
+	}
+
+	respondTransferResult(c, result)
+}
+
+// @Summary Lista as devoluções de uma transferência
+// @Description Lista as devoluções vinculadas a uma transferência, com situação, valor e motivo de cada uma
+// @Tags Transfer
+// @Produce json
+// @Param id path string true "ID da transferência"
+// @Success 200 {array} domain.Transfer
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/{id}/refunds [get]
+func (h *TransferHandler) ListRefunds(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	refunds, err := h.service.ListRefunds(accountID, c.Param("id"))
+	if err != nil {
+		respondTransferLookupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, refunds)
+}
+
+func respondTransferLookupError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, service.ErrTransferNotFound) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, models.ErrorResponse{
+		Type:    models.ErrorInvalidTransfer,
+		Message: err.Error(),
+	})
+}
+
+// respondTransferResult escreve o resultado de uma transferência iniciada pelo cliente;
+// falhas de 2FA viram 401 para o app solicitar o código e o reenvio de um requestId com
+// outros dados, 422.
+func respondTransferResult(c *gin.Context, result *models.Result[service.TransferResponse]) {
+	if !result.IsSuccess {
+		status := http.StatusBadRequest
+		switch result.ErrorType {
+		case models.ErrorTwoFactorRequired, models.ErrorInvalidTwoFactor:
+			status = http.StatusUnauthorized
+		case models.ErrorIdempotencyKeyReused:
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, models.ErrorResponse{
+			Type:    result.ErrorType,
+			Message: result.ErrorMessage,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result.Data)
+}
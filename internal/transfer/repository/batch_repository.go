@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"time"
+
+	"bankmore/internal/transfer/domain"
+
+	"gorm.io/gorm"
+)
+
+type BatchRepository interface {
+	Create(batch *domain.TransferBatch) error
+	GetByID(id string) (*domain.TransferBatch, error)
+	GetByRequestID(requestID string) (*domain.TransferBatch, error)
+	GetByAccountID(accountID string) ([]domain.TransferBatch, error)
+	GetItems(batchID string) ([]domain.TransferBatchItem, error)
+	Update(batch *domain.TransferBatch) error
+	UpdateItem(item *domain.TransferBatchItem) error
+	GetProcessable(staleBefore time.Time, limit int) ([]domain.TransferBatch, error)
+	Claim(id string, staleBefore, now time.Time) (bool, error)
+	Heartbeat(id string, now time.Time) error
+}
+
+type batchRepository struct {
+	db *gorm.DB
+}
+
+func NewBatchRepository(db *gorm.DB) BatchRepository {
+	return &batchRepository{db: db}
+}
+
+// Create grava o lote e seus itens na mesma transação.
+func (r *batchRepository) Create(batch *domain.TransferBatch) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		if len(batch.Items) == 0 {
+			return nil
+		}
+		return tx.Create(&batch.Items).Error
+	})
+}
+
+func (r *batchRepository) GetByID(id string) (*domain.TransferBatch, error) {
+	var batch domain.TransferBatch
+	err := r.db.Where("idlote = ?", id).First(&batch).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *batchRepository) GetByRequestID(requestID string) (*domain.TransferBatch, error) {
+	var batch domain.TransferBatch
+	err := r.db.Where("idempotencia_key = ?", requestID).First(&batch).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *batchRepository) GetByAccountID(accountID string) ([]domain.TransferBatch, error) {
+	var batches []domain.TransferBatch
+	err := r.db.Where("idcontacorrente = ?", accountID).
+		Order("data_criacao DESC").
+		Find(&batches).Error
+	return batches, err
+}
+
+func (r *batchRepository) GetItems(batchID string) ([]domain.TransferBatchItem, error) {
+	var items []domain.TransferBatchItem
+	err := r.db.Where("idlote = ?", batchID).
+		Order("sequencia ASC").
+		Find(&items).Error
+	return items, err
+}
+
+func (r *batchRepository) Update(batch *domain.TransferBatch) error {
+	return r.db.Save(batch).Error
+}
+
+func (r *batchRepository) UpdateItem(item *domain.TransferBatchItem) error {
+	return r.db.Save(item).Error
+}
+
+// GetProcessable devolve os lotes pendentes e os que estão em processamento sem sinal
+// de vida desde staleBefore (a instância que os processava caiu).
+func (r *batchRepository) GetProcessable(staleBefore time.Time, limit int) ([]domain.TransferBatch, error) {
+	var batches []domain.TransferBatch
+	err := r.db.Where("situacao = ? OR (situacao = ? AND data_processamento < ?)", domain.BatchStatusPending, domain.BatchStatusProcessing, staleBefore).
+		Order("data_criacao ASC").
+		Limit(limit).
+		Find(&batches).Error
+	return batches, err
+}
+
+// Claim assume o processamento do lote de forma atômica, para que duas instâncias não
+// processem o mesmo lote ao mesmo tempo.
+func (r *batchRepository) Claim(id string, staleBefore, now time.Time) (bool, error) {
+	result := r.db.Model(&domain.TransferBatch{}).
+		Where("idlote = ? AND (situacao = ? OR (situacao = ? AND data_processamento < ?))", id, domain.BatchStatusPending, domain.BatchStatusProcessing, staleBefore).
+		Updates(map[string]interface{}{
+			"situacao":           domain.BatchStatusProcessing,
+			"data_processamento": now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *batchRepository) Heartbeat(id string, now time.Time) error {
+	return r.db.Model(&domain.TransferBatch{}).
+		Where("idlote = ?", id).
+		Update("data_processamento", now).Error
+}
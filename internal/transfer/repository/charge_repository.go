@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"bankmore/internal/transfer/domain"
+
+	"gorm.io/gorm"
+)
+
+type ChargeRepository interface {
+	Create(charge *domain.Charge) error
+	GetByTxID(txID string) (*domain.Charge, error)
+	GetByAccountID(accountID string) ([]domain.Charge, error)
+	Update(charge *domain.Charge) error
+	ClaimForPayment(txID string) (bool, error)
+	ReleaseClaim(txID string) error
+}
+
+type chargeRepository struct {
+	db *gorm.DB
+}
+
+func NewChargeRepository(db *gorm.DB) ChargeRepository {
+	return &chargeRepository{db: db}
+}
+
+func (r *chargeRepository) Create(charge *domain.Charge) error {
+	return r.db.Create(charge).Error
+}
+
+func (r *chargeRepository) GetByTxID(txID string) (*domain.Charge, error) {
+	var charge domain.Charge
+	err := r.db.Where("txid = ?", txID).First(&charge).Error
+	if err != nil {
+		return nil, err
+	}
+	return &charge, nil
+}
+
+func (r *chargeRepository) GetByAccountID(accountID string) ([]domain.Charge, error) {
+	var charges []domain.Charge
+	err := r.db.Where("idcontacorrente = ?", accountID).
+		Order("data_criacao DESC").
+		Find(&charges).Error
+	return charges, err
+}
+
+func (r *chargeRepository) Update(charge *domain.Charge) error {
+	return r.db.Save(charge).Error
+}
+
+// ClaimForPayment passa a cobrança de ACTIVE para PROCESSING de forma atômica, para
+// que dois pagamentos simultâneos do mesmo QR Code não sejam executados.
+func (r *chargeRepository) ClaimForPayment(txID string) (bool, error) {
+	result := r.db.Model(&domain.Charge{}).
+		Where("txid = ? AND situacao = ?", txID, domain.ChargeStatusActive).
+		Update("situacao", domain.ChargeStatusProcessing)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *chargeRepository) ReleaseClaim(txID string) error {
+	return r.db.Model(&domain.Charge{}).
+		Where("txid = ? AND situacao = ?", txID, domain.ChargeStatusProcessing).
+		Update("situacao", domain.ChargeStatusActive).Error
+}
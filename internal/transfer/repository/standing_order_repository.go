@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"bankmore/internal/transfer/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StandingOrderRepository interface {
+	Create(order *domain.StandingOrder) error
+	GetByID(id string) (*domain.StandingOrder, error)
+	GetByAccountID(accountID string) ([]domain.StandingOrder, error)
+	Update(order *domain.StandingOrder) error
+	GetDue(now time.Time, limit int) ([]domain.StandingOrder, error)
+	Materialize(order *domain.StandingOrder, transfer *domain.Transfer, previousOccurrences int) (bool, error)
+}
+
+type standingOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewStandingOrderRepository(db *gorm.DB) StandingOrderRepository {
+	return &standingOrderRepository{db: db}
+}
+
+func (r *standingOrderRepository) Create(order *domain.StandingOrder) error {
+	return r.db.Create(order).Error
+}
+
+func (r *standingOrderRepository) GetByID(id string) (*domain.StandingOrder, error) {
+	var order domain.StandingOrder
+	err := r.db.Where("idordem = ?", id).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *standingOrderRepository) GetByAccountID(accountID string) ([]domain.StandingOrder, error) {
+	var orders []domain.StandingOrder
+	err := r.db.Where("idcontacorrente_origem = ?", accountID).
+		Order("data_criacao DESC").
+		Find(&orders).Error
+	return orders, err
+}
+
+func (r *standingOrderRepository) Update(order *domain.StandingOrder) error {
+	return r.db.Save(order).Error
+}
+
+func (r *standingOrderRepository) GetDue(now time.Time, limit int) ([]domain.StandingOrder, error) {
+	var orders []domain.StandingOrder
+	err := r.db.Where("situacao = ? AND data_proxima_execucao <= ?", domain.StandingOrderStatusActive, now).
+		Order("data_proxima_execucao ASC").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
+// Materialize grava a transferência da ocorrência e avança a ordem na mesma transação.
+// O avanço só acontece se a ordem continuar ativa e com a contagem lida antes, o que
+// impede duas instâncias do agendador de processarem a mesma ocorrência. Uma
+// transferência já existente (mesmo ID determinístico) não é gravada de novo.
+func (r *standingOrderRepository) Materialize(order *domain.StandingOrder, transfer *domain.Transfer, previousOccurrences int) (bool, error) {
+	advanced := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.StandingOrder{}).
+			Where("idordem = ? AND situacao = ? AND ocorrencias = ?", order.ID, domain.StandingOrderStatusActive, previousOccurrences).
+			Select("*").
+			Updates(order)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return nil
+		}
+		advanced = true
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(transfer).Error
+	})
+	return advanced, err
+}
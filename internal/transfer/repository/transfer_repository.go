@@ -1,7 +1,10 @@
 package repository
 
 import (
+	ledgerRepository "bankmore/internal/ledger/repository"
 	"bankmore/internal/transfer/domain"
+	"strconv"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,6 +14,22 @@ type TransferRepository interface {
 	GetByID(id string) (*domain.Transfer, error)
 	Update(transfer *domain.Transfer) error
 	GetByAccountID(accountID string) ([]domain.Transfer, error)
+	GetAccountIDByNumber(accountNumber string) (string, error)
+	GetAccountNumberByID(accountID string) (string, error)
+	GetAccountNameByID(accountID string) (string, error)
+	GetScheduledByAccountID(accountID string) ([]domain.Transfer, error)
+	GetDueScheduled(now, staleBefore time.Time, limit int) ([]domain.Transfer, error)
+	ClaimScheduled(id string, staleBefore, now time.Time) (bool, error)
+	GetByStandingOrderID(orderID string) ([]domain.Transfer, error)
+	GetByIdempotencyKey(key string) (*domain.Transfer, error)
+	GetRefunds(originalTransferID string) ([]domain.Transfer, error)
+	AddRefundedAmount(id string, amount float64) (bool, error)
+	ReleaseRefundedAmount(id string, amount float64) error
+	GetOutgoingAmountSince(accountID string, since time.Time, excludeTransferID string) (float64, error)
+	GetPendingAsync(staleBefore time.Time, limit int) ([]domain.Transfer, error)
+	ClaimAsync(id string, staleBefore, now time.Time) (bool, error)
+	MarkEventPublished(id string, publishedAt time.Time) error
+	GetAccountingDate(at time.Time) (time.Time, error)
 }
 
 type transferRepository struct {
@@ -45,3 +64,154 @@ func (r *transferRepository) GetByAccountID(accountID string) ([]domain.Transfer
 		Find(&transfers).Error
 	return transfers, err
 }
+
+func (r *transferRepository) GetAccountIDByNumber(accountNumber string) (string, error) {
+	var accountID string
+	err := r.db.Table("contacorrente").
+		Select("idcontacorrente").
+		Where("numero = ?", accountNumber).
+		Scan(&accountID).Error
+	return accountID, err
+}
+
+func (r *transferRepository) GetAccountNumberByID(accountID string) (string, error) {
+	var accountNumber int
+	err := r.db.Table("contacorrente").
+		Select("numero").
+		Where("idcontacorrente = ?", accountID).
+		Scan(&accountNumber).Error
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(accountNumber), nil
+}
+
+func (r *transferRepository) GetAccountNameByID(accountID string) (string, error) {
+	var name string
+	err := r.db.Table("contacorrente").
+		Select("nome").
+		Where("idcontacorrente = ?", accountID).
+		Scan(&name).Error
+	return name, err
+}
+
+func (r *transferRepository) GetScheduledByAccountID(accountID string) ([]domain.Transfer, error) {
+	var transfers []domain.Transfer
+	err := r.db.Where("idcontacorrente_origem = ? AND data_agendamento IS NOT NULL", accountID).
+		Order("data_agendamento ASC").
+		Find(&transfers).Error
+	return transfers, err
+}
+
+// GetDueScheduled devolve as agendadas cuja data (ou próxima tentativa) já chegou e as
+// assumidas pelo agendador há mais de staleBefore (a instância que as executava caiu).
+func (r *transferRepository) GetDueScheduled(now, staleBefore time.Time, limit int) ([]domain.Transfer, error) {
+	var transfers []domain.Transfer
+	err := r.db.Where("(status = ? AND COALESCE(data_proxima_tentativa, data_agendamento) <= ?) OR (status = ? AND data_agendamento IS NOT NULL AND assincrona = ? AND data_processamento < ?)",
+		domain.TransferStatusScheduled, now, domain.TransferStatusPending, false, staleBefore).
+		Order("data_agendamento ASC").
+		Limit(limit).
+		Find(&transfers).Error
+	return transfers, err
+}
+
+// ClaimScheduled passa a transferência de agendada para pendente de forma atômica,
+// para que duas instâncias do agendador não a executem ao mesmo tempo. Uma agendada
+// assumida antes de staleBefore pode ser assumida de novo.
+func (r *transferRepository) ClaimScheduled(id string, staleBefore, now time.Time) (bool, error) {
+	result := r.db.Model(&domain.Transfer{}).
+		Where("idtransferencia = ? AND (status = ? OR (status = ? AND data_agendamento IS NOT NULL AND assincrona = ? AND data_processamento < ?))",
+			id, domain.TransferStatusScheduled, domain.TransferStatusPending, false, staleBefore).
+		Updates(map[string]interface{}{"status": domain.TransferStatusPending, "data_processamento": now})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *transferRepository) GetByStandingOrderID(orderID string) ([]domain.Transfer, error) {
+	var transfers []domain.Transfer
+	err := r.db.Where("idordem = ?", orderID).
+		Order("data_agendamento DESC").
+		Find(&transfers).Error
+	return transfers, err
+}
+
+func (r *transferRepository) GetByIdempotencyKey(key string) (*domain.Transfer, error) {
+	var transfer domain.Transfer
+	err := r.db.Where("idempotencia_key = ?", key).First(&transfer).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *transferRepository) GetRefunds(originalTransferID string) ([]domain.Transfer, error) {
+	var transfers []domain.Transfer
+	err := r.db.Where("idtransferencia_original = ?", originalTransferID).
+		Order("datamovimento DESC").
+		Find(&transfers).Error
+	return transfers, err
+}
+
+// AddRefundedAmount soma o valor ao total devolvido de forma atômica, desde que o total
+// não passe do valor da transferência. Duas devoluções simultâneas não conseguem
+// devolver mais do que o valor original.
+func (r *transferRepository) AddRefundedAmount(id string, amount float64) (bool, error) {
+	result := r.db.Model(&domain.Transfer{}).
+		Where("idtransferencia = ? AND valor_devolvido + ? <= valor + 0.005", id, amount).
+		Update("valor_devolvido", gorm.Expr("valor_devolvido + ?", amount))
+	return result.RowsAffected == 1, result.Error
+}
+
+// ReleaseRefundedAmount desfaz AddRefundedAmount quando a devolução falha.
+func (r *transferRepository) ReleaseRefundedAmount(id string, amount float64) error {
+	return r.db.Model(&domain.Transfer{}).
+		Where("idtransferencia = ?", id).
+		Update("valor_devolvido", gorm.Expr("valor_devolvido - ?", amount)).Error
+}
+
+// GetOutgoingAmountSince soma as transferências executadas pela conta desde since que
+// estão pendentes ou concluídas; as ainda não executadas (assíncronas aceitas) contam
+// pela data de criação. Devoluções não entram na soma, nem excludeTransferID: a
+// transferência em execução já pode estar gravada e não conta contra si mesma.
+func (r *transferRepository) GetOutgoingAmountSince(accountID string, since time.Time, excludeTransferID string) (float64, error) {
+	var total float64
+	err := r.db.Model(&domain.Transfer{}).
+		Select("COALESCE(SUM(valor), 0)").
+		Where("idcontacorrente_origem = ? AND COALESCE(data_execucao, datamovimento) >= ? AND status IN ? AND idtransferencia_original IS NULL AND idtransferencia <> ?",
+			accountID, since, []int{domain.TransferStatusPending, domain.TransferStatusCompleted}, excludeTransferID).
+		Scan(&total).Error
+	return total, err
+}
+
+// GetPendingAsync devolve as transferências assíncronas ainda não assumidas por um worker
+// e as assumidas há mais de staleBefore (a instância que as executava caiu).
+func (r *transferRepository) GetPendingAsync(staleBefore time.Time, limit int) ([]domain.Transfer, error) {
+	var transfers []domain.Transfer
+	err := r.db.Where("status = ? AND assincrona = ? AND (data_processamento IS NULL OR data_processamento < ?)", domain.TransferStatusPending, true, staleBefore).
+		Order("datamovimento ASC").
+		Limit(limit).
+		Find(&transfers).Error
+	return transfers, err
+}
+
+// ClaimAsync assume a execução de uma transferência assíncrona de forma atômica, para
+// que dois workers não a executem ao mesmo tempo.
+func (r *transferRepository) ClaimAsync(id string, staleBefore, now time.Time) (bool, error) {
+	result := r.db.Model(&domain.Transfer{}).
+		Where("idtransferencia = ? AND status = ? AND assincrona = ? AND (data_processamento IS NULL OR data_processamento < ?)", id, domain.TransferStatusPending, true, staleBefore).
+		Update("data_processamento", now)
+	return result.RowsAffected == 1, result.Error
+}
+
+// MarkEventPublished registra que o evento da transferência chegou ao Kafka. A conciliação
+// usa a marca para achar transferências concluídas cujo evento se perdeu.
+func (r *transferRepository) MarkEventPublished(id string, publishedAt time.Time) error {
+	return r.db.Model(&domain.Transfer{}).
+		Where("idtransferencia = ?", id).
+		Update("data_publicacao_evento", publishedAt).Error
+}
+
+// GetAccountingDate devolve a data contábil de uma transferência feita em at, pelo
+// mesmo calendário e corte do razão.
+func (r *transferRepository) GetAccountingDate(at time.Time) (time.Time, error) {
+	return ledgerRepository.AccountingDate(r.db, at)
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"bankmore/internal/shared/kafka"
+	"bankmore/internal/shared/models"
+	"bankmore/internal/transfer/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	asyncQueueSize = 100
+	asyncBatchSize = 50
+	// asyncClaimTimeout é o tempo sem conclusão após o qual uma transferência assumida
+	// volta a ser elegível; cobre a queda do worker no meio da execução.
+	asyncClaimTimeout = 2 * time.Minute
+)
+
+// submitAsyncTransfer grava a transferência como pendente e a entrega aos workers. A
+// resposta traz a URL de status, que passa a concluída ou falha quando o worker termina.
+func (s *transferService) submitAsyncTransfer(request CreateTransferRequest, prepared *PreparedTransfer, feeAmount *float64) *models.Result[TransferResponse] {
+	transfer := domain.NewAsyncTransfer(prepared.OriginAccountID, prepared.DestinationAccountID, prepared.Amount, prepared.Description, &request.RequestID, feeAmount)
+
+	if err := s.repo.Create(transfer); err != nil {
+		s.logger.WithError(err).Error("Error creating async transfer")
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInternalError,
+			ErrorMessage: "Erro interno do servidor",
+		}
+	}
+
+	s.enqueueAsyncTransfer(transfer.ID)
+
+	s.logger.WithFields(logrus.Fields{
+		"transferId":      transfer.ID,
+		"originAccountId": prepared.OriginAccountID,
+		"amount":          prepared.Amount,
+		"requestId":       request.RequestID,
+	}).Info("Async transfer accepted")
+
+	return acceptedTransfer(transfer)
+}
+
+func (s *transferService) enqueueAsyncTransfer(transferID string) {
+	select {
+	case s.asyncQueue <- transferID:
+	default:
+		// Fila cheia: a transferência continua pendente e é apanhada pela varredura.
+		s.logger.WithField("transferId", transferID).Warn("Async transfer queue full")
+	}
+}
+
+func (s *transferService) AsyncTransferQueue() <-chan string {
+	return s.asyncQueue
+}
+
+// ExecuteAsyncTransfer assume e executa uma transferência assíncrona. Retorna false
+// quando ela não existe, já foi assumida por outro worker ou falhou.
+func (s *transferService) ExecuteAsyncTransfer(transferID string) bool {
+	now := time.Now()
+	claimed, err := s.repo.ClaimAsync(transferID, now.Add(-asyncClaimTimeout), now)
+	if err != nil {
+		s.logger.WithError(err).WithField("transferId", transferID).Error("Error claiming async transfer")
+		return false
+	}
+	if !claimed {
+		return false
+	}
+
+	transfer, err := s.repo.GetByID(transferID)
+	if err != nil {
+		s.logger.WithError(err).WithField("transferId", transferID).Error("Error getting async transfer")
+		return false
+	}
+
+	// O requestId da reserva é o mesmo em toda execução: se o worker cair depois de
+	// reservar, a nova execução reaproveita a reserva em vez de criar outra.
+	result := s.runTransfer(transfer, transferExecution{
+		RequestID:                *transfer.IdempotencyKey,
+		OriginAccountID:          transfer.OriginAccountID,
+		DestinationAccountID:     transfer.DestinationAccountID,
+		DestinationAccountNumber: s.getAccountNumberByID(transfer.DestinationAccountID),
+		Amount:                   transfer.Amount,
+		Description:              transfer.Description,
+		Type:                     kafka.TransferTypeStandard,
+		FeeAmount:                transfer.GuaranteedFee,
+	}, true)
+
+	event := kafka.TransferStatusEvent{
+		TransferID:      transfer.ID,
+		RequestID:       *transfer.IdempotencyKey,
+		OriginAccountID: transfer.OriginAccountID,
+		Status:          kafka.TransferStatusCompleted,
+		OccurredAt:      time.Now(),
+	}
+
+	if !result.IsSuccess {
+		transfer.LastError = result.ErrorMessage
+		transfer.Fail()
+		if err := s.repo.Update(transfer); err != nil {
+			s.logger.WithError(err).WithField("transferId", transfer.ID).Error("Error recording async transfer failure")
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"transferId": transfer.ID,
+			"errorType":  result.ErrorType,
+			"error":      result.ErrorMessage,
+		}).Warn("Async transfer failed")
+
+		event.Status = kafka.TransferStatusFailed
+		event.ErrorType = result.ErrorType
+		event.ErrorMessage = result.ErrorMessage
+	}
+
+	if err := s.producer.PublishTransferStatusEvent(event); err != nil {
+		s.logger.WithError(err).Error("Error publishing transfer status event")
+	}
+
+	return result.IsSuccess
+}
+
+// ProcessPendingAsyncTransfers executa as assíncronas que não passaram pela fila (fila
+// cheia ou reinício do serviço) ou cujo worker caiu. Retorna quantas foram concluídas.
+func (s *transferService) ProcessPendingAsyncTransfers() (int, error) {
+	pending, err := s.repo.GetPendingAsync(time.Now().Add(-asyncClaimTimeout), asyncBatchSize)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing pending async transfers")
+		return 0, fmt.Errorf("erro interno do servidor")
+	}
+
+	completed := 0
+	for _, transfer := range pending {
+		if s.ExecuteAsyncTransfer(transfer.ID) {
+			completed++
+		}
+	}
+
+	return completed, nil
+}
+
+// acceptedTransfer monta a resposta 202 de uma transferência assíncrona, nova ou reenviada.
+func acceptedTransfer(transfer *domain.Transfer) *models.Result[TransferResponse] {
+	message := "Transferência recebida para processamento"
+	switch transfer.Status {
+	case domain.TransferStatusCompleted:
+		message = "Transferência realizada com sucesso"
+	case domain.TransferStatusFailed:
+		message = "Transferência não realizada"
+	}
+
+	status := transfer.Status
+	return &models.Result[TransferResponse]{
+		IsSuccess: true,
+		Data: TransferResponse{
+			TransferID: transfer.ID,
+			Message:    message,
+			Status:     &status,
+			StatusURL:  fmt.Sprintf("/api/transfer/%s", transfer.ID),
+		},
+	}
+}
+
+// StartAsyncTransferWorkers inicia os workers que executam as transferências da fila e
+// a varredura periódica das pendentes, até o canal stop ser fechado.
+func StartAsyncTransferWorkers(service TransferService, workers int, interval time.Duration, stop <-chan struct{}) {
+	queue := service.AsyncTransferQueue()
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case transferID := <-queue:
+					service.ExecuteAsyncTransfer(transferID)
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				service.ProcessPendingAsyncTransfers()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// GetAsyncWorkerCount lê TRANSFER_ASYNC_WORKERS (padrão de 4 workers).
+func GetAsyncWorkerCount() int {
+	if value := os.Getenv("TRANSFER_ASYNC_WORKERS"); value != "" {
+		if workers, err := strconv.Atoi(value); err == nil && workers > 0 {
+			return workers
+		}
+	}
+	return 4
+}
+
+// GetAsyncPollInterval lê TRANSFER_ASYNC_POLL_INTERVAL_SECONDS (padrão de 10 segundos).
+func GetAsyncPollInterval() time.Duration {
+	if value := os.Getenv("TRANSFER_ASYNC_POLL_INTERVAL_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 10 * time.Second
+}
@@ -0,0 +1,550 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"bankmore/internal/shared/kafka"
+	"bankmore/internal/shared/models"
+	"bankmore/internal/transfer/domain"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	batchClaimSize      = 10
+	batchLeaseTimeout   = 5 * time.Minute
+	batchHoldTTLSeconds = 3600
+)
+
+var ErrBatchNotFound = errors.New("lote não encontrado")
+
+type BatchItemRequest struct {
+	DestinationAccountNumber string  `json:"destinationAccountNumber"`
+	DestinationKey           string  `json:"destinationKey"`
+	Amount                   float64 `json:"amount"`
+	Description              string  `json:"description"`
+}
+
+// CreateBatchRequest: mode é ALL_OR_NOTHING ou BEST_EFFORT (padrão). O 2FA é exigido
+// uma única vez quando o total do lote passa do limite.
+type CreateBatchRequest struct {
+	RequestID     string             `json:"requestId" binding:"required"`
+	Mode          string             `json:"mode"`
+	Items         []BatchItemRequest `json:"items" binding:"required"`
+	TOTPCode      string             `json:"totpCode,omitempty"`
+	Authorization string             `json:"-"`
+}
+
+// BatchItemError aponta o item recusado pela posição no lote (1 é o primeiro item).
+type BatchItemError struct {
+	Item    int    `json:"item"`
+	Message string `json:"message"`
+}
+
+// BatchValidationError lista os itens recusados na validação; nenhum item do lote é
+// gravado.
+type BatchValidationError struct {
+	Items []BatchItemError
+}
+
+func (e *BatchValidationError) Error() string {
+	return fmt.Sprintf("%d item(ns) inválido(s) no lote", len(e.Items))
+}
+
+// CreateBatch valida todos os itens antes de gravar o lote, que é processado depois
+// pelo processador de lotes. A chamada é idempotente por requestId.
+func (s *transferService) CreateBatch(originAccountID string, request CreateBatchRequest) (*models.Result[domain.TransferBatch], error) {
+	existing, err := s.batchRepo.GetByRequestID(request.RequestID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.WithError(err).Error("Error checking batch idempotency")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if existing != nil {
+		if existing.AccountID != originAccountID {
+			return &models.Result[domain.TransferBatch]{
+				IsSuccess:    false,
+				ErrorType:    models.ErrorInvalidData,
+				ErrorMessage: "requestId já utilizado",
+			}, nil
+		}
+		s.logger.WithField("requestId", request.RequestID).Info("Duplicate batch request ignored")
+		batch, err := s.GetBatch(originAccountID, existing.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.Result[domain.TransferBatch]{IsSuccess: true, Data: *batch}, nil
+	}
+
+	maxItems := getBatchMaxItems()
+	if len(request.Items) == 0 || len(request.Items) > maxItems {
+		return &models.Result[domain.TransferBatch]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidData,
+			ErrorMessage: fmt.Sprintf("O lote deve ter entre 1 e %d itens", maxItems),
+		}, nil
+	}
+
+	mode := strings.ToUpper(strings.TrimSpace(request.Mode))
+	if mode == "" {
+		mode = domain.BatchModeBestEffort
+	}
+
+	items := make([]domain.TransferBatchItem, 0, len(request.Items))
+	var itemErrors []BatchItemError
+	total := 0.0
+
+	for i, itemRequest := range request.Items {
+		prepared, result := s.validateTransfer(CreateTransferRequest{
+			DestinationAccountNumber: strings.TrimSpace(itemRequest.DestinationAccountNumber),
+			DestinationKey:           strings.TrimSpace(itemRequest.DestinationKey),
+			Amount:                   itemRequest.Amount,
+		}, originAccountID)
+		if result != nil {
+			itemErrors = append(itemErrors, BatchItemError{Item: i + 1, Message: result.ErrorMessage})
+			continue
+		}
+
+		description := itemRequest.Description
+		if description == "" {
+			description = prepared.Description
+		}
+
+		items = append(items, domain.TransferBatchItem{
+			DestinationAccountID:     prepared.DestinationAccountID,
+			DestinationAccountNumber: prepared.DestinationAccountNumber,
+			DestinationKey:           strings.TrimSpace(itemRequest.DestinationKey),
+			Amount:                   prepared.Amount,
+			Description:              description,
+		})
+		total += prepared.Amount
+	}
+
+	if len(itemErrors) > 0 {
+		return nil, &BatchValidationError{Items: itemErrors}
+	}
+
+	if total > s.getTwoFactorThreshold() {
+		if result := s.verifyTwoFactor(request.Authorization, request.TOTPCode); result != nil {
+			return &models.Result[domain.TransferBatch]{
+				IsSuccess:    false,
+				ErrorType:    result.ErrorType,
+				ErrorMessage: result.ErrorMessage,
+			}, nil
+		}
+	}
+
+	batch, err := domain.NewTransferBatch(originAccountID, request.RequestID, mode, items)
+	if err != nil {
+		return &models.Result[domain.TransferBatch]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidArgument,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
+
+	if err := s.batchRepo.Create(batch); err != nil {
+		s.logger.WithError(err).Error("Error creating transfer batch")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"batchId":         batch.ID,
+		"originAccountId": originAccountID,
+		"mode":            batch.Mode,
+		"items":           batch.ItemCount,
+		"totalAmount":     batch.TotalAmount,
+		"requestId":       request.RequestID,
+	}).Info("Transfer batch accepted")
+
+	return &models.Result[domain.TransferBatch]{IsSuccess: true, Data: *batch}, nil
+}
+
+func (s *transferService) GetBatch(originAccountID, batchID string) (*domain.TransferBatch, error) {
+	batch, err := s.batchRepo.GetByID(batchID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBatchNotFound
+		}
+		s.logger.WithError(err).Error("Error getting transfer batch")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if batch.AccountID != originAccountID {
+		return nil, ErrBatchNotFound
+	}
+
+	batch.Items, err = s.batchRepo.GetItems(batch.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting transfer batch items")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	return batch, nil
+}
+
+func (s *transferService) ListBatches(originAccountID string) ([]domain.TransferBatch, error) {
+	batches, err := s.batchRepo.GetByAccountID(originAccountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing transfer batches")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return batches, nil
+}
+
+// GetBatchReport gera o relatório do lote em CSV, com a situação de cada item.
+func (s *transferService) GetBatchReport(originAccountID, batchID string) ([]byte, error) {
+	batch, err := s.GetBatch(originAccountID, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"sequence", "destinationAccountNumber", "destinationKey", "amount", "description", "status", "transferId", "error"})
+
+	for _, item := range batch.Items {
+		transferID := ""
+		if item.TransferID != nil {
+			transferID = *item.TransferID
+		}
+		writer.Write([]string{
+			strconv.Itoa(item.Sequence),
+			item.DestinationAccountNumber,
+			item.DestinationKey,
+			strconv.FormatFloat(item.Amount, 'f', 2, 64),
+			item.Description,
+			item.Status,
+			transferID,
+			item.Error,
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		s.logger.WithError(err).Error("Error writing batch report")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ProcessPendingBatches processa os lotes pendentes e retoma os que ficaram sem sinal
+// de vida por mais de batchLeaseTimeout. Retorna quantos lotes foram concluídos.
+func (s *transferService) ProcessPendingBatches() (int, error) {
+	now := time.Now()
+	staleBefore := now.Add(-batchLeaseTimeout)
+
+	batches, err := s.batchRepo.GetProcessable(staleBefore, batchClaimSize)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing pending transfer batches")
+		return 0, fmt.Errorf("erro interno do servidor")
+	}
+
+	processed := 0
+	for i := range batches {
+		batch := &batches[i]
+
+		claimed, err := s.batchRepo.Claim(batch.ID, staleBefore, time.Now())
+		if err != nil {
+			s.logger.WithError(err).WithField("batchId", batch.ID).Error("Error claiming transfer batch")
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.processBatch(batch); err != nil {
+			s.logger.WithError(err).WithField("batchId", batch.ID).Error("Error processing transfer batch")
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+func (s *transferService) processBatch(batch *domain.TransferBatch) error {
+	items, err := s.batchRepo.GetItems(batch.ID)
+	if err != nil {
+		return err
+	}
+
+	originAccountNumber := s.getAccountNumberByID(batch.AccountID)
+
+	if batch.Mode == domain.BatchModeAllOrNothing {
+		s.reserveBatch(batch, items, originAccountNumber)
+	}
+
+	for i := range items {
+		item := &items[i]
+		if item.IsFinal() {
+			continue
+		}
+
+		if err := s.batchRepo.Heartbeat(batch.ID, time.Now()); err != nil {
+			s.logger.WithError(err).WithField("batchId", batch.ID).Error("Error refreshing transfer batch lease")
+		}
+
+		s.processBatchItem(batch, item)
+	}
+
+	batch.Finish(items)
+	if err := s.batchRepo.Update(batch); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"batchId":         batch.ID,
+		"status":          batch.Status,
+		"completedItems":  batch.CompletedCount,
+		"failedItems":     batch.FailedCount,
+		"completedAmount": batch.CompletedAmount,
+	}).Info("Transfer batch processed")
+
+	return nil
+}
+
+// reserveBatch confere o limite diário para o total do lote e reserva o valor de cada
+// item antes de qualquer transferência. Se o total passar do limite ou um item não
+// puder ser reservado, as reservas feitas são canceladas e os demais itens também. As
+// reservas usam o mesmo requestId da execução do item, então são reaproveitadas em vez
+// de duplicadas quando o item é executado.
+func (s *transferService) reserveBatch(batch *domain.TransferBatch, items []domain.TransferBatchItem, originAccountNumber string) {
+	total := 0.0
+	for i := range items {
+		if items[i].Status == domain.BatchItemStatusPending {
+			total += items[i].Amount
+		}
+	}
+	if total > 0 {
+		if result := s.checkDailyLimit(batch.AccountID, "", roundCents(total)); result != nil {
+			s.cancelBatchItems(items, fmt.Sprintf("Lote cancelado: %s", result.ErrorMessage))
+			s.logger.WithFields(logrus.Fields{
+				"batchId": batch.ID,
+				"amount":  total,
+				"error":   result.ErrorMessage,
+			}).Warn("All-or-nothing transfer batch rejected")
+			return
+		}
+	}
+
+	var failed *domain.TransferBatchItem
+
+	for i := range items {
+		item := &items[i]
+		if item.Status != domain.BatchItemStatusPending {
+			continue
+		}
+
+		holdID, err := s.placeHold(originAccountNumber, s.batchItemExecution(batch, item))
+		if err != nil {
+			reason := err.Error()
+			if errors.Is(err, errInsufficientBalance) {
+				reason = "Saldo insuficiente"
+			}
+			item.Fail(reason)
+			s.saveBatchItem(item)
+			failed = item
+			break
+		}
+
+		item.Reserve(holdID)
+		s.saveBatchItem(item)
+	}
+
+	if failed == nil {
+		return
+	}
+
+	s.cancelBatchItems(items, fmt.Sprintf("Lote cancelado: item %d não pôde ser reservado", failed.Sequence))
+
+	s.logger.WithFields(logrus.Fields{
+		"batchId":  batch.ID,
+		"sequence": failed.Sequence,
+		"error":    failed.Error,
+	}).Warn("All-or-nothing transfer batch rejected")
+}
+
+// cancelBatchItems cancela os itens ainda não concluídos e as reservas já feitas.
+func (s *transferService) cancelBatchItems(items []domain.TransferBatchItem, reason string) {
+	for i := range items {
+		item := &items[i]
+		if item.IsFinal() {
+			continue
+		}
+		if item.HoldID != nil {
+			s.voidHold(*item.HoldID)
+		}
+		item.Cancel(reason)
+		s.saveBatchItem(item)
+	}
+}
+
+// processBatchItem executa um item. A transferência usa o ID do item, então um item
+// retomado após queda encontra a transferência já gravada: se concluída ou falha, só
+// atualiza o item; se pendente, reexecuta as etapas, que são idempotentes.
+func (s *transferService) processBatchItem(batch *domain.TransferBatch, item *domain.TransferBatchItem) {
+	transfer, err := s.repo.GetByID(item.ID)
+	persisted := err == nil
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		s.logger.WithError(err).WithField("itemId", item.ID).Error("Error getting batch item transfer")
+		item.Fail("Erro interno do servidor")
+		s.saveBatchItem(item)
+		return
+	case !persisted:
+		transfer = domain.NewTransfer(batch.AccountID, item.DestinationAccountID, item.Amount, item.Description, &item.ID)
+		transfer.ID = item.ID
+	case transfer.Status == domain.TransferStatusCompleted:
+		item.Complete(transfer.ID)
+		s.saveBatchItem(item)
+		return
+	case transfer.Status == domain.TransferStatusFailed:
+		item.Fail("Erro ao processar transferência")
+		s.saveBatchItem(item)
+		return
+	}
+
+	result := s.runTransfer(transfer, s.batchItemExecution(batch, item), persisted)
+	if result.IsSuccess {
+		item.Complete(transfer.ID)
+	} else {
+		// Recusado antes da reserva: a reserva feita por reserveBatch não seria capturada.
+		if result.ErrorType == models.ErrorDailyLimitExceeded && item.HoldID != nil {
+			s.voidHold(*item.HoldID)
+		}
+		item.Fail(result.ErrorMessage)
+	}
+	s.saveBatchItem(item)
+}
+
+func (s *transferService) batchItemExecution(batch *domain.TransferBatch, item *domain.TransferBatchItem) transferExecution {
+	return transferExecution{
+		RequestID:                item.ID,
+		HoldRequestID:            item.ID + "-hold",
+		HoldTTLSeconds:           batchHoldTTLSeconds,
+		OriginAccountID:          batch.AccountID,
+		DestinationAccountID:     item.DestinationAccountID,
+		DestinationAccountNumber: item.DestinationAccountNumber,
+		Amount:                   item.Amount,
+		Description:              item.Description,
+		Type:                     kafka.TransferTypeStandard,
+	}
+}
+
+func (s *transferService) saveBatchItem(item *domain.TransferBatchItem) {
+	if err := s.batchRepo.UpdateItem(item); err != nil {
+		s.logger.WithError(err).WithField("itemId", item.ID).Error("Error updating batch item")
+	}
+}
+
+// ParseBatchCSV lê os itens de um CSV com cabeçalho. As colunas reconhecidas são
+// destinationAccountNumber, destinationKey, amount e description, em qualquer ordem.
+// Aceita vírgula ou ponto e vírgula como separador; com ponto e vírgula, o valor pode
+// usar vírgula decimal.
+func ParseBatchCSV(reader io.Reader) ([]BatchItemRequest, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("não foi possível ler o arquivo")
+	}
+
+	header, _, _ := strings.Cut(string(content), "\n")
+	separator := ','
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		separator = ';'
+	}
+
+	csvReader := csv.NewReader(bytes.NewReader(content))
+	csvReader.Comma = separator
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("CSV inválido: %s", err.Error())
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("o arquivo não tem itens")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	if _, ok := columns["amount"]; !ok {
+		return nil, fmt.Errorf("coluna amount obrigatória")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	items := make([]BatchItemRequest, 0, len(records)-1)
+	for line, record := range records[1:] {
+		amountText := field(record, "amount")
+		if separator == ';' {
+			amountText = strings.ReplaceAll(strings.ReplaceAll(amountText, ".", ""), ",", ".")
+		}
+		amount, err := strconv.ParseFloat(amountText, 64)
+		if err != nil {
+			return nil, fmt.Errorf("valor inválido no item %d", line+1)
+		}
+
+		items = append(items, BatchItemRequest{
+			DestinationAccountNumber: field(record, "destinationAccountNumber"),
+			DestinationKey:           field(record, "destinationKey"),
+			Amount:                   amount,
+			Description:              field(record, "description"),
+		})
+	}
+
+	return items, nil
+}
+
+// StartBatchProcessor executa ProcessPendingBatches periodicamente até o canal stop
+// ser fechado.
+func StartBatchProcessor(service TransferService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				service.ProcessPendingBatches()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// GetBatchProcessorInterval lê TRANSFER_BATCH_INTERVAL_SECONDS (padrão de 5 segundos).
+func GetBatchProcessorInterval() time.Duration {
+	if value := os.Getenv("TRANSFER_BATCH_INTERVAL_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 5 * time.Second
+}
+
+func getBatchMaxItems() int {
+	if value := os.Getenv("TRANSFER_BATCH_MAX_ITEMS"); value != "" {
+		if maxItems, err := strconv.Atoi(value); err == nil && maxItems > 0 {
+			return maxItems
+		}
+	}
+	return 500
+}
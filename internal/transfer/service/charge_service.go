@@ -0,0 +1,384 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"bankmore/internal/shared/brcode"
+	"bankmore/internal/shared/models"
+	"bankmore/internal/transfer/domain"
+	"bankmore/internal/transfer/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const defaultChargeTTL = time.Hour
+
+var ErrChargeNotFound = errors.New("cobrança não encontrada")
+
+type ChargeService interface {
+	CreateStaticCharge(accountID string, request StaticChargeRequest) (*StaticChargeResponse, error)
+	CreateDynamicCharge(accountID string, request DynamicChargeRequest) (*DynamicChargeResponse, error)
+	GetCharge(accountID, txID string) (*domain.Charge, error)
+	ListCharges(accountID string) ([]domain.Charge, error)
+	PayByPayload(request PayByPayloadRequest, payerAccountID string) (*models.Result[TransferResponse], error)
+}
+
+type chargeService struct {
+	repo         repository.ChargeRepository
+	transferRepo repository.TransferRepository
+	transfers    TransferService
+	logger       *logrus.Logger
+}
+
+func NewChargeService(repo repository.ChargeRepository, transferRepo repository.TransferRepository, transfers TransferService, logger *logrus.Logger) ChargeService {
+	return &chargeService{
+		repo:         repo,
+		transferRepo: transferRepo,
+		transfers:    transfers,
+		logger:       logger,
+	}
+}
+
+// StaticChargeRequest gera um QR Code reutilizável; sem valor, o pagador informa o valor.
+type StaticChargeRequest struct {
+	Key         string  `json:"key" binding:"required"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description"`
+}
+
+type StaticChargeResponse struct {
+	Payload   string `json:"payload"`
+	QRCodePNG []byte `json:"qrCodePng"`
+}
+
+type DynamicChargeRequest struct {
+	Key              string  `json:"key" binding:"required"`
+	Amount           float64 `json:"amount" binding:"required"`
+	Description      string  `json:"description"`
+	ExpiresInSeconds int     `json:"expiresInSeconds"`
+}
+
+type DynamicChargeResponse struct {
+	domain.Charge
+	QRCodePNG []byte `json:"qrCodePng"`
+}
+
+// PayByPayloadRequest: Amount só é usado quando o QR Code não traz valor.
+type PayByPayloadRequest struct {
+	RequestID     string  `json:"requestId" binding:"required"`
+	Payload       string  `json:"payload" binding:"required"`
+	Amount        float64 `json:"amount"`
+	TOTPCode      string  `json:"totpCode,omitempty"`
+	Authorization string  `json:"-"`
+}
+
+func (s *chargeService) CreateStaticCharge(accountID string, request StaticChargeRequest) (*StaticChargeResponse, error) {
+	if request.Amount < 0 {
+		return nil, fmt.Errorf("valor não pode ser negativo")
+	}
+
+	key, err := s.ownKey(accountID, request.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := s.encode(accountID, brcode.Payload{
+		Key:         key,
+		Description: request.Description,
+		Amount:      request.Amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	png, err := brcode.RenderPNG(payload, brcode.DefaultQRCodeSize)
+	if err != nil {
+		s.logger.WithError(err).Error("Error rendering QR code")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	return &StaticChargeResponse{
+		Payload:   payload,
+		QRCodePNG: png,
+	}, nil
+}
+
+func (s *chargeService) CreateDynamicCharge(accountID string, request DynamicChargeRequest) (*DynamicChargeResponse, error) {
+	if request.Amount <= 0 {
+		return nil, fmt.Errorf("valor deve ser positivo")
+	}
+
+	key, err := s.ownKey(accountID, request.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := defaultChargeTTL
+	if request.ExpiresInSeconds > 0 {
+		ttl = time.Duration(request.ExpiresInSeconds) * time.Second
+	}
+
+	charge := domain.NewCharge(accountID, key, request.Amount, request.Description, ttl)
+
+	charge.Payload, err = s.encode(accountID, brcode.Payload{
+		Key:         key,
+		Description: request.Description,
+		Amount:      request.Amount,
+		TxID:        charge.TxID,
+		Dynamic:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	png, err := brcode.RenderPNG(charge.Payload, brcode.DefaultQRCodeSize)
+	if err != nil {
+		s.logger.WithError(err).Error("Error rendering QR code")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if err := s.repo.Create(charge); err != nil {
+		s.logger.WithError(err).Error("Error creating charge")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"chargeId":  charge.ID,
+		"txid":      charge.TxID,
+		"accountId": accountID,
+		"amount":    charge.Amount,
+		"expiresAt": charge.ExpiresAt,
+	}).Info("Dynamic charge created")
+
+	return &DynamicChargeResponse{
+		Charge:    *charge,
+		QRCodePNG: png,
+	}, nil
+}
+
+func (s *chargeService) GetCharge(accountID, txID string) (*domain.Charge, error) {
+	charge, err := s.getCharge(txID)
+	if err != nil {
+		return nil, err
+	}
+	if charge.AccountID != accountID {
+		return nil, ErrChargeNotFound
+	}
+	return charge, nil
+}
+
+func (s *chargeService) ListCharges(accountID string) ([]domain.Charge, error) {
+	charges, err := s.repo.GetByAccountID(accountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing charges")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	now := time.Now()
+	for i := range charges {
+		charges[i].RefreshStatus(now)
+	}
+	return charges, nil
+}
+
+// PayByPayload interpreta o BR Code, valida CRC e, para cobranças dinâmicas, o txid,
+// valor e validade, e executa a transferência para a chave do recebedor.
+func (s *chargeService) PayByPayload(request PayByPayloadRequest, payerAccountID string) (*models.Result[TransferResponse], error) {
+	payload, err := brcode.Parse(request.Payload)
+	if err != nil {
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidPaymentCode,
+			ErrorMessage: fmt.Sprintf("QR Code inválido: %s", err.Error()),
+		}, nil
+	}
+
+	amount := payload.Amount
+	if amount == 0 {
+		amount = request.Amount
+	} else if request.Amount != 0 && !sameAmount(request.Amount, payload.Amount) {
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidAmount,
+			ErrorMessage: "Valor informado difere do valor do QR Code",
+		}, nil
+	}
+
+	var charge *domain.Charge
+	claimedAt := time.Now()
+	if payload.Dynamic {
+		charge, err = s.claimCharge(payload)
+		if err != nil {
+			return &models.Result[TransferResponse]{
+				IsSuccess:    false,
+				ErrorType:    models.ErrorInvalidPaymentCode,
+				ErrorMessage: err.Error(),
+			}, nil
+		}
+	}
+
+	result, err := s.transfers.CreateTransfer(CreateTransferRequest{
+		RequestID:      request.RequestID,
+		DestinationKey: payload.Key,
+		Amount:         amount,
+		TOTPCode:       request.TOTPCode,
+		Authorization:  request.Authorization,
+	}, payerAccountID)
+
+	if charge == nil {
+		return result, err
+	}
+
+	if err == nil && result.IsSuccess {
+		result, err = s.checkChargePayment(charge, result, claimedAt)
+	}
+
+	if err != nil || !result.IsSuccess {
+		if releaseErr := s.repo.ReleaseClaim(charge.TxID); releaseErr != nil {
+			s.logger.WithError(releaseErr).WithField("txid", charge.TxID).Error("Error releasing charge claim")
+		}
+		return result, err
+	}
+
+	charge.MarkPaid(result.Data.TransferID, time.Now())
+	if err := s.repo.Update(charge); err != nil {
+		s.logger.WithError(err).WithField("txid", charge.TxID).Error("Error marking charge as paid")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"txid":       charge.TxID,
+		"transferId": result.Data.TransferID,
+		"payerId":    payerAccountID,
+		"amount":     amount,
+	}).Info("Dynamic charge paid")
+
+	return result, nil
+}
+
+// checkChargePayment garante que a transferência devolvida foi criada agora, para o
+// recebedor e pelo valor da cobrança. Um requestId já usado devolve a transferência
+// original, que não pode quitar a cobrança sem que dinheiro novo tenha sido movido.
+func (s *chargeService) checkChargePayment(charge *domain.Charge, result *models.Result[TransferResponse], claimedAt time.Time) (*models.Result[TransferResponse], error) {
+	transfer, err := s.transferRepo.GetByID(result.Data.TransferID)
+	if err != nil {
+		s.logger.WithError(err).WithField("transferId", result.Data.TransferID).Error("Error getting charge payment transfer")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if transfer.DestinationAccountID != charge.AccountID || !sameAmount(transfer.Amount, charge.Amount) || transfer.Date.Before(claimedAt) {
+		s.logger.WithFields(logrus.Fields{
+			"txid":       charge.TxID,
+			"transferId": transfer.ID,
+		}).Warn("Charge payment returned a transfer not made for the charge")
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidPaymentCode,
+			ErrorMessage: "requestId já utilizado em outra transferência",
+		}, nil
+	}
+
+	return result, nil
+}
+
+// claimCharge confere o QR Code dinâmico com a cobrança registrada e a reserva para
+// este pagamento.
+func (s *chargeService) claimCharge(payload *brcode.Payload) (*domain.Charge, error) {
+	if payload.TxID == "" {
+		return nil, fmt.Errorf("QR Code dinâmico sem txid")
+	}
+
+	charge, err := s.getCharge(payload.TxID)
+	if err != nil {
+		return nil, err
+	}
+
+	if charge.Key != payload.Key || !sameAmount(charge.Amount, payload.Amount) {
+		return nil, fmt.Errorf("QR Code não confere com a cobrança registrada")
+	}
+
+	if err := charge.CanBePaid(time.Now()); err != nil {
+		return nil, err
+	}
+
+	claimed, err := s.repo.ClaimForPayment(charge.TxID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error claiming charge")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if !claimed {
+		return nil, fmt.Errorf("cobrança com pagamento em andamento")
+	}
+
+	return charge, nil
+}
+
+// ownKey garante que a chave do QR Code pertence à conta do recebedor e devolve a
+// chave normalizada.
+func (s *chargeService) ownKey(accountID, key string) (string, error) {
+	resolved, err := resolvePixKey(key)
+	if err != nil {
+		s.logger.WithError(err).Error("Error resolving charge key")
+		return "", fmt.Errorf("chave não encontrada")
+	}
+
+	ownerID, err := s.transferRepo.GetAccountIDByNumber(resolved.AccountNumber)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting account by number")
+		return "", fmt.Errorf("erro interno do servidor")
+	}
+
+	if ownerID != accountID {
+		return "", fmt.Errorf("a chave não pertence à conta logada")
+	}
+
+	return resolved.Key, nil
+}
+
+func (s *chargeService) encode(accountID string, payload brcode.Payload) (string, error) {
+	name, err := s.transferRepo.GetAccountNameByID(accountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting account name")
+		return "", fmt.Errorf("erro interno do servidor")
+	}
+
+	payload.MerchantName = name
+	payload.MerchantCity = getMerchantCity()
+
+	return payload.Encode()
+}
+
+func (s *chargeService) getCharge(txID string) (*domain.Charge, error) {
+	charge, err := s.repo.GetByTxID(txID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChargeNotFound
+		}
+		s.logger.WithError(err).Error("Error getting charge")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if charge.RefreshStatus(time.Now()) {
+		if err := s.repo.Update(charge); err != nil {
+			s.logger.WithError(err).WithField("txid", charge.TxID).Error("Error expiring charge")
+		}
+	}
+
+	return charge, nil
+}
+
+func getMerchantCity() string {
+	city := os.Getenv("PIX_MERCHANT_CITY")
+	if city == "" {
+		return "SAO PAULO"
+	}
+	return city
+}
+
+func sameAmount(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
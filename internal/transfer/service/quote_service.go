@@ -0,0 +1,294 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"bankmore/internal/shared/kafka"
+	"bankmore/internal/shared/middleware"
+	"bankmore/internal/shared/models"
+	"bankmore/internal/shared/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// quoteTokenPurpose separa a chave das cotações da chave dos tokens de acesso.
+const quoteTokenPurpose = "transfer-quote"
+
+// QuoteRequest identifica o destino como em CreateTransferRequest.
+type QuoteRequest struct {
+	DestinationAccountNumber string  `json:"destinationAccountNumber"`
+	DestinationKey           string  `json:"destinationKey"`
+	Amount                   float64 `json:"amount" binding:"required"`
+}
+
+// QuoteDestination traz os dados do titular de destino mascarados. O número da conta
+// só aparece quando o destino foi informado por ele, e não por uma chave Pix.
+type QuoteDestination struct {
+	AccountNumber string `json:"accountNumber,omitempty"`
+	Name          string `json:"name"`
+}
+
+// TransferQuote é o que o app mostra antes da confirmação. O quoteId, enviado em
+// CreateTransferRequest até expiresAt, garante a tarifa cotada.
+type TransferQuote struct {
+	QuoteID             string           `json:"quoteId"`
+	Destination         QuoteDestination `json:"destination"`
+	Amount              float64          `json:"amount"`
+	Fee                 float64          `json:"fee"`
+	Total               float64          `json:"total"`
+	AvailableBalance    float64          `json:"availableBalance"`
+	ResultingBalance    float64          `json:"resultingBalance"`
+	SufficientBalance   bool             `json:"sufficientBalance"`
+	DailyLimit          float64          `json:"dailyLimit"`
+	DailyLimitRemaining float64          `json:"dailyLimitRemaining"`
+	WithinDailyLimit    bool             `json:"withinDailyLimit"`
+	TwoFactorRequired   bool             `json:"twoFactorRequired"`
+	ExpiresAt           time.Time        `json:"expiresAt"`
+}
+
+// quoteClaims é o conteúdo assinado do quoteId: a cotação só vale para a mesma origem,
+// destino e valor.
+type quoteClaims struct {
+	OriginAccountID      string  `json:"origin"`
+	DestinationAccountID string  `json:"destination"`
+	Amount               float64 `json:"amount"`
+	Fee                  float64 `json:"fee"`
+	jwt.RegisteredClaims
+}
+
+// QuoteTransfer valida a transferência como CreateTransfer faria (sem 2FA) e devolve
+// titular de destino, tarifa pelas regras da API de tarifas, saldo resultante e limite
+// diário, com um quoteId assinado de curta duração.
+func (s *transferService) QuoteTransfer(originAccountID string, request QuoteRequest) (*models.Result[TransferQuote], error) {
+	prepared, result := s.validateTransfer(CreateTransferRequest{
+		DestinationAccountNumber: request.DestinationAccountNumber,
+		DestinationKey:           request.DestinationKey,
+		Amount:                   request.Amount,
+	}, originAccountID)
+	if result != nil {
+		return &models.Result[TransferQuote]{
+			IsSuccess:    false,
+			ErrorType:    result.ErrorType,
+			ErrorMessage: result.ErrorMessage,
+		}, nil
+	}
+
+	fee, err := getFeeQuote(kafka.TransferTypeStandard)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting fee quote")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	availableBalance, err := getAvailableBalance(s.getAccountNumberByID(originAccountID))
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting origin balance")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	dailyLimit := getDailyLimit()
+	remaining, err := s.getDailyLimitRemaining(originAccountID, dailyLimit, "")
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting daily limit usage")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	destinationName, err := s.repo.GetAccountNameByID(prepared.DestinationAccountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting destination holder name")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	destination := QuoteDestination{Name: utils.MaskName(destinationName)}
+	if request.DestinationKey == "" {
+		destination.AccountNumber = prepared.DestinationAccountNumber
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(getQuoteTTL()).Truncate(time.Second)
+
+	quoteID, err := middleware.SignPurposeToken(quoteTokenPurpose, quoteClaims{
+		OriginAccountID:      originAccountID,
+		DestinationAccountID: prepared.DestinationAccountID,
+		Amount:               prepared.Amount,
+		Fee:                  fee,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	if err != nil {
+		s.logger.WithError(err).Error("Error signing transfer quote")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	total := roundCents(prepared.Amount + fee)
+
+	return &models.Result[TransferQuote]{
+		IsSuccess: true,
+		Data: TransferQuote{
+			QuoteID:             quoteID,
+			Destination:         destination,
+			Amount:              prepared.Amount,
+			Fee:                 fee,
+			Total:               total,
+			AvailableBalance:    availableBalance,
+			ResultingBalance:    roundCents(availableBalance - total),
+			SufficientBalance:   availableBalance >= total,
+			DailyLimit:          dailyLimit,
+			DailyLimitRemaining: remaining,
+			WithinDailyLimit:    prepared.Amount <= remaining,
+			TwoFactorRequired:   prepared.Amount > s.getTwoFactorThreshold(),
+			ExpiresAt:           expiresAt,
+		},
+	}, nil
+}
+
+// redeemQuote confere se o quoteId foi emitido para esta transferência e ainda vale, e
+// devolve a tarifa cotada.
+func (s *transferService) redeemQuote(quoteID string, prepared *PreparedTransfer) (float64, *models.Result[TransferResponse]) {
+	var claims quoteClaims
+	if err := middleware.ParsePurposeToken(quoteTokenPurpose, quoteID, &claims); err != nil {
+		s.logger.WithError(err).Warn("Invalid or expired transfer quote")
+		return 0, &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidQuote,
+			ErrorMessage: "Cotação inválida ou expirada",
+		}
+	}
+
+	if claims.OriginAccountID != prepared.OriginAccountID ||
+		claims.DestinationAccountID != prepared.DestinationAccountID ||
+		math.Abs(claims.Amount-prepared.Amount) >= 0.005 {
+		return 0, &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidQuote,
+			ErrorMessage: "Cotação não corresponde à transferência",
+		}
+	}
+
+	return claims.Fee, nil
+}
+
+// checkDailyLimit recusa a transferência que passaria do limite diário. transferID é a
+// transferência já gravada que está sendo executada, vazio na imediata.
+func (s *transferService) checkDailyLimit(originAccountID, transferID string, amount float64) *models.Result[TransferResponse] {
+	remaining, err := s.getDailyLimitRemaining(originAccountID, getDailyLimit(), transferID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error getting daily limit usage")
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInternalError,
+			ErrorMessage: "Erro interno do servidor",
+		}
+	}
+
+	if amount > remaining {
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorDailyLimitExceeded,
+			ErrorMessage: fmt.Sprintf("Valor excede o limite diário disponível (%.2f)", remaining),
+		}
+	}
+	return nil
+}
+
+// getDailyLimitRemaining desconta do limite o que a conta já enviou desde a meia-noite.
+func (s *transferService) getDailyLimitRemaining(originAccountID string, dailyLimit float64, excludeTransferID string) (float64, error) {
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	used, err := s.repo.GetOutgoingAmountSince(originAccountID, startOfDay, excludeTransferID)
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Max(roundCents(dailyLimit-used), 0), nil
+}
+
+// getFeeQuote pergunta à API de tarifas quanto seria cobrado por uma transferência do tipo.
+func getFeeQuote(transferType string) (float64, error) {
+	feeAPIURL := os.Getenv("FEE_API_URL")
+	if feeAPIURL == "" {
+		feeAPIURL = "http://localhost:8003"
+	}
+
+	quoteURL := fmt.Sprintf("%s/api/fee/quote?type=%s", feeAPIURL, transferType)
+	resp, err := middleware.DoServiceRequest("transfer-api", http.MethodGet, quoteURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("fee API returned status %d", resp.StatusCode)
+	}
+
+	var quote struct {
+		Amount float64 `json:"amount"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&quote); err != nil {
+		return 0, err
+	}
+
+	return quote.Amount, nil
+}
+
+// getAvailableBalance lê o saldo disponível (descontados bloqueios e reservas) da conta.
+func getAvailableBalance(accountNumber string) (float64, error) {
+	accountAPIURL := os.Getenv("ACCOUNT_API_URL")
+	if accountAPIURL == "" {
+		accountAPIURL = "http://localhost:8001"
+	}
+
+	balanceURL := fmt.Sprintf("%s/api/account/balance/%s", accountAPIURL, accountNumber)
+	resp, err := middleware.DoServiceRequest("transfer-api", http.MethodGet, balanceURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("account API returned status %d", resp.StatusCode)
+	}
+
+	var balance struct {
+		AvailableBalance float64 `json:"availableBalance"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&balance); err != nil {
+		return 0, err
+	}
+
+	return balance.AvailableBalance, nil
+}
+
+// getDailyLimit lê TRANSFER_DAILY_LIMIT (padrão de 5000,00).
+func getDailyLimit() float64 {
+	if value := os.Getenv("TRANSFER_DAILY_LIMIT"); value != "" {
+		if limit, err := strconv.ParseFloat(value, 64); err == nil && limit > 0 {
+			return limit
+		}
+	}
+	return 5000.00
+}
+
+// getQuoteTTL lê TRANSFER_QUOTE_TTL_SECONDS (padrão de 120 segundos).
+func getQuoteTTL() time.Duration {
+	if value := os.Getenv("TRANSFER_QUOTE_TTL_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 120 * time.Second
+}
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
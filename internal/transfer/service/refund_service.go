@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"bankmore/internal/shared/kafka"
+	"bankmore/internal/shared/models"
+	"bankmore/internal/transfer/domain"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// RefundRequest: sem amount, devolve todo o valor ainda não devolvido.
+type RefundRequest struct {
+	RequestID   string  `json:"requestId" binding:"required"`
+	Amount      float64 `json:"amount"`
+	ReasonCode  string  `json:"reasonCode" binding:"required"`
+	Description string  `json:"description"`
+}
+
+// GetTransfer devolve uma transferência em que a conta é origem ou destino.
+func (s *transferService) GetTransfer(accountID, transferID string) (*domain.Transfer, error) {
+	transfer, err := s.repo.GetByID(transferID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransferNotFound
+		}
+		s.logger.WithError(err).Error("Error getting transfer")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if transfer.OriginAccountID != accountID && transfer.DestinationAccountID != accountID {
+		return nil, ErrTransferNotFound
+	}
+	return transfer, nil
+}
+
+func (s *transferService) ListRefunds(accountID, transferID string) ([]domain.Transfer, error) {
+	transfer, err := s.GetTransfer(accountID, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds, err := s.repo.GetRefunds(transfer.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing refunds")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return refunds, nil
+}
+
+// RefundTransfer devolve ao pagador parte ou todo o valor de uma transferência recebida
+// pela conta. O valor devolvido é reservado na original antes da execução, para que
+// devoluções simultâneas não passem do valor original, e liberado se a devolução falhar.
+func (s *transferService) RefundTransfer(accountID, transferID string, request RefundRequest) (*models.Result[TransferResponse], error) {
+	original, err := s.repo.GetByID(transferID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransferNotFound
+		}
+		s.logger.WithError(err).Error("Error getting transfer")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if original.DestinationAccountID != accountID {
+		return nil, ErrTransferNotFound
+	}
+
+	existing, err := s.repo.GetByIdempotencyKey(request.RequestID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.WithError(err).Error("Error checking refund idempotency")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if existing != nil {
+		if existing.OriginalTransferID == nil || *existing.OriginalTransferID != original.ID {
+			return refundFailure(models.ErrorInvalidData, "requestId já utilizado"), nil
+		}
+		s.logger.WithField("requestId", request.RequestID).Info("Duplicate refund request ignored")
+		if existing.Status != domain.TransferStatusCompleted {
+			return refundFailure(models.ErrorInvalidOperation, "Devolução com este requestId não foi concluída"), nil
+		}
+		return &models.Result[TransferResponse]{
+			IsSuccess: true,
+			Data: TransferResponse{
+				TransferID: existing.ID,
+				Message:    "Devolução realizada com sucesso",
+			},
+		}, nil
+	}
+
+	reasonCode := strings.ToUpper(strings.TrimSpace(request.ReasonCode))
+	if _, ok := domain.RefundReasons[reasonCode]; !ok {
+		return refundFailure(models.ErrorInvalidArgument, "Código de motivo inválido"), nil
+	}
+
+	if err := original.CanBeRefunded(time.Now(), getRefundWindow()); err != nil {
+		return refundFailure(models.ErrorInvalidOperation, err.Error()), nil
+	}
+
+	amount := request.Amount
+	if amount == 0 {
+		amount = math.Round(original.RefundableAmount()*100) / 100
+	}
+	if amount <= 0 {
+		return refundFailure(models.ErrorInvalidAmount, "Valor deve ser positivo"), nil
+	}
+
+	reserved, err := s.repo.AddRefundedAmount(original.ID, amount)
+	if err != nil {
+		s.logger.WithError(err).Error("Error reserving refund amount")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if !reserved {
+		return refundFailure(models.ErrorInvalidAmount, fmt.Sprintf("Valor excede o disponível para devolução (%.2f)", original.RefundableAmount())), nil
+	}
+
+	description := request.Description
+	if description == "" {
+		description = fmt.Sprintf("Devolução da transferência %s (%s)", original.ID, domain.RefundReasons[reasonCode])
+	}
+
+	refund := domain.NewRefund(original, amount, reasonCode, description, request.RequestID)
+
+	result := s.runTransfer(refund, transferExecution{
+		RequestID:                request.RequestID,
+		OriginAccountID:          refund.OriginAccountID,
+		DestinationAccountID:     refund.DestinationAccountID,
+		DestinationAccountNumber: s.getAccountNumberByID(refund.DestinationAccountID),
+		Amount:                   amount,
+		Description:              description,
+		Type:                     kafka.TransferTypeRefund,
+	}, false)
+
+	if !result.IsSuccess {
+		if err := s.repo.ReleaseRefundedAmount(original.ID, amount); err != nil {
+			s.logger.WithError(err).WithField("transferId", original.ID).Error("Error releasing refund amount")
+		}
+		return result, nil
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"refundId":           refund.ID,
+		"originalTransferId": original.ID,
+		"amount":             amount,
+		"reasonCode":         reasonCode,
+	}).Info("Transfer refunded")
+
+	result.Data.Message = "Devolução realizada com sucesso"
+	return result, nil
+}
+
+func refundFailure(errorType, message string) *models.Result[TransferResponse] {
+	return &models.Result[TransferResponse]{
+		IsSuccess:    false,
+		ErrorType:    errorType,
+		ErrorMessage: message,
+	}
+}
+
+// getRefundWindow lê TRANSFER_REFUND_WINDOW_DAYS (padrão de 90 dias).
+func getRefundWindow() time.Duration {
+	if value := os.Getenv("TRANSFER_REFUND_WINDOW_DAYS"); value != "" {
+		if days, err := strconv.Atoi(value); err == nil && days > 0 {
+			return time.Duration(days) * 24 * time.Hour
+		}
+	}
+	return 90 * 24 * time.Hour
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"bankmore/internal/shared/calendar"
+	"bankmore/internal/shared/kafka"
+	"bankmore/internal/shared/models"
+	"bankmore/internal/transfer/domain"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	maxScheduleHorizon = 365 * 24 * time.Hour
+	scheduledBatchSize = 50
+	// scheduledClaimTimeout é o tempo sem conclusão após o qual uma agendada assumida
+	// volta a ser elegível; cobre a queda do agendador no meio da execução.
+	scheduledClaimTimeout = 2 * time.Minute
+)
+
+var ErrTransferNotFound = errors.New("transferência não encontrada")
+
+// ScheduleRetryPolicy define quantas vezes uma transferência agendada é tentada e a
+// que horas do próximo dia útil ocorre a nova tentativa (por padrão, na abertura).
+type ScheduleRetryPolicy struct {
+	MaxAttempts int
+	RetryHour   int
+	RetryMinute int
+}
+
+// NextAttempt devolve o horário da nova tentativa no dia útil seguinte ao de now, quando
+// o saldo e o limite diário já foram renovados.
+func (p ScheduleRetryPolicy) NextAttempt(now time.Time) time.Time {
+	day := calendar.NextBusinessDay(now)
+	return time.Date(day.Year(), day.Month(), day.Day(), p.RetryHour, p.RetryMinute, 0, 0, day.Location())
+}
+
+// scheduleTransfer grava a transferência para execução futura. Saldo e situação da
+// conta só são verificados na execução.
+func (s *transferService) scheduleTransfer(request CreateTransferRequest, prepared *PreparedTransfer) *models.Result[TransferResponse] {
+	transfer := domain.NewScheduledTransfer(prepared.OriginAccountID, prepared.DestinationAccountID, prepared.Amount, prepared.Description, &request.RequestID, *request.ScheduledFor)
+
+	if err := s.repo.Create(transfer); err != nil {
+		s.logger.WithError(err).Error("Error creating scheduled transfer")
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInternalError,
+			ErrorMessage: "Erro interno do servidor",
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"transferId":      transfer.ID,
+		"originAccountId": prepared.OriginAccountID,
+		"amount":          prepared.Amount,
+		"scheduledFor":    transfer.ScheduledFor,
+		"requestId":       request.RequestID,
+	}).Info("Transfer scheduled")
+
+	return &models.Result[TransferResponse]{
+		IsSuccess: true,
+		Data: TransferResponse{
+			TransferID:   transfer.ID,
+			Message:      fmt.Sprintf("Transferência agendada para %s", transfer.ScheduledFor.Format("02/01/2006 15:04")),
+			ScheduledFor: transfer.ScheduledFor,
+		},
+	}
+}
+
+func (s *transferService) ListScheduledTransfers(originAccountID string) ([]domain.Transfer, error) {
+	transfers, err := s.repo.GetScheduledByAccountID(originAccountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing scheduled transfers")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return transfers, nil
+}
+
+func (s *transferService) CancelScheduledTransfer(originAccountID, transferID string) error {
+	transfer, err := s.repo.GetByID(transferID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTransferNotFound
+		}
+		s.logger.WithError(err).Error("Error getting transfer")
+		return fmt.Errorf("erro interno do servidor")
+	}
+
+	if transfer.OriginAccountID != originAccountID {
+		return ErrTransferNotFound
+	}
+
+	if transfer.Status != domain.TransferStatusScheduled {
+		return fmt.Errorf("somente transferências agendadas podem ser canceladas")
+	}
+
+	// O claim impede que o agendador comece a executar a transferência enquanto ela
+	// é cancelada. Execuções interrompidas não são assumidas aqui: a reserva pode já
+	// ter sido capturada.
+	claimed, err := s.repo.ClaimScheduled(transfer.ID, time.Time{}, time.Now())
+	if err != nil {
+		s.logger.WithError(err).Error("Error claiming scheduled transfer")
+		return fmt.Errorf("erro interno do servidor")
+	}
+	if !claimed {
+		return fmt.Errorf("transferência já está em execução")
+	}
+
+	if err := transfer.Cancel(); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(transfer); err != nil {
+		s.logger.WithError(err).Error("Error cancelling scheduled transfer")
+		return fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"transferId":      transfer.ID,
+		"originAccountId": originAccountID,
+	}).Info("Scheduled transfer cancelled")
+
+	return nil
+}
+
+// ExecuteDueTransfers executa as transferências agendadas que venceram. Retorna
+// quantas foram concluídas.
+func (s *transferService) ExecuteDueTransfers() (int, error) {
+	now := time.Now()
+	due, err := s.repo.GetDueScheduled(now, now.Add(-scheduledClaimTimeout), scheduledBatchSize)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing due scheduled transfers")
+		return 0, fmt.Errorf("erro interno do servidor")
+	}
+
+	completed := 0
+	for i := range due {
+		transfer := &due[i]
+
+		claimedAt := time.Now()
+		claimed, err := s.repo.ClaimScheduled(transfer.ID, claimedAt.Add(-scheduledClaimTimeout), claimedAt)
+		if err != nil {
+			s.logger.WithError(err).WithField("transferId", transfer.ID).Error("Error claiming scheduled transfer")
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		resumed := isInterruptedAttempt(transfer)
+		transfer.ClaimedAt = &claimedAt
+
+		if s.executeScheduledTransfer(transfer, resumed) {
+			completed++
+		}
+	}
+
+	return completed, nil
+}
+
+// executeScheduledTransfer executa uma tentativa. resumed retoma a tentativa de uma
+// execução interrompida com o mesmo número, para que o requestId da reserva se repita e
+// a API de contas devolva a reserva já feita em vez de reservar de novo.
+func (s *transferService) executeScheduledTransfer(transfer *domain.Transfer, resumed bool) bool {
+	if !resumed {
+		transfer.Attempts++
+	}
+
+	requestID := transfer.ID
+	if transfer.IdempotencyKey != nil {
+		requestID = *transfer.IdempotencyKey
+	}
+
+	// O limite diário é conferido por runTransfer na execução: no agendamento, o dia
+	// ainda não era o da transferência.
+	result := s.runTransfer(transfer, transferExecution{
+		RequestID:                requestID,
+		HoldRequestID:            fmt.Sprintf("%s-hold-%d", transfer.ID, transfer.Attempts),
+		OriginAccountID:          transfer.OriginAccountID,
+		DestinationAccountID:     transfer.DestinationAccountID,
+		DestinationAccountNumber: s.getAccountNumberByID(transfer.DestinationAccountID),
+		Amount:                   transfer.Amount,
+		Description:              transfer.Description,
+		Type:                     kafka.TransferTypeStandard,
+	}, true)
+
+	if result.IsSuccess {
+		return true
+	}
+
+	policy := s.getScheduleRetryPolicy()
+
+	// runTransfer só marca a transferência como falha depois de tentar capturar a
+	// reserva. A partir daí o débito pode ter acontecido e o crédito e o estorno usam
+	// requestIds fixos, que a conciliação confere; uma nova tentativa debitaria de novo
+	// sem creditar, então a falha é definitiva.
+	captureAttempted := transfer.Status == domain.TransferStatusFailed
+
+	var nextAttempt *time.Time
+	if !captureAttempted && isRetryableScheduleError(result.ErrorType) && transfer.Attempts < policy.MaxAttempts {
+		next := policy.NextAttempt(time.Now())
+		nextAttempt = &next
+	}
+
+	transfer.RecordFailedAttempt(result.ErrorMessage, nextAttempt)
+	if err := s.repo.Update(transfer); err != nil {
+		s.logger.WithError(err).WithField("transferId", transfer.ID).Error("Error recording scheduled transfer failure")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"transferId":    transfer.ID,
+		"attempt":       transfer.Attempts,
+		"errorType":     result.ErrorType,
+		"error":         result.ErrorMessage,
+		"nextAttemptAt": transfer.NextAttemptAt,
+	}).Warn("Scheduled transfer attempt failed")
+
+	return false
+}
+
+// isInterruptedAttempt indica se a transferência foi assumida por uma execução que caiu
+// depois de gravar a reserva: a execução gravada é posterior ao claim anterior.
+func isInterruptedAttempt(transfer *domain.Transfer) bool {
+	return transfer.Status == domain.TransferStatusPending &&
+		transfer.ClaimedAt != nil &&
+		transfer.ExecutedAt != nil &&
+		!transfer.ExecutedAt.Before(*transfer.ClaimedAt)
+}
+
+// StartTransferScheduler executa ExecuteDueTransfers periodicamente até o canal stop
+// ser fechado.
+func StartTransferScheduler(service TransferService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				service.ExecuteDueTransfers()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// GetSchedulerInterval lê TRANSFER_SCHEDULER_INTERVAL_SECONDS (padrão de um minuto).
+func GetSchedulerInterval() time.Duration {
+	if value := os.Getenv("TRANSFER_SCHEDULER_INTERVAL_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return time.Minute
+}
+
+func (s *transferService) getScheduleRetryPolicy() ScheduleRetryPolicy {
+	policy := ScheduleRetryPolicy{MaxAttempts: 2, RetryHour: 8, RetryMinute: 0}
+
+	if value := os.Getenv("TRANSFER_SCHEDULE_MAX_ATTEMPTS"); value != "" {
+		maxAttempts, err := strconv.Atoi(value)
+		if err != nil || maxAttempts < 1 {
+			s.logger.WithField("value", value).Error("Invalid TRANSFER_SCHEDULE_MAX_ATTEMPTS")
+		} else {
+			policy.MaxAttempts = maxAttempts
+		}
+	}
+
+	if value := os.Getenv("TRANSFER_SCHEDULE_RETRY_TIME"); value != "" {
+		retryTime, err := time.Parse("15:04", value)
+		if err != nil {
+			s.logger.WithField("value", value).Error("Invalid TRANSFER_SCHEDULE_RETRY_TIME")
+		} else {
+			policy.RetryHour = retryTime.Hour()
+			policy.RetryMinute = retryTime.Minute()
+		}
+	}
+
+	return policy
+}
+
+// isRetryableScheduleError considera temporárias a falta de saldo, o limite diário
+// esgotado e as falhas internas; erros de cadastro, como conta de destino inexistente,
+// falham de imediato.
+func isRetryableScheduleError(errorType string) bool {
+	return errorType == models.ErrorInsufficientBalance || errorType == models.ErrorDailyLimitExceeded || errorType == models.ErrorInternalError
+}
+
+func validateScheduledFor(scheduledFor time.Time) *models.Result[TransferResponse] {
+	now := time.Now()
+	if !scheduledFor.After(now) {
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidArgument,
+			ErrorMessage: "Data de agendamento deve ser futura",
+		}
+	}
+	if scheduledFor.After(now.Add(maxScheduleHorizon)) {
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidArgument,
+			ErrorMessage: "Data de agendamento deve ser em até um ano",
+		}
+	}
+	return nil
+}
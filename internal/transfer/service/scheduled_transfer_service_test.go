@@ -0,0 +1,250 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"bankmore/internal/transfer/domain"
+	"bankmore/internal/transfer/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// accountAPI simula as rotas da API de contas usadas na execução de uma transferência:
+// reservas, captura e lançamentos. Os créditos respondem com os status configurados,
+// na ordem; depois deles, com 204.
+type accountAPI struct {
+	mu            sync.Mutex
+	holds         []string
+	captures      []string
+	movements     []map[string]interface{}
+	creditResults []int
+}
+
+func (a *accountAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case req.Method == http.MethodPost && req.URL.Path == "/api/account/holds":
+		var request map[string]interface{}
+		json.NewDecoder(req.Body).Decode(&request)
+		a.holds = append(a.holds, request["requestId"].(string))
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"id": fmt.Sprintf("hold-%d", len(a.holds))})
+
+	case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/capture"):
+		a.captures = append(a.captures, req.URL.Path)
+		// Só a primeira captura é aceita: uma segunda já é o débito em dobro que o teste
+		// procura, e recusá-la evita que a execução siga até a publicação no Kafka.
+		if len(a.captures) > 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/void"):
+		w.WriteHeader(http.StatusOK)
+
+	case req.Method == http.MethodPost && req.URL.Path == "/api/account/movement":
+		var request map[string]interface{}
+		json.NewDecoder(req.Body).Decode(&request)
+		a.movements = append(a.movements, request)
+		status := http.StatusNoContent
+		if strings.HasSuffix(request["requestId"].(string), "-credit") && len(a.creditResults) > 0 {
+			status = a.creditResults[0]
+			a.creditResults = a.creditResults[1:]
+		}
+		w.WriteHeader(status)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (a *accountAPI) movementIDs() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ids := make([]string, 0, len(a.movements))
+	for _, movement := range a.movements {
+		ids = append(ids, movement["requestId"].(string))
+	}
+	return ids
+}
+
+func newTestTransferService(t *testing.T, api *accountAPI) (*transferService, *gorm.DB) {
+	t.Helper()
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	t.Setenv("ACCOUNT_API_URL", server.URL)
+	t.Setenv("TRANSFER_SCHEDULE_MAX_ATTEMPTS", "3")
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "transfer.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("erro ao abrir o banco: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Transfer{}); err != nil {
+		t.Fatalf("erro ao migrar o banco: %v", err)
+	}
+	if err := db.Exec("CREATE TABLE contacorrente (idcontacorrente TEXT PRIMARY KEY, numero INTEGER, nome TEXT)").Error; err != nil {
+		t.Fatalf("erro ao criar contas: %v", err)
+	}
+	if err := db.Exec("INSERT INTO contacorrente VALUES ('origem', 1001, 'Origem'), ('destino', 1002, 'Destino')").Error; err != nil {
+		t.Fatalf("erro ao criar contas: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	// Sem produtor: nenhum caminho deste teste deve chegar à publicação do evento.
+	service := NewTransferService(repository.NewTransferRepository(db), repository.NewBatchRepository(db), nil, log)
+	return service.(*transferService), db
+}
+
+// TestScheduledTransferNotRetriedAfterCapture falha o crédito do destino na primeira
+// execução e roda o agendador de novo com a tentativa já vencida: a origem não pode ser
+// debitada outra vez.
+func TestScheduledTransferNotRetriedAfterCapture(t *testing.T) {
+	api := &accountAPI{creditResults: []int{http.StatusInternalServerError}}
+	service, db := newTestTransferService(t, api)
+
+	transfer := domain.NewScheduledTransfer("origem", "destino", 150, "Aluguel", nil, time.Now().Add(time.Hour))
+	past := time.Now().Add(-time.Minute)
+	transfer.ScheduledFor = &past
+	if err := service.repo.Create(transfer); err != nil {
+		t.Fatalf("erro ao gravar a transferência: %v", err)
+	}
+
+	completed, err := service.ExecuteDueTransfers()
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if completed != 0 {
+		t.Fatalf("%d transferências concluídas, esperado 0", completed)
+	}
+
+	stored, err := service.repo.GetByID(transfer.ID)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if stored.Status != domain.TransferStatusFailed || stored.NextAttemptAt != nil {
+		t.Fatalf("status %d com próxima tentativa %v, esperada falha definitiva", stored.Status, stored.NextAttemptAt)
+	}
+
+	// Uma nova tentativa agendada indevidamente já estaria vencida aqui.
+	if err := db.Model(&domain.Transfer{}).Where("idtransferencia = ?", transfer.ID).
+		Update("data_proxima_tentativa", past).Error; err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if _, err := service.ExecuteDueTransfers(); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if len(api.holds) != 1 || len(api.captures) != 1 {
+		t.Errorf("%d reservas e %d capturas, esperada uma de cada", len(api.holds), len(api.captures))
+	}
+
+	want := []string{transfer.ID + "-credit", transfer.ID + "-rollback"}
+	got := api.movementIDs()
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("lançamentos %v, esperado %v", got, want)
+	}
+}
+
+// TestScheduledTransferRetriedBeforeCapture confere que o limite diário esgotado, que
+// recusa a execução antes de qualquer reserva, continua agendando nova tentativa.
+func TestScheduledTransferRetriedBeforeCapture(t *testing.T) {
+	api := &accountAPI{}
+	service, _ := newTestTransferService(t, api)
+	t.Setenv("TRANSFER_DAILY_LIMIT", "100")
+
+	transfer := domain.NewScheduledTransfer("origem", "destino", 150, "Aluguel", nil, time.Now().Add(time.Hour))
+	past := time.Now().Add(-time.Minute)
+	transfer.ScheduledFor = &past
+	if err := service.repo.Create(transfer); err != nil {
+		t.Fatalf("erro ao gravar a transferência: %v", err)
+	}
+
+	if _, err := service.ExecuteDueTransfers(); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	stored, err := service.repo.GetByID(transfer.ID)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if stored.Status != domain.TransferStatusScheduled || stored.NextAttemptAt == nil {
+		t.Errorf("status %d com próxima tentativa %v, esperada nova tentativa", stored.Status, stored.NextAttemptAt)
+	}
+	if len(api.holds) != 0 {
+		t.Errorf("%d reservas, esperada nenhuma", len(api.holds))
+	}
+}
+
+// TestScheduledTransferStaleClaimResumed simula um agendador que caiu depois de gravar a
+// reserva: a transferência só volta a ser assumida depois do timeout e é retomada com o
+// mesmo requestId de reserva, sem reservar de novo.
+func TestScheduledTransferStaleClaimResumed(t *testing.T) {
+	api := &accountAPI{creditResults: []int{http.StatusInternalServerError}}
+	service, db := newTestTransferService(t, api)
+
+	transfer := domain.NewScheduledTransfer("origem", "destino", 150, "Aluguel", nil, time.Now().Add(time.Hour))
+	past := time.Now().Add(-time.Hour)
+	transfer.ScheduledFor = &past
+	transfer.Status = domain.TransferStatusPending
+	transfer.Attempts = 1
+	holdID := "hold-anterior"
+	transfer.HoldID = &holdID
+	claimedAt := time.Now().Add(-time.Minute)
+	executedAt := claimedAt.Add(time.Second)
+	transfer.ClaimedAt = &claimedAt
+	transfer.ExecutedAt = &executedAt
+	if err := service.repo.Create(transfer); err != nil {
+		t.Fatalf("erro ao gravar a transferência: %v", err)
+	}
+
+	if _, err := service.ExecuteDueTransfers(); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(api.holds) != 0 {
+		t.Fatalf("transferência assumida há um minuto executada de novo")
+	}
+
+	stale := time.Now().Add(-scheduledClaimTimeout - time.Minute)
+	staleExecutedAt := stale.Add(time.Second)
+	if err := db.Model(&domain.Transfer{}).Where("idtransferencia = ?", transfer.ID).
+		Updates(map[string]interface{}{"data_processamento": stale, "data_execucao": staleExecutedAt}).Error; err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	if _, err := service.ExecuteDueTransfers(); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	want := transfer.ID + "-hold-1"
+	if len(api.holds) != 1 || api.holds[0] != want {
+		t.Errorf("reservas %v, esperada só %s", api.holds, want)
+	}
+
+	stored, err := service.repo.GetByID(transfer.ID)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if stored.Attempts != 1 {
+		t.Errorf("%d tentativas, esperada 1", stored.Attempts)
+	}
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bankmore/internal/shared/models"
+	"bankmore/internal/transfer/domain"
+	"bankmore/internal/transfer/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const standingOrderBatchSize = 50
+
+var ErrStandingOrderNotFound = errors.New("ordem recorrente não encontrada")
+
+type StandingOrderService interface {
+	CreateStandingOrder(originAccountID string, request CreateStandingOrderRequest) (*models.Result[domain.StandingOrder], error)
+	ListStandingOrders(originAccountID string) ([]domain.StandingOrder, error)
+	GetStandingOrder(originAccountID, orderID string) (*StandingOrderDetails, error)
+	PauseStandingOrder(originAccountID, orderID string) (*domain.StandingOrder, error)
+	ResumeStandingOrder(originAccountID, orderID string) (*domain.StandingOrder, error)
+	CancelStandingOrder(originAccountID, orderID string) (*domain.StandingOrder, error)
+	MaterializeDueOrders() (int, error)
+}
+
+type standingOrderService struct {
+	repo         repository.StandingOrderRepository
+	transferRepo repository.TransferRepository
+	transfers    TransferService
+	logger       *logrus.Logger
+}
+
+func NewStandingOrderService(repo repository.StandingOrderRepository, transferRepo repository.TransferRepository, transfers TransferService, logger *logrus.Logger) StandingOrderService {
+	return &standingOrderService{
+		repo:         repo,
+		transferRepo: transferRepo,
+		transfers:    transfers,
+		logger:       logger,
+	}
+}
+
+// CreateStandingOrderRequest: dayOfMonth é obrigatório para MONTHLY e cronExpression
+// para CRON. Sem startDate a ordem começa agora; endDate e maxOccurrences são opcionais.
+type CreateStandingOrderRequest struct {
+	DestinationAccountNumber string     `json:"destinationAccountNumber"`
+	DestinationKey           string     `json:"destinationKey"`
+	Amount                   float64    `json:"amount" binding:"required"`
+	Description              string     `json:"description"`
+	Frequency                string     `json:"frequency" binding:"required"`
+	DayOfMonth               int        `json:"dayOfMonth"`
+	CronExpression           string     `json:"cronExpression"`
+	StartDate                *time.Time `json:"startDate"`
+	EndDate                  *time.Time `json:"endDate"`
+	MaxOccurrences           int        `json:"maxOccurrences"`
+	TOTPCode                 string     `json:"totpCode,omitempty"`
+	Authorization            string     `json:"-"`
+}
+
+// StandingOrderDetails traz a ordem e as transferências já geradas por ela.
+type StandingOrderDetails struct {
+	domain.StandingOrder
+	Transfers []domain.Transfer `json:"transfers"`
+}
+
+// CreateStandingOrder valida o destino e o 2FA uma única vez, na criação; as
+// ocorrências são executadas depois sem nova confirmação.
+func (s *standingOrderService) CreateStandingOrder(originAccountID string, request CreateStandingOrderRequest) (*models.Result[domain.StandingOrder], error) {
+	prepared, result := s.transfers.PrepareTransfer(CreateTransferRequest{
+		DestinationAccountNumber: request.DestinationAccountNumber,
+		DestinationKey:           request.DestinationKey,
+		Amount:                   request.Amount,
+		TOTPCode:                 request.TOTPCode,
+		Authorization:            request.Authorization,
+	}, originAccountID)
+	if result != nil {
+		return &models.Result[domain.StandingOrder]{
+			IsSuccess:    false,
+			ErrorType:    result.ErrorType,
+			ErrorMessage: result.ErrorMessage,
+		}, nil
+	}
+
+	now := time.Now()
+	startDate := now
+	if request.StartDate != nil {
+		startDate = *request.StartDate
+	}
+
+	description := request.Description
+	if description == "" {
+		description = fmt.Sprintf("Transferência recorrente para conta %s", prepared.DestinationAccountNumber)
+	}
+
+	order, err := domain.NewStandingOrder(
+		prepared.OriginAccountID,
+		prepared.DestinationAccountID,
+		prepared.DestinationAccountNumber,
+		prepared.Amount,
+		description,
+		strings.ToUpper(strings.TrimSpace(request.Frequency)),
+		request.DayOfMonth,
+		strings.TrimSpace(request.CronExpression),
+		startDate,
+		request.EndDate,
+		request.MaxOccurrences,
+		now,
+	)
+	if err != nil {
+		return &models.Result[domain.StandingOrder]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidArgument,
+			ErrorMessage: err.Error(),
+		}, nil
+	}
+
+	if err := s.repo.Create(order); err != nil {
+		s.logger.WithError(err).Error("Error creating standing order")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"standingOrderId": order.ID,
+		"originAccountId": originAccountID,
+		"frequency":       order.Frequency,
+		"amount":          order.Amount,
+		"nextRunAt":       order.NextRunAt,
+	}).Info("Standing order created")
+
+	return &models.Result[domain.StandingOrder]{
+		IsSuccess: true,
+		Data:      *order,
+	}, nil
+}
+
+func (s *standingOrderService) ListStandingOrders(originAccountID string) ([]domain.StandingOrder, error) {
+	orders, err := s.repo.GetByAccountID(originAccountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing standing orders")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return orders, nil
+}
+
+func (s *standingOrderService) GetStandingOrder(originAccountID, orderID string) (*StandingOrderDetails, error) {
+	order, err := s.getOwnOrder(originAccountID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers, err := s.transferRepo.GetByStandingOrderID(order.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing standing order transfers")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	return &StandingOrderDetails{
+		StandingOrder: *order,
+		Transfers:     transfers,
+	}, nil
+}
+
+func (s *standingOrderService) PauseStandingOrder(originAccountID, orderID string) (*domain.StandingOrder, error) {
+	order, err := s.getOwnOrder(originAccountID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := order.Pause(); err != nil {
+		return nil, err
+	}
+
+	if err := s.saveAndCancelPending(order, "Standing order paused"); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (s *standingOrderService) ResumeStandingOrder(originAccountID, orderID string) (*domain.StandingOrder, error) {
+	order, err := s.getOwnOrder(originAccountID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := order.Resume(time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(order); err != nil {
+		s.logger.WithError(err).Error("Error resuming standing order")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"standingOrderId": order.ID,
+		"status":          order.Status,
+		"nextRunAt":       order.NextRunAt,
+	}).Info("Standing order resumed")
+
+	return order, nil
+}
+
+func (s *standingOrderService) CancelStandingOrder(originAccountID, orderID string) (*domain.StandingOrder, error) {
+	order, err := s.getOwnOrder(originAccountID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := order.Cancel(); err != nil {
+		return nil, err
+	}
+
+	if err := s.saveAndCancelPending(order, "Standing order cancelled"); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// saveAndCancelPending grava a ordem pausada ou cancelada e cancela as ocorrências
+// já geradas que ainda aguardam execução (por exemplo, à espera de nova tentativa).
+func (s *standingOrderService) saveAndCancelPending(order *domain.StandingOrder, message string) error {
+	if err := s.repo.Update(order); err != nil {
+		s.logger.WithError(err).WithField("standingOrderId", order.ID).Error("Error updating standing order")
+		return fmt.Errorf("erro interno do servidor")
+	}
+
+	transfers, err := s.transferRepo.GetByStandingOrderID(order.ID)
+	if err != nil {
+		s.logger.WithError(err).WithField("standingOrderId", order.ID).Error("Error listing standing order transfers")
+	}
+
+	cancelled := 0
+	for _, transfer := range transfers {
+		if transfer.Status != domain.TransferStatusScheduled {
+			continue
+		}
+		if err := s.transfers.CancelScheduledTransfer(order.OriginAccountID, transfer.ID); err != nil {
+			s.logger.WithError(err).WithField("transferId", transfer.ID).Warn("Could not cancel standing order occurrence")
+			continue
+		}
+		cancelled++
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"standingOrderId":      order.ID,
+		"cancelledOccurrences": cancelled,
+	}).Info(message)
+
+	return nil
+}
+
+// MaterializeDueOrders gera a transferência agendada de cada ordem vencida e avança a
+// ordem para a próxima ocorrência. A execução e as novas tentativas ficam com o
+// agendador de transferências. Retorna quantas ocorrências foram geradas.
+func (s *standingOrderService) MaterializeDueOrders() (int, error) {
+	orders, err := s.repo.GetDue(time.Now(), standingOrderBatchSize)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing due standing orders")
+		return 0, fmt.Errorf("erro interno do servidor")
+	}
+
+	materialized := 0
+	for i := range orders {
+		order := &orders[i]
+		runAt := *order.NextRunAt
+		previousOccurrences := order.Occurrences
+
+		transfer := order.NewOccurrence(runAt)
+		order.Advance(runAt)
+
+		advanced, err := s.repo.Materialize(order, transfer, previousOccurrences)
+		if err != nil {
+			s.logger.WithError(err).WithField("standingOrderId", order.ID).Error("Error materializing standing order occurrence")
+			continue
+		}
+		if !advanced {
+			continue
+		}
+		materialized++
+
+		s.logger.WithFields(logrus.Fields{
+			"standingOrderId": order.ID,
+			"transferId":      transfer.ID,
+			"period":          order.Period(runAt),
+			"occurrence":      order.Occurrences,
+			"nextRunAt":       order.NextRunAt,
+		}).Info("Standing order occurrence scheduled")
+	}
+
+	return materialized, nil
+}
+
+func (s *standingOrderService) getOwnOrder(originAccountID, orderID string) (*domain.StandingOrder, error) {
+	order, err := s.repo.GetByID(orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStandingOrderNotFound
+		}
+		s.logger.WithError(err).Error("Error getting standing order")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	if order.OriginAccountID != originAccountID {
+		return nil, ErrStandingOrderNotFound
+	}
+	return order, nil
+}
+
+// StartStandingOrderScheduler executa MaterializeDueOrders periodicamente até o canal
+// stop ser fechado.
+func StartStandingOrderScheduler(service StandingOrderService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				service.MaterializeDueOrders()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
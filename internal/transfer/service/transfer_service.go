@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"bankmore/internal/shared/kafka"
+	"bankmore/internal/shared/middleware"
 	"bankmore/internal/shared/models"
 	"bankmore/internal/transfer/domain"
 	"bankmore/internal/transfer/repository"
@@ -20,93 +23,445 @@ import (
 
 type TransferService interface {
 	CreateTransfer(request CreateTransferRequest, originAccountID string) (*models.Result[TransferResponse], error)
+	PrepareTransfer(request CreateTransferRequest, originAccountID string) (*PreparedTransfer, *models.Result[TransferResponse])
+	CreateSweepTransfer(request SweepTransferRequest) (*models.Result[TransferResponse], error)
+	ListScheduledTransfers(originAccountID string) ([]domain.Transfer, error)
+	CancelScheduledTransfer(originAccountID, transferID string) error
+	ExecuteDueTransfers() (int, error)
+	CreateBatch(originAccountID string, request CreateBatchRequest) (*models.Result[domain.TransferBatch], error)
+	GetBatch(originAccountID, batchID string) (*domain.TransferBatch, error)
+	ListBatches(originAccountID string) ([]domain.TransferBatch, error)
+	GetBatchReport(originAccountID, batchID string) ([]byte, error)
+	ProcessPendingBatches() (int, error)
+	GetTransfer(accountID, transferID string) (*domain.Transfer, error)
+	ListRefunds(accountID, transferID string) ([]domain.Transfer, error)
+	RefundTransfer(accountID, transferID string, request RefundRequest) (*models.Result[TransferResponse], error)
+	QuoteTransfer(originAccountID string, request QuoteRequest) (*models.Result[TransferQuote], error)
+	AsyncTransferQueue() <-chan string
+	ExecuteAsyncTransfer(transferID string) bool
+	ProcessPendingAsyncTransfers() (int, error)
 }
 
 type transferService struct {
-	repo     repository.TransferRepository
-	producer *kafka.Producer
-	logger   *logrus.Logger
+	repo       repository.TransferRepository
+	batchRepo  repository.BatchRepository
+	producer   *kafka.Producer
+	logger     *logrus.Logger
+	asyncQueue chan string
 }
 
-func NewTransferService(repo repository.TransferRepository, producer *kafka.Producer, logger *logrus.Logger) TransferService {
+func NewTransferService(repo repository.TransferRepository, batchRepo repository.BatchRepository, producer *kafka.Producer, logger *logrus.Logger) TransferService {
 	return &transferService{
-		repo:     repo,
-		producer: producer,
-		logger:   logger,
+		repo:       repo,
+		batchRepo:  batchRepo,
+		producer:   producer,
+		logger:     logger,
+		asyncQueue: make(chan string, asyncQueueSize),
 	}
 }
 
+// CreateTransferRequest identifica o destino pelo número da conta ou por uma chave Pix.
+// Com scheduledFor a transferência é agendada em vez de executada na hora. Com quoteId
+// (de POST /api/transfer/quote) a tarifa cobrada é a da cotação. Com async a
+// transferência é aceita (202) e executada em segundo plano.
 type CreateTransferRequest struct {
+	RequestID                string     `json:"requestId" binding:"required"`
+	DestinationAccountNumber string     `json:"destinationAccountNumber"`
+	DestinationKey           string     `json:"destinationKey"`
+	Amount                   float64    `json:"amount" binding:"required"`
+	ScheduledFor             *time.Time `json:"scheduledFor,omitempty"`
+	TOTPCode                 string     `json:"totpCode,omitempty"`
+	QuoteID                  string     `json:"quoteId,omitempty"`
+	Async                    bool       `json:"async,omitempty"`
+	Authorization            string     `json:"-"`
+}
+
+type TransferResponse struct {
+	TransferID   string     `json:"transferId"`
+	Message      string     `json:"message"`
+	ScheduledFor *time.Time `json:"scheduledFor,omitempty"`
+	Status       *int       `json:"status,omitempty"`
+	StatusURL    string     `json:"statusUrl,omitempty"`
+}
+
+type SweepTransferRequest struct {
 	RequestID                string  `json:"requestId" binding:"required"`
+	OriginAccountID          string  `json:"originAccountId" binding:"required"`
 	DestinationAccountNumber string  `json:"destinationAccountNumber" binding:"required"`
 	Amount                   float64 `json:"amount" binding:"required"`
 }
 
-type TransferResponse struct {
-	TransferID string `json:"transferId"`
-	Message    string `json:"message"`
-}
+// transferHoldTTLSeconds limita por quanto tempo uma transferência interrompida
+// mantém o saldo da origem reservado.
+const transferHoldTTLSeconds = 300
+
+var errInsufficientBalance = errors.New("saldo insuficiente")
 
 type AccountAPIResponse struct {
 	AccountNumber string  `json:"accountNumber"`
 	Balance       float64 `json:"balance"`
 }
 
+// PreparedTransfer é uma transferência de cliente já validada: destino resolvido,
+// contas distintas e 2FA conferido quando exigido.
+type PreparedTransfer struct {
+	OriginAccountID          string
+	DestinationAccountID     string
+	DestinationAccountNumber string
+	Amount                   float64
+	Description              string
+}
+
 func (s *transferService) CreateTransfer(request CreateTransferRequest, originAccountID string) (*models.Result[TransferResponse], error) {
-	if request.Amount <= 0 {
+	// O reenvio de um requestId já usado devolve a transferência original antes de
+	// qualquer validação, inclusive do 2FA, cujo código já foi consumido.
+	existing, result := s.findResubmission(request, originAccountID)
+	if result != nil {
+		return result, nil
+	}
+	if existing != nil {
+		return resubmittedTransfer(existing), nil
+	}
+
+	if request.ScheduledFor != nil {
+		if result := validateScheduledFor(*request.ScheduledFor); result != nil {
+			return result, nil
+		}
+	}
+
+	prepared, result := s.PrepareTransfer(request, originAccountID)
+	if result != nil {
+		return result, nil
+	}
+
+	if request.ScheduledFor != nil {
+		if request.QuoteID != "" {
+			return &models.Result[TransferResponse]{
+				IsSuccess:    false,
+				ErrorType:    models.ErrorInvalidQuote,
+				ErrorMessage: "Cotação não se aplica a transferências agendadas",
+			}, nil
+		}
+		return s.scheduleTransfer(request, prepared), nil
+	}
+
+	var feeAmount *float64
+	if request.QuoteID != "" {
+		fee, result := s.redeemQuote(request.QuoteID, prepared)
+		if result != nil {
+			return result, nil
+		}
+		feeAmount = &fee
+	}
+
+	if request.Async {
+		// O limite é conferido também no aceite, para recusar de imediato o que já
+		// não cabe no dia; runTransfer confere de novo na execução.
+		if result := s.checkDailyLimit(originAccountID, "", prepared.Amount); result != nil {
+			return result, nil
+		}
+		return s.submitAsyncTransfer(request, prepared, feeAmount), nil
+	}
+
+	return s.executeTransfer(transferExecution{
+		RequestID:                request.RequestID,
+		OriginAccountID:          prepared.OriginAccountID,
+		DestinationAccountID:     prepared.DestinationAccountID,
+		DestinationAccountNumber: prepared.DestinationAccountNumber,
+		Amount:                   prepared.Amount,
+		Description:              prepared.Description,
+		Type:                     kafka.TransferTypeStandard,
+		FeeAmount:                feeAmount,
+	}), nil
+}
+
+// findResubmission devolve a transferência já criada com o mesmo requestId. Um requestId
+// usado por outra conta, ou com outro valor ou destino, é recusado.
+func (s *transferService) findResubmission(request CreateTransferRequest, originAccountID string) (*domain.Transfer, *models.Result[TransferResponse]) {
+	existing, err := s.repo.GetByIdempotencyKey(request.RequestID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		s.logger.WithError(err).Error("Error checking transfer idempotency")
+		return nil, &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInternalError,
+			ErrorMessage: "Erro interno do servidor",
+		}
+	}
+
+	if existing.OriginAccountID != originAccountID {
+		return nil, &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidData,
+			ErrorMessage: "requestId já utilizado",
+		}
+	}
+
+	destinationAccountNumber := request.DestinationAccountNumber
+	if destinationAccountNumber == "" && request.DestinationKey != "" {
+		resolved, err := resolvePixKey(request.DestinationKey)
+		if err != nil {
+			s.logger.WithError(err).Error("Error resolving pix key of resubmitted transfer")
+			return nil, &models.Result[TransferResponse]{
+				IsSuccess:    false,
+				ErrorType:    models.ErrorInternalError,
+				ErrorMessage: "Erro interno do servidor",
+			}
+		}
+		destinationAccountNumber = resolved.AccountNumber
+	}
+
+	if !sameAmount(existing.Amount, request.Amount) || s.getAccountNumberByID(existing.DestinationAccountID) != destinationAccountNumber {
+		return nil, &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorIdempotencyKeyReused,
+			ErrorMessage: "requestId já utilizado em transferência com outro valor ou destino",
+		}
+	}
+
+	s.logger.WithField("requestId", request.RequestID).Info("Duplicate transfer request ignored")
+	return existing, nil
+}
+
+// resubmittedTransfer responde ao reenvio com a situação da transferência original:
+// assíncronas voltam como aceitas, concluídas e agendadas como sucesso, e as demais
+// como erro, sem nova execução.
+func resubmittedTransfer(existing *domain.Transfer) *models.Result[TransferResponse] {
+	if existing.Async {
+		return acceptedTransfer(existing)
+	}
+
+	status := existing.Status
+	switch existing.Status {
+	case domain.TransferStatusCompleted:
+		return &models.Result[TransferResponse]{
+			IsSuccess: true,
+			Data: TransferResponse{
+				TransferID: existing.ID,
+				Message:    "Transferência realizada com sucesso",
+				Status:     &status,
+			},
+		}
+	case domain.TransferStatusScheduled:
+		return &models.Result[TransferResponse]{
+			IsSuccess: true,
+			Data: TransferResponse{
+				TransferID:   existing.ID,
+				Message:      fmt.Sprintf("Transferência agendada para %s", existing.ScheduledFor.Format("02/01/2006 15:04")),
+				ScheduledFor: existing.ScheduledFor,
+				Status:       &status,
+			},
+		}
+	case domain.TransferStatusPending:
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidOperation,
+			ErrorMessage: "Transferência com este requestId está em processamento",
+		}
+	default:
 		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidOperation,
+			ErrorMessage: "Transferência com este requestId não foi realizada",
+		}
+	}
+}
+
+// PrepareTransfer valida valor e destino (número da conta ou chave Pix) e exige 2FA
+// acima do limite. Retorna o resultado de erro quando a transferência não pode seguir.
+func (s *transferService) PrepareTransfer(request CreateTransferRequest, originAccountID string) (*PreparedTransfer, *models.Result[TransferResponse]) {
+	prepared, result := s.validateTransfer(request, originAccountID)
+	if result != nil {
+		return nil, result
+	}
+
+	if request.Amount > s.getTwoFactorThreshold() {
+		if result := s.verifyTwoFactor(request.Authorization, request.TOTPCode); result != nil {
+			return nil, result
+		}
+	}
+
+	return prepared, nil
+}
+
+// validateTransfer faz as validações de PrepareTransfer, exceto o 2FA.
+func (s *transferService) validateTransfer(request CreateTransferRequest, originAccountID string) (*PreparedTransfer, *models.Result[TransferResponse]) {
+	if request.Amount <= 0 {
+		return nil, &models.Result[TransferResponse]{
 			IsSuccess:    false,
 			ErrorType:    models.ErrorInvalidAmount,
 			ErrorMessage: "Valor deve ser positivo",
-		}, nil
+		}
 	}
 
-	destinationAccountID, err := s.getAccountIDByNumber(request.DestinationAccountNumber)
+	if (request.DestinationAccountNumber == "") == (request.DestinationKey == "") {
+		return nil, &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidData,
+			ErrorMessage: "Informe o número da conta ou a chave de destino",
+		}
+	}
+
+	destinationAccountNumber := request.DestinationAccountNumber
+	if request.DestinationKey != "" {
+		resolved, err := resolvePixKey(request.DestinationKey)
+		if err != nil {
+			s.logger.WithError(err).Error("Error resolving destination key")
+			return nil, &models.Result[TransferResponse]{
+				IsSuccess:    false,
+				ErrorType:    models.ErrorPixKeyNotFound,
+				ErrorMessage: "Chave de destino não encontrada",
+			}
+		}
+		destinationAccountNumber = resolved.AccountNumber
+	}
+
+	destinationAccountID, err := s.getAccountIDByNumber(destinationAccountNumber)
 	if err != nil {
 		s.logger.WithError(err).Error("Error getting destination account")
-		return &models.Result[TransferResponse]{
+		return nil, &models.Result[TransferResponse]{
 			IsSuccess:    false,
 			ErrorType:    models.ErrorAccountNotFound,
 			ErrorMessage: "Conta de destino não encontrada",
-		}, nil
+		}
 	}
 
 	if originAccountID == destinationAccountID {
-		return &models.Result[TransferResponse]{
+		return nil, &models.Result[TransferResponse]{
 			IsSuccess:    false,
 			ErrorType:    models.ErrorInvalidTransfer,
 			ErrorMessage: "Não é possível transferir para a mesma conta",
+		}
+	}
+
+	return &PreparedTransfer{
+		OriginAccountID:          originAccountID,
+		DestinationAccountID:     destinationAccountID,
+		DestinationAccountNumber: destinationAccountNumber,
+		Amount:                   request.Amount,
+		Description:              fmt.Sprintf("Transferência para conta %s", destinationAccountNumber),
+	}, nil
+}
+
+// CreateSweepTransfer move o saldo remanescente de uma conta em encerramento para a
+// conta indicada pelo titular. É chamado apenas pela API de contas e não gera tarifa.
+func (s *transferService) CreateSweepTransfer(request SweepTransferRequest) (*models.Result[TransferResponse], error) {
+	if request.Amount <= 0 {
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidAmount,
+			ErrorMessage: "Valor deve ser positivo",
 		}, nil
 	}
 
-	originBalance, err := s.getAccountBalance(originAccountID)
+	destinationAccountID, err := s.getAccountIDByNumber(request.DestinationAccountNumber)
 	if err != nil {
-		s.logger.WithError(err).Error("Error getting origin account balance")
+		s.logger.WithError(err).Error("Error getting sweep destination account")
 		return &models.Result[TransferResponse]{
 			IsSuccess:    false,
-			ErrorType:    models.ErrorInternalError,
-			ErrorMessage: "Erro interno do servidor",
+			ErrorType:    models.ErrorAccountNotFound,
+			ErrorMessage: "Conta de destino não encontrada",
 		}, nil
 	}
 
-	if originBalance < request.Amount {
+	if request.OriginAccountID == destinationAccountID {
 		return &models.Result[TransferResponse]{
 			IsSuccess:    false,
-			ErrorType:    models.ErrorInsufficientBalance,
-			ErrorMessage: "Saldo insuficiente",
+			ErrorType:    models.ErrorInvalidTransfer,
+			ErrorMessage: "Não é possível transferir para a mesma conta",
 		}, nil
 	}
 
-	description := fmt.Sprintf("Transferência para conta %s", request.DestinationAccountNumber)
-	transfer := domain.NewTransfer(originAccountID, destinationAccountID, request.Amount, description, &request.RequestID)
+	description := fmt.Sprintf("Transferência de saldo por encerramento para conta %s", request.DestinationAccountNumber)
 
-	if err := s.repo.Create(transfer); err != nil {
-		s.logger.WithError(err).Error("Error creating transfer")
+	return s.executeTransfer(transferExecution{
+		RequestID:                request.RequestID,
+		OriginAccountID:          request.OriginAccountID,
+		DestinationAccountID:     destinationAccountID,
+		DestinationAccountNumber: request.DestinationAccountNumber,
+		Amount:                   request.Amount,
+		Description:              description,
+		Type:                     kafka.TransferTypeSweep,
+	}), nil
+}
+
+type transferExecution struct {
+	RequestID                string
+	HoldRequestID            string
+	HoldTTLSeconds           int
+	OriginAccountID          string
+	DestinationAccountID     string
+	DestinationAccountNumber string
+	Amount                   float64
+	Description              string
+	Type                     string
+	FeeAmount                *float64
+}
+
+// executeTransfer registra e executa uma transferência imediata. As validações de
+// entrada ficam a cargo de cada ponto de entrada.
+func (s *transferService) executeTransfer(execution transferExecution) *models.Result[TransferResponse] {
+	transfer := domain.NewTransfer(execution.OriginAccountID, execution.DestinationAccountID, execution.Amount, execution.Description, &execution.RequestID)
+	return s.runTransfer(transfer, execution, false)
+}
+
+// runTransfer confere o limite diário, reserva o valor na conta de origem, grava a
+// transferência, captura a reserva, credita o destino e publica o evento. É o caminho
+// comum das transferências imediatas, assíncronas, agendadas e de lote; devoluções e
+// transferências de encerramento não contam para o limite. persisted indica uma
+// transferência já gravada, que é atualizada em vez de criada.
+func (s *transferService) runTransfer(transfer *domain.Transfer, execution transferExecution, persisted bool) *models.Result[TransferResponse] {
+	if execution.Type == kafka.TransferTypeStandard {
+		if result := s.checkDailyLimit(execution.OriginAccountID, transfer.ID, execution.Amount); result != nil {
+			return result
+		}
+	}
+
+	originAccountNumber := s.getAccountNumberByID(execution.OriginAccountID)
+
+	holdID, err := s.placeHold(originAccountNumber, execution)
+	if err != nil {
+		if errors.Is(err, errInsufficientBalance) {
+			return &models.Result[TransferResponse]{
+				IsSuccess:    false,
+				ErrorType:    models.ErrorInsufficientBalance,
+				ErrorMessage: "Saldo insuficiente",
+			}
+		}
+		s.logger.WithError(err).Error("Error placing hold on origin account")
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInvalidOperation,
+			ErrorMessage: err.Error(),
+		}
+	}
+
+	executedAt := time.Now()
+	transfer.HoldID = &holdID
+	transfer.Status = domain.TransferStatusPending
+	transfer.Type = execution.Type
+	transfer.ExecutedAt = &executedAt
+	if accountingDate, err := s.repo.GetAccountingDate(executedAt); err != nil {
+		s.logger.WithError(err).Error("Error resolving transfer accounting date")
+	} else {
+		transfer.AccountingDate = &accountingDate
+	}
+
+	save := s.repo.Create
+	if persisted {
+		save = s.repo.Update
+	}
+
+	if err := save(transfer); err != nil {
+		s.logger.WithError(err).Error("Error saving transfer")
+		s.voidHold(holdID)
 		return &models.Result[TransferResponse]{
 			IsSuccess:    false,
 			ErrorType:    models.ErrorInternalError,
 			ErrorMessage: "Erro interno do servidor",
-		}, nil
+		}
 	}
 
 	if err := s.processTransferMovements(transfer); err != nil {
@@ -117,7 +472,7 @@ func (s *transferService) CreateTransfer(request CreateTransferRequest, originAc
 			IsSuccess:    false,
 			ErrorType:    models.ErrorInternalError,
 			ErrorMessage: "Erro ao processar transferência",
-		}, nil
+		}
 	}
 
 	transfer.Complete()
@@ -126,24 +481,29 @@ func (s *transferService) CreateTransfer(request CreateTransferRequest, originAc
 	}
 
 	event := kafka.TransferEvent{
-		RequestID:               request.RequestID,
-		OriginAccountID:         originAccountID,
-		DestinationAccountID:    destinationAccountID,
-		DestinationAccountNumber: request.DestinationAccountNumber,
-		Amount:                  request.Amount,
-		TransferID:              transfer.ID,
+		RequestID:                execution.RequestID,
+		OriginAccountID:          execution.OriginAccountID,
+		DestinationAccountID:     execution.DestinationAccountID,
+		DestinationAccountNumber: execution.DestinationAccountNumber,
+		Amount:                   execution.Amount,
+		TransferID:               transfer.ID,
+		Type:                     execution.Type,
+		FeeAmount:                execution.FeeAmount,
 	}
 
 	if err := s.producer.PublishTransferEvent(event); err != nil {
 		s.logger.WithError(err).Error("Error publishing transfer event")
+	} else if err := s.repo.MarkEventPublished(transfer.ID, time.Now()); err != nil {
+		s.logger.WithError(err).Error("Error marking transfer event as published")
 	}
 
 	s.logger.WithFields(logrus.Fields{
-		"transferId":         transfer.ID,
-		"originAccountId":    originAccountID,
-		"destinationAccountId": destinationAccountID,
-		"amount":             request.Amount,
-		"requestId":          request.RequestID,
+		"transferId":           transfer.ID,
+		"originAccountId":      execution.OriginAccountID,
+		"destinationAccountId": execution.DestinationAccountID,
+		"amount":               execution.Amount,
+		"requestId":            execution.RequestID,
+		"type":                 execution.Type,
 	}).Info("Transfer completed successfully")
 
 	return &models.Result[TransferResponse]{
@@ -152,7 +512,118 @@ func (s *transferService) CreateTransfer(request CreateTransferRequest, originAc
 			TransferID: transfer.ID,
 			Message:    "Transferência realizada com sucesso",
 		},
-	}, nil
+	}
+}
+
+func (s *transferService) getTwoFactorThreshold() float64 {
+	thresholdStr := os.Getenv("TRANSFER_2FA_THRESHOLD")
+	if thresholdStr == "" {
+		return 1000.00
+	}
+
+	threshold, err := strconv.ParseFloat(thresholdStr, 64)
+	if err != nil {
+		s.logger.WithError(err).Error("Error parsing transfer 2FA threshold")
+		return 1000.00
+	}
+
+	return threshold
+}
+
+// verifyTwoFactor pede à API de contas a validação do código TOTP do titular do token.
+// Retorna nil quando a transferência pode prosseguir.
+func (s *transferService) verifyTwoFactor(authorization, code string) *models.Result[TransferResponse] {
+	accountAPIURL := os.Getenv("ACCOUNT_API_URL")
+	if accountAPIURL == "" {
+		accountAPIURL = "http://localhost:8001"
+	}
+
+	jsonData, err := json.Marshal(map[string]string{"code": code})
+	if err != nil {
+		s.logger.WithError(err).Error("Error marshaling two-factor request")
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInternalError,
+			ErrorMessage: "Erro interno do servidor",
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/account/2fa/verify", accountAPIURL), strings.NewReader(string(jsonData)))
+	if err != nil {
+		s.logger.WithError(err).Error("Error building two-factor request")
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInternalError,
+			ErrorMessage: "Erro interno do servidor",
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		s.logger.WithError(err).Error("Error calling two-factor verification")
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInternalError,
+			ErrorMessage: "Erro interno do servidor",
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	var errorResp models.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errorResp); err != nil || errorResp.Type == "" {
+		return &models.Result[TransferResponse]{
+			IsSuccess:    false,
+			ErrorType:    models.ErrorInternalError,
+			ErrorMessage: "Erro ao validar autenticação em dois fatores",
+		}
+	}
+
+	return &models.Result[TransferResponse]{
+		IsSuccess:    false,
+		ErrorType:    errorResp.Type,
+		ErrorMessage: errorResp.Message,
+	}
+}
+
+type resolvedPixKey struct {
+	Key           string `json:"key"`
+	AccountNumber string `json:"accountNumber"`
+}
+
+// resolvePixKey consulta o diretório de chaves da API de contas e devolve a chave
+// normalizada e o número da conta vinculada.
+func resolvePixKey(key string) (*resolvedPixKey, error) {
+	accountAPIURL := os.Getenv("ACCOUNT_API_URL")
+	if accountAPIURL == "" {
+		accountAPIURL = "http://localhost:8001"
+	}
+
+	resolveURL := fmt.Sprintf("%s/api/account/keys/resolve?key=%s", accountAPIURL, url.QueryEscape(key))
+	resp, err := middleware.DoServiceRequest("transfer-api", http.MethodGet, resolveURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("account API returned status %d", resp.StatusCode)
+	}
+
+	var resolved resolvedPixKey
+	if err := json.NewDecoder(resp.Body).Decode(&resolved); err != nil {
+		return nil, err
+	}
+	if resolved.AccountNumber == "" {
+		return nil, errors.New("pix key resolved without account number")
+	}
+
+	return &resolved, nil
 }
 
 func (s *transferService) getAccountIDByNumber(accountNumber string) (string, error) {
@@ -163,7 +634,7 @@ func (s *transferService) getAccountIDByNumber(accountNumber string) (string, er
 
 	url := fmt.Sprintf("%s/api/account/balance/%s", accountAPIURL, accountNumber)
 	
-	resp, err := http.Get(url)
+	resp, err := middleware.DoServiceRequest("transfer-api", http.MethodGet, url, nil)
 	if err != nil {
 		return "", err
 	}
@@ -182,42 +653,20 @@ func (s *transferService) getAccountIDByNumber(accountNumber string) (string, er
 		return "", err
 	}
 
-	return s.getAccountIDByNumberFromDB(accountNumber)
-}
-
-func (s *transferService) getAccountIDByNumberFromDB(accountNumber string) (string, error) {
-	var accountID string
-	err := s.repo.(*transferRepository).db.Table("contacorrente").
-		Select("idcontacorrente").
-		Where("numero = ?", accountNumber).
-		Scan(&accountID).Error
-	return accountID, err
-}
-
-func (s *transferService) getAccountBalance(accountID string) (float64, error) {
-	var balance float64
-	err := s.repo.(*transferRepository).db.Table("movimento").
-		Select("COALESCE(SUM(CASE WHEN tipomovimento = 'C' THEN valor ELSE -valor END), 0)").
-		Where("idcontacorrente = ?", accountID).
-		Scan(&balance).Error
-	return balance, err
+	return s.repo.GetAccountIDByNumber(accountNumber)
 }
 
+// processTransferMovements captura a reserva da origem e credita o destino. Se a
+// captura falhar a reserva é cancelada; se o crédito falhar o débito é estornado.
 func (s *transferService) processTransferMovements(transfer *domain.Transfer) error {
 	accountAPIURL := os.Getenv("ACCOUNT_API_URL")
 	if accountAPIURL == "" {
 		accountAPIURL = "http://localhost:8001"
 	}
 
-	debitRequest := map[string]interface{}{
-		"requestId":     transfer.ID + "-debit",
-		"accountNumber": s.getAccountNumberByID(transfer.OriginAccountID),
-		"amount":        transfer.Amount,
-		"type":          "D",
-	}
-
-	if err := s.callAccountMovementAPI(accountAPIURL, debitRequest); err != nil {
-		return fmt.Errorf("failed to debit origin account: %w", err)
+	if err := s.captureHold(*transfer.HoldID); err != nil {
+		s.voidHold(*transfer.HoldID)
+		return fmt.Errorf("failed to capture origin hold: %w", err)
 	}
 
 	creditRequest := map[string]interface{}{
@@ -225,6 +674,7 @@ func (s *transferService) processTransferMovements(transfer *domain.Transfer) er
 		"accountNumber": s.getAccountNumberByID(transfer.DestinationAccountID),
 		"amount":        transfer.Amount,
 		"type":          "C",
+		"category":      "TRANSFER",
 	}
 
 	if err := s.callAccountMovementAPI(accountAPIURL, creditRequest); err != nil {
@@ -233,6 +683,7 @@ func (s *transferService) processTransferMovements(transfer *domain.Transfer) er
 			"accountNumber": s.getAccountNumberByID(transfer.OriginAccountID),
 			"amount":        transfer.Amount,
 			"type":          "C",
+			"category":      "TRANSFER",
 		}
 		s.callAccountMovementAPI(accountAPIURL, rollbackRequest)
 		return fmt.Errorf("failed to credit destination account: %w", err)
@@ -241,13 +692,101 @@ func (s *transferService) processTransferMovements(transfer *domain.Transfer) er
 	return nil
 }
 
+// placeHold reserva o valor da transferência na API de contas. A reserva usa o
+// requestId da transferência, então reenvios não duplicam a reserva.
+func (s *transferService) placeHold(accountNumber string, execution transferExecution) (string, error) {
+	accountAPIURL := os.Getenv("ACCOUNT_API_URL")
+	if accountAPIURL == "" {
+		accountAPIURL = "http://localhost:8001"
+	}
+
+	holdRequestID := execution.HoldRequestID
+	if holdRequestID == "" {
+		holdRequestID = execution.RequestID + "-hold"
+	}
+
+	holdTTL := execution.HoldTTLSeconds
+	if holdTTL == 0 {
+		holdTTL = transferHoldTTLSeconds
+	}
+
+	request := map[string]interface{}{
+		"requestId":        holdRequestID,
+		"accountNumber":    accountNumber,
+		"amount":           execution.Amount,
+		"description":      execution.Description,
+		"expiresInSeconds": holdTTL,
+	}
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/api/account/holds", accountAPIURL)
+	resp, err := middleware.DoServiceRequest("transfer-api", http.MethodPost, url, strings.NewReader(string(jsonData)))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		var errorResp models.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil && errorResp.Message != "" {
+			if errorResp.Type == models.ErrorInsufficientBalance {
+				return "", errInsufficientBalance
+			}
+			return "", errors.New(errorResp.Message)
+		}
+		return "", fmt.Errorf("account API returned status %d", resp.StatusCode)
+	}
+
+	var holdResp struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&holdResp); err != nil {
+		return "", err
+	}
+
+	return holdResp.ID, nil
+}
+
+func (s *transferService) captureHold(holdID string) error {
+	return s.callHoldAPI(holdID, "capture")
+}
+
+func (s *transferService) voidHold(holdID string) {
+	if err := s.callHoldAPI(holdID, "void"); err != nil {
+		s.logger.WithError(err).WithField("holdId", holdID).Error("Error voiding hold")
+	}
+}
+
+func (s *transferService) callHoldAPI(holdID, action string) error {
+	accountAPIURL := os.Getenv("ACCOUNT_API_URL")
+	if accountAPIURL == "" {
+		accountAPIURL = "http://localhost:8001"
+	}
+
+	url := fmt.Sprintf("%s/api/account/holds/%s/%s", accountAPIURL, holdID, action)
+	resp, err := middleware.DoServiceRequest("transfer-api", http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("account API returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
 func (s *transferService) getAccountNumberByID(accountID string) string {
-	var accountNumber int
-	s.repo.(*transferRepository).db.Table("contacorrente").
-		Select("numero").
-		Where("idcontacorrente = ?", accountID).
-		Scan(&accountNumber)
-	return strconv.Itoa(accountNumber)
+	accountNumber, err := s.repo.GetAccountNumberByID(accountID)
+	if err != nil {
+		s.logger.WithError(err).WithField("accountId", accountID).Error("Error getting account number")
+	}
+	return accountNumber
 }
 
 func (s *transferService) callAccountMovementAPI(baseURL string, request map[string]interface{}) error {
@@ -258,7 +797,7 @@ func (s *transferService) callAccountMovementAPI(baseURL string, request map[str
 		return err
 	}
 
-	resp, err := http.Post(url, "application/json", strings.NewReader(string(jsonData)))
+	resp, err := middleware.DoServiceRequest("transfer-api", http.MethodPost, url, strings.NewReader(string(jsonData)))
 	if err != nil {
 		return err
 	}
@@ -270,7 +809,3 @@ func (s *transferService) callAccountMovementAPI(baseURL string, request map[str
 
 	return nil
 }
-
-type transferRepository struct {
-	db *gorm.DB
-}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"testing"
+
+	"bankmore/internal/shared/models"
+	"bankmore/internal/transfer/domain"
+)
+
+func TestFindResubmission(t *testing.T) {
+	service, _ := newTestTransferService(t, &accountAPI{})
+
+	requestID := "req-1"
+	original := domain.NewTransfer("origem", "destino", 150, "Aluguel", &requestID)
+	original.Status = domain.TransferStatusCompleted
+	if err := service.repo.Create(original); err != nil {
+		t.Fatalf("erro ao gravar a transferência: %v", err)
+	}
+
+	cases := []struct {
+		name      string
+		request   CreateTransferRequest
+		origin    string
+		errorType string
+	}{
+		{"reenvio idêntico", CreateTransferRequest{RequestID: requestID, DestinationAccountNumber: "1002", Amount: 150}, "origem", ""},
+		{"outro valor", CreateTransferRequest{RequestID: requestID, DestinationAccountNumber: "1002", Amount: 151}, "origem", models.ErrorIdempotencyKeyReused},
+		{"outro destino", CreateTransferRequest{RequestID: requestID, DestinationAccountNumber: "1001", Amount: 150}, "origem", models.ErrorIdempotencyKeyReused},
+		{"outra conta", CreateTransferRequest{RequestID: requestID, DestinationAccountNumber: "1002", Amount: 150}, "destino", models.ErrorInvalidData},
+	}
+
+	for _, c := range cases {
+		existing, result := service.findResubmission(c.request, c.origin)
+		if c.errorType == "" {
+			if result != nil || existing == nil || existing.ID != original.ID {
+				t.Errorf("%s: esperada a transferência original, recebido %+v", c.name, result)
+			}
+			continue
+		}
+		if result == nil || result.ErrorType != c.errorType {
+			t.Errorf("%s: esperado erro %s, recebido %+v", c.name, c.errorType, result)
+		}
+	}
+
+	if existing, result := service.findResubmission(CreateTransferRequest{RequestID: "req-novo", Amount: 10}, "origem"); existing != nil || result != nil {
+		t.Errorf("requestId novo: esperado nada, recebido %+v %+v", existing, result)
+	}
+}
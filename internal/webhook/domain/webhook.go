@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tipos de evento que um webhook pode assinar.
+const (
+	EventTransferCompleted = "transfer.completed"
+	EventTransferReceived  = "transfer.received"
+	EventTransferFailed    = "transfer.failed"
+	EventFeeCharged        = "fee.charged"
+)
+
+var EventTypes = map[string]string{
+	EventTransferCompleted: "Transferência enviada pela conta concluída",
+	EventTransferReceived:  "Transferência recebida pela conta",
+	EventTransferFailed:    "Transferência assíncrona da conta falhou",
+	EventFeeCharged:        "Tarifa debitada da conta",
+}
+
+const (
+	WebhookStatusActive   = "ACTIVE"
+	WebhookStatusDisabled = "DISABLED"
+)
+
+// Webhook é um endpoint do cliente que recebe os eventos assinados da conta. O segredo
+// assina cada entrega e só é mostrado no cadastro.
+type Webhook struct {
+	ID                  string     `json:"id" gorm:"column:idwebhook;primaryKey"`
+	AccountID           string     `json:"-" gorm:"column:idcontacorrente"`
+	URL                 string     `json:"url" gorm:"column:url"`
+	EventFilter         string     `json:"-" gorm:"column:eventos"`
+	Events              []string   `json:"events" gorm:"-"`
+	Secret              string     `json:"-" gorm:"column:segredo"`
+	Status              string     `json:"status" gorm:"column:situacao"`
+	ConsecutiveFailures int        `json:"consecutiveFailures" gorm:"column:falhas_consecutivas"`
+	DisabledReason      string     `json:"disabledReason,omitempty" gorm:"column:motivo_desativacao"`
+	CreatedAt           time.Time  `json:"createdAt" gorm:"column:data_criacao"`
+	DisabledAt          *time.Time `json:"disabledAt,omitempty" gorm:"column:data_desativacao"`
+}
+
+func (Webhook) TableName() string {
+	return "webhook"
+}
+
+func NewWebhook(accountID, url string, events []string, secret string) *Webhook {
+	return &Webhook{
+		ID:        uuid.New().String(),
+		AccountID: accountID,
+		URL:       url,
+		Events:    events,
+		Secret:    secret,
+		Status:    WebhookStatusActive,
+		CreatedAt: time.Now(),
+	}
+}
+
+// BeforeSave e AfterFind mantêm Events e a coluna eventos (separada por vírgulas) em sincronia.
+func (w *Webhook) BeforeSave(tx *gorm.DB) error {
+	w.EventFilter = strings.Join(w.Events, ",")
+	return nil
+}
+
+func (w *Webhook) AfterFind(tx *gorm.DB) error {
+	w.Events = nil
+	if w.EventFilter != "" {
+		w.Events = strings.Split(w.EventFilter, ",")
+	}
+	return nil
+}
+
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, event := range w.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Webhook) IsActive() bool {
+	return w.Status == WebhookStatusActive
+}
+
+// Enable reativa o webhook e zera a contagem de falhas.
+func (w *Webhook) Enable() {
+	w.Status = WebhookStatusActive
+	w.ConsecutiveFailures = 0
+	w.DisabledReason = ""
+	w.DisabledAt = nil
+}
+
+func (w *Webhook) Disable(reason string, now time.Time) {
+	w.Status = WebhookStatusDisabled
+	w.DisabledReason = reason
+	w.DisabledAt = &now
+}
+
+const (
+	DeliveryStatusPending   = "PENDING"
+	DeliveryStatusSucceeded = "SUCCEEDED"
+	DeliveryStatusFailed    = "FAILED"
+)
+
+// deliveryNamespace gera o ID da entrega a partir do webhook e do evento, para que o
+// mesmo evento consumido duas vezes do Kafka não seja entregue duas vezes.
+var deliveryNamespace = uuid.MustParse("6f1c2f9e-3b5d-4a8e-9c7a-2d4e5f60718a")
+
+// NewEventID gera o ID do evento a partir do tipo e do recurso de origem (transferência
+// ou tarifa), estável entre reprocessamentos da mesma mensagem.
+func NewEventID(eventType, sourceID string) string {
+	return uuid.NewSHA1(deliveryNamespace, []byte(eventType+"|"+sourceID)).String()
+}
+
+// Delivery é uma entrega de evento a um webhook, com as tentativas e a última resposta.
+// O conjunto das entregas é o log consultado pelo cliente.
+type Delivery struct {
+	ID             string     `json:"id" gorm:"column:identrega;primaryKey"`
+	WebhookID      string     `json:"webhookId" gorm:"column:idwebhook"`
+	EventID        string     `json:"eventId" gorm:"column:idevento"`
+	EventType      string     `json:"eventType" gorm:"column:tipo_evento"`
+	Payload        string     `json:"payload" gorm:"column:payload"`
+	Status         string     `json:"status" gorm:"column:situacao"`
+	Attempts       int        `json:"attempts" gorm:"column:tentativas"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt,omitempty" gorm:"column:data_proxima_tentativa"`
+	LastStatusCode int        `json:"lastStatusCode,omitempty" gorm:"column:ultimo_status_http"`
+	LastError      string     `json:"lastError,omitempty" gorm:"column:ultimo_erro"`
+	RedeliveryOf   *string    `json:"redeliveryOf,omitempty" gorm:"column:identrega_original"`
+	CreatedAt      time.Time  `json:"createdAt" gorm:"column:data_criacao"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty" gorm:"column:data_entrega"`
+}
+
+func (Delivery) TableName() string {
+	return "entrega_webhook"
+}
+
+func NewDelivery(webhookID, eventID, eventType, payload string, now time.Time) *Delivery {
+	return &Delivery{
+		ID:            uuid.NewSHA1(deliveryNamespace, []byte(webhookID+"|"+eventID)).String(),
+		WebhookID:     webhookID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        DeliveryStatusPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+	}
+}
+
+// NewRedelivery cria uma nova entrega do mesmo evento, pedida manualmente pelo cliente.
+func NewRedelivery(original *Delivery, now time.Time) *Delivery {
+	return &Delivery{
+		ID:            uuid.New().String(),
+		WebhookID:     original.WebhookID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        DeliveryStatusPending,
+		NextAttemptAt: &now,
+		RedeliveryOf:  &original.ID,
+		CreatedAt:     now,
+	}
+}
+
+func (d *Delivery) Succeed(statusCode int, now time.Time) {
+	d.Status = DeliveryStatusSucceeded
+	d.LastStatusCode = statusCode
+	d.LastError = ""
+	d.NextAttemptAt = nil
+	d.DeliveredAt = &now
+}
+
+// RecordFailure registra uma tentativa sem sucesso. Com nextAttempt a entrega continua
+// pendente; sem ele, falha em definitivo.
+func (d *Delivery) RecordFailure(statusCode int, reason string, nextAttempt *time.Time) {
+	d.LastStatusCode = statusCode
+	d.LastError = reason
+	d.NextAttemptAt = nextAttempt
+	if nextAttempt == nil {
+		d.Status = DeliveryStatusFailed
+	}
+}
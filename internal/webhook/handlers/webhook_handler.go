@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"bankmore/internal/shared/models"
+	"bankmore/internal/webhook/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookHandler struct {
+	service service.WebhookService
+	logger  *logrus.Logger
+}
+
+func NewWebhookHandler(service service.WebhookService, logger *logrus.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// @Summary Cadastra um webhook
+// @Description Cadastra uma URL https que recebe, por POST, os eventos assinados da conta (transfer.completed, transfer.received, transfer.failed, fee.charged). Cada entrega é assinada com HMAC-SHA256 no cabeçalho X-BankMore-Signature (t=<unix>,v1=<hex>); o segredo só é retornado nesta resposta
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param request body service.CreateWebhookRequest true "URL e eventos"
+// @Success 201 {object} service.CreatedWebhook
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	var request service.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(accountID, request)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// @Summary Lista os webhooks
+// @Description Lista os webhooks da conta logada com eventos, situação e falhas seguidas
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} domain.Webhook
+// @Failure 400 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.service.ListWebhooks(accountID)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// @Summary Consulta um webhook
+// @Description Retorna o webhook, incluindo o motivo da desativação automática, se houver
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "ID do webhook"
+// @Success 200 {object} domain.Webhook
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.service.GetWebhook(accountID, c.Param("id"))
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// @Summary Altera um webhook
+// @Description Altera URL, eventos ou situação. active=true reativa um webhook desativado por falhas e zera a contagem; as entregas pendentes voltam a ser enviadas
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "ID do webhook"
+// @Param request body service.UpdateWebhookRequest true "Campos a alterar"
+// @Success 200 {object} domain.Webhook
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	var request service.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Type:    models.ErrorInvalidData,
+			Message: "Dados inválidos",
+		})
+		return
+	}
+
+	webhook, err := h.service.UpdateWebhook(accountID, c.Param("id"), request)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// @Summary Remove um webhook
+// @Description Remove o webhook e o seu log de entregas
+// @Tags Webhooks
+// @Param id path string true "ID do webhook"
+// @Success 204
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteWebhook(accountID, c.Param("id")); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Lista as entregas de um webhook
+// @Description Retorna as últimas 100 entregas com situação, tentativas, último status HTTP e erro
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "ID do webhook"
+// @Success 200 {array} domain.Delivery
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.service.ListDeliveries(accountID, c.Param("id"))
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// @Summary Reenvia uma entrega
+// @Description Agenda um novo envio do evento de uma entrega concluída ou falha. O webhook precisa estar ativo
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "ID do webhook"
+// @Param deliveryId path string true "ID da entrega"
+// @Success 202 {object} domain.Delivery
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /api/transfer/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	accountID, ok := accountIDFromToken(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.Redeliver(accountID, c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func respondWebhookError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, service.ErrWebhookNotFound) || errors.Is(err, service.ErrDeliveryNotFound) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, models.ErrorResponse{
+		Type:    models.ErrorInvalidOperation,
+		Message: err.Error(),
+	})
+}
+
+func accountIDFromToken(c *gin.Context) (string, bool) {
+	accountID, exists := c.Get("accountId")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Type:    models.ErrorUserUnauthorized,
+			Message: "Token inválido",
+		})
+		return "", false
+	}
+	return accountID.(string), true
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"time"
+
+	"bankmore/internal/webhook/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository interface {
+	Create(webhook *domain.Webhook) error
+	GetByID(id string) (*domain.Webhook, error)
+	GetByAccountID(accountID string) ([]domain.Webhook, error)
+	CountByAccountID(accountID string) (int64, error)
+	Update(webhook *domain.Webhook) error
+	Delete(id string) error
+	ResetFailures(id string) error
+	IncrementFailures(id string) error
+	DisableIfFailing(id string, threshold int, reason string, now time.Time) (bool, error)
+	CreateDeliveries(deliveries []domain.Delivery) error
+	GetDelivery(id string) (*domain.Delivery, error)
+	GetDeliveries(webhookID string, limit int) ([]domain.Delivery, error)
+	GetDueDeliveries(now time.Time, limit int) ([]domain.Delivery, error)
+	ClaimDelivery(id string, now, leaseUntil time.Time) (bool, error)
+	UpdateDelivery(delivery *domain.Delivery) error
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) Create(webhook *domain.Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+func (r *webhookRepository) GetByID(id string) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := r.db.Where("idwebhook = ?", id).First(&webhook).Error
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) GetByAccountID(accountID string) ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	err := r.db.Where("idcontacorrente = ?", accountID).
+		Order("data_criacao ASC").
+		Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *webhookRepository) CountByAccountID(accountID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Webhook{}).Where("idcontacorrente = ?", accountID).Count(&count).Error
+	return count, err
+}
+
+func (r *webhookRepository) Update(webhook *domain.Webhook) error {
+	return r.db.Save(webhook).Error
+}
+
+// Delete apaga o webhook e o seu log de entregas.
+func (r *webhookRepository) Delete(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("idwebhook = ?", id).Delete(&domain.Delivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("idwebhook = ?", id).Delete(&domain.Webhook{}).Error
+	})
+}
+
+func (r *webhookRepository) ResetFailures(id string) error {
+	return r.db.Model(&domain.Webhook{}).
+		Where("idwebhook = ?", id).
+		Update("falhas_consecutivas", 0).Error
+}
+
+// IncrementFailures soma uma falha de forma atômica, já que vários workers podem
+// entregar ao mesmo webhook ao mesmo tempo.
+func (r *webhookRepository) IncrementFailures(id string) error {
+	return r.db.Model(&domain.Webhook{}).
+		Where("idwebhook = ?", id).
+		Update("falhas_consecutivas", gorm.Expr("falhas_consecutivas + 1")).Error
+}
+
+// DisableIfFailing desativa o webhook ativo que chegou a threshold falhas seguidas.
+// Retorna true apenas para quem efetivamente o desativou.
+func (r *webhookRepository) DisableIfFailing(id string, threshold int, reason string, now time.Time) (bool, error) {
+	result := r.db.Model(&domain.Webhook{}).
+		Where("idwebhook = ? AND situacao = ? AND falhas_consecutivas >= ?", id, domain.WebhookStatusActive, threshold).
+		Updates(map[string]interface{}{
+			"situacao":           domain.WebhookStatusDisabled,
+			"motivo_desativacao": reason,
+			"data_desativacao":   now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// CreateDeliveries ignora entregas que já existem: o ID vem do webhook e do evento,
+// então um evento consumido de novo não gera entrega duplicada.
+func (r *webhookRepository) CreateDeliveries(deliveries []domain.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+func (r *webhookRepository) GetDelivery(id string) (*domain.Delivery, error) {
+	var delivery domain.Delivery
+	err := r.db.Where("identrega = ?", id).First(&delivery).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepository) GetDeliveries(webhookID string, limit int) ([]domain.Delivery, error) {
+	var deliveries []domain.Delivery
+	err := r.db.Where("idwebhook = ?", webhookID).
+		Order("data_criacao DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// GetDueDeliveries devolve as entregas pendentes com tentativa vencida de webhooks
+// ativos. Entregas de webhooks desativados esperam a reativação.
+func (r *webhookRepository) GetDueDeliveries(now time.Time, limit int) ([]domain.Delivery, error) {
+	var deliveries []domain.Delivery
+	err := r.db.Joins("JOIN webhook ON webhook.idwebhook = entrega_webhook.idwebhook").
+		Where("entrega_webhook.situacao = ? AND entrega_webhook.data_proxima_tentativa <= ? AND webhook.situacao = ?", domain.DeliveryStatusPending, now, domain.WebhookStatusActive).
+		Order("entrega_webhook.data_proxima_tentativa ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimDelivery adia a próxima tentativa até leaseUntil de forma atômica: só um worker
+// assume a entrega e, se ele cair, ela volta a vencer depois do prazo.
+func (r *webhookRepository) ClaimDelivery(id string, now, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&domain.Delivery{}).
+		Where("identrega = ? AND situacao = ? AND data_proxima_tentativa <= ?", id, domain.DeliveryStatusPending, now).
+		Update("data_proxima_tentativa", leaseUntil)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *domain.Delivery) error {
+	return r.db.Save(delivery).Error
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"bankmore/internal/webhook/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderSignature = "X-BankMore-Signature"
+	HeaderEvent     = "X-BankMore-Event"
+	HeaderDelivery  = "X-BankMore-Delivery"
+	HeaderTimestamp = "X-BankMore-Timestamp"
+
+	deliveryBatchSize = 50
+	initialBackoff    = 30 * time.Second
+	maxBackoff        = time.Hour
+	maxErrorLength    = 500
+)
+
+// sender envia as entregas sem seguir redirecionamentos: o cliente precisa cadastrar a
+// URL final, e um redirecionamento não deve levar o payload assinado a outro host.
+type sender struct {
+	client *http.Client
+}
+
+func newSender(timeout time.Duration) *sender {
+	return &sender{
+		client: &http.Client{
+			Timeout: timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Sign calcula a assinatura enviada em X-BankMore-Signature: t=<unix>,v1=<hex>, com
+// v1 = HMAC-SHA256(segredo, "<unix>.<corpo>"). O cliente deve recalculá-la e rejeitar
+// timestamps antigos para evitar reenvio de requisições capturadas.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// send retorna o status HTTP da resposta (0 sem resposta) e o motivo da falha, vazio
+// quando o cliente respondeu 2xx.
+func (s *sender) send(webhook *domain.Webhook, delivery *domain.Delivery, now time.Time) (int, string) {
+	body := []byte(delivery.Payload)
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err.Error()
+	}
+
+	timestamp := now.Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "BankMore-Webhooks/1.0")
+	request.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+	request.Header.Set(HeaderEvent, delivery.EventType)
+	request.Header.Set(HeaderDelivery, delivery.ID)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, truncate(err.Error())
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Sprintf("resposta HTTP %d", response.StatusCode)
+	}
+	return response.StatusCode, ""
+}
+
+// DeliverDue envia as entregas vencidas. Falhas são reagendadas com backoff exponencial
+// até WEBHOOK_MAX_ATTEMPTS; o webhook é desativado após WEBHOOK_DISABLE_AFTER_FAILURES
+// falhas seguidas. Retorna quantas entregas foram concluídas.
+func (s *webhookService) DeliverDue() (int, error) {
+	now := time.Now()
+	due, err := s.repo.GetDueDeliveries(now, deliveryBatchSize)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing due webhook deliveries")
+		return 0, fmt.Errorf("erro interno do servidor")
+	}
+
+	// O prazo cobre o envio com folga; se o worker cair, a entrega volta a vencer.
+	lease := 2 * s.sender.client.Timeout
+	webhooks := make(map[string]*domain.Webhook)
+	delivered := 0
+
+	for i := range due {
+		delivery := &due[i]
+		claimed, err := s.repo.ClaimDelivery(delivery.ID, now, time.Now().Add(lease))
+		if err != nil {
+			s.logger.WithError(err).WithField("deliveryId", delivery.ID).Error("Error claiming webhook delivery")
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = s.repo.GetByID(delivery.WebhookID)
+			if err != nil {
+				s.logger.WithError(err).WithField("webhookId", delivery.WebhookID).Error("Error getting webhook")
+				continue
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+		if !webhook.IsActive() {
+			// Desativado neste lote: a entrega espera a reativação.
+			continue
+		}
+
+		if s.deliver(webhook, delivery) {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+func (s *webhookService) deliver(webhook *domain.Webhook, delivery *domain.Delivery) bool {
+	now := time.Now()
+	statusCode, failure := s.sender.send(webhook, delivery, now)
+	delivery.Attempts++
+
+	fields := logrus.Fields{
+		"webhookId":  webhook.ID,
+		"deliveryId": delivery.ID,
+		"eventType":  delivery.EventType,
+		"attempt":    delivery.Attempts,
+		"statusCode": statusCode,
+	}
+
+	if failure == "" {
+		delivery.Succeed(statusCode, now)
+		if err := s.repo.UpdateDelivery(delivery); err != nil {
+			s.logger.WithError(err).WithFields(fields).Error("Error recording webhook delivery")
+		}
+		if err := s.repo.ResetFailures(webhook.ID); err != nil {
+			s.logger.WithError(err).WithFields(fields).Error("Error resetting webhook failures")
+		}
+		s.logger.WithFields(fields).Info("Webhook delivered")
+		return true
+	}
+
+	var nextAttempt *time.Time
+	if delivery.Attempts < GetMaxAttempts() {
+		next := now.Add(backoff(delivery.Attempts))
+		nextAttempt = &next
+	}
+	delivery.RecordFailure(statusCode, failure, nextAttempt)
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		s.logger.WithError(err).WithFields(fields).Error("Error recording webhook delivery")
+	}
+
+	fields["error"] = failure
+	fields["nextAttemptAt"] = nextAttempt
+	s.logger.WithFields(fields).Warn("Webhook delivery failed")
+
+	if err := s.repo.IncrementFailures(webhook.ID); err != nil {
+		s.logger.WithError(err).WithFields(fields).Error("Error recording webhook failure")
+		return false
+	}
+
+	threshold := GetDisableThreshold()
+	reason := fmt.Sprintf("Desativado após %d falhas de entrega seguidas", threshold)
+	disabled, err := s.repo.DisableIfFailing(webhook.ID, threshold, reason, now)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Error("Error disabling webhook")
+		return false
+	}
+	if disabled {
+		webhook.Disable(reason, now)
+		s.logger.WithFields(logrus.Fields{
+			"webhookId": webhook.ID,
+			"accountId": webhook.AccountID,
+		}).Warn("Webhook disabled after consecutive failures")
+	}
+
+	return false
+}
+
+// backoff dobra a espera a cada tentativa, de 30 segundos até no máximo 1 hora.
+func backoff(attempts int) time.Duration {
+	wait := initialBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxBackoff {
+		return maxBackoff
+	}
+	return wait
+}
+
+func truncate(message string) string {
+	if len(message) > maxErrorLength {
+		return message[:maxErrorLength]
+	}
+	return message
+}
+
+// StartDeliveryWorker envia periodicamente as entregas vencidas até o canal stop ser fechado.
+func StartDeliveryWorker(service WebhookService, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				service.DeliverDue()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// GetDeliveryInterval lê WEBHOOK_DELIVERY_INTERVAL_SECONDS (padrão de 5 segundos).
+func GetDeliveryInterval() time.Duration {
+	return durationSeconds("WEBHOOK_DELIVERY_INTERVAL_SECONDS", 5)
+}
+
+// GetDeliveryTimeout lê WEBHOOK_TIMEOUT_SECONDS (padrão de 10 segundos).
+func GetDeliveryTimeout() time.Duration {
+	return durationSeconds("WEBHOOK_TIMEOUT_SECONDS", 10)
+}
+
+// GetMaxAttempts lê WEBHOOK_MAX_ATTEMPTS (padrão de 8 tentativas por entrega).
+func GetMaxAttempts() int {
+	return positiveInt("WEBHOOK_MAX_ATTEMPTS", 8)
+}
+
+// GetDisableThreshold lê WEBHOOK_DISABLE_AFTER_FAILURES (padrão de 20 falhas seguidas).
+func GetDisableThreshold() int {
+	return positiveInt("WEBHOOK_DISABLE_AFTER_FAILURES", 20)
+}
+
+func durationSeconds(name string, fallback int) time.Duration {
+	return time.Duration(positiveInt(name, fallback)) * time.Second
+}
+
+func positiveInt(name string, fallback int) int {
+	if value := os.Getenv(name); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return fallback
+}
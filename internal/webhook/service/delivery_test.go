@@ -0,0 +1,400 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"bankmore/internal/webhook/domain"
+	"bankmore/internal/webhook/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testSecret = "whsec_teste"
+
+// receivedRequest é o que o receptor de teste viu em uma entrega.
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver é um endpoint de cliente local: grava as entregas e responde com o status
+// configurado para a tentativa.
+type receiver struct {
+	mu       sync.Mutex
+	requests []receivedRequest
+	statuses []int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	r.requests = append(r.requests, receivedRequest{header: req.Header.Clone(), body: body})
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status = r.statuses[0]
+		r.statuses = r.statuses[1:]
+	}
+	r.mu.Unlock()
+
+	w.WriteHeader(status)
+}
+
+func (r *receiver) received() []receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedRequest(nil), r.requests...)
+}
+
+// verifySignature refaz, do lado do cliente, a conferência documentada em Sign.
+func verifySignature(secret string, header http.Header, body []byte) error {
+	var timestamp, signature string
+	for _, part := range strings.Split(header.Get(HeaderSignature), ",") {
+		switch {
+		case strings.HasPrefix(part, "t="):
+			timestamp = strings.TrimPrefix(part, "t=")
+		case strings.HasPrefix(part, "v1="):
+			signature = strings.TrimPrefix(part, "v1=")
+		}
+	}
+	if timestamp == "" || signature == "" {
+		return fmt.Errorf("assinatura malformada: %q", header.Get(HeaderSignature))
+	}
+	if timestamp != header.Get(HeaderTimestamp) {
+		return fmt.Errorf("timestamp da assinatura %s difere do cabeçalho %s", timestamp, header.Get(HeaderTimestamp))
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return fmt.Errorf("assinatura %s, esperada %s", signature, expected)
+	}
+	return nil
+}
+
+func newTestService(t *testing.T) (*webhookService, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "webhook.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("erro ao abrir o banco: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.Webhook{}, &domain.Delivery{}); err != nil {
+		t.Fatalf("erro ao migrar o banco: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	return &webhookService{
+		repo:   repository.NewWebhookRepository(db),
+		sender: newSender(2 * time.Second),
+		logger: log,
+	}, db
+}
+
+// queueDelivery cadastra um webhook para url e grava uma entrega vencida para ele.
+func queueDelivery(t *testing.T, service *webhookService, url string) (*domain.Webhook, *domain.Delivery) {
+	t.Helper()
+
+	webhook := domain.NewWebhook("conta-1", url, []string{domain.EventTransferCompleted}, testSecret)
+	if err := service.repo.Create(webhook); err != nil {
+		t.Fatalf("erro ao criar webhook: %v", err)
+	}
+
+	eventID := domain.NewEventID(domain.EventTransferCompleted, "transferencia-1")
+	payload := fmt.Sprintf(`{"id":%q,"type":%q,"data":{"transferId":"transferencia-1","amount":150.5}}`, eventID, domain.EventTransferCompleted)
+	delivery := domain.NewDelivery(webhook.ID, eventID, domain.EventTransferCompleted, payload, time.Now().Add(-time.Second))
+	if err := service.repo.CreateDeliveries([]domain.Delivery{*delivery}); err != nil {
+		t.Fatalf("erro ao criar entrega: %v", err)
+	}
+	return webhook, delivery
+}
+
+// makeDue antecipa a próxima tentativa para que o worker envie a entrega de novo.
+func makeDue(t *testing.T, db *gorm.DB, deliveryID string) {
+	t.Helper()
+	err := db.Model(&domain.Delivery{}).
+		Where("identrega = ?", deliveryID).
+		Update("data_proxima_tentativa", time.Now().Add(-time.Second)).Error
+	if err != nil {
+		t.Fatalf("erro ao antecipar entrega: %v", err)
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"evt"}`)
+	signature := Sign(testSecret, 1700000000, body)
+
+	header := http.Header{}
+	header.Set(HeaderSignature, signature)
+	header.Set(HeaderTimestamp, "1700000000")
+
+	if !strings.HasPrefix(signature, "t=1700000000,v1=") {
+		t.Fatalf("formato inesperado: %s", signature)
+	}
+	if err := verifySignature(testSecret, header, body); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifySignature("outro-segredo", header, body); err == nil {
+		t.Error("assinatura aceita com outro segredo")
+	}
+	if err := verifySignature(testSecret, header, []byte(`{"id":"alterado"}`)); err == nil {
+		t.Error("assinatura aceita com corpo alterado")
+	}
+	if Sign(testSecret, 1700000001, body) == signature {
+		t.Error("assinatura não depende do timestamp")
+	}
+}
+
+func TestDeliverDueSendsSignedPayload(t *testing.T) {
+	service, _ := newTestService(t)
+	endpoint := &receiver{}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	_, delivery := queueDelivery(t, service, server.URL)
+
+	before := time.Now().Unix()
+	delivered, err := service.DeliverDue()
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if delivered != 1 {
+		t.Fatalf("%d entregas concluídas, esperada 1", delivered)
+	}
+
+	requests := endpoint.received()
+	if len(requests) != 1 {
+		t.Fatalf("receptor recebeu %d requisições, esperada 1", len(requests))
+	}
+	request := requests[0]
+
+	if string(request.body) != delivery.Payload {
+		t.Errorf("corpo %s, esperado %s", request.body, delivery.Payload)
+	}
+	if err := verifySignature(testSecret, request.header, request.body); err != nil {
+		t.Error(err)
+	}
+	timestamp, err := strconv.ParseInt(request.header.Get(HeaderTimestamp), 10, 64)
+	if err != nil || timestamp < before || timestamp > time.Now().Unix() {
+		t.Errorf("timestamp %q fora do envio", request.header.Get(HeaderTimestamp))
+	}
+	if got := request.header.Get(HeaderEvent); got != domain.EventTransferCompleted {
+		t.Errorf("evento %q, esperado %q", got, domain.EventTransferCompleted)
+	}
+	if got := request.header.Get(HeaderDelivery); got != delivery.ID {
+		t.Errorf("entrega %q, esperada %q", got, delivery.ID)
+	}
+	if got := request.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type %q", got)
+	}
+
+	stored, err := service.repo.GetDelivery(delivery.ID)
+	if err != nil {
+		t.Fatalf("erro ao ler entrega: %v", err)
+	}
+	if stored.Status != domain.DeliveryStatusSucceeded || stored.Attempts != 1 || stored.LastStatusCode != http.StatusOK {
+		t.Errorf("entrega %s, %d tentativas, HTTP %d", stored.Status, stored.Attempts, stored.LastStatusCode)
+	}
+	if stored.DeliveredAt == nil || stored.NextAttemptAt != nil {
+		t.Error("entrega concluída sem data de entrega ou com nova tentativa")
+	}
+
+	// Concluída, a entrega não é enviada de novo.
+	if delivered, _ := service.DeliverDue(); delivered != 0 || len(endpoint.received()) != 1 {
+		t.Error("entrega concluída foi reenviada")
+	}
+}
+
+func TestDeliverDueRetriesWithBackoff(t *testing.T) {
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "3")
+
+	service, db := newTestService(t)
+	endpoint := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK}}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	_, delivery := queueDelivery(t, service, server.URL)
+
+	for attempt, wait := range []time.Duration{30 * time.Second, time.Minute} {
+		start := time.Now()
+		if delivered, err := service.DeliverDue(); err != nil || delivered != 0 {
+			t.Fatalf("tentativa %d: %d concluídas, erro %v", attempt+1, delivered, err)
+		}
+
+		stored, err := service.repo.GetDelivery(delivery.ID)
+		if err != nil {
+			t.Fatalf("erro ao ler entrega: %v", err)
+		}
+		if stored.Status != domain.DeliveryStatusPending || stored.Attempts != attempt+1 {
+			t.Fatalf("tentativa %d: entrega %s com %d tentativas", attempt+1, stored.Status, stored.Attempts)
+		}
+		if stored.LastError == "" || stored.LastStatusCode < 500 {
+			t.Errorf("tentativa %d: falha não registrada (HTTP %d, %q)", attempt+1, stored.LastStatusCode, stored.LastError)
+		}
+		if stored.NextAttemptAt == nil {
+			t.Fatalf("tentativa %d: sem nova tentativa", attempt+1)
+		}
+		if got := stored.NextAttemptAt.Sub(start); got < wait || got > wait+5*time.Second {
+			t.Errorf("tentativa %d: nova tentativa em %s, esperado %s", attempt+1, got, wait)
+		}
+
+		// Antes do prazo, o worker não tenta de novo.
+		if _, err := service.DeliverDue(); err != nil || len(endpoint.received()) != attempt+1 {
+			t.Fatalf("tentativa %d: reenviada antes do backoff", attempt+1)
+		}
+		makeDue(t, db, delivery.ID)
+	}
+
+	if delivered, _ := service.DeliverDue(); delivered != 1 {
+		t.Fatal("terceira tentativa não concluiu a entrega")
+	}
+	stored, _ := service.repo.GetDelivery(delivery.ID)
+	if stored.Status != domain.DeliveryStatusSucceeded || stored.Attempts != 3 {
+		t.Errorf("entrega %s com %d tentativas", stored.Status, stored.Attempts)
+	}
+
+	// A assinatura é refeita a cada tentativa.
+	for i, request := range endpoint.received() {
+		if err := verifySignature(testSecret, request.header, request.body); err != nil {
+			t.Errorf("tentativa %d: %v", i+1, err)
+		}
+	}
+}
+
+func TestDeliverDueFailsAfterMaxAttempts(t *testing.T) {
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "2")
+
+	service, db := newTestService(t)
+	endpoint := &receiver{statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusOK}}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	_, delivery := queueDelivery(t, service, server.URL)
+
+	service.DeliverDue()
+	makeDue(t, db, delivery.ID)
+	service.DeliverDue()
+
+	stored, _ := service.repo.GetDelivery(delivery.ID)
+	if stored.Status != domain.DeliveryStatusFailed || stored.Attempts != 2 || stored.NextAttemptAt != nil {
+		t.Fatalf("entrega %s com %d tentativas, próxima em %v", stored.Status, stored.Attempts, stored.NextAttemptAt)
+	}
+
+	makeDue(t, db, delivery.ID)
+	service.DeliverDue()
+	if len(endpoint.received()) != 2 {
+		t.Errorf("receptor recebeu %d requisições após a falha definitiva, esperadas 2", len(endpoint.received()))
+	}
+}
+
+func TestDeliverDueDisablesFailingWebhook(t *testing.T) {
+	t.Setenv("WEBHOOK_DISABLE_AFTER_FAILURES", "2")
+
+	service, db := newTestService(t)
+	endpoint := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError}}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	webhook, delivery := queueDelivery(t, service, server.URL)
+
+	service.DeliverDue()
+	stored, _ := service.repo.GetByID(webhook.ID)
+	if !stored.IsActive() || stored.ConsecutiveFailures != 1 {
+		t.Fatalf("após uma falha: situação %s, %d falhas", stored.Status, stored.ConsecutiveFailures)
+	}
+
+	makeDue(t, db, delivery.ID)
+	service.DeliverDue()
+	stored, _ = service.repo.GetByID(webhook.ID)
+	if stored.IsActive() || stored.DisabledAt == nil || stored.DisabledReason == "" {
+		t.Fatalf("webhook não desativado: situação %s", stored.Status)
+	}
+
+	// Desativado, o webhook não recebe a entrega pendente.
+	makeDue(t, db, delivery.ID)
+	service.DeliverDue()
+	if len(endpoint.received()) != 2 {
+		t.Errorf("webhook desativado recebeu %d requisições, esperadas 2", len(endpoint.received()))
+	}
+}
+
+func TestDeliverDueResetsFailuresOnSuccess(t *testing.T) {
+	service, db := newTestService(t)
+	endpoint := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusNoContent}}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	webhook, delivery := queueDelivery(t, service, server.URL)
+
+	service.DeliverDue()
+	makeDue(t, db, delivery.ID)
+	service.DeliverDue()
+
+	stored, _ := service.repo.GetByID(webhook.ID)
+	if stored.ConsecutiveFailures != 0 {
+		t.Errorf("%d falhas seguidas após entrega concluída, esperado 0", stored.ConsecutiveFailures)
+	}
+}
+
+func TestDeliverDueDoesNotFollowRedirects(t *testing.T) {
+	service, _ := newTestService(t)
+	target := &receiver{}
+	targetServer := httptest.NewServer(target)
+	defer targetServer.Close()
+
+	redirect := httptest.NewServer(http.RedirectHandler(targetServer.URL, http.StatusFound))
+	defer redirect.Close()
+
+	_, delivery := queueDelivery(t, service, redirect.URL)
+	service.DeliverDue()
+
+	if len(target.received()) != 0 {
+		t.Error("payload assinado seguiu o redirecionamento")
+	}
+	stored, _ := service.repo.GetDelivery(delivery.ID)
+	if stored.Status != domain.DeliveryStatusPending || stored.LastStatusCode != http.StatusFound {
+		t.Errorf("entrega %s com HTTP %d, esperada pendente com HTTP 302", stored.Status, stored.LastStatusCode)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, esperado %s", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"bankmore/internal/shared/kafka"
+	"bankmore/internal/webhook/domain"
+	"bankmore/internal/webhook/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	maxWebhooksPerAccount = 10
+	deliveryListLimit     = 100
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook não encontrado")
+	ErrDeliveryNotFound = errors.New("entrega não encontrada")
+)
+
+type WebhookService interface {
+	CreateWebhook(accountID string, request CreateWebhookRequest) (*CreatedWebhook, error)
+	ListWebhooks(accountID string) ([]domain.Webhook, error)
+	GetWebhook(accountID, webhookID string) (*domain.Webhook, error)
+	UpdateWebhook(accountID, webhookID string, request UpdateWebhookRequest) (*domain.Webhook, error)
+	DeleteWebhook(accountID, webhookID string) error
+	ListDeliveries(accountID, webhookID string) ([]domain.Delivery, error)
+	Redeliver(accountID, webhookID, deliveryID string) (*domain.Delivery, error)
+	HandleMessage(topic string, value []byte) error
+	DeliverDue() (int, error)
+}
+
+type webhookService struct {
+	repo   repository.WebhookRepository
+	sender *sender
+	logger *logrus.Logger
+}
+
+func NewWebhookService(repo repository.WebhookRepository, logger *logrus.Logger) WebhookService {
+	return &webhookService{
+		repo:   repo,
+		sender: newSender(GetDeliveryTimeout()),
+		logger: logger,
+	}
+}
+
+// CreateWebhookRequest: events usa os tipos de domain.EventTypes.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+}
+
+// UpdateWebhookRequest altera apenas os campos informados. active=true reativa um
+// webhook desativado por falhas; as entregas pendentes voltam a ser enviadas.
+type UpdateWebhookRequest struct {
+	URL    *string  `json:"url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+// CreatedWebhook é a resposta do cadastro, a única que traz o segredo de assinatura.
+type CreatedWebhook struct {
+	domain.Webhook
+	Secret string `json:"secret"`
+}
+
+func (s *webhookService) CreateWebhook(accountID string, request CreateWebhookRequest) (*CreatedWebhook, error) {
+	endpoint, err := validateURL(request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := validateEvents(request.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountByAccountID(accountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error counting webhooks")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if count >= maxWebhooksPerAccount {
+		return nil, fmt.Errorf("limite de %d webhooks por conta atingido", maxWebhooksPerAccount)
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		s.logger.WithError(err).Error("Error generating webhook secret")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	webhook := domain.NewWebhook(accountID, endpoint, events, secret)
+	if err := s.repo.Create(webhook); err != nil {
+		s.logger.WithError(err).Error("Error creating webhook")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"webhookId": webhook.ID,
+		"accountId": accountID,
+		"events":    webhook.Events,
+	}).Info("Webhook created")
+
+	return &CreatedWebhook{
+		Webhook: *webhook,
+		Secret:  secret,
+	}, nil
+}
+
+func (s *webhookService) ListWebhooks(accountID string) ([]domain.Webhook, error) {
+	webhooks, err := s.repo.GetByAccountID(accountID)
+	if err != nil {
+		s.logger.WithError(err).Error("Error listing webhooks")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return webhooks, nil
+}
+
+func (s *webhookService) GetWebhook(accountID, webhookID string) (*domain.Webhook, error) {
+	webhook, err := s.repo.GetByID(webhookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("webhookId", webhookID).Error("Error getting webhook")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if webhook.AccountID != accountID {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (s *webhookService) UpdateWebhook(accountID, webhookID string, request UpdateWebhookRequest) (*domain.Webhook, error) {
+	webhook, err := s.GetWebhook(accountID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.URL != nil {
+		endpoint, err := validateURL(*request.URL)
+		if err != nil {
+			return nil, err
+		}
+		webhook.URL = endpoint
+	}
+
+	if request.Events != nil {
+		events, err := validateEvents(request.Events)
+		if err != nil {
+			return nil, err
+		}
+		webhook.Events = events
+	}
+
+	if request.Active != nil {
+		if *request.Active {
+			webhook.Enable()
+		} else if webhook.IsActive() {
+			webhook.Disable("Desativado pelo cliente", time.Now())
+		}
+	}
+
+	if err := s.repo.Update(webhook); err != nil {
+		s.logger.WithError(err).WithField("webhookId", webhook.ID).Error("Error updating webhook")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"webhookId": webhook.ID,
+		"status":    webhook.Status,
+		"events":    webhook.Events,
+	}).Info("Webhook updated")
+
+	return webhook, nil
+}
+
+func (s *webhookService) DeleteWebhook(accountID, webhookID string) error {
+	webhook, err := s.GetWebhook(accountID, webhookID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(webhook.ID); err != nil {
+		s.logger.WithError(err).WithField("webhookId", webhook.ID).Error("Error deleting webhook")
+		return fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithField("webhookId", webhook.ID).Info("Webhook deleted")
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(accountID, webhookID string) ([]domain.Delivery, error) {
+	webhook, err := s.GetWebhook(accountID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.repo.GetDeliveries(webhook.ID, deliveryListLimit)
+	if err != nil {
+		s.logger.WithError(err).WithField("webhookId", webhook.ID).Error("Error listing webhook deliveries")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	return deliveries, nil
+}
+
+// Redeliver agenda um novo envio do evento de uma entrega já concluída ou falha. A
+// entrega original fica inalterada no log.
+func (s *webhookService) Redeliver(accountID, webhookID, deliveryID string) (*domain.Delivery, error) {
+	webhook, err := s.GetWebhook(accountID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if !webhook.IsActive() {
+		return nil, fmt.Errorf("webhook desativado; reative-o antes de reenviar")
+	}
+
+	original, err := s.repo.GetDelivery(deliveryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("deliveryId", deliveryID).Error("Error getting webhook delivery")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+	if original.WebhookID != webhook.ID {
+		return nil, ErrDeliveryNotFound
+	}
+	if original.Status == domain.DeliveryStatusPending {
+		return nil, fmt.Errorf("entrega ainda pendente de envio")
+	}
+
+	delivery := domain.NewRedelivery(original, time.Now())
+	if err := s.repo.CreateDeliveries([]domain.Delivery{*delivery}); err != nil {
+		s.logger.WithError(err).WithField("deliveryId", original.ID).Error("Error creating webhook redelivery")
+		return nil, fmt.Errorf("erro interno do servidor")
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"webhookId":    webhook.ID,
+		"deliveryId":   delivery.ID,
+		"redeliveryOf": original.ID,
+	}).Info("Webhook redelivery scheduled")
+
+	return delivery, nil
+}
+
+// envelope é o corpo enviado ao cliente.
+type envelope struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+type transferEventData struct {
+	TransferID               string  `json:"transferId"`
+	RequestID                string  `json:"requestId,omitempty"`
+	Amount                   float64 `json:"amount"`
+	DestinationAccountNumber string  `json:"destinationAccountNumber,omitempty"`
+	TransferType             string  `json:"transferType,omitempty"`
+}
+
+type transferFailedData struct {
+	TransferID   string `json:"transferId"`
+	RequestID    string `json:"requestId"`
+	ErrorType    string `json:"errorType"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+type feeChargedData struct {
+	FeeID      string  `json:"feeId"`
+	Amount     float64 `json:"amount"`
+	TransferID string  `json:"transferId"`
+}
+
+// HandleMessage converte as mensagens dos tópicos de transferência e tarifa em eventos
+// de webhook e grava uma entrega para cada webhook ativo da conta que os assina.
+func (s *webhookService) HandleMessage(topic string, value []byte) error {
+	now := time.Now()
+
+	switch topic {
+	case kafka.TopicTransferEvents:
+		var event kafka.TransferEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		if err := s.publish(event.OriginAccountID, envelope{
+			ID:        domain.NewEventID(domain.EventTransferCompleted, event.TransferID),
+			Type:      domain.EventTransferCompleted,
+			CreatedAt: now,
+			Data: transferEventData{
+				TransferID:               event.TransferID,
+				RequestID:                event.RequestID,
+				Amount:                   event.Amount,
+				DestinationAccountNumber: event.DestinationAccountNumber,
+				TransferType:             event.Type,
+			},
+		}); err != nil {
+			return err
+		}
+		return s.publish(event.DestinationAccountID, envelope{
+			ID:        domain.NewEventID(domain.EventTransferReceived, event.TransferID),
+			Type:      domain.EventTransferReceived,
+			CreatedAt: now,
+			Data: transferEventData{
+				TransferID:   event.TransferID,
+				Amount:       event.Amount,
+				TransferType: event.Type,
+			},
+		})
+
+	case kafka.TopicTransferStatusEvents:
+		var event kafka.TransferStatusEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		if event.Status != kafka.TransferStatusFailed {
+			// As concluídas chegam pelo tópico de transferências.
+			return nil
+		}
+		return s.publish(event.OriginAccountID, envelope{
+			ID:        domain.NewEventID(domain.EventTransferFailed, event.TransferID),
+			Type:      domain.EventTransferFailed,
+			CreatedAt: event.OccurredAt,
+			Data: transferFailedData{
+				TransferID:   event.TransferID,
+				RequestID:    event.RequestID,
+				ErrorType:    event.ErrorType,
+				ErrorMessage: event.ErrorMessage,
+			},
+		})
+
+	case kafka.TopicFeeEvents:
+		var event kafka.FeeEvent
+		if err := json.Unmarshal(value, &event); err != nil {
+			return err
+		}
+		return s.publish(event.AccountID, envelope{
+			ID:        domain.NewEventID(domain.EventFeeCharged, event.FeeID),
+			Type:      domain.EventFeeCharged,
+			CreatedAt: event.ChargedAt,
+			Data: feeChargedData{
+				FeeID:      event.FeeID,
+				Amount:     event.Amount,
+				TransferID: event.TransferID,
+			},
+		})
+	}
+
+	return nil
+}
+
+func (s *webhookService) publish(accountID string, event envelope) error {
+	if accountID == "" {
+		return nil
+	}
+
+	webhooks, err := s.repo.GetByAccountID(accountID)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []domain.Delivery
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhook.IsActive() || !webhook.Subscribes(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return err
+			}
+		}
+		deliveries = append(deliveries, *domain.NewDelivery(webhook.ID, event.ID, event.Type, string(payload), time.Now()))
+	}
+
+	if err := s.repo.CreateDeliveries(deliveries); err != nil {
+		return err
+	}
+
+	if len(deliveries) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"eventId":    event.ID,
+			"eventType":  event.Type,
+			"deliveries": len(deliveries),
+		}).Info("Webhook event queued")
+	}
+	return nil
+}
+
+func validateURL(raw string) (string, error) {
+	endpoint := strings.TrimSpace(raw)
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return "", fmt.Errorf("URL inválida")
+	}
+	if parsed.Scheme != "https" && !(parsed.Scheme == "http" && allowHTTP()) {
+		return "", fmt.Errorf("a URL do webhook deve usar https")
+	}
+	if parsed.User != nil {
+		return "", fmt.Errorf("a URL do webhook não pode conter credenciais")
+	}
+	return endpoint, nil
+}
+
+func validateEvents(events []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var valid []string
+	for _, event := range events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if _, ok := domain.EventTypes[event]; !ok {
+			return nil, fmt.Errorf("tipo de evento inválido: %s", event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			valid = append(valid, event)
+		}
+	}
+	if len(valid) == 0 {
+		return nil, fmt.Errorf("informe ao menos um tipo de evento")
+	}
+	return valid, nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// allowHTTP lê WEBHOOK_ALLOW_HTTP; URLs http só devem ser aceitas em desenvolvimento.
+func allowHTTP() bool {
+	return os.Getenv("WEBHOOK_ALLOW_HTTP") == "true"
+}
@@ -19,17 +19,38 @@ CGO_ENABLED=1 go build -o bin/transfer-api ./cmd/transfer-api
 echo "📦 Building Fee API..."
 CGO_ENABLED=1 go build -o bin/fee-api ./cmd/fee-api
 
+# Build Notification Worker
+echo "📦 Building Notification Worker..."
+CGO_ENABLED=1 go build -o bin/notification-worker ./cmd/notification-worker
+
+# Build Reconciliation Job
+echo "📦 Building Reconciliation Job..."
+CGO_ENABLED=1 go build -o bin/reconcile ./cmd/reconcile
+
+# Build Income Report Job
+echo "📦 Building Income Report Job..."
+CGO_ENABLED=1 go build -o bin/income-report ./cmd/income-report
+
+# Build CNAB Validator
+echo "📦 Building CNAB Validator..."
+CGO_ENABLED=1 go build -o bin/cnab ./cmd/cnab
+
 echo "✅ Build completed successfully!"
 echo ""
 echo "📋 Available binaries:"
 echo "  - bin/account-api  (Account API - Port 8001)"
 echo "  - bin/transfer-api (Transfer API - Port 8002)"
 echo "  - bin/fee-api      (Fee API - Port 8003)"
+echo "  - bin/notification-worker (Notification Worker)"
+echo "  - bin/reconcile    (Reconciliation Job - run daily)"
+echo "  - bin/income-report (Income Report Job - run yearly, in February)"
+echo "  - bin/cnab         (CNAB 240/400 file validator)"
 echo ""
 echo "🚀 To run the services:"
 echo "  ./bin/account-api"
 echo "  ./bin/transfer-api"
 echo "  ./bin/fee-api"
+echo "  ./bin/notification-worker"
 echo ""
 echo "🐳 To run with Docker:"
 echo "  docker-compose -f deployments/docker-compose.yml up --build"